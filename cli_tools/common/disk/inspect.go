@@ -133,6 +133,12 @@ func (i *bootInspector) assembleErrors(reference string, results *pb.InspectionR
 // validate checks the fields from a pb.InspectionResults object for consistency, returning
 // an error if an issue is found.
 func (i *bootInspector) validate(results *pb.InspectionResults) error {
+	for _, installation := range results.OsInstallations {
+		if installation.RootPartition == "" || installation.OsRelease == nil {
+			return fmt.Errorf("worker returned incomplete OsInstallation: %v", installation)
+		}
+	}
+
 	// Only populate OsRelease when one OS is found.
 	if results.OsCount != 1 {
 		if results.OsRelease != nil {
@@ -154,6 +160,12 @@ func (i *bootInspector) validate(results *pb.InspectionResults) error {
 		return errors.New("worker should not return Distro name, only DistroId")
 	}
 
+	// The worker can't mount ZFS, so FreeBSD systems with a ZFS root
+	// are returned without a version or architecture.
+	if results.OsRelease.DistroId == pb.Distro_FREEBSD && results.OsRelease.MajorVersion == "" {
+		return nil
+	}
+
 	if results.OsRelease.MajorVersion == "" {
 		return errors.New("missing MajorVersion")
 	}
@@ -173,22 +185,37 @@ func (i *bootInspector) validate(results *pb.InspectionResults) error {
 // This is required since the worker is unaware of import-specific idioms, such as the formatting
 // used by gcloud's --os argument.
 func (i *bootInspector) populate(results *pb.InspectionResults) error {
-	if results.ErrorWhen == pb.InspectionResults_NO_ERROR && results.OsCount == 1 {
-		distroEnum, major, minor := results.OsRelease.DistroId,
-			results.OsRelease.MajorVersion, results.OsRelease.MinorVersion
+	if results.ErrorWhen != pb.InspectionResults_NO_ERROR {
+		return nil
+	}
+	if results.OsCount == 1 {
+		i.populateOsRelease(results.OsRelease)
+	}
+	for _, installation := range results.OsInstallations {
+		i.populateOsRelease(installation.OsRelease)
+	}
+	return nil
+}
 
-		distroName := strings.ReplaceAll(strings.ToLower(results.OsRelease.GetDistroId().String()), "_", "-")
+// populateOsRelease fills Distro and CliFormatted using the fields returned by the worker.
+func (i *bootInspector) populateOsRelease(osRelease *pb.OsRelease) {
+	distroEnum, major, minor := osRelease.DistroId,
+		osRelease.MajorVersion, osRelease.MinorVersion
 
-		results.OsRelease.Distro = distroName
-		version, err := distro.FromComponents(distroName, major, minor,
-			results.OsRelease.Architecture.String())
-		if err != nil {
-			i.logger.Trace(
-				fmt.Sprintf("Failed to interpret version distro=%q, major=%q, minor=%q: %v",
-					distroEnum, major, minor, err))
-		} else {
-			results.OsRelease.CliFormatted = version.AsGcloudArg()
-		}
+	distroName := strings.ReplaceAll(strings.ToLower(osRelease.GetDistroId().String()), "_", "-")
+	if distroEnum == pb.Distro_WINDOWS {
+		major = distro.WindowsMajorVersionForBuild(major, int(osRelease.BuildNumber))
+		osRelease.MajorVersion = major
+	}
+
+	osRelease.Distro = distroName
+	version, err := distro.FromComponents(distroName, major, minor,
+		osRelease.Architecture.String())
+	if err != nil {
+		i.logger.Trace(
+			fmt.Sprintf("Failed to interpret version distro=%q, major=%q, minor=%q: %v",
+				distroEnum, major, minor, err))
+	} else {
+		osRelease.CliFormatted = version.AsGcloudArg()
 	}
-	return nil
 }
@@ -138,6 +138,25 @@ func TestBootInspector_Inspect_InvalidWorkerResponses(t *testing.T) {
 			},
 			expectErrorToContain: "worker should return OsRelease when OsCount == 1",
 		},
+		{
+			caseName: "Fail when OsInstallation is missing its root partition",
+			responseFromInspection: &pb.InspectionResults{
+				OsCount: 2,
+				OsInstallations: []*pb.OsInstallation{
+					{RootPartition: "/dev/sda1", OsRelease: &pb.OsRelease{}},
+					{OsRelease: &pb.OsRelease{}},
+				},
+			},
+			expectResults: &pb.InspectionResults{
+				OsCount:   2,
+				ErrorWhen: pb.InspectionResults_INTERPRETING_INSPECTION_RESULTS,
+				OsInstallations: []*pb.OsInstallation{
+					{RootPartition: "/dev/sda1", OsRelease: &pb.OsRelease{}},
+					{OsRelease: &pb.OsRelease{}},
+				},
+			},
+			expectErrorToContain: "worker returned incomplete OsInstallation",
+		},
 		{
 			caseName: "Fail when OsCount > 1 and OsRelease non-nil",
 			responseFromInspection: &pb.InspectionResults{
@@ -276,6 +295,113 @@ func TestBootInspector_Inspect_InvalidWorkerResponses(t *testing.T) {
 	}
 }
 
+func TestBootInspector_Inspect_PopulatesCliFormatted(t *testing.T) {
+	for _, tt := range []struct {
+		caseName          string
+		osRelease         *pb.OsRelease
+		expectMajor       string
+		expectDistro      string
+		expectCliFormated string
+	}{
+		{
+			caseName:          "debian 12",
+			osRelease:         &pb.OsRelease{DistroId: pb.Distro_DEBIAN, MajorVersion: "12", MinorVersion: "5"},
+			expectMajor:       "12",
+			expectDistro:      "debian",
+			expectCliFormated: "debian-12",
+		}, {
+			caseName:          "ubuntu 24.04",
+			osRelease:         &pb.OsRelease{DistroId: pb.Distro_UBUNTU, MajorVersion: "24", MinorVersion: "04"},
+			expectMajor:       "24",
+			expectDistro:      "ubuntu",
+			expectCliFormated: "ubuntu-2404",
+		}, {
+			caseName:          "rhel 10",
+			osRelease:         &pb.OsRelease{DistroId: pb.Distro_RHEL, MajorVersion: "10", MinorVersion: "0"},
+			expectMajor:       "10",
+			expectDistro:      "rhel",
+			expectCliFormated: "rhel-10",
+		}, {
+			caseName:          "rocky 10",
+			osRelease:         &pb.OsRelease{DistroId: pb.Distro_ROCKY, MajorVersion: "10", MinorVersion: "0"},
+			expectMajor:       "10",
+			expectDistro:      "rocky",
+			expectCliFormated: "rocky-10",
+		}, {
+			caseName:          "freebsd 14",
+			osRelease:         &pb.OsRelease{DistroId: pb.Distro_FREEBSD, MajorVersion: "14", MinorVersion: "1"},
+			expectMajor:       "14",
+			expectDistro:      "freebsd",
+			expectCliFormated: "freebsd-14",
+		}, {
+			caseName:          "freebsd without version",
+			osRelease:         &pb.OsRelease{DistroId: pb.Distro_FREEBSD},
+			expectDistro:      "freebsd",
+			expectCliFormated: "",
+		}, {
+			caseName:          "windows 10 by build",
+			osRelease:         &pb.OsRelease{DistroId: pb.Distro_WINDOWS, MajorVersion: "10", BuildNumber: 19045},
+			expectMajor:       "10",
+			expectDistro:      "windows",
+			expectCliFormated: "windows-10-x64",
+		}, {
+			caseName:          "windows 11 by build",
+			osRelease:         &pb.OsRelease{DistroId: pb.Distro_WINDOWS, MajorVersion: "10", BuildNumber: 22631},
+			expectMajor:       "11",
+			expectDistro:      "windows",
+			expectCliFormated: "windows-11-x64",
+		}, {
+			caseName:          "windows server 2025 by build",
+			osRelease:         &pb.OsRelease{DistroId: pb.Distro_WINDOWS, MajorVersion: "2016", BuildNumber: 26100},
+			expectMajor:       "2025",
+			expectDistro:      "windows",
+			expectCliFormated: "windows-2025",
+		},
+	} {
+		t.Run(tt.caseName, func(t *testing.T) {
+			tt.osRelease.Architecture = pb.Architecture_X64
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+			worker := mocks.NewMockDaisyWorker(mockCtrl)
+			worker.EXPECT().RunAndReadSerialValue("inspect_pb", gomock.Any()).Return(
+				encodeToBase64(&pb.InspectionResults{OsCount: 1, OsRelease: tt.osRelease}), nil)
+			inspector := bootInspector{worker, logging.NewToolLogger(t.Name())}
+
+			actual, err := inspector.Inspect("reference")
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectMajor, actual.OsRelease.MajorVersion)
+			assert.Equal(t, tt.expectDistro, actual.OsRelease.Distro)
+			assert.Equal(t, tt.expectCliFormated, actual.OsRelease.CliFormatted)
+		})
+	}
+}
+
+func TestBootInspector_Inspect_PopulatesEachInstallation(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	worker := mocks.NewMockDaisyWorker(mockCtrl)
+	worker.EXPECT().RunAndReadSerialValue("inspect_pb", gomock.Any()).Return(
+		encodeToBase64(&pb.InspectionResults{
+			OsCount: 2,
+			OsInstallations: []*pb.OsInstallation{
+				{RootPartition: "/dev/sda2", OsRelease: &pb.OsRelease{
+					DistroId: pb.Distro_UBUNTU, MajorVersion: "22", MinorVersion: "04", Architecture: pb.Architecture_X64}},
+				{RootPartition: "/dev/sda3", OsRelease: &pb.OsRelease{
+					DistroId: pb.Distro_DEBIAN, MajorVersion: "12", Architecture: pb.Architecture_X64}},
+			},
+		}), nil)
+	inspector := bootInspector{worker, logging.NewToolLogger(t.Name())}
+
+	actual, err := inspector.Inspect("reference")
+	assert.NoError(t, err)
+	assert.Nil(t, actual.OsRelease)
+	assert.Len(t, actual.OsInstallations, 2)
+	assert.Equal(t, "ubuntu", actual.OsInstallations[0].OsRelease.Distro)
+	assert.Equal(t, "ubuntu-2204", actual.OsInstallations[0].OsRelease.CliFormatted)
+	assert.Equal(t, "debian", actual.OsInstallations[1].OsRelease.Distro)
+	assert.Equal(t, "debian-12", actual.OsInstallations[1].OsRelease.CliFormatted)
+}
+
 func TestBootInspector_ForwardsCancelToWorkflow(t *testing.T) {
 	for _, tt := range []struct {
 		name      string
@@ -25,10 +25,14 @@ import (
 )
 
 const (
+	almalinux    = "almalinux"
+	amazon       = "amazon"
 	centosStream = "centos-stream"
 	centos       = "centos"
 	debian       = "debian"
+	freebsd      = "freebsd"
 	opensuse     = "opensuse"
+	oracle       = "oracle"
 	rhel         = "rhel"
 	rocky        = "rocky"
 	sles         = "sles"
@@ -106,8 +110,15 @@ func FromComponents(distro string, major string, minor string, architecture stri
 		return nil, err
 	}
 
-	if standardDistro == windows {
+	switch standardDistro {
+	case windows:
 		return newWindowsRelease(major, minor, standardArch)
+	case freebsd:
+		majorInt, minorInt, err := parseMajorMinor(major, minor)
+		if err != nil {
+			return nil, err
+		}
+		return newFreeBSDRelease(majorInt, minorInt)
 	}
 
 	return newLinuxRelease(standardDistro, major, minor)
@@ -141,7 +152,7 @@ func standardizeDistro(distro string) (string, error) {
 		return "", errors.New("distro name required")
 	}
 	d := strings.ReplaceAll(strings.ToLower(distro), "_", "-")
-	for _, known := range []string{centosStream, centos, debian, opensuse, rhel, rocky, slesSAP, sles, ubuntu, windows} {
+	for _, known := range []string{almalinux, amazon, centosStream, centos, debian, freebsd, opensuse, oracle, rhel, rocky, slesSAP, sles, ubuntu, windows} {
 		if strings.Contains(d, known) {
 			return known, nil
 		}
@@ -149,25 +160,37 @@ func standardizeDistro(distro string) (string, error) {
 	return "", fmt.Errorf("Unrecognized distro `%s`", distro)
 }
 
-func newLinuxRelease(distro string, major string, minor string) (Release, error) {
-	majorInt, e := strconv.Atoi(major)
+// parseMajorMinor parses the integer versions used by Linux and FreeBSD releases.
+// An empty minor version is interpreted as zero.
+func parseMajorMinor(major string, minor string) (majorInt, minorInt int, e error) {
+	majorInt, e = strconv.Atoi(major)
 	if e != nil || majorInt < 1 {
-		return nil, fmt.Errorf(
+		return 0, 0, fmt.Errorf(
 			"major version required to be an integer greater than zero. Received: `%s`", major)
 	}
-	var minorInt int
 	if minor == "" {
-		minorInt = 0
-	} else {
-		minorInt, e = strconv.Atoi(minor)
-		if e != nil || minorInt < 0 {
-			return nil, errors.New(
-				"minor version required to be an integer greater than or equal to zero. Received: " + minor)
-		}
+		return majorInt, 0, nil
+	}
+	minorInt, e = strconv.Atoi(minor)
+	if e != nil || minorInt < 0 {
+		return 0, 0, errors.New(
+			"minor version required to be an integer greater than or equal to zero. Received: " + minor)
+	}
+	return majorInt, minorInt, nil
+}
+
+func newLinuxRelease(distro string, major string, minor string) (Release, error) {
+	majorInt, minorInt, e := parseMajorMinor(major, minor)
+	if e != nil {
+		return nil, e
 	}
 	switch distro {
 	case ubuntu:
 		return newUbuntuRelease(majorInt, minorInt)
+	case almalinux:
+		fallthrough
+	case amazon:
+		fallthrough
 	case centos:
 		fallthrough
 	case centosStream:
@@ -176,6 +199,8 @@ func newLinuxRelease(distro string, major string, minor string) (Release, error)
 		fallthrough
 	case opensuse:
 		fallthrough
+	case oracle:
+		fallthrough
 	case rhel:
 		fallthrough
 	case rocky:
@@ -259,7 +284,7 @@ func (r commonLinuxRelease) ImportCompatible(other Release) bool {
 }
 
 func commonLinuxDistros() []string {
-	return []string{centosStream, centos, debian, opensuse, rhel, rocky}
+	return []string{almalinux, amazon, centosStream, centos, debian, opensuse, oracle, rhel, rocky}
 }
 
 // The caller is responsible for verifying the syntax of the arguments.
@@ -277,6 +302,29 @@ func newCommonLinuxRelease(distro string, major, minor int) (Release, error) {
 	}, nil
 }
 
+// freebsdRelease is a Release for FreeBSD. Releases are compatible when they
+// share a major version, since minor versions of FreeBSD keep the same ABI.
+type freebsdRelease struct {
+	major int
+	minor int
+}
+
+func (r freebsdRelease) AsGcloudArg() string {
+	return fmt.Sprintf("%s-%d", freebsd, r.major)
+}
+
+func (r freebsdRelease) ImportCompatible(other Release) bool {
+	realOther, ok := other.(freebsdRelease)
+	return ok && r.major == realOther.major
+}
+
+// The caller is responsible for verifying that major is >= 1 and minor is >= 0.
+func newFreeBSDRelease(major, minor int) (Release, error) {
+	assert.GreaterThanOrEqualTo(major, 1)
+	assert.GreaterThanOrEqualTo(minor, 0)
+	return freebsdRelease{major: major, minor: minor}, nil
+}
+
 // windowsRelease uses marketing versions rather than NT versions.
 // Currently the only minor version is "r2". For example, the versions
 // 2012 and 2012r2 and *not* import compatible, and have different
@@ -341,7 +389,7 @@ func WindowsServerVersionforNTVersion(major string, minor string) (marketingMajo
 		{"6", "1", "2008", "r2"},
 		{"6", "2", "2012", ""},
 		{"6", "3", "2012", "r2"},
-		{"10", "0", "2016", ""}, // NT 10.0 is also 2019 & 2022
+		{"10", "0", "2016", ""}, // NT 10.0 is also 2019, 2022 & 2025
 	} {
 		if major == t.ntMajor && minor == t.ntMinor {
 			return t.marketingMajor, t.marketingMinor, nil
@@ -350,6 +398,49 @@ func WindowsServerVersionforNTVersion(major string, minor string) (marketingMajo
 	return "", "", fmt.Errorf("`%s.%s` is not a recognized Windows NT version", major, minor)
 }
 
+// Windows releases that share NT version 10.0, along with the first build of each,
+// sorted by descending build. Source: https://wikipedia.org/wiki/List_of_Microsoft_Windows_versions
+var (
+	windowsNT10ClientBuilds = []struct {
+		firstBuild int
+		major      string
+	}{
+		{22000, "11"},
+		{10240, "10"},
+	}
+	windowsNT10ServerBuilds = []struct {
+		firstBuild int
+		major      string
+	}{
+		{26100, "2025"},
+		{20348, "2022"},
+		{17763, "2019"},
+		{14393, "2016"},
+	}
+)
+
+// WindowsMajorVersionForBuild uses a build number to disambiguate Windows releases that share
+// NT version 10.0. For example, Windows 11 reports itself as Windows 10, but its builds start
+// at 22000. major is the marketing version determined by inspection, such as "10" or "2016".
+//
+// major is returned unchanged when build is zero, or when major isn't an NT 10.0 release.
+func WindowsMajorVersionForBuild(major string, build int) string {
+	builds := windowsNT10ServerBuilds
+	switch major {
+	case "10", "11":
+		builds = windowsNT10ClientBuilds
+	case "2016", "2019", "2022", "2025":
+	default:
+		return major
+	}
+	for _, b := range builds {
+		if build >= b.firstBuild {
+			return b.major
+		}
+	}
+	return major
+}
+
 // slesRelease is a Release that represents the SLES distro and its variants (such as SLES for SAP).
 // Compatibility requires the same variant and major version.
 type slesRelease struct {
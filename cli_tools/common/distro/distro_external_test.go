@@ -0,0 +1,51 @@
+//  Copyright 2026 Google Inc. All Rights Reserved.
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+package distro_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/GoogleCloudPlatform/compute-image-import/cli_tools/common/distro"
+	"github.com/GoogleCloudPlatform/compute-image-import/cli_tools/common/utils/daisyutils"
+)
+
+// daisyutils validates its translation settings using this package, so tests that
+// iterate over them are in an external test package to avoid an import cycle.
+
+func TestFromGcloudOSArgument_HappyCases(t *testing.T) {
+	for _, osID := range daisyutils.GetSortedOSIDs() {
+		t.Run(osID, func(t *testing.T) {
+			d, e := distro.FromGcloudOSArgument(osID)
+			assert.NoError(t, e)
+			var expected string
+			if osID == "windows-8-1-x64-byol" {
+				// windows-8-1-x64-byol is a legacy flag value, and it's the only value that
+				// includes an extra hyphen between its major and minor version. The non-legacy
+				// flag is windows-8-x64-byol.
+				expected = "windows-8-x64"
+			} else if strings.HasSuffix(osID, "-byol") {
+				// The Release interface is orthogonal to license, so
+				// its AsGcloudArg doesn't include license info.
+				expected = osID[:len(osID)-5]
+			} else {
+				expected = osID
+			}
+			assert.Equal(t, expected, d.AsGcloudArg())
+		})
+	}
+}
@@ -16,38 +16,11 @@ package distro
 
 import (
 	"fmt"
-	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
-
-	"github.com/GoogleCloudPlatform/compute-image-import/cli_tools/common/utils/daisyutils"
 )
 
-func TestFromGcloudOSArgument_HappyCases(t *testing.T) {
-	daisyutils.GetSortedOSIDs()
-	for _, osID := range daisyutils.GetSortedOSIDs() {
-		t.Run(osID, func(t *testing.T) {
-			d, e := FromGcloudOSArgument(osID)
-			assert.NoError(t, e)
-			var expected string
-			if osID == "windows-8-1-x64-byol" {
-				// windows-8-1-x64-byol is a legacy flag value, and it's the only value that
-				// includes an extra hyphen between its major and minor version. The non-legacy
-				// flag is windows-8-x64-byol.
-				expected = "windows-8-x64"
-			} else if strings.HasSuffix(osID, "-byol") {
-				// The Release interface is orthogonal to license, so
-				// its AsGcloudArg doesn't include license info.
-				expected = osID[:len(osID)-5]
-			} else {
-				expected = osID
-			}
-			assert.Equal(t, expected, d.AsGcloudArg())
-		})
-	}
-}
-
 func TestFromGcloudOSArgument_DistroNameErrors(t *testing.T) {
 	var cases = []struct {
 		in  string
@@ -135,6 +108,13 @@ func TestDistroFromComponents_HappyCasesLinux(t *testing.T) {
 		{"rhel", "6", "", "rhel-6"},
 		{"rhel", "8", "2", "rhel-8"},
 		{"rocky", "8", "4", "rocky-8"},
+		{"almalinux", "9", "4", "almalinux-9"},
+		{"oracle", "8", "10", "oracle-8"},
+		{"oraclelinux", "9", "", "oracle-9"},
+		{"amazon", "2", "", "amazon-2"},
+		{"amazon", "2023", "", "amazon-2023"},
+		{"freebsd", "13", "", "freebsd-13"},
+		{"freebsd", "14", "1", "freebsd-14"},
 		{"ubuntu", "14", "04", "ubuntu-1404"},
 		{"ubuntu", "14", "10", "ubuntu-1410"},
 	}
@@ -218,6 +198,31 @@ func TestWindowsServerVersionforNTVersion(t *testing.T) {
 	}
 }
 
+func TestWindowsMajorVersionForBuild(t *testing.T) {
+	var cases = []struct {
+		major         string
+		build         int
+		expectedMajor string
+	}{
+		{major: "10", build: 0, expectedMajor: "10"},
+		{major: "10", build: 19045, expectedMajor: "10"},
+		{major: "10", build: 22000, expectedMajor: "11"},
+		{major: "10", build: 22631, expectedMajor: "11"},
+		{major: "11", build: 19045, expectedMajor: "10"},
+		{major: "2016", build: 0, expectedMajor: "2016"},
+		{major: "2016", build: 14393, expectedMajor: "2016"},
+		{major: "2016", build: 17763, expectedMajor: "2019"},
+		{major: "2016", build: 20348, expectedMajor: "2022"},
+		{major: "2016", build: 26100, expectedMajor: "2025"},
+		{major: "2012", build: 9600, expectedMajor: "2012"},
+	}
+	for _, tt := range cases {
+		t.Run(fmt.Sprintf("%s-%d", tt.major, tt.build), func(t *testing.T) {
+			assert.Equal(t, tt.expectedMajor, WindowsMajorVersionForBuild(tt.major, tt.build))
+		})
+	}
+}
+
 func TestDistroFromComponents_ArchitectureValidation(t *testing.T) {
 	var cases = []struct {
 		inputArch, expectedArch, expectErrorToContain string
@@ -301,12 +306,14 @@ func TestDistroFromComponents_LinuxVersionErrors(t *testing.T) {
 			err:   "minor version required to be an integer greater than or equal to zero. Received: 1a",
 		},
 	}
-	for _, tt := range cases {
-		t.Run(tt.name, func(t *testing.T) {
-			actual, err := FromComponents("centos", tt.major, tt.minor, "")
-			assert.Nil(t, actual)
-			assert.EqualError(t, err, tt.err)
-		})
+	for _, distro := range []string{"centos", "freebsd"} {
+		for _, tt := range cases {
+			t.Run(distro+" "+tt.name, func(t *testing.T) {
+				actual, err := FromComponents(distro, tt.major, tt.minor, "")
+				assert.Nil(t, actual)
+				assert.EqualError(t, err, tt.err)
+			})
+		}
 	}
 }
 
@@ -377,6 +384,36 @@ func TestImportCompatible(t *testing.T) {
 		fromID("rhel-8-byol"),
 		fromComponents("rhel", "8"),
 		fromComponents("rhel", "8", "1"),
+	}, {
+		fromID("almalinux-8"),
+		fromComponents("almalinux", "8"),
+		fromComponents("almalinux", "8", "9"),
+	}, {
+		fromID("almalinux-9"),
+		fromComponents("almalinux", "9"),
+		fromComponents("almalinux", "9", "4"),
+	}, {
+		fromID("oracle-8"),
+		fromComponents("oracle", "8"),
+		fromComponents("oracle", "8", "10"),
+	}, {
+		fromID("oracle-9"),
+		fromComponents("oracle", "9"),
+		fromComponents("oracle", "9", "4"),
+	}, {
+		fromID("amazon-2"),
+		fromComponents("amazon", "2"),
+	}, {
+		fromID("amazon-2023"),
+		fromComponents("amazon", "2023"),
+	}, {
+		fromID("freebsd-13"),
+		fromComponents("freebsd", "13"),
+		fromComponents("freebsd", "13", "3"),
+	}, {
+		fromID("freebsd-14"),
+		fromComponents("freebsd", "14"),
+		fromComponents("freebsd", "14", "1"),
 	}, {
 		fromID("debian-7"),
 		fromComponents("debian", "7"),
@@ -110,6 +110,7 @@ type ScratchBucketCreatorInterface interface {
 type ResourceLocationRetrieverInterface interface {
 	GetZone(storageRegion string, project string) (string, error)
 	GetLargestStorageLocation(storageLocation string) string
+	GetZonesInRegion(region string, project string) ([]string, error)
 }
 
 // HTTPClientInterface represents HTTP client
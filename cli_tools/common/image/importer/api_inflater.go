@@ -62,6 +62,7 @@ type apiInflaterProperties struct {
 	request          ImageImportRequest
 	computeClient    daisyCompute.Client
 	storageClient    domain.StorageClientInterface
+	workflowClients  daisyutils.WorkflowClients
 	logger           logging.Logger
 	isShadowInflater bool
 	needChecksum     bool
@@ -225,6 +226,7 @@ func (inflater *apiInflater) calculateChecksum(diskURI string) (string, error) {
 	}
 
 	env := inflater.request.EnvironmentSettings()
+	env.WorkflowClients = inflater.workflowClients
 	if env.DaisyLogLinePrefix != "" {
 		env.DaisyLogLinePrefix += "-"
 	}
@@ -233,7 +235,7 @@ func (inflater *apiInflater) calculateChecksum(diskURI string) (string, error) {
 		return inflater.getCalculateChecksumWorkflow(diskURI, daisyPrefix), nil
 	}, env, inflater.logger)
 	checksum, err := worker.RunAndReadSerialValue("disk-checksum", map[string]string{})
-	if err != nil {
+	if err != nil && !daisyutils.IsWorkflowNotRun(err) {
 		err = daisy.Errf("Failed to calculate checksum: %v", err)
 	}
 	return checksum, err
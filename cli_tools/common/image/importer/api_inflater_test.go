@@ -23,6 +23,7 @@ import (
 	"google.golang.org/api/compute/v1"
 	"google.golang.org/api/googleapi"
 
+	"github.com/GoogleCloudPlatform/compute-image-import/cli_tools/common/utils/daisyutils"
 	"github.com/GoogleCloudPlatform/compute-image-import/cli_tools/common/utils/logging"
 	"github.com/GoogleCloudPlatform/compute-image-import/cli_tools/common/utils/storage"
 	"github.com/GoogleCloudPlatform/compute-image-import/cli_tools/mocks"
@@ -34,7 +35,7 @@ func TestCreateAPIInflater_IncludesUEFIGuestOSFeature(t *testing.T) {
 	request := ImageImportRequest{
 		UefiCompatible: true,
 	}
-	apiInflater := createAPIInflater(&apiInflaterProperties{request, nil, &storage.Client{}, logging.NewToolLogger(t.Name()), true, true})
+	apiInflater := createAPIInflater(&apiInflaterProperties{request, nil, &storage.Client{}, daisyutils.WorkflowClients{}, logging.NewToolLogger(t.Name()), true, true})
 	assert.Contains(t, apiInflater.guestOsFeatures,
 		&compute.GuestOsFeature{Type: "UEFI_COMPATIBLE"})
 }
@@ -57,7 +58,7 @@ func TestAPIInflater_ShadowInflate_CreateDiskFailed_CancelWithoutDeleteDisk(t *t
 		ExecutionID:  "1234",
 		NoExternalIP: false,
 		WorkflowDir:  daisyWorkflows,
-	}, mockComputeClient, &storage.Client{}, mockLogger, true, true})
+	}, mockComputeClient, &storage.Client{}, daisyutils.WorkflowClients{}, mockLogger, true, true})
 
 	// Send a cancel signal in prior to guarantee cancellation logic can be executed.
 	cancelResult := apiInflater.Cancel("cancel")
@@ -86,7 +87,7 @@ func TestAPIInflater_ShadowInflate_CreateDiskSuccess_CancelWithDeleteDisk(t *tes
 		ExecutionID:  "1234",
 		NoExternalIP: false,
 		WorkflowDir:  daisyWorkflows,
-	}, mockComputeClient, &storage.Client{}, mockLogger, true, true})
+	}, mockComputeClient, &storage.Client{}, daisyutils.WorkflowClients{}, mockLogger, true, true})
 
 	// Send a cancel signal in prior to guarantee cancellation logic can be executed.
 	cancelResult := apiInflater.Cancel("cancel")
@@ -113,7 +114,7 @@ func TestAPIInflater_ShadowInflate_Cancel_CleanupFailedToVerify(t *testing.T) {
 		ExecutionID:  "1234",
 		NoExternalIP: false,
 		WorkflowDir:  daisyWorkflows,
-	}, mockComputeClient, &storage.Client{}, mockLogger, true, true})
+	}, mockComputeClient, &storage.Client{}, daisyutils.WorkflowClients{}, mockLogger, true, true})
 
 	cancelResult := apiInflater.Cancel("cancel")
 	assert.False(t, cancelResult)
@@ -136,7 +137,7 @@ func TestAPIInflater_ShadowInflate_Cancel_CleanupFailed(t *testing.T) {
 		ExecutionID:  "1234",
 		NoExternalIP: false,
 		WorkflowDir:  daisyWorkflows,
-	}, mockComputeClient, &storage.Client{}, mockLogger, true, true})
+	}, mockComputeClient, &storage.Client{}, daisyutils.WorkflowClients{}, mockLogger, true, true})
 
 	cancelResult := apiInflater.Cancel("cancel")
 	assert.False(t, cancelResult)
@@ -151,7 +152,7 @@ func TestAPIInflater_getCalculateChecksumWorkflow(t *testing.T) {
 		ExecutionID:  "1234",
 		NoExternalIP: false,
 		WorkflowDir:  daisyWorkflows,
-	}, nil, &storage.Client{}, logging.NewToolLogger(t.Name()), true, true})
+	}, nil, &storage.Client{}, daisyutils.WorkflowClients{}, logging.NewToolLogger(t.Name()), true, true})
 
 	w := apiInflater.getCalculateChecksumWorkflow("", "shadow")
 	assert.Equal(t, "default", w.Vars["compute_service_account"].Value)
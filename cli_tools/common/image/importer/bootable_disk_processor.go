@@ -52,7 +52,8 @@ func (b *bootableDiskProcessor) cancel(reason string) bool {
 	return true
 }
 
-func newBootableDiskProcessor(request ImageImportRequest, wfPath string, logger logging.Logger, detectedOs distro.Release) processor {
+func newBootableDiskProcessor(request ImageImportRequest, workflowClients daisyutils.WorkflowClients, wfPath string,
+	logger logging.Logger, detectedOs distro.Release, rootDevice string) processor {
 	vars := map[string]string{
 		"image_name":           request.ImageName,
 		"install_gce_packages": strconv.FormatBool(!request.NoGuestEnvironment),
@@ -76,11 +77,13 @@ func newBootableDiskProcessor(request ImageImportRequest, wfPath string, logger
 			return nil, err
 		}
 		updateWorkflowWithDataDisks(wf, request)
+		updateWorkflowWithRootDevice(wf, rootDevice)
 
 		return wf, err
 	}
 
 	env := request.EnvironmentSettings()
+	env.WorkflowClients = workflowClients
 	if env.DaisyLogLinePrefix != "" {
 		env.DaisyLogLinePrefix += "-"
 	}
@@ -111,6 +114,28 @@ func updateWorkflowWithDataDisks(wf *daisy.Workflow, request ImageImportRequest)
 	updateLinuxWorkflowWithDataDisks(wf, request)
 }
 
+// updateWorkflowWithRootDevice tells the translation worker which root filesystem to
+// translate, for disks with multiple operating systems. It's currently supported for
+// Linux only; the planner rejects a root device for Windows.
+func updateWorkflowWithRootDevice(wf *daisy.Workflow, rootDevice string) {
+	if rootDevice == "" || isWindowsWorkflow(wf) {
+		return
+	}
+
+	var instance *daisy.Instance
+	if wf.Steps["translate-disk"] != nil {
+		instance = wf.Steps["translate-disk"].IncludeWorkflow.Workflow.Steps["translate-disk-inst"].CreateInstances.Instances[0]
+	} else if wf.Steps["translate-disk-inst"] != nil {
+		instance = wf.Steps["translate-disk-inst"].CreateInstances.Instances[0]
+	} else {
+		return
+	}
+	if instance.Metadata == nil {
+		instance.Metadata = map[string]string{}
+	}
+	instance.Metadata["root_device"] = rootDevice
+}
+
 func isWindowsWorkflow(wf *daisy.Workflow) bool {
 	return strings.Contains(strings.ToLower(wf.Name), "windows")
 }
@@ -100,8 +100,8 @@ func TestBootableDiskProcessor_SupportsNoExternalIPForWorker(t *testing.T) {
 func TestBootableDiskProcessor_SetsWorkflowNameToGcloudPrefix(t *testing.T) {
 	args := defaultImportArgs()
 	args.DaisyLogLinePrefix = "disk-1"
-	processor := newBootableDiskProcessor(args, opensuse15workflow, logging.NewToolLogger(t.Name()),
-		distro.FromGcloudOSArgumentMustParse("windows-2008r2"))
+	processor := newBootableDiskProcessor(args, daisyutils.WorkflowClients{}, opensuse15workflow, logging.NewToolLogger(t.Name()),
+		distro.FromGcloudOSArgumentMustParse("windows-2008r2"), "")
 
 	daisyutils.CheckEnvironment((processor.(*bootableDiskProcessor)).worker, func(env daisyutils.EnvironmentSettings) {
 		assert.Equal(t, "disk-1-translate", env.DaisyLogLinePrefix)
@@ -243,8 +243,8 @@ func TestBootableDiskProcessor_PermitsUnsetStorageLocation(t *testing.T) {
 
 func TestBootableDiskProcessor_SupportsCancel(t *testing.T) {
 	args := defaultImportArgs()
-	processor := newBootableDiskProcessor(args, opensuse15workflow, logging.NewToolLogger(t.Name()),
-		distro.FromGcloudOSArgumentMustParse("windows-2008r2"))
+	processor := newBootableDiskProcessor(args, daisyutils.WorkflowClients{}, opensuse15workflow, logging.NewToolLogger(t.Name()),
+		distro.FromGcloudOSArgumentMustParse("windows-2008r2"), "")
 
 	realProcessor := processor.(*bootableDiskProcessor)
 	realProcessor.cancel("timed-out")
@@ -263,8 +263,8 @@ func TestBootableDiskProcessor_AttachDataDisksWithEnterpriseLinux(t *testing.T)
 		args.DataDisks = append(args.DataDisks, disk)
 	}
 
-	processor := newBootableDiskProcessor(args, centos7workflow, logging.NewToolLogger(t.Name()),
-		distro.FromGcloudOSArgumentMustParse("centos-7"))
+	processor := newBootableDiskProcessor(args, daisyutils.WorkflowClients{}, centos7workflow, logging.NewToolLogger(t.Name()),
+		distro.FromGcloudOSArgumentMustParse("centos-7"), "")
 
 	realProcessor := processor.(*bootableDiskProcessor)
 
@@ -292,8 +292,8 @@ func TestBootableDiskProcessor_AttachDataDisksWithLinux(t *testing.T) {
 		args.DataDisks = append(args.DataDisks, disk)
 	}
 
-	processor := newBootableDiskProcessor(args, ubuntu1804workflow, logging.NewToolLogger(t.Name()),
-		distro.FromGcloudOSArgumentMustParse("ubuntu-1804"))
+	processor := newBootableDiskProcessor(args, daisyutils.WorkflowClients{}, ubuntu1804workflow, logging.NewToolLogger(t.Name()),
+		distro.FromGcloudOSArgumentMustParse("ubuntu-1804"), "")
 
 	realProcessor := processor.(*bootableDiskProcessor)
 
@@ -306,6 +306,46 @@ func TestBootableDiskProcessor_AttachDataDisksWithLinux(t *testing.T) {
 	})
 }
 
+func TestBootableDiskProcessor_PassesRootDeviceToTranslation(t *testing.T) {
+	for _, tt := range []struct {
+		name       string
+		wfPath     string
+		instanceOf func(wf *daisy.Workflow) *daisy.Instance
+	}{
+		{"ubuntu", ubuntu1804workflow, func(wf *daisy.Workflow) *daisy.Instance {
+			return wf.Steps["translate-disk"].IncludeWorkflow.Workflow.Steps["translate-disk-inst"].CreateInstances.Instances[0]
+		}},
+		{"enterprise linux", centos7workflow, func(wf *daisy.Workflow) *daisy.Instance {
+			return wf.Steps["translate-disk"].IncludeWorkflow.Workflow.Steps["translate-disk-inst"].CreateInstances.Instances[0]
+		}},
+		{"without internal workflow", opensuse15workflow, func(wf *daisy.Workflow) *daisy.Instance {
+			return wf.Steps["translate-disk-inst"].CreateInstances.Instances[0]
+		}},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			processor := newBootableDiskProcessor(defaultImportArgs(), daisyutils.WorkflowClients{}, tt.wfPath, logging.NewToolLogger(t.Name()),
+				nil, "/dev/sda3")
+
+			realProcessor := processor.(*bootableDiskProcessor)
+			daisyutils.CheckWorkflow(realProcessor.worker, func(wf *daisy.Workflow, err error) {
+				assert.NoError(t, err)
+				assert.Equal(t, "/dev/sda3", tt.instanceOf(wf).Metadata["root_device"])
+			})
+		})
+	}
+}
+
+func TestBootableDiskProcessor_OmitsRootDeviceWhenEmpty(t *testing.T) {
+	processor := newBootableDiskProcessor(defaultImportArgs(), daisyutils.WorkflowClients{}, ubuntu1804workflow, logging.NewToolLogger(t.Name()),
+		nil, "")
+
+	realProcessor := processor.(*bootableDiskProcessor)
+	daisyutils.CheckWorkflow(realProcessor.worker, func(wf *daisy.Workflow, err error) {
+		instance := wf.Steps["translate-disk"].IncludeWorkflow.Workflow.Steps["translate-disk-inst"].CreateInstances.Instances[0]
+		assert.NotContains(t, instance.Metadata, "root_device")
+	})
+}
+
 func TestBootableDiskProcessor_AttachDataDisksWithWindows(t *testing.T) {
 	args := defaultImportArgs()
 
@@ -316,8 +356,8 @@ func TestBootableDiskProcessor_AttachDataDisksWithWindows(t *testing.T) {
 		args.DataDisks = append(args.DataDisks, disk)
 	}
 
-	processor := newBootableDiskProcessor(args, windows2019workflow, logging.NewToolLogger(t.Name()),
-		distro.FromGcloudOSArgumentMustParse("windows-2019"))
+	processor := newBootableDiskProcessor(args, daisyutils.WorkflowClients{}, windows2019workflow, logging.NewToolLogger(t.Name()),
+		distro.FromGcloudOSArgumentMustParse("windows-2019"), "")
 
 	realProcessor := processor.(*bootableDiskProcessor)
 
@@ -337,8 +377,8 @@ func TestBootableDiskProcessor_AttachDataDisksWithoutInternalWorkflow(t *testing
 		args.DataDisks = append(args.DataDisks, disk)
 	}
 
-	processor := newBootableDiskProcessor(args, opensuse15workflow, logging.NewToolLogger(t.Name()),
-		distro.FromGcloudOSArgumentMustParse("opensuse-15"))
+	processor := newBootableDiskProcessor(args, daisyutils.WorkflowClients{}, opensuse15workflow, logging.NewToolLogger(t.Name()),
+		distro.FromGcloudOSArgumentMustParse("opensuse-15"), "")
 
 	realProcessor := processor.(*bootableDiskProcessor)
 
@@ -352,8 +392,8 @@ func TestBootableDiskProcessor_AttachDataDisksWithoutInternalWorkflow(t *testing
 }
 
 func createProcessor(t *testing.T, request ImageImportRequest) *bootableDiskProcessor {
-	processor := newBootableDiskProcessor(request, opensuse15workflow, logging.NewToolLogger(t.Name()),
-		distro.FromGcloudOSArgumentMustParse("windows-2008r2"))
+	processor := newBootableDiskProcessor(request, daisyutils.WorkflowClients{}, opensuse15workflow, logging.NewToolLogger(t.Name()),
+		distro.FromGcloudOSArgumentMustParse("windows-2008r2"), "")
 	realTranslator := processor.(*bootableDiskProcessor)
 	// A concrete logger is required since the import/export logging framework writes a log entry
 	// when the workflow starts. Without this there's a panic.
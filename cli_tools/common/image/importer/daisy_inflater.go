@@ -55,6 +55,10 @@ type daisyInflater struct {
 	source          Source
 	inflatedDiskURI string
 	logger          logging.Logger
+
+	// workflow is the most recently run inflation workflow. Its zone differs from
+	// the request's zone when the workflow failed over to another zone.
+	workflow *daisy.Workflow
 }
 
 func (inflater *daisyInflater) Inflate() (persistentDisk, inflationInfo, error) {
@@ -67,24 +71,30 @@ func (inflater *daisyInflater) Inflate() (persistentDisk, inflationInfo, error)
 	if err == nil {
 		inflater.logger.User("Finished creating Google Compute Engine disk")
 	}
+	uri := inflater.inflatedDiskURI
+	if inflater.workflow != nil && inflater.workflow.Zone != "" {
+		uri = fmt.Sprintf("zones/%s/disks/%s", inflater.workflow.Zone, daisyutils.GetResourceID(uri))
+	}
 	return persistentDisk{
-			uri:        inflater.inflatedDiskURI,
-			sizeGb:     enforceMinimumDiskSize(string_utils.SafeStringToInt(serialValues[targetSizeGBKey])),
-			sourceGb:   string_utils.SafeStringToInt(serialValues[sourceSizeGBKey]),
-			sourceType: serialValues[importFileFormatKey],
-		}, inflationInfo{
-			checksum:      serialValues[diskChecksumKey],
-			inflationTime: time.Since(startTime),
-			inflationType: "qemu",
-		}, err
+		uri:        uri,
+		sizeGb:     enforceMinimumDiskSize(string_utils.SafeStringToInt(serialValues[targetSizeGBKey])),
+		sourceGb:   string_utils.SafeStringToInt(serialValues[sourceSizeGBKey]),
+		sourceType: serialValues[importFileFormatKey],
+	}, inflationInfo{
+		checksum:      serialValues[diskChecksumKey],
+		inflationTime: time.Since(startTime),
+		inflationType: "qemu",
+	}, err
 }
 
 // NewDaisyInflater returns an inflater that uses a Daisy workflow.
-func NewDaisyInflater(request ImageImportRequest, fileMetadata imagefile.Metadata, logger logging.Logger) (Inflater, error) {
-	return newDaisyInflater(request, fileMetadata, logger)
+func NewDaisyInflater(request ImageImportRequest, workflowClients daisyutils.WorkflowClients, fileMetadata imagefile.Metadata,
+	logger logging.Logger) (Inflater, error) {
+	return newDaisyInflater(request, workflowClients, fileMetadata, logger)
 }
 
-func newDaisyInflater(request ImageImportRequest, fileMetadata imagefile.Metadata, logger logging.Logger) (*daisyInflater, error) {
+func newDaisyInflater(request ImageImportRequest, workflowClients daisyutils.WorkflowClients, fileMetadata imagefile.Metadata,
+	logger logging.Logger) (*daisyInflater, error) {
 	diskName := getDiskName(request.ExecutionID)
 
 	var wfPath string
@@ -104,6 +114,12 @@ func newDaisyInflater(request ImageImportRequest, fileMetadata imagefile.Metadat
 		inflationDiskIndex = 1 // First disk is for the worker
 	}
 
+	inflater := &daisyInflater{
+		inflatedDiskURI: fmt.Sprintf("zones/%s/disks/%s", request.Zone, diskName),
+		logger:          logger,
+		source:          request.Source,
+		vars:            vars,
+	}
 	workflowProvider := func() (*daisy.Workflow, error) {
 		wf, err := daisyutils.ParseWorkflow(path.Join(request.WorkflowDir, wfPath), vars,
 			request.Project, request.Zone, request.ScratchBucketGcsPath, request.Oauth, request.Timeout.String(),
@@ -121,21 +137,18 @@ func newDaisyInflater(request ImageImportRequest, fileMetadata imagefile.Metadat
 		if strings.Contains(request.OS, "windows") {
 			addFeatureToDisk(wf, "WINDOWS", inflationDiskIndex)
 		}
+		inflater.workflow = wf
 		return wf, err
 	}
 
 	env := request.EnvironmentSettings()
+	env.WorkflowClients = workflowClients
 	if env.DaisyLogLinePrefix != "" {
 		env.DaisyLogLinePrefix += "-"
 	}
 	env.DaisyLogLinePrefix += "inflate"
-	return &daisyInflater{
-		worker:          daisyutils.NewDaisyWorker(workflowProvider, env, logger),
-		inflatedDiskURI: fmt.Sprintf("zones/%s/disks/%s", request.Zone, diskName),
-		logger:          logger,
-		source:          request.Source,
-		vars:            vars,
-	}, nil
+	inflater.worker = daisyutils.NewDaisyWorker(workflowProvider, env, logger)
+	return inflater, nil
 }
 
 // addFeatureToDisk finds the first `CreateDisk` step, and adds `feature` as
@@ -39,7 +39,7 @@ func TestDaisyInflater_Inflate_ReadsDiskStatsFromWorker(t *testing.T) {
 
 	mockWorker := mocks.NewMockDaisyWorker(ctrl)
 	inflater := daisyInflater{
-		mockWorker, map[string]string{}, nil, "/disk/uri", logging.NewToolLogger("test"),
+		mockWorker, map[string]string{}, nil, "/disk/uri", logging.NewToolLogger("test"), nil,
 	}
 	mockWorker.EXPECT().RunAndReadSerialValues(inflater.vars, targetSizeGBKey,
 		sourceSizeGBKey, importFileFormatKey, diskChecksumKey).DoAndReturn(
@@ -68,7 +68,7 @@ func TestDaisyInflater_Inflate_IncludesDiskStatsOnError(t *testing.T) {
 
 	mockWorker := mocks.NewMockDaisyWorker(ctrl)
 	inflater := daisyInflater{
-		mockWorker, map[string]string{}, nil, "/disk/uri", logging.NewToolLogger("test"),
+		mockWorker, map[string]string{}, nil, "/disk/uri", logging.NewToolLogger("test"), nil,
 	}
 	mockWorker.EXPECT().RunAndReadSerialValues(inflater.vars, targetSizeGBKey,
 		sourceSizeGBKey, importFileFormatKey, diskChecksumKey).Return(map[string]string{
@@ -84,6 +84,24 @@ func TestDaisyInflater_Inflate_IncludesDiskStatsOnError(t *testing.T) {
 	assert.Equal(t, inflationInfo{checksum: "9abc", inflationType: "qemu"}, shadowFields)
 }
 
+func TestDaisyInflater_Inflate_UsesZoneOfWorkflowAfterZoneFailover(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockWorker := mocks.NewMockDaisyWorker(ctrl)
+	wf := daisy.New()
+	wf.Zone = "us-west1-b"
+	inflater := daisyInflater{
+		mockWorker, map[string]string{}, nil, "zones/us-west1-a/disks/disk-1234", logging.NewToolLogger("test"), wf,
+	}
+	mockWorker.EXPECT().RunAndReadSerialValues(inflater.vars, targetSizeGBKey,
+		sourceSizeGBKey, importFileFormatKey, diskChecksumKey).Return(map[string]string{}, nil)
+	pDisk, _, err := inflater.Inflate()
+	assert.NoError(t, err)
+	assert.Equal(t, "zones/us-west1-b/disks/disk-1234", pDisk.uri)
+	assert.Equal(t, "us-west1-b", pDisk.zone("us-west1-a"))
+}
+
 // gcloud expects log lines to start with the substring "[import". Daisy
 // constructs the log prefix using the workflow's name.
 func TestCreateDaisyInflater_SetsWorkflowNameToGcloudPrefix(t *testing.T) {
@@ -370,7 +388,7 @@ func createDaisyInflaterSafe(t *testing.T, request ImageImportRequest,
 	}
 
 	request.WorkflowDir = "../../../../daisy_workflows"
-	daisyInflater, err := newDaisyInflater(request, fileMetadata, logging.NewToolLogger("test"))
+	daisyInflater, err := newDaisyInflater(request, daisyutils.WorkflowClients{}, fileMetadata, logging.NewToolLogger("test"))
 	assert.NoError(t, err)
 	return daisyInflater
 }
@@ -31,7 +31,7 @@ type dataDiskProcessor struct {
 
 func newDataDiskProcessor(pd persistentDisk, client daisyCompute.Client, project string,
 	userLabels map[string]string, userStorageLocation string,
-	description string, family string, imageName string) processor {
+	description string, family string, imageName string) *dataDiskProcessor {
 	labels := map[string]string{"gce-image-import": "true"}
 	for k, v := range userLabels {
 		labels[k] = v
@@ -16,6 +16,7 @@ package importer
 
 import (
 	"context"
+	"fmt"
 	"log"
 	"path"
 	"sync"
@@ -28,7 +29,9 @@ import (
 	"github.com/GoogleCloudPlatform/compute-image-import/cli_tools/common/disk"
 	"github.com/GoogleCloudPlatform/compute-image-import/cli_tools/common/domain"
 	"github.com/GoogleCloudPlatform/compute-image-import/cli_tools/common/imagefile"
+	"github.com/GoogleCloudPlatform/compute-image-import/cli_tools/common/utils/daisyutils"
 	"github.com/GoogleCloudPlatform/compute-image-import/cli_tools/common/utils/logging"
+	pathutils "github.com/GoogleCloudPlatform/compute-image-import/cli_tools/common/utils/path"
 	"github.com/GoogleCloudPlatform/compute-image-import/proto/go/pb"
 )
 
@@ -39,31 +42,58 @@ type Importer interface {
 	Run(ctx context.Context) error
 }
 
-// NewImporter constructs an Importer instance.
-func NewImporter(request ImageImportRequest, computeClient daisyCompute.Client, storageClient domain.StorageClientInterface, logger logging.Logger) (Importer, error) {
+// NewImporter constructs an Importer instance. Its workflows use workflowClients, or create
+// their own clients when they're unset.
+func NewImporter(request ImageImportRequest, computeClient daisyCompute.Client, storageClient domain.StorageClientInterface,
+	workflowClients daisyutils.WorkflowClients, logger logging.Logger) (Importer, error) {
 	if err := request.validate(); err != nil {
 		return nil, err
 	}
 
-	inflater, err := NewInflater(request, computeClient, storageClient, imagefile.NewGCSInspector(), logger)
+	inflater, err := NewInflater(request, computeClient, storageClient, workflowClients, imagefile.NewGCSInspector(), logger)
 	if err != nil {
 		return nil, err
 	}
 
-	inspector, err := disk.NewInspector(request.EnvironmentSettings(), logger)
+	env := request.EnvironmentSettings()
+	env.WorkflowClients = workflowClients
+	inspector, err := disk.NewInspector(env, logger)
 	if err != nil {
 		return nil, err
 	}
+
+	var additionalInflaters []Inflater
+	for i, source := range request.AdditionalSources {
+		additionalInflater, err := NewInflater(additionalDiskRequest(request, source, i+2),
+			computeClient, storageClient, workflowClients, imagefile.NewGCSInspector(), logger)
+		if err != nil {
+			return nil, err
+		}
+		additionalInflaters = append(additionalInflaters, additionalInflater)
+	}
+
+	var newLVMConsolidator func(zone string, additionalDisks []persistentDisk) processor
+	if request.ConsolidateLVM {
+		newLVMConsolidator = func(zone string, additionalDisks []persistentDisk) processor {
+			consolidationRequest := request
+			consolidationRequest.Zone = zone
+			return newLVMConsolidationProcessor(consolidationRequest, workflowClients, additionalDisks, computeClient, logger)
+		}
+	}
 	return &importer{
-		project:      request.Project,
-		zone:         request.Zone,
-		timeout:      request.Timeout,
-		preValidator: newPreValidator(request, computeClient),
-		inflater:     inflater,
+		project:             request.Project,
+		zone:                request.Zone,
+		timeout:             request.Timeout,
+		preValidator:        newPreValidator(request, computeClient),
+		inflater:            inflater,
+		additionalInflaters: additionalInflaters,
+		newLVMConsolidator:  newLVMConsolidator,
 		processorProvider: defaultProcessorProvider{
 			request,
 			computeClient,
+			workflowClients,
 			newProcessPlanner(request, inspector, logger),
+			inspector,
 			logger,
 		},
 		diskClient: computeClient,
@@ -71,13 +101,33 @@ func NewImporter(request ImageImportRequest, computeClient daisyCompute.Client,
 	}, nil
 }
 
+// additionalDiskRequest returns the request to inflate an additional disk of the boot disk.
+// diskNumber is the disk's position, where the boot disk is 1.
+func additionalDiskRequest(request ImageImportRequest, source Source, diskNumber int) ImageImportRequest {
+	diskRequest := request
+	diskRequest.Source = source
+	diskRequest.AdditionalSources = nil
+	diskRequest.ExecutionID = fmt.Sprintf("%s-%d", request.ExecutionID, diskNumber)
+	diskRequest.ScratchBucketGcsPath = pathutils.JoinURL(request.ScratchBucketGcsPath, diskRequest.ExecutionID)
+	diskRequest.DaisyLogLinePrefix = fmt.Sprintf("disk-%d", diskNumber)
+	return diskRequest
+}
+
 // importer is an implementation of Importer that uses a combination of Daisy workflows
 // and GCP API calls.
 type importer struct {
-	project, zone     string
-	pd                persistentDisk
-	preValidator      validator
-	inflater          Inflater
+	project, zone string
+	pd            persistentDisk
+	preValidator  validator
+	inflater      Inflater
+
+	// additionalInflaters inflate the other disks of a boot disk whose LVM volume group
+	// spans multiple disks. newLVMConsolidator is set when the volume group is to be
+	// moved onto the boot disk, and is called with the boot disk's zone.
+	additionalInflaters []Inflater
+	additionalPds       []persistentDisk
+	newLVMConsolidator  func(zone string, additionalDisks []persistentDisk) processor
+
 	processorProvider processorProvider
 	diskClient        diskClient
 	logger            logging.Logger
@@ -100,6 +150,10 @@ func (i *importer) Run(ctx context.Context) error {
 		return err
 	}
 
+	if err := i.runConsolidate(ctx); err != nil {
+		return err
+	}
+
 	err := i.runProcess(ctx)
 	if err != nil {
 		return err
@@ -109,7 +163,7 @@ func (i *importer) Run(ctx context.Context) error {
 }
 
 func (i *importer) runInflate(ctx context.Context) (err error) {
-	return i.runStep(ctx, func() error {
+	err = i.runStep(ctx, func() error {
 		var err error
 		i.pd, _, err = i.inflater.Inflate()
 		if i.pd.sizeGb > 0 {
@@ -121,10 +175,50 @@ func (i *importer) runInflate(ctx context.Context) (err error) {
 		}
 		return err
 	}, i.inflater.Cancel)
+	if err != nil {
+		return err
+	}
+
+	for _, inflater := range i.additionalInflaters {
+		inflater := inflater
+		err = i.runStep(ctx, func() error {
+			pd, _, err := inflater.Inflate()
+			if pd.uri != "" {
+				i.additionalPds = append(i.additionalPds, pd)
+			}
+			return err
+		}, inflater.Cancel)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// runConsolidate moves an LVM volume group that spans the additional disks onto the
+// boot disk. The additional disks are deleted afterwards.
+func (i *importer) runConsolidate(ctx context.Context) error {
+	if i.newLVMConsolidator == nil || len(i.additionalPds) == 0 {
+		return nil
+	}
+	consolidator := i.newLVMConsolidator(i.pd.zone(i.zone), i.additionalPds)
+	err := i.runStep(ctx, func() error {
+		var err error
+		i.pd, err = consolidator.process(i.pd)
+		return err
+	}, consolidator.cancel)
+	if err != nil {
+		return err
+	}
+	for _, pd := range i.additionalPds {
+		deleteDisk(i.diskClient, i.project, i.zone, pd)
+	}
+	i.additionalPds = nil
+	return nil
 }
 
 func (i *importer) runProcess(ctx context.Context) error {
-	processors, err := i.processorProvider.provide(i.pd)
+	processors, err := i.processorProvider.provide(i.pd, i.additionalPds)
 	if err != nil {
 		return err
 	}
@@ -195,15 +289,19 @@ func (i *importer) getCtxError(ctx context.Context) (err error) {
 
 func (i *importer) deleteDisk() {
 	deleteDisk(i.diskClient, i.project, i.zone, i.pd)
+	for _, pd := range i.additionalPds {
+		deleteDisk(i.diskClient, i.project, i.zone, pd)
+	}
 }
 
+// deleteDisk deletes pd. zone is used when pd's URI doesn't include its zone.
 func deleteDisk(diskClient diskClient, project string, zone string, pd persistentDisk) {
 	if pd.uri == "" {
 		return
 	}
 
 	diskName := path.Base(pd.uri)
-	if err := diskClient.DeleteDisk(project, zone, diskName); err != nil {
+	if err := diskClient.DeleteDisk(project, pd.zone(zone), diskName); err != nil {
 		gAPIErr, isGAPIErr := err.(*googleapi.Error)
 		if isGAPIErr && gAPIErr.Code != 404 {
 			log.Printf("Failed to remove temporary disk %v: %e", pd, err)
@@ -0,0 +1,135 @@
+//  Copyright 2026 Google Inc. All Rights Reserved.
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+package importer
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"cloud.google.com/go/storage"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/api/compute/v1"
+	"google.golang.org/api/option"
+	"google.golang.org/protobuf/proto"
+
+	computeutils "github.com/GoogleCloudPlatform/compute-image-import/cli_tools/common/utils/compute"
+	"github.com/GoogleCloudPlatform/compute-image-import/cli_tools/common/utils/daisyutils"
+	"github.com/GoogleCloudPlatform/compute-image-import/cli_tools/common/utils/logging"
+	"github.com/GoogleCloudPlatform/compute-image-import/proto/go/pb"
+)
+
+// TestRun_FakeClient runs an import of an Ubuntu image against the in-memory compute
+// client: the disk is inflated, inspected, and translated by workers whose serial port
+// output is scripted.
+func TestRun_FakeClient(t *testing.T) {
+	client := computeutils.NewFakeClient()
+	client.AddProject("project", "us-west1-a")
+	client.AddImage("project", &compute.Image{Name: "source", DiskSizeGb: 10})
+	addWorkerImages(client)
+	client.ScriptInstances("run-inspection", computeutils.InstanceScript{
+		SerialPortOutput: map[int64][]string{1: {
+			"Status: inspection started.\n" +
+				fmt.Sprintf("Status: <serial-output key:'inspect_pb' value:'%s'>\n", encodedInspectionResults(t)) +
+				"Success: Done!\n",
+		}},
+	})
+	client.ScriptInstances("inst-translator", computeutils.InstanceScript{
+		SerialPortOutput: map[int64][]string{1: {"TranslateStatus: Installing packages.\nTranslateSuccess: Done!\n"}},
+	})
+
+	source, err := newImageSource("projects/project/global/images/source")
+	assert.NoError(t, err)
+	request := ImageImportRequest{
+		ExecutionID:          "abcde",
+		CloudLogsDisabled:    true,
+		GcsLogsDisabled:      true,
+		StdoutLogsDisabled:   true,
+		WorkflowDir:          daisyWorkflows,
+		ImageName:            "imported",
+		Inspect:              true,
+		Network:              "global/networks/default",
+		Project:              "project",
+		ScratchBucketGcsPath: "gs://scratch/abcde",
+		Source:               source,
+		Timeout:              time.Hour,
+		Tool:                 daisyutils.Tool{HumanReadableName: "image import", ResourceLabelName: "image-import"},
+		Zone:                 "us-west1-a",
+	}
+	workflowClients := daisyutils.WorkflowClients{ComputeClient: client, StorageClient: newFakeStorageClient(t)}
+	importer, err := NewImporter(request, client, nil, workflowClients, logging.NewToolLogger("[test]"))
+	if !assert.NoError(t, err) {
+		return
+	}
+	if !assert.NoError(t, importer.Run(context.Background())) {
+		return
+	}
+
+	image, err := client.GetImage("project", "imported")
+	if assert.NoError(t, err) {
+		assert.Equal(t, int64(10), image.DiskSizeGb)
+		assert.Contains(t, image.Licenses, "projects/ubuntu-os-cloud/global/licenses/ubuntu-2204-lts")
+	}
+	disks, _ := client.ListDisks("project", "us-west1-a")
+	assert.Empty(t, disks, "the inflated disk and the workers' disks are deleted")
+	instances, _ := client.ListInstances("project", "us-west1-a")
+	assert.Empty(t, instances)
+}
+
+// addWorkerImages creates the images that are used by the import workflows' workers.
+func addWorkerImages(client *computeutils.FakeClient) {
+	client.AddProject("compute-image-import")
+	client.AddLicense("compute-image-import", "virtual-disk-import")
+	for _, name := range []string{"debian-10-worker-v20230926", "debian-11-worker-v20241212"} {
+		client.AddImage("compute-image-import", &compute.Image{Name: name, DiskSizeGb: 10})
+	}
+	client.AddProject("ubuntu-os-cloud")
+	client.AddLicense("ubuntu-os-cloud", "ubuntu-2204-lts")
+}
+
+// encodedInspectionResults returns the inspection worker's result for a BIOS-bootable
+// Ubuntu 22.04 disk.
+func encodedInspectionResults(t *testing.T) string {
+	bytes, err := proto.Marshal(&pb.InspectionResults{
+		OsCount:      1,
+		BiosBootable: true,
+		OsRelease: &pb.OsRelease{
+			MajorVersion: "22",
+			MinorVersion: "04",
+			Architecture: pb.Architecture_X64,
+			DistroId:     pb.Distro_UBUNTU,
+		},
+	})
+	assert.NoError(t, err)
+	return base64.StdEncoding.EncodeToString(bytes)
+}
+
+// newFakeStorageClient returns a storage client whose requests all succeed, for
+// workflows that validate their scratch bucket and upload their sources.
+func newFakeStorageClient(t *testing.T) *storage.Client {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		// "done" and "resource" complete the rewrite calls that copy GCS sources.
+		w.Write([]byte(`{"bucket": "scratch", "name": "object", "done": true, "resource": {"bucket": "scratch", "name": "object"}}`))
+	}))
+	t.Cleanup(ts.Close)
+	client, err := storage.NewClient(context.Background(), option.WithEndpoint(ts.URL), option.WithoutAuthentication())
+	assert.NoError(t, err)
+	return client
+}
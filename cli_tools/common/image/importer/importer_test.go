@@ -439,6 +439,78 @@ func TestRunStep_VeryShortTimeout(t *testing.T) {
 	})
 }
 
+func TestRun_PassesAdditionalDisksToProcessing(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockDiskClient := mockDiskClient{}
+	mockProcessorProvider := mockProcessorProvider{processors: []processor{&mockProcessor{}}}
+	importer := importer{
+		diskClient:   &mockDiskClient,
+		preValidator: mockValidator{},
+		inflater:     &mockInflater{pd: persistentDisk{uri: "disk-1"}},
+		additionalInflaters: []Inflater{
+			&mockInflater{pd: persistentDisk{uri: "disk-2"}},
+			&mockInflater{pd: persistentDisk{uri: "disk-3"}},
+		},
+		processorProvider: &mockProcessorProvider,
+		logger:            mocks.NewMockLogger(ctrl),
+	}
+	assert.NoError(t, importer.Run(context.Background()))
+	assert.Equal(t, []persistentDisk{{uri: "disk-2"}, {uri: "disk-3"}}, mockProcessorProvider.additionalDisks)
+	assert.Equal(t, 3, mockDiskClient.interactions)
+}
+
+func TestRun_ConsolidatesAdditionalDisksPriorToProcessing(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockDiskClient := mockDiskClient{}
+	mockProcessorProvider := mockProcessorProvider{processors: []processor{&mockProcessor{}}}
+	consolidator := mockProcessor{}
+	var consolidatedDisks []persistentDisk
+	importer := importer{
+		diskClient:   &mockDiskClient,
+		preValidator: mockValidator{},
+		inflater:     &mockInflater{pd: persistentDisk{uri: "disk-1"}},
+		additionalInflaters: []Inflater{
+			&mockInflater{pd: persistentDisk{uri: "disk-2"}},
+		},
+		newLVMConsolidator: func(zone string, additionalDisks []persistentDisk) processor {
+			consolidatedDisks = additionalDisks
+			return &consolidator
+		},
+		processorProvider: &mockProcessorProvider,
+		logger:            mocks.NewMockLogger(ctrl),
+	}
+	assert.NoError(t, importer.Run(context.Background()))
+	assert.Equal(t, []persistentDisk{{uri: "disk-2"}}, consolidatedDisks)
+	assert.Equal(t, 1, consolidator.interactions)
+	assert.Empty(t, mockProcessorProvider.additionalDisks)
+	assert.Equal(t, 2, mockDiskClient.interactions)
+}
+
+func TestRun_DeletesAdditionalDisks_WhenConsolidationFails(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockDiskClient := mockDiskClient{}
+	mockProcessorProvider := mockProcessorProvider{}
+	importer := importer{
+		diskClient:   &mockDiskClient,
+		preValidator: mockValidator{},
+		inflater:     &mockInflater{pd: persistentDisk{uri: "disk-1"}},
+		additionalInflaters: []Inflater{
+			&mockInflater{pd: persistentDisk{uri: "disk-2"}},
+		},
+		newLVMConsolidator: func(zone string, additionalDisks []persistentDisk) processor {
+			return &mockProcessor{err: errors.New("pvmove failed")}
+		},
+		processorProvider: &mockProcessorProvider,
+		logger:            mocks.NewMockLogger(ctrl),
+	}
+	assert.EqualError(t, importer.Run(context.Background()), "pvmove failed")
+	assert.Equal(t, 0, mockProcessorProvider.interactions)
+	assert.Equal(t, 2, mockDiskClient.interactions)
+}
+
 // doTestWithTimeOut allows a test to be run for a predefined amount of time.
 // If this time passes, the test fails
 func doTestWithTimeOut(t *testing.T, timeout time.Duration, test func(t *testing.T)) {
@@ -456,13 +528,15 @@ func doTestWithTimeOut(t *testing.T, timeout time.Duration, test func(t *testing
 }
 
 type mockProcessorProvider struct {
-	processors   []processor
-	err          error
-	interactions int
+	processors      []processor
+	err             error
+	interactions    int
+	additionalDisks []persistentDisk
 }
 
-func (m *mockProcessorProvider) provide(pd persistentDisk) ([]processor, error) {
+func (m *mockProcessorProvider) provide(pd persistentDisk, additionalDisks []persistentDisk) ([]processor, error) {
 	m.interactions++
+	m.additionalDisks = additionalDisks
 	return m.processors, m.err
 }
 
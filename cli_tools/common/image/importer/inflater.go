@@ -42,6 +42,17 @@ type persistentDisk struct {
 	sourceType string
 }
 
+var diskZoneRegex = regexp.MustCompile(`zones/([^/]+)/disks/`)
+
+// zone returns the zone in the disk's URI, or defaultZone when the URI doesn't include
+// one. It differs from the request's zone when a workflow failed over to another zone.
+func (pd persistentDisk) zone(defaultZone string) string {
+	if match := diskZoneRegex.FindStringSubmatch(pd.uri); match != nil {
+		return match[1]
+	}
+	return defaultZone
+}
+
 type inflationInfo struct {
 	// Below fields are for inflation metrics
 	checksum      string
@@ -52,7 +63,7 @@ type inflationInfo struct {
 // NewInflater returns an Inflater object that uses either PD API or Daisy workflow to create a 1:1 data copy
 // of disk file into GCP disk
 func NewInflater(request ImageImportRequest, computeClient daisyCompute.Client, storageClient domain.StorageClientInterface,
-	inspector imagefile.Inspector, logger logging.Logger) (Inflater, error) {
+	workflowClients daisyutils.WorkflowClients, inspector imagefile.Inspector, logger logging.Logger) (Inflater, error) {
 
 	var fileMetadata = imagefile.Metadata{}
 	if !isImage(request.Source) {
@@ -68,7 +79,7 @@ func NewInflater(request ImageImportRequest, computeClient daisyCompute.Client,
 		fileMetadata, _ = inspector.Inspect(deadline, request.Source.Path())
 	}
 
-	di, err := newDaisyInflater(request, fileMetadata, logger)
+	di, err := newDaisyInflater(request, workflowClients, fileMetadata, logger)
 	if err != nil {
 		return nil, err
 	}
@@ -83,14 +94,14 @@ func NewInflater(request ImageImportRequest, computeClient daisyCompute.Client,
 	if isShadowTestFormat(request) {
 		return &shadowTestInflaterFacade{
 			mainInflater:   di,
-			shadowInflater: createAPIInflater(&apiInflaterProperties{request, computeClient, storageClient, logger, true, true}),
+			shadowInflater: createAPIInflater(&apiInflaterProperties{request, computeClient, storageClient, workflowClients, logger, true, true}),
 			logger:         logger,
 			qemuChecksum:   fileMetadata.Checksum,
 		}, nil
 	}
 
 	return &inflaterFacade{
-		apiInflater:   createAPIInflater(&apiInflaterProperties{request, computeClient, storageClient, logger, false, fileMetadata.Checksum != ""}),
+		apiInflater:   createAPIInflater(&apiInflaterProperties{request, computeClient, storageClient, workflowClients, logger, false, fileMetadata.Checksum != ""}),
 		daisyInflater: di,
 		logger:        logger,
 		qemuChecksum:  fileMetadata.Checksum,
@@ -148,7 +159,7 @@ func (facade *inflaterFacade) Inflate() (persistentDisk, inflationInfo, error) {
 			diskName := getDiskName(facade.request.ExecutionID)
 
 			// If checksum mismatches , delete the corrupted disk.
-			err = facade.computeClient.DeleteDisk(facade.request.Project, facade.request.Zone, diskName)
+			err = facade.computeClient.DeleteDisk(facade.request.Project, pd.zone(facade.request.Zone), diskName)
 			if err != nil {
 				return pd, ii, daisy.Errf("Tried to delete the disk after checksum mismatch is detected, but failed on: %v", err)
 			}
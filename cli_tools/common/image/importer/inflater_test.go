@@ -48,7 +48,7 @@ func TestCreateFallbackInflater_File(t *testing.T) {
 		},
 		NoExternalIP: false,
 		WorkflowDir:  daisyWorkflows,
-	}, nil, &storage.Client{}, mockInspector{
+	}, nil, &storage.Client{}, daisyutils.WorkflowClients{}, mockInspector{
 		t:                 t,
 		expectedReference: "gs://bucket/vmdk",
 		errorToReturn:     nil,
@@ -94,7 +94,7 @@ func TestCreateShadowTestInflater_File(t *testing.T) {
 		},
 		NoExternalIP: false,
 		WorkflowDir:  daisyWorkflows,
-	}, nil, &storage.Client{}, mockInspector{
+	}, nil, &storage.Client{}, daisyutils.WorkflowClients{}, mockInspector{
 		t:                 t,
 		expectedReference: "gs://bucket/vmdk",
 		errorToReturn:     nil,
@@ -132,7 +132,7 @@ func TestCreateInflater_Image(t *testing.T) {
 		Tool: daisyutils.Tool{
 			ResourceLabelName: "image-import",
 		},
-	}, nil, &storage.Client{}, nil, logging.NewToolLogger("test"))
+	}, nil, &storage.Client{}, daisyutils.WorkflowClients{}, nil, logging.NewToolLogger("test"))
 	assert.NoError(t, err)
 	realInflater, ok := inflater.(*daisyInflater)
 	assert.True(t, ok)
@@ -155,7 +155,7 @@ func TestCreateInflater_ImageWithChangedReleaseProject(t *testing.T) {
 		Tool: daisyutils.Tool{
 			ResourceLabelName: "image-import",
 		},
-	}, nil, &storage.Client{}, nil, logging.NewToolLogger("test"))
+	}, nil, &storage.Client{}, daisyutils.WorkflowClients{}, nil, logging.NewToolLogger("test"))
 	assert.NoError(t, err)
 	realInflater, ok := inflater.(*daisyInflater)
 	assert.True(t, ok)
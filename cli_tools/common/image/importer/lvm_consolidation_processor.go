@@ -0,0 +1,134 @@
+//  Copyright 2026 Google Inc. All Rights Reserved.
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+package importer
+
+import (
+	"fmt"
+	"path"
+	"strconv"
+
+	daisy "github.com/GoogleCloudPlatform/compute-daisy"
+	daisyCompute "github.com/GoogleCloudPlatform/compute-daisy/compute"
+	"google.golang.org/api/compute/v1"
+
+	"github.com/GoogleCloudPlatform/compute-image-import/cli_tools/common/utils/daisyutils"
+	"github.com/GoogleCloudPlatform/compute-image-import/cli_tools/common/utils/logging"
+)
+
+const lvmConsolidationWorkflow = "image_import/lvm/consolidate_lvm.wf.json"
+
+// lvmConsolidationProcessor moves an LVM volume group that spans multiple disks onto
+// the boot disk. The boot disk is grown by the size of the additional disks, and a
+// worker runs pvmove to empty them.
+type lvmConsolidationProcessor struct {
+	project, zone     string
+	computeDiskClient daisyCompute.Client
+	worker            daisyutils.DaisyWorker
+	vars              map[string]string
+	additionalDisks   []persistentDisk
+	logger            logging.Logger
+}
+
+func newLVMConsolidationProcessor(request ImageImportRequest, workflowClients daisyutils.WorkflowClients,
+	additionalDisks []persistentDisk, computeDiskClient daisyCompute.Client, logger logging.Logger) processor {
+	vars := map[string]string{
+		"import_network": request.Network,
+		"import_subnet":  request.Subnet,
+	}
+	if request.ComputeServiceAccount != "" {
+		vars["compute_service_account"] = request.ComputeServiceAccount
+	}
+
+	workflowProvider := func() (*daisy.Workflow, error) {
+		wf, err := daisyutils.ParseWorkflow(path.Join(request.WorkflowDir, lvmConsolidationWorkflow), vars,
+			request.Project, request.Zone, request.ScratchBucketGcsPath, request.Oauth, request.Timeout.String(),
+			request.GcsLogsDisabled, request.CloudLogsDisabled, request.StdoutLogsDisabled)
+		if err != nil {
+			return nil, err
+		}
+		updateWorkflowWithAdditionalDisks(wf, additionalDisks)
+		return wf, nil
+	}
+
+	env := request.EnvironmentSettings()
+	env.WorkflowClients = workflowClients
+	if env.DaisyLogLinePrefix != "" {
+		env.DaisyLogLinePrefix += "-"
+	}
+	env.DaisyLogLinePrefix += "consolidate-lvm"
+	return &lvmConsolidationProcessor{
+		project:           request.Project,
+		zone:              request.Zone,
+		computeDiskClient: computeDiskClient,
+		worker:            daisyutils.NewDaisyWorker(workflowProvider, env, logger, createResourceLabeler(request)),
+		vars:              vars,
+		additionalDisks:   additionalDisks,
+		logger:            logger,
+	}
+}
+
+// updateWorkflowWithAdditionalDisks attaches the additional disks to the worker. Device
+// names let the worker distinguish them from the boot disk.
+func updateWorkflowWithAdditionalDisks(wf *daisy.Workflow, additionalDisks []persistentDisk) {
+	attachDisks := ([]*daisy.AttachDisk)(*wf.Steps["attach-disks"].AttachDisks)
+	instance := wf.Steps["consolidate-disk-inst"].CreateInstances.Instances[0]
+	if instance.Metadata == nil {
+		instance.Metadata = map[string]string{}
+	}
+	instance.Metadata["input_disks_count"] = strconv.Itoa(len(additionalDisks) + 1)
+	for i, additionalDisk := range additionalDisks {
+		attachDisks = append(attachDisks, &daisy.AttachDisk{
+			Instance: instance.Name,
+			AttachedDisk: compute.AttachedDisk{
+				Source:     additionalDisk.uri,
+				DeviceName: fmt.Sprintf("additional-disk-%d", i+2),
+			},
+		})
+	}
+	wf.Steps["attach-disks"].AttachDisks = (*daisy.AttachDisks)(&attachDisks)
+}
+
+func (p *lvmConsolidationProcessor) process(pd persistentDisk) (persistentDisk, error) {
+	p.logger.User("Consolidating LVM volume group onto the boot disk")
+	bootDisk, err := p.computeDiskClient.GetDisk(p.project, p.zone, daisyutils.GetResourceID(pd.uri))
+	if err != nil {
+		return pd, daisy.Errf("Failed to get disk: %v", err)
+	}
+	newSizeGb := bootDisk.SizeGb
+	for _, additionalDisk := range p.additionalDisks {
+		d, err := p.computeDiskClient.GetDisk(p.project, p.zone, daisyutils.GetResourceID(additionalDisk.uri))
+		if err != nil {
+			return pd, daisy.Errf("Failed to get disk: %v", err)
+		}
+		newSizeGb += d.SizeGb
+	}
+	if err = p.computeDiskClient.ResizeDisk(p.project, p.zone, bootDisk.Name,
+		&compute.DisksResizeRequest{SizeGb: newSizeGb}); err != nil {
+		return pd, daisy.Errf("Failed to resize disk: %v", err)
+	}
+	pd.sizeGb = newSizeGb
+
+	p.vars["source_disk"] = pd.uri
+	if err = p.worker.Run(p.vars); err != nil {
+		return pd, err
+	}
+	p.logger.User("Finished consolidating LVM volume group")
+	return pd, nil
+}
+
+func (p *lvmConsolidationProcessor) cancel(reason string) bool {
+	p.worker.Cancel(reason)
+	return true
+}
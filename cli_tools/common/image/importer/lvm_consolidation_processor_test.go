@@ -0,0 +1,101 @@
+//  Copyright 2026 Google Inc. All Rights Reserved.
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+package importer
+
+import (
+	"errors"
+	"testing"
+
+	daisy "github.com/GoogleCloudPlatform/compute-daisy"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/api/compute/v1"
+
+	"github.com/GoogleCloudPlatform/compute-image-import/cli_tools/common/utils/daisyutils"
+	"github.com/GoogleCloudPlatform/compute-image-import/cli_tools/common/utils/logging"
+	"github.com/GoogleCloudPlatform/compute-image-import/cli_tools/mocks"
+)
+
+func TestLVMConsolidationProcessor_Process_GrowsBootDiskAndRunsWorker(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	computeClient := mocks.NewMockClient(ctrl)
+	computeClient.EXPECT().GetDisk("test-project", "test-zone", "disk-1").Return(&compute.Disk{Name: "disk-1", SizeGb: 10}, nil)
+	computeClient.EXPECT().GetDisk("test-project", "test-zone", "disk-2").Return(&compute.Disk{Name: "disk-2", SizeGb: 20}, nil)
+	computeClient.EXPECT().GetDisk("test-project", "test-zone", "disk-3").Return(&compute.Disk{Name: "disk-3", SizeGb: 5}, nil)
+	computeClient.EXPECT().ResizeDisk("test-project", "test-zone", "disk-1",
+		&compute.DisksResizeRequest{SizeGb: 35}).Return(nil)
+	worker := mocks.NewMockDaisyWorker(ctrl)
+	worker.EXPECT().Run(map[string]string{"source_disk": "zones/test-zone/disks/disk-1"})
+	processor := &lvmConsolidationProcessor{
+		project:           "test-project",
+		zone:              "test-zone",
+		computeDiskClient: computeClient,
+		worker:            worker,
+		vars:              map[string]string{},
+		additionalDisks: []persistentDisk{
+			{uri: "zones/test-zone/disks/disk-2"},
+			{uri: "zones/test-zone/disks/disk-3"},
+		},
+		logger: logging.NewToolLogger("test"),
+	}
+	pd, err := processor.process(persistentDisk{uri: "zones/test-zone/disks/disk-1"})
+	assert.NoError(t, err)
+	assert.Equal(t, persistentDisk{uri: "zones/test-zone/disks/disk-1", sizeGb: 35}, pd)
+}
+
+func TestLVMConsolidationProcessor_Process_PropagatesWorkerError(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	computeClient := mocks.NewMockClient(ctrl)
+	computeClient.EXPECT().GetDisk("test-project", "test-zone", gomock.Any()).Return(&compute.Disk{Name: "disk-1", SizeGb: 10}, nil).Times(2)
+	computeClient.EXPECT().ResizeDisk("test-project", "test-zone", "disk-1", gomock.Any()).Return(nil)
+	worker := mocks.NewMockDaisyWorker(ctrl)
+	worker.EXPECT().Run(gomock.Any()).Return(errors.New("pvmove failed"))
+	processor := &lvmConsolidationProcessor{
+		project:           "test-project",
+		zone:              "test-zone",
+		computeDiskClient: computeClient,
+		worker:            worker,
+		vars:              map[string]string{},
+		additionalDisks:   []persistentDisk{{uri: "zones/test-zone/disks/disk-2"}},
+		logger:            logging.NewToolLogger("test"),
+	}
+	_, err := processor.process(persistentDisk{uri: "zones/test-zone/disks/disk-1"})
+	assert.EqualError(t, err, "pvmove failed")
+}
+
+func TestLVMConsolidationProcessor_AttachesAdditionalDisksToWorker(t *testing.T) {
+	request := defaultImportArgs()
+	request.WorkflowDir = "../../../../daisy_workflows"
+	processor := newLVMConsolidationProcessor(request, daisyutils.WorkflowClients{}, []persistentDisk{
+		{uri: "zones/test-zone/disks/disk-2"},
+		{uri: "zones/test-zone/disks/disk-3"},
+	}, nil, logging.NewToolLogger(t.Name()))
+
+	realProcessor := processor.(*lvmConsolidationProcessor)
+	daisyutils.CheckWorkflow(realProcessor.worker, func(wf *daisy.Workflow, err error) {
+		assert.NoError(t, err)
+		attachDisks := *wf.Steps["attach-disks"].AttachDisks
+		assert.Len(t, attachDisks, 3)
+		assert.Equal(t, "boot-disk", attachDisks[0].DeviceName)
+		assert.Equal(t, "zones/test-zone/disks/disk-2", attachDisks[1].Source)
+		assert.Equal(t, "additional-disk-2", attachDisks[1].DeviceName)
+		assert.Equal(t, "zones/test-zone/disks/disk-3", attachDisks[2].Source)
+		assert.Equal(t, "additional-disk-3", attachDisks[2].DeviceName)
+		instance := wf.Steps["consolidate-disk-inst"].CreateInstances.Instances[0]
+		assert.Equal(t, "3", instance.Metadata["input_disks_count"])
+	})
+}
@@ -49,6 +49,16 @@ type processingPlan struct {
 	requiredFeatures        []*compute.GuestOsFeature
 	translationWorkflowPath string
 	detectedOs              distro.Release
+
+	// rootDevice is the block device holding the root filesystem of the OS to
+	// translate. Empty unless the disk has multiple operating systems, or the
+	// user chose one with -root_partition.
+	rootDevice string
+
+	// uefiConversionWorkflowPath is the workflow that converts the disk to boot
+	// with UEFI. Empty unless -convert_to_uefi was specified and the disk
+	// doesn't already have a UEFI bootloader.
+	uefiConversionWorkflowPath string
 }
 
 // metadataChangesRequired returns whether metadata needs to be updated on the
@@ -71,44 +81,96 @@ func (p *defaultPlanner) plan(pd persistentDisk) (*processingPlan, error) {
 
 	inspectionResults, inspectionError := p.inspectDisk(pd.uri)
 	var detectedOs distro.Release
+	var detectedRelease *pb.OsRelease
 	osID := p.request.OS
 	requiresUEFI := p.request.UefiCompatible
+	rootDevice := p.request.RootPartition
+	uefiBootable := false
 	if inspectionError == nil && inspectionResults != nil {
-		if inspectionResults.GetOsCount() == 1 && inspectionResults.GetOsRelease() != nil {
-			detectedOs, _ = distro.FromGcloudOSArgument(inspectionResults.GetOsRelease().CliFormatted)
+		if inspectionResults.GetOsCount() == 1 {
+			detectedRelease = inspectionResults.GetOsRelease()
+		}
+		var biosBootable bool
+		uefiBootable, biosBootable = inspectionResults.GetUefiBootable(), inspectionResults.GetBiosBootable()
+		installation, err := p.selectInstallation(inspectionResults)
+		if err != nil {
+			return nil, err
 		}
-		if osID == "" && inspectionResults.GetOsCount() == 1 && inspectionResults.GetOsRelease() != nil {
-			osID = inspectionResults.GetOsRelease().CliFormatted
+		if installation != nil {
+			detectedRelease = installation.GetOsRelease()
+			// The boot mode of a single installation is detected from the partition table.
+			if len(inspectionResults.GetOsInstallations()) > 1 {
+				rootDevice = installation.GetRootPartition()
+				uefiBootable, biosBootable = installation.GetUefiBootable(), installation.GetBiosBootable()
+			}
+		}
+
+		if detectedRelease != nil {
+			detectedOs, _ = distro.FromGcloudOSArgument(detectedRelease.CliFormatted)
+		}
+		if osID == "" && detectedRelease != nil {
+			osID = detectedRelease.CliFormatted
 			if p.request.BYOL {
 				osID += "-byol"
 			}
 		}
 
-		if requiresUEFI {
-			if !inspectionResults.GetUefiBootable() {
+		switch {
+		case p.request.ConvertToUEFI:
+			// Conversion is planned after the OS is known.
+		case requiresUEFI:
+			if !uefiBootable {
 				p.logger.User("UEFI booting was specified, but we could not detect a UEFI bootloader. " +
 					"Specifying an incorrect boot type can increase load times, or lead to boot failures.")
 			}
-		} else {
-			hybridGPTBootable := inspectionResults.GetUefiBootable() && inspectionResults.GetBiosBootable()
+		default:
+			hybridGPTBootable := uefiBootable && biosBootable
 			if hybridGPTBootable {
 				p.logger.User("The boot disk can boot with either BIOS or a UEFI bootloader. The default setting for booting is BIOS. " +
 					"If you want to boot using UEFI, please see https://cloud.google.com/compute/docs/import/importing-virtual-disks#importing_a_virtual_disk_with_uefi_bootloader'.")
 			}
-			requiresUEFI = inspectionResults.GetUefiBootable() && !hybridGPTBootable
+			requiresUEFI = uefiBootable && !hybridGPTBootable
 		}
 	}
 
+	if osID == "" && detectedRelease.GetDistroId() == pb.Distro_FREEBSD {
+		// The inspection worker can't mount ZFS, so the version of FreeBSD
+		// systems with a ZFS root isn't known.
+		return nil, errors.New("detected FreeBSD, but could not determine its version. " +
+			"Please re-import with the operating system specified, such as -os=freebsd-14")
+	}
 	if osID == "" {
 		return nil, errors.New("could not detect operating system. Please re-import with the operating system specified. " +
 			"For more information, see https://cloud.google.com/compute/docs/import/importing-virtual-disks#bootable")
 	}
 
+	if rootDevice != "" && strings.Contains(osID, "windows") {
+		// The Windows translation worker finds the Windows installation itself.
+		return nil, fmt.Errorf("-%s and -%s are not supported for Windows", RootPartitionFlag, SelectOSFlag)
+	}
+
 	settings, err := daisyutils.GetTranslationSettings(osID)
 	if err != nil {
 		return nil, err
 	}
 
+	var uefiConversionWorkflowPath string
+	if p.request.ConvertToUEFI {
+		if strings.HasPrefix(osID, "freebsd") {
+			return nil, fmt.Errorf("-%s is not supported for FreeBSD", ConvertToUEFIFlag)
+		}
+		if uefiBootable {
+			p.logger.User("The disk already has a UEFI bootloader. Skipping UEFI conversion.")
+		} else {
+			workflow := "convert_to_uefi_linux.wf.json"
+			if strings.Contains(osID, "windows") {
+				workflow = "convert_to_uefi_windows.wf.json"
+			}
+			uefiConversionWorkflowPath = path.Join(p.request.WorkflowDir, "image_import", "uefi", workflow)
+		}
+		requiresUEFI = true
+	}
+
 	var requiredGuestOSFeatures []*compute.GuestOsFeature
 	if strings.Contains(osID, "windows") {
 		requiredGuestOSFeatures = append(requiredGuestOSFeatures, &compute.GuestOsFeature{Type: "WINDOWS"})
@@ -117,14 +179,108 @@ func (p *defaultPlanner) plan(pd persistentDisk) (*processingPlan, error) {
 		requiredGuestOSFeatures = append(requiredGuestOSFeatures, &compute.GuestOsFeature{Type: "UEFI_COMPATIBLE"})
 	}
 
+	var requiredLicenses []string
+	if settings.LicenseURI != "" {
+		requiredLicenses = append(requiredLicenses, settings.LicenseURI)
+	}
+	requiredLicenses, requiredGuestOSFeatures = p.addExportedMetadata(requiredLicenses, requiredGuestOSFeatures)
+
 	return &processingPlan{
-		requiredLicenses:        []string{settings.LicenseURI},
+		requiredLicenses:        requiredLicenses,
 		requiredFeatures:        requiredGuestOSFeatures,
 		translationWorkflowPath: path.Join(p.request.WorkflowDir, "image_import", settings.WorkflowPath),
 		detectedOs:              detectedOs,
+		rootDevice:              rootDevice,
+
+		uefiConversionWorkflowPath: uefiConversionWorkflowPath,
 	}, nil
 }
 
+// addExportedMetadata adds the licenses and guest OS features from -metadata_file that
+// aren't already required. OS licenses from -metadata_file are dropped, since the disk
+// is translated, and translation applies the license of the OS that it's run for; they're
+// only restored when translation is skipped.
+func (p *defaultPlanner) addExportedMetadata(licenses []string, features []*compute.GuestOsFeature) (
+	[]string, []*compute.GuestOsFeature) {
+	required := &compute.Disk{Licenses: licenses, GuestOsFeatures: features}
+	for _, license := range p.request.Licenses {
+		if !isOSLicense(license) && !hasLicense(required, license) {
+			required.Licenses = append(required.Licenses, license)
+		}
+	}
+	for _, featureType := range p.request.GuestOSFeatures {
+		feature := &compute.GuestOsFeature{Type: featureType}
+		if !hasGuestOSFeature(required, feature) {
+			required.GuestOsFeatures = append(required.GuestOsFeatures, feature)
+		}
+	}
+	return required.Licenses, required.GuestOsFeatures
+}
+
+// selectInstallation returns the installation to import, as chosen by -root_partition
+// or -select_os. An error is returned when the disk has multiple installations and
+// the choice is missing or ambiguous. Returns nil when the worker didn't report
+// installations.
+func (p *defaultPlanner) selectInstallation(results *pb.InspectionResults) (*pb.OsInstallation, error) {
+	installations := results.GetOsInstallations()
+	if len(installations) == 0 {
+		return nil, nil
+	}
+	var flag, value string
+	var matches []*pb.OsInstallation
+	switch {
+	case p.request.RootPartition != "":
+		flag, value = RootPartitionFlag, p.request.RootPartition
+		for _, installation := range installations {
+			if installation.GetRootPartition() == p.request.RootPartition {
+				matches = append(matches, installation)
+			}
+		}
+	case p.request.SelectOS != "":
+		flag, value = SelectOSFlag, p.request.SelectOS
+		selected, err := distro.FromGcloudOSArgument(p.request.SelectOS)
+		if err != nil {
+			return nil, err
+		}
+		for _, installation := range installations {
+			release, err := distro.FromGcloudOSArgument(installation.GetOsRelease().GetCliFormatted())
+			if err == nil && selected.ImportCompatible(release) {
+				matches = append(matches, installation)
+			}
+		}
+	case len(installations) == 1:
+		return installations[0], nil
+	default:
+		return nil, fmt.Errorf("found %d operating systems: %s. Please re-import with -%s or -%s to choose one",
+			len(installations), describeInstallations(installations), RootPartitionFlag, SelectOSFlag)
+	}
+
+	switch len(matches) {
+	case 0:
+		return nil, fmt.Errorf("-%s=%s doesn't match a detected operating system. Found: %s",
+			flag, value, describeInstallations(installations))
+	case 1:
+		return matches[0], nil
+	default:
+		return nil, fmt.Errorf("-%s=%s matches multiple operating systems: %s. Please re-import with -%s to choose one",
+			flag, value, describeInstallations(matches), RootPartitionFlag)
+	}
+}
+
+// describeInstallations returns a user-readable list of installations, such as
+// "ubuntu-2204 on /dev/sda2, debian-12 on /dev/sda3".
+func describeInstallations(installations []*pb.OsInstallation) string {
+	var descriptions []string
+	for _, installation := range installations {
+		name := installation.GetOsRelease().GetCliFormatted()
+		if name == "" {
+			name = strings.ToLower(installation.GetOsRelease().GetDistroId().String())
+		}
+		descriptions = append(descriptions, fmt.Sprintf("%s on %s", name, installation.GetRootPartition()))
+	}
+	return strings.Join(descriptions, ", ")
+}
+
 func (p *defaultPlanner) inspectDisk(uri string) (*pb.InspectionResults, error) {
 	p.logger.User("Inspecting disk for OS and bootloader")
 	ir, err := p.diskInspector.Inspect(uri)
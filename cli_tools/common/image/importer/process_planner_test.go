@@ -25,6 +25,7 @@ import (
 	"github.com/GoogleCloudPlatform/compute-image-import/cli_tools/common/disk"
 	mock_disk "github.com/GoogleCloudPlatform/compute-image-import/cli_tools/common/disk/mocks"
 	"github.com/GoogleCloudPlatform/compute-image-import/cli_tools/common/distro"
+	"github.com/GoogleCloudPlatform/compute-image-import/cli_tools/common/utils/daisyutils"
 	"github.com/GoogleCloudPlatform/compute-image-import/cli_tools/common/utils/logging"
 	"github.com/GoogleCloudPlatform/compute-image-import/cli_tools/mocks"
 	"github.com/GoogleCloudPlatform/compute-image-import/proto/go/pb"
@@ -187,6 +188,22 @@ func Test_DefaultPlanner_Plan_InspectionSucceeds(t *testing.T) {
 				detectedOs:              distro.FromGcloudOSArgumentMustParse("rhel-8"),
 			},
 		},
+		{
+			name: "Don't require a license when the OS doesn't have one",
+			request: ImageImportRequest{
+				WorkflowDir: "workflowroot",
+			},
+			inspectionResults: &pb.InspectionResults{
+				OsCount: 1,
+				OsRelease: &pb.OsRelease{
+					CliFormatted: "oracle-8",
+				},
+			},
+			expectedResults: &processingPlan{
+				translationWorkflowPath: "workflowroot/image_import/enterprise_linux/translate_oracle_8.wf.json",
+				detectedOs:              distro.FromGcloudOSArgumentMustParse("oracle-8"),
+			},
+		},
 		{
 			name: "Fail when BYOL is specified, but detected OS doesn't support it.",
 			request: ImageImportRequest{
@@ -224,6 +241,36 @@ func Test_DefaultPlanner_Plan_InspectionSucceeds(t *testing.T) {
 			},
 			expectErrorToContain: "lease re-import with the operating system specified",
 		},
+		{
+			name: "Fail when FreeBSD is detected without a version, and OS is not provided.",
+			request: ImageImportRequest{
+				WorkflowDir: "workflowroot",
+			},
+			inspectionResults: &pb.InspectionResults{
+				OsCount: 1,
+				OsRelease: &pb.OsRelease{
+					DistroId: pb.Distro_FREEBSD,
+				},
+			},
+			expectErrorToContain: "detected FreeBSD, but could not determine its version",
+		},
+		{
+			name: "Use detected FreeBSD release.",
+			request: ImageImportRequest{
+				WorkflowDir: "workflowroot",
+			},
+			inspectionResults: &pb.InspectionResults{
+				OsCount: 1,
+				OsRelease: &pb.OsRelease{
+					CliFormatted: "freebsd-14",
+					DistroId:     pb.Distro_FREEBSD,
+				},
+			},
+			expectedResults: &processingPlan{
+				translationWorkflowPath: "workflowroot/image_import/freebsd/translate_freebsd_14.wf.json",
+				detectedOs:              distro.FromGcloudOSArgumentMustParse("freebsd-14"),
+			},
+		},
 		{
 			name: "Use provided UEFI argument, even when inspection shows UEFI is not supported.",
 			request: ImageImportRequest{
@@ -273,3 +320,297 @@ func Test_DefaultPlanner_Plan_InspectionSucceeds(t *testing.T) {
 		})
 	}
 }
+
+func Test_DefaultPlanner_Plan_MultipleInstallations(t *testing.T) {
+	pd := persistentDisk{uri: "disk/uri"}
+	ubuntu := &pb.OsInstallation{
+		RootPartition: "/dev/sda2",
+		OsRelease:     &pb.OsRelease{CliFormatted: "ubuntu-2204"},
+		UefiBootable:  true,
+	}
+	debian := &pb.OsInstallation{
+		RootPartition: "/dev/sda3",
+		OsRelease:     &pb.OsRelease{CliFormatted: "debian-12"},
+		BiosBootable:  true,
+	}
+	debianOnSdb := &pb.OsInstallation{
+		RootPartition: "/dev/sdb1",
+		OsRelease:     &pb.OsRelease{CliFormatted: "debian-12"},
+	}
+	windows := &pb.OsInstallation{
+		RootPartition: "/dev/sda4",
+		OsRelease:     &pb.OsRelease{CliFormatted: "windows-2019"},
+	}
+	for _, tt := range []struct {
+		name                 string
+		request              ImageImportRequest
+		inspectionResults    *pb.InspectionResults
+		expectErrorToContain string
+		expectedResults      *processingPlan
+	}{
+		{
+			name:    "Fail when multiple operating systems are found and none is chosen",
+			request: ImageImportRequest{WorkflowDir: "workflowroot"},
+			inspectionResults: &pb.InspectionResults{
+				OsCount:         2,
+				OsInstallations: []*pb.OsInstallation{ubuntu, debian},
+			},
+			expectErrorToContain: "found 2 operating systems: ubuntu-2204 on /dev/sda2, debian-12 on /dev/sda3. " +
+				"Please re-import with -root_partition or -select_os to choose one",
+		},
+		{
+			name:    "Choose by root partition",
+			request: ImageImportRequest{WorkflowDir: "workflowroot", RootPartition: "/dev/sda3"},
+			inspectionResults: &pb.InspectionResults{
+				OsCount:         2,
+				OsInstallations: []*pb.OsInstallation{ubuntu, debian},
+				UefiBootable:    true,
+			},
+			expectedResults: &processingPlan{
+				requiredLicenses:        []string{"projects/debian-cloud/global/licenses/debian-12-bookworm"},
+				translationWorkflowPath: "workflowroot/image_import/debian/translate_debian_12.wf.json",
+				detectedOs:              distro.FromGcloudOSArgumentMustParse("debian-12"),
+				rootDevice:              "/dev/sda3",
+			},
+		},
+		{
+			name:    "Choose by OS, using the installation's boot mode",
+			request: ImageImportRequest{WorkflowDir: "workflowroot", SelectOS: "ubuntu-2204"},
+			inspectionResults: &pb.InspectionResults{
+				OsCount:         2,
+				OsInstallations: []*pb.OsInstallation{ubuntu, debian},
+				BiosBootable:    true,
+			},
+			expectedResults: &processingPlan{
+				requiredLicenses:        []string{"projects/ubuntu-os-cloud/global/licenses/ubuntu-2204-lts"},
+				requiredFeatures:        []*compute.GuestOsFeature{{Type: "UEFI_COMPATIBLE"}},
+				translationWorkflowPath: "workflowroot/image_import/ubuntu/translate_ubuntu_2204.wf.json",
+				detectedOs:              distro.FromGcloudOSArgumentMustParse("ubuntu-2204"),
+				rootDevice:              "/dev/sda2",
+			},
+		},
+		{
+			name:    "Fail when root partition doesn't match",
+			request: ImageImportRequest{WorkflowDir: "workflowroot", RootPartition: "/dev/sda9"},
+			inspectionResults: &pb.InspectionResults{
+				OsCount:         2,
+				OsInstallations: []*pb.OsInstallation{ubuntu, debian},
+			},
+			expectErrorToContain: "-root_partition=/dev/sda9 doesn't match a detected operating system. " +
+				"Found: ubuntu-2204 on /dev/sda2, debian-12 on /dev/sda3",
+		},
+		{
+			name:    "Fail when selected OS matches multiple installations",
+			request: ImageImportRequest{WorkflowDir: "workflowroot", SelectOS: "debian-12"},
+			inspectionResults: &pb.InspectionResults{
+				OsCount:         3,
+				OsInstallations: []*pb.OsInstallation{ubuntu, debian, debianOnSdb},
+			},
+			expectErrorToContain: "-select_os=debian-12 matches multiple operating systems: " +
+				"debian-12 on /dev/sda3, debian-12 on /dev/sdb1. Please re-import with -root_partition to choose one",
+		},
+		{
+			name:    "Fail when Windows is selected from multiple operating systems",
+			request: ImageImportRequest{WorkflowDir: "workflowroot", SelectOS: "windows-2019"},
+			inspectionResults: &pb.InspectionResults{
+				OsCount:         2,
+				OsInstallations: []*pb.OsInstallation{ubuntu, windows},
+			},
+			expectErrorToContain: "-root_partition and -select_os are not supported for Windows",
+		},
+		{
+			name:    "Fail when root partition is specified for Windows",
+			request: ImageImportRequest{WorkflowDir: "workflowroot", RootPartition: "/dev/sda4"},
+			inspectionResults: &pb.InspectionResults{
+				OsCount:         1,
+				OsRelease:       windows.OsRelease,
+				OsInstallations: []*pb.OsInstallation{windows},
+			},
+			expectErrorToContain: "-root_partition and -select_os are not supported for Windows",
+		},
+		{
+			name:    "Pass root partition through when there's a single OS",
+			request: ImageImportRequest{WorkflowDir: "workflowroot", RootPartition: "/dev/sda3"},
+			inspectionResults: &pb.InspectionResults{
+				OsCount:         1,
+				OsRelease:       debian.OsRelease,
+				OsInstallations: []*pb.OsInstallation{debian},
+			},
+			expectedResults: &processingPlan{
+				requiredLicenses:        []string{"projects/debian-cloud/global/licenses/debian-12-bookworm"},
+				translationWorkflowPath: "workflowroot/image_import/debian/translate_debian_12.wf.json",
+				detectedOs:              distro.FromGcloudOSArgumentMustParse("debian-12"),
+				rootDevice:              "/dev/sda3",
+			},
+		},
+		{
+			name:    "Use the partition table's boot mode when there's a single OS",
+			request: ImageImportRequest{WorkflowDir: "workflowroot"},
+			inspectionResults: &pb.InspectionResults{
+				OsCount:         1,
+				OsRelease:       ubuntu.OsRelease,
+				OsInstallations: []*pb.OsInstallation{ubuntu},
+				BiosBootable:    true,
+			},
+			expectedResults: &processingPlan{
+				requiredLicenses:        []string{"projects/ubuntu-os-cloud/global/licenses/ubuntu-2204-lts"},
+				translationWorkflowPath: "workflowroot/image_import/ubuntu/translate_ubuntu_2204.wf.json",
+				detectedOs:              distro.FromGcloudOSArgumentMustParse("ubuntu-2204"),
+			},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+			mockInspector := mock_disk.NewMockInspector(mockCtrl)
+			mockInspector.EXPECT().Inspect(pd.uri).Return(tt.inspectionResults, nil)
+			processPlanner := newProcessPlanner(tt.request, mockInspector, logging.NewToolLogger("test"))
+			actualResults, actualError := processPlanner.plan(pd)
+			if tt.expectErrorToContain == "" {
+				assert.NoError(t, actualError)
+			} else {
+				assert.Error(t, actualError)
+				assert.Contains(t, actualError.Error(), tt.expectErrorToContain)
+			}
+			assert.Equal(t, tt.expectedResults, actualResults)
+		})
+	}
+}
+
+func Test_DefaultPlanner_Plan_ConvertToUEFI(t *testing.T) {
+	pd := persistentDisk{uri: "disk/uri"}
+	for _, tt := range []struct {
+		name                 string
+		request              ImageImportRequest
+		inspectionResults    *pb.InspectionResults
+		expectErrorToContain string
+		expectedResults      *processingPlan
+	}{
+		{
+			name:    "Convert Linux BIOS disk",
+			request: ImageImportRequest{WorkflowDir: "workflowroot", ConvertToUEFI: true},
+			inspectionResults: &pb.InspectionResults{
+				OsCount:      1,
+				OsRelease:    &pb.OsRelease{CliFormatted: "debian-12"},
+				BiosBootable: true,
+			},
+			expectedResults: &processingPlan{
+				requiredLicenses:           []string{"projects/debian-cloud/global/licenses/debian-12-bookworm"},
+				requiredFeatures:           []*compute.GuestOsFeature{{Type: "UEFI_COMPATIBLE"}},
+				translationWorkflowPath:    "workflowroot/image_import/debian/translate_debian_12.wf.json",
+				detectedOs:                 distro.FromGcloudOSArgumentMustParse("debian-12"),
+				uefiConversionWorkflowPath: "workflowroot/image_import/uefi/convert_to_uefi_linux.wf.json",
+			},
+		},
+		{
+			name:    "Convert Windows BIOS disk",
+			request: ImageImportRequest{WorkflowDir: "workflowroot", ConvertToUEFI: true, OS: "windows-2019"},
+			inspectionResults: &pb.InspectionResults{
+				BiosBootable: true,
+			},
+			expectedResults: &processingPlan{
+				requiredLicenses:           []string{"projects/windows-cloud/global/licenses/windows-server-2019-dc"},
+				requiredFeatures:           []*compute.GuestOsFeature{{Type: "WINDOWS"}, {Type: "UEFI_COMPATIBLE"}},
+				translationWorkflowPath:    "workflowroot/image_import/windows/translate_windows_2019.wf.json",
+				uefiConversionWorkflowPath: "workflowroot/image_import/uefi/convert_to_uefi_windows.wf.json",
+			},
+		},
+		{
+			name:    "Skip conversion when a UEFI bootloader exists",
+			request: ImageImportRequest{WorkflowDir: "workflowroot", ConvertToUEFI: true},
+			inspectionResults: &pb.InspectionResults{
+				OsCount:      1,
+				OsRelease:    &pb.OsRelease{CliFormatted: "debian-12"},
+				BiosBootable: true,
+				UefiBootable: true,
+			},
+			expectedResults: &processingPlan{
+				requiredLicenses:        []string{"projects/debian-cloud/global/licenses/debian-12-bookworm"},
+				requiredFeatures:        []*compute.GuestOsFeature{{Type: "UEFI_COMPATIBLE"}},
+				translationWorkflowPath: "workflowroot/image_import/debian/translate_debian_12.wf.json",
+				detectedOs:              distro.FromGcloudOSArgumentMustParse("debian-12"),
+			},
+		},
+		{
+			name:    "Fail for FreeBSD",
+			request: ImageImportRequest{WorkflowDir: "workflowroot", ConvertToUEFI: true, OS: "freebsd-14"},
+			inspectionResults: &pb.InspectionResults{
+				BiosBootable: true,
+			},
+			expectErrorToContain: "-convert_to_uefi is not supported for FreeBSD",
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+			mockInspector := mock_disk.NewMockInspector(mockCtrl)
+			mockInspector.EXPECT().Inspect(pd.uri).Return(tt.inspectionResults, nil)
+			processPlanner := newProcessPlanner(tt.request, mockInspector, logging.NewToolLogger("test"))
+			actualResults, actualError := processPlanner.plan(pd)
+			if tt.expectErrorToContain == "" {
+				assert.NoError(t, actualError)
+			} else {
+				assert.Error(t, actualError)
+				assert.Contains(t, actualError.Error(), tt.expectErrorToContain)
+			}
+			assert.Equal(t, tt.expectedResults, actualResults)
+		})
+	}
+}
+
+func Test_DefaultPlanner_Plan_AddsExportedMetadata(t *testing.T) {
+	pd := persistentDisk{uri: "disk/uri"}
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	mockInspector := mock_disk.NewMockInspector(mockCtrl)
+	mockInspector.EXPECT().Inspect(pd.uri).Return(&pb.InspectionResults{
+		OsCount:      1,
+		OsRelease:    &pb.OsRelease{CliFormatted: "debian-12"},
+		BiosBootable: true,
+	}, nil)
+	processPlanner := newProcessPlanner(ImageImportRequest{
+		WorkflowDir: "workflowroot",
+		Licenses: []string{
+			"projects/debian-cloud/global/licenses/debian-12-bookworm",
+			"projects/my-project/global/licenses/my-license",
+		},
+		GuestOSFeatures: []string{"GVNIC", "UEFI_COMPATIBLE"},
+	}, mockInspector, logging.NewToolLogger("test"))
+
+	actualResults, actualError := processPlanner.plan(pd)
+	assert.NoError(t, actualError)
+	assert.Equal(t, []string{
+		"projects/debian-cloud/global/licenses/debian-12-bookworm",
+		"projects/my-project/global/licenses/my-license",
+	}, actualResults.requiredLicenses)
+	assert.Equal(t, []*compute.GuestOsFeature{{Type: "GVNIC"}, {Type: "UEFI_COMPATIBLE"}}, actualResults.requiredFeatures)
+}
+
+func Test_DefaultPlanner_Plan_DropsExportedOSLicenses_WhenOSIsSpecified(t *testing.T) {
+	pd := persistentDisk{uri: "disk/uri"}
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	mockInspector := mock_disk.NewMockInspector(mockCtrl)
+	mockInspector.EXPECT().Inspect(pd.uri).Return(&pb.InspectionResults{
+		OsCount:      1,
+		OsRelease:    &pb.OsRelease{CliFormatted: "debian-12"},
+		BiosBootable: true,
+	}, nil)
+	processPlanner := newProcessPlanner(ImageImportRequest{
+		WorkflowDir: "workflowroot",
+		OS:          "ubuntu-2204",
+		Licenses: []string{
+			"projects/debian-cloud/global/licenses/debian-12-bookworm",
+			"projects/my-project/global/licenses/my-license",
+		},
+	}, mockInspector, logging.NewToolLogger("test"))
+	settings, err := daisyutils.GetTranslationSettings("ubuntu-2204")
+	assert.NoError(t, err)
+
+	actualResults, actualError := processPlanner.plan(pd)
+	assert.NoError(t, actualError)
+	assert.Equal(t, []string{
+		settings.LicenseURI,
+		"projects/my-project/global/licenses/my-license",
+	}, actualResults.requiredLicenses)
+}
@@ -16,7 +16,10 @@ package importer
 
 import (
 	daisyCompute "github.com/GoogleCloudPlatform/compute-daisy/compute"
+	"google.golang.org/api/compute/v1"
 
+	"github.com/GoogleCloudPlatform/compute-image-import/cli_tools/common/disk"
+	"github.com/GoogleCloudPlatform/compute-image-import/cli_tools/common/utils/daisyutils"
 	"github.com/GoogleCloudPlatform/compute-image-import/cli_tools/common/utils/logging"
 )
 
@@ -33,17 +36,24 @@ type processor interface {
 
 // processorProvider allows the processor to be determined after the pd has been inflated.
 type processorProvider interface {
-	provide(pd persistentDisk) ([]processor, error)
+	// provide returns the processors for pd. additionalDisks are the other disks of
+	// pd's LVM volume group, when it spans multiple disks.
+	provide(pd persistentDisk, additionalDisks []persistentDisk) ([]processor, error)
 }
 
 type defaultProcessorProvider struct {
 	ImageImportRequest
-	computeClient daisyCompute.Client
-	planner       processPlanner
-	logger        logging.Logger
+	computeClient   daisyCompute.Client
+	workflowClients daisyutils.WorkflowClients
+	planner         processPlanner
+	inspector       disk.Inspector
+	logger          logging.Logger
 }
 
-func (d defaultProcessorProvider) provide(pd persistentDisk) ([]processor, error) {
+func (d defaultProcessorProvider) provide(pd persistentDisk, additionalDisks []persistentDisk) ([]processor, error) {
+	// Later workflows run in the zone of the inflated disk, in case
+	// inflation failed over to another zone.
+	d.Zone = pd.zone(d.Zone)
 
 	if d.DataDisk {
 		return []processor{
@@ -52,12 +62,22 @@ func (d defaultProcessorProvider) provide(pd persistentDisk) ([]processor, error
 				d.Family, d.ImageName)}, nil
 	}
 
+	if d.canSkipTranslation(additionalDisks) {
+		d.logger.User("The licenses from -" + MetadataFileFlag + " show that the disk is already configured " +
+			"for Compute Engine. Skipping translation.")
+		return []processor{d.newExportedImageProcessor(pd)}, nil
+	}
+
 	plan, err := d.planner.plan(pd)
 	if err != nil {
 		return nil, err
 	}
 
 	var processors []processor
+	if plan.uefiConversionWorkflowPath != "" {
+		processors = append(processors, newUEFIConversionProcessor(d.ImageImportRequest, d.workflowClients,
+			plan.uefiConversionWorkflowPath, plan.rootDevice, d.computeClient, d.inspector, d.logger))
+	}
 	if plan.metadataChangesRequired() {
 		p := newMetadataProcessor(d.ImageImportRequest.Project, d.ImageImportRequest.Zone, d.computeClient)
 		p.requiredLicenses = plan.requiredLicenses
@@ -65,9 +85,70 @@ func (d defaultProcessorProvider) provide(pd persistentDisk) ([]processor, error
 		processors = append(processors, p)
 	}
 
-	bootableDiskProcessor := newBootableDiskProcessor(d.ImageImportRequest, plan.translationWorkflowPath, d.logger, plan.detectedOs)
-	if err != nil {
-		return nil, err
+	// The translation worker needs the additional disks to mount the root filesystem.
+	request := d.ImageImportRequest
+	for _, additionalDisk := range additionalDisks {
+		dataDisk, err := disk.NewDisk(d.Project, additionalDisk.zone(d.Zone), daisyutils.GetResourceID(additionalDisk.uri))
+		if err != nil {
+			return nil, err
+		}
+		request.DataDisks = append(request.DataDisks, dataDisk)
+	}
+
+	bootableDiskProcessor := newBootableDiskProcessor(request, d.workflowClients, plan.translationWorkflowPath, d.logger, plan.detectedOs, plan.rootDevice)
+	processors = append(processors, bootableDiskProcessor)
+
+	// Without consolidation, the additional disks are kept as images that
+	// are attached alongside the boot disk.
+	for i, additionalDisk := range additionalDisks {
+		processors = append(processors, newDataDiskProcessor(additionalDisk, d.computeClient, d.Project,
+			d.Labels, d.StorageLocation, d.Description, "", additionalImageName(d.ImageName, i+2)))
+	}
+	return processors, nil
+}
+
+// canSkipTranslation returns whether the licenses from -metadata_file show that the disk
+// was exported from Compute Engine, and the request doesn't ask for the disk to be
+// translated or otherwise modified.
+func (d defaultProcessorProvider) canSkipTranslation(additionalDisks []persistentDisk) bool {
+	if d.CustomWorkflow != "" || d.OS != "" || d.ConvertToUEFI || d.RootPartition != "" || d.SelectOS != "" {
+		return false
+	}
+	return len(additionalDisks) == 0 && hasOSLicense(d.Licenses)
+}
+
+// newExportedImageProcessor creates an image from a disk that was exported from Compute Engine,
+// restoring the image's licenses, guest OS features, and architecture.
+func (d defaultProcessorProvider) newExportedImageProcessor(pd persistentDisk) processor {
+	p := newDataDiskProcessor(pd, d.computeClient, d.Project,
+		d.Labels, d.StorageLocation, d.Description,
+		d.Family, d.ImageName)
+	p.request.Licenses = d.Licenses
+	p.request.Architecture = d.Architecture
+	for _, feature := range d.GuestOSFeatures {
+		p.request.GuestOsFeatures = append(p.request.GuestOsFeatures, &compute.GuestOsFeature{Type: feature})
+	}
+	return p
+}
+
+// hasOSLicense returns whether licenses include the license of an OS that can be imported.
+func hasOSLicense(licenses []string) bool {
+	for _, license := range licenses {
+		if isOSLicense(license) {
+			return true
+		}
+	}
+	return false
+}
+
+// isOSLicense returns whether license is the license of an OS that can be imported.
+func isOSLicense(license string) bool {
+	disk := &compute.Disk{Licenses: []string{license}}
+	for _, osID := range daisyutils.GetSortedOSIDs() {
+		settings, err := daisyutils.GetTranslationSettings(osID)
+		if err == nil && settings.LicenseURI != "" && hasLicense(disk, settings.LicenseURI) {
+			return true
+		}
 	}
-	return append(processors, bootableDiskProcessor), nil
+	return false
 }
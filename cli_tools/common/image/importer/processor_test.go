@@ -19,6 +19,7 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"google.golang.org/api/compute/v1"
 
 	"github.com/GoogleCloudPlatform/compute-image-import/cli_tools/common/utils/logging"
 )
@@ -30,7 +31,7 @@ func Test_DefaultProcessorProvider_SkipsPlanningForDataDisk(t *testing.T) {
 		},
 	}
 
-	processors, err := processorProvider.provide(persistentDisk{})
+	processors, err := processorProvider.provide(persistentDisk{}, nil)
 	assert.NoError(t, err)
 	assert.Len(t, processors, 1)
 	assert.IsType(t, &dataDiskProcessor{}, processors[0])
@@ -49,7 +50,7 @@ func Test_DefaultProcessorProvider_IncludesMetadataStepWhenMetadataChangesRequir
 		},
 		logger: logging.NewToolLogger("test"),
 	}
-	processors, err := processorProvider.provide(persistentDisk{})
+	processors, err := processorProvider.provide(persistentDisk{}, nil)
 	assert.NoError(t, err)
 	assert.Len(t, processors, 2)
 	assert.IsType(t, &metadataProcessor{}, processors[0])
@@ -68,17 +69,73 @@ func Test_DefaultProcessorProvider_SkipsMetadataStepWhenNoChangesRequired(t *tes
 		},
 		logger: logging.NewToolLogger("test"),
 	}
-	processors, err := processorProvider.provide(persistentDisk{})
+	processors, err := processorProvider.provide(persistentDisk{}, nil)
 	assert.NoError(t, err)
 	assert.Len(t, processors, 1)
 	assert.IsType(t, &bootableDiskProcessor{}, processors[0])
 }
 
+func Test_DefaultProcessorProvider_IncludesUEFIConversionStepFirst(t *testing.T) {
+	processorProvider := defaultProcessorProvider{
+		ImageImportRequest: ImageImportRequest{
+			WorkflowDir: "../../../../daisy_workflows",
+		},
+		planner: mockProcessPlanner{
+			result: &processingPlan{
+				requiredFeatures:           []*compute.GuestOsFeature{{Type: "UEFI_COMPATIBLE"}},
+				translationWorkflowPath:    opensuse15workflow,
+				uefiConversionWorkflowPath: "../../../../daisy_workflows/image_import/uefi/convert_to_uefi_linux.wf.json",
+			},
+		},
+		logger: logging.NewToolLogger("test"),
+	}
+	processors, err := processorProvider.provide(persistentDisk{}, nil)
+	assert.NoError(t, err)
+	assert.Len(t, processors, 3)
+	assert.IsType(t, &uefiConversionProcessor{}, processors[0])
+	assert.IsType(t, &metadataProcessor{}, processors[1])
+	assert.IsType(t, &bootableDiskProcessor{}, processors[2])
+}
+
+func Test_DefaultProcessorProvider_AttachesAndPublishesAdditionalDisks(t *testing.T) {
+	processorProvider := defaultProcessorProvider{
+		ImageImportRequest: ImageImportRequest{
+			Project:     "project",
+			Zone:        "zone",
+			ImageName:   "image",
+			Family:      "family",
+			WorkflowDir: "../../../../daisy_workflows",
+		},
+		planner: mockProcessPlanner{
+			result: &processingPlan{
+				translationWorkflowPath: opensuse15workflow,
+			},
+		},
+		logger: logging.NewToolLogger("test"),
+	}
+	processors, err := processorProvider.provide(persistentDisk{}, []persistentDisk{
+		{uri: "zones/zone/disks/disk-2"},
+		{uri: "zones/zone/disks/disk-3"},
+	})
+	assert.NoError(t, err)
+	assert.Len(t, processors, 3)
+	bootableDiskProcessor := processors[0].(*bootableDiskProcessor)
+	assert.Len(t, bootableDiskProcessor.request.DataDisks, 2)
+	assert.Equal(t, "projects/project/zones/zone/disks/disk-2", bootableDiskProcessor.request.DataDisks[0].GetURI())
+	assert.Equal(t, "projects/project/zones/zone/disks/disk-3", bootableDiskProcessor.request.DataDisks[1].GetURI())
+
+	for i, expected := range []string{"image-disk-2", "image-disk-3"} {
+		dataDiskProcessor := processors[i+1].(*dataDiskProcessor)
+		assert.Equal(t, expected, dataDiskProcessor.request.Name)
+		assert.Empty(t, dataDiskProcessor.request.Family)
+	}
+}
+
 func Test_DefaultProcessorProvider_FailsWhenPlanningFails(t *testing.T) {
 	processorProvider := defaultProcessorProvider{
 		planner: mockProcessPlanner{err: errors.New("planning failed")},
 	}
-	_, err := processorProvider.provide(persistentDisk{})
+	_, err := processorProvider.provide(persistentDisk{}, nil)
 	assert.Error(t, err, "planning failed")
 }
 
@@ -90,3 +147,57 @@ type mockProcessPlanner struct {
 func (m mockProcessPlanner) plan(pd persistentDisk) (*processingPlan, error) {
 	return m.result, m.err
 }
+
+func Test_DefaultProcessorProvider_SkipsTranslation_WhenLicensesIncludeOS(t *testing.T) {
+	processorProvider := defaultProcessorProvider{
+		ImageImportRequest: ImageImportRequest{
+			Project:         "project",
+			ImageName:       "image",
+			Family:          "family",
+			Licenses:        []string{"projects/debian-cloud/global/licenses/debian-12-bookworm"},
+			GuestOSFeatures: []string{"UEFI_COMPATIBLE"},
+			Architecture:    "X86_64",
+		},
+		planner: mockProcessPlanner{err: errors.New("unexpected planning")},
+		logger:  logging.NewToolLogger("test"),
+	}
+	processors, err := processorProvider.provide(persistentDisk{uri: "zones/zone/disks/disk"}, nil)
+	assert.NoError(t, err)
+	assert.Len(t, processors, 1)
+	dataDiskProcessor := processors[0].(*dataDiskProcessor)
+	assert.Equal(t, "image", dataDiskProcessor.request.Name)
+	assert.Equal(t, "family", dataDiskProcessor.request.Family)
+	assert.Equal(t, []string{"projects/debian-cloud/global/licenses/debian-12-bookworm"}, dataDiskProcessor.request.Licenses)
+	assert.Equal(t, []*compute.GuestOsFeature{{Type: "UEFI_COMPATIBLE"}}, dataDiskProcessor.request.GuestOsFeatures)
+	assert.Equal(t, "X86_64", dataDiskProcessor.request.Architecture)
+}
+
+func Test_DefaultProcessorProvider_Translates_WhenLicensesIncludeOSAndDiskIsModified(t *testing.T) {
+	for name, request := range map[string]ImageImportRequest{
+		"convert_to_uefi": {ConvertToUEFI: true},
+		"root_partition":  {RootPartition: "/dev/sda2"},
+		"select_os":       {SelectOS: "debian-12"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			request.Licenses = []string{"projects/debian-cloud/global/licenses/debian-12-bookworm"}
+			processorProvider := defaultProcessorProvider{
+				ImageImportRequest: request,
+				planner:            mockProcessPlanner{err: errors.New("planning failed")},
+				logger:             logging.NewToolLogger("test"),
+			}
+			_, err := processorProvider.provide(persistentDisk{}, nil)
+			assert.EqualError(t, err, "planning failed")
+		})
+	}
+}
+
+func Test_DefaultProcessorProvider_Translates_WhenLicensesDoNotIncludeOS(t *testing.T) {
+	processorProvider := defaultProcessorProvider{
+		ImageImportRequest: ImageImportRequest{
+			Licenses: []string{"projects/compute-image-import/global/licenses/virtual-disk-import"},
+		},
+		planner: mockProcessPlanner{err: errors.New("planning failed")},
+	}
+	_, err := processorProvider.provide(persistentDisk{}, nil)
+	assert.EqualError(t, err, "planning failed")
+}
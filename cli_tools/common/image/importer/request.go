@@ -27,12 +27,18 @@ import (
 
 // Flags that are validated.
 const (
-	ImageFlag          = "image_name"
-	ClientFlag         = "client_id"
-	BYOLFlag           = "byol"
-	DataDiskFlag       = "data_disk"
-	OSFlag             = "os"
-	CustomWorkflowFlag = "custom_translate_workflow"
+	ImageFlag             = "image_name"
+	ClientFlag            = "client_id"
+	BYOLFlag              = "byol"
+	DataDiskFlag          = "data_disk"
+	OSFlag                = "os"
+	CustomWorkflowFlag    = "custom_translate_workflow"
+	RootPartitionFlag     = "root_partition"
+	SelectOSFlag          = "select_os"
+	ConvertToUEFIFlag     = "convert_to_uefi"
+	AdditionalSourcesFlag = "additional_source_files"
+	ConsolidateLVMFlag    = "consolidate_lvm"
+	MetadataFileFlag      = "metadata_file"
 )
 
 func (args *ImageImportRequest) validate() error {
@@ -52,6 +58,53 @@ func (args *ImageImportRequest) validate() error {
 		return fmt.Errorf("-%s and -%s can't be both specified",
 			OSFlag, CustomWorkflowFlag)
 	}
+	if (args.RootPartition != "" || args.SelectOS != "") && (args.DataDisk || args.CustomWorkflow != "") {
+		return fmt.Errorf("when -%s or -%s is specified, -%s and -%s should be empty",
+			RootPartitionFlag, SelectOSFlag, DataDiskFlag, CustomWorkflowFlag)
+	}
+	if args.RootPartition != "" && args.SelectOS != "" {
+		return fmt.Errorf("-%s and -%s can't be both specified",
+			RootPartitionFlag, SelectOSFlag)
+	}
+	if args.SelectOS != "" && args.OS != "" {
+		return fmt.Errorf("-%s and -%s can't be both specified",
+			SelectOSFlag, OSFlag)
+	}
+	if args.ConvertToUEFI && (args.DataDisk || args.CustomWorkflow != "") {
+		return fmt.Errorf("when -%s is specified, -%s and -%s should be empty",
+			ConvertToUEFIFlag, DataDiskFlag, CustomWorkflowFlag)
+	}
+	if len(args.AdditionalSources) > 0 && (args.DataDisk || len(args.DataDisks) > 0 || args.CustomWorkflow != "") {
+		return fmt.Errorf("when -%s is specified, -%s and -%s should be empty",
+			AdditionalSourcesFlag, DataDiskFlag, CustomWorkflowFlag)
+	}
+	if len(args.AdditionalSources) > 0 && !args.ConsolidateLVM && args.OS == "" {
+		return fmt.Errorf("-%s is required when -%s is specified without -%s",
+			OSFlag, AdditionalSourcesFlag, ConsolidateLVMFlag)
+	}
+	if len(args.AdditionalSources) > 0 && !args.ConsolidateLVM && args.ConvertToUEFI {
+		return fmt.Errorf("-%s requires -%s when -%s is specified",
+			ConvertToUEFIFlag, ConsolidateLVMFlag, AdditionalSourcesFlag)
+	}
+	if len(args.AdditionalSources) > 0 && !args.ConsolidateLVM {
+		// The longest name is that of the last additional disk.
+		lastName := additionalImageName(args.ImageName, len(args.AdditionalSources)+1)
+		if len(lastName) > maxImageNameLength {
+			return fmt.Errorf("-%s is too long: when -%s is specified without -%s, additional disks "+
+				"are imported as images named like %s, which exceeds %d characters",
+				ImageFlag, AdditionalSourcesFlag, ConsolidateLVMFlag, lastName, maxImageNameLength)
+		}
+	}
+	if (len(args.Licenses) > 0 || len(args.GuestOSFeatures) > 0) && (args.DataDisk || args.CustomWorkflow != "") {
+		return fmt.Errorf("when -%s is specified, -%s and -%s should be empty",
+			MetadataFileFlag, DataDiskFlag, CustomWorkflowFlag)
+	}
+	if args.ConsolidateLVM && len(args.AdditionalSources) == 0 {
+		return fmt.Errorf("-%s requires -%s", ConsolidateLVMFlag, AdditionalSourcesFlag)
+	}
+	if args.RootPartition != "" && !strings.HasPrefix(args.RootPartition, "/dev/") {
+		return fmt.Errorf("-%s must be a block device, such as /dev/sda2", RootPartitionFlag)
+	}
 	if !strings.HasSuffix(args.ScratchBucketGcsPath, args.ExecutionID) {
 		return fmt.Errorf("Scratch bucket should have been namespaced with execution ID")
 	}
@@ -60,9 +113,23 @@ func (args *ImageImportRequest) validate() error {
 			return err
 		}
 	}
+	if args.SelectOS != "" {
+		if err := daisyutils.ValidateOS(args.SelectOS); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
+// maxImageNameLength is the maximum length of a Compute Engine image name.
+const maxImageNameLength = 63
+
+// additionalImageName returns the name of the image created from an additional source,
+// where diskNumber is the disk's position after the boot disk, which is disk 1.
+func additionalImageName(imageName string, diskNumber int) string {
+	return fmt.Sprintf("%s-disk-%d", imageName, diskNumber)
+}
+
 func (args *ImageImportRequest) checkRequiredArguments() error {
 	if args.ExecutionID == "" {
 		return errors.New("execution_id has to be specified")
@@ -138,6 +205,35 @@ type ImageImportRequest struct {
 	NestedVirtualizationEnabled bool
 	WorkerMachineSeries         []string
 	EndpointsOverride           daisyutils.EndpointsOverride
+	EmitWorkflowsDir            string
+	EmitWorkflowsOnly           bool
+
+	// QuotaBudget is set when the request is one of several imports that run in parallel.
+	QuotaBudget *daisyutils.QuotaBudget
+
+	// RootPartition and SelectOS choose which OS to import when the disk
+	// has more than one. RootPartition is a block device, such as /dev/sda2;
+	// SelectOS uses the format of OS, such as ubuntu-2204.
+	RootPartition string
+	SelectOS      string
+
+	// ConvertToUEFI converts a BIOS-booted disk to boot with UEFI prior to translation.
+	ConvertToUEFI bool
+
+	// AdditionalSources are the other disks of a boot disk whose root filesystem
+	// is on an LVM volume group that spans multiple disks. When ConsolidateLVM is
+	// set, the volume group is moved onto the boot disk prior to translation.
+	// Otherwise, each additional disk is imported as an image.
+	AdditionalSources []Source
+	ConsolidateLVM    bool
+
+	// Licenses, GuestOSFeatures, and Architecture restore the metadata of an image
+	// that was exported from Compute Engine; see -metadata_file. When Licenses
+	// include the license of an OS that Compute Engine supports, the disk is
+	// already configured for Compute Engine, and isn't translated.
+	Licenses        []string
+	GuestOSFeatures []string
+	Architecture    string
 }
 
 // FixBYOLAndOSArguments fixes the user's arguments for the --os and --byol flags
@@ -181,5 +277,8 @@ func (args ImageImportRequest) EnvironmentSettings() daisyutils.EnvironmentSetti
 		Tool:                        args.Tool,
 		NestedVirtualizationEnabled: args.NestedVirtualizationEnabled,
 		WorkerMachineSeries:         args.WorkerMachineSeries,
+		QuotaBudget:                 args.QuotaBudget,
+		EmitWorkflowsDir:            args.EmitWorkflowsDir,
+		EmitWorkflowsOnly:           args.EmitWorkflowsOnly,
 	}
 }
@@ -16,6 +16,7 @@ package importer
 
 import (
 	"fmt"
+	"strings"
 	"testing"
 	"time"
 
@@ -189,6 +190,7 @@ func makeValidRequest() ImageImportRequest {
 		Subnet:                "default",
 		SysprepWindows:        true,
 		Timeout:               time.Hour,
+		Tool:                  daisyutils.Tool{HumanReadableName: "image import", ResourceLabelName: "image-import"},
 		UefiCompatible:        true,
 		Zone:                  "us-central1-a",
 	}
@@ -234,15 +236,87 @@ func Test_validate_ChecksForConflictingArguments(t *testing.T) {
 			request:       ImageImportRequest{BYOL: true, DataDisk: true},
 			expectedError: "when -byol is specified, -data_disk, -os, and -custom_translate_workflow have to be empty",
 		},
+		{
+			request:       ImageImportRequest{RootPartition: "/dev/sda2", DataDisk: true},
+			expectedError: "when -root_partition or -select_os is specified, -data_disk and -custom_translate_workflow should be empty",
+		},
+		{
+			request:       ImageImportRequest{SelectOS: "ubuntu-2204", CustomWorkflow: "workflow.json"},
+			expectedError: "when -root_partition or -select_os is specified, -data_disk and -custom_translate_workflow should be empty",
+		},
+		{
+			request:       ImageImportRequest{RootPartition: "/dev/sda2", SelectOS: "ubuntu-2204"},
+			expectedError: "-root_partition and -select_os can't be both specified",
+		},
+		{
+			request:       ImageImportRequest{SelectOS: "ubuntu-2204", OS: "ubuntu-2204"},
+			expectedError: "-select_os and -os can't be both specified",
+		},
+		{
+			request:       ImageImportRequest{RootPartition: "sda2"},
+			expectedError: "-root_partition must be a block device, such as /dev/sda2",
+		},
+		{
+			request:       ImageImportRequest{ConvertToUEFI: true, DataDisk: true},
+			expectedError: "when -convert_to_uefi is specified, -data_disk and -custom_translate_workflow should be empty",
+		},
+		{
+			request:       ImageImportRequest{ConvertToUEFI: true, CustomWorkflow: "workflow.json"},
+			expectedError: "when -convert_to_uefi is specified, -data_disk and -custom_translate_workflow should be empty",
+		},
+		{
+			request:       ImageImportRequest{AdditionalSources: []Source{fileSource{}}, DataDisk: true},
+			expectedError: "when -additional_source_files is specified, -data_disk and -custom_translate_workflow should be empty",
+		},
+		{
+			request:       ImageImportRequest{AdditionalSources: []Source{fileSource{}}},
+			expectedError: "-os is required when -additional_source_files is specified without -consolidate_lvm",
+		},
+		{
+			request:       ImageImportRequest{AdditionalSources: []Source{fileSource{}}, OS: "ubuntu-2204", ConvertToUEFI: true},
+			expectedError: "-convert_to_uefi requires -consolidate_lvm when -additional_source_files is specified",
+		},
+		{
+			request:       ImageImportRequest{ConsolidateLVM: true},
+			expectedError: "-consolidate_lvm requires -additional_source_files",
+		},
+		{
+			name: "image name too long for additional disks",
+			request: ImageImportRequest{ImageName: strings.Repeat("i", 57),
+				AdditionalSources: []Source{fileSource{}, fileSource{}}, OS: "ubuntu-2204"},
+			expectedError: "-image_name is too long: when -additional_source_files is specified without -consolidate_lvm, " +
+				"additional disks are imported as images named like " + strings.Repeat("i", 57) + "-disk-3, " +
+				"which exceeds 63 characters",
+		},
+		{
+			name:          "metadata file with data disk",
+			request:       ImageImportRequest{Licenses: []string{"projects/p/global/licenses/l"}, DataDisk: true},
+			expectedError: "when -metadata_file is specified, -data_disk and -custom_translate_workflow should be empty",
+		},
+		{
+			name:          "metadata file with custom workflow",
+			request:       ImageImportRequest{GuestOSFeatures: []string{"GVNIC"}, CustomWorkflow: "workflow.json"},
+			expectedError: "when -metadata_file is specified, -data_disk and -custom_translate_workflow should be empty",
+		},
 	}
 	for _, tt := range flagtests {
 		t.Run(tt.name, func(t *testing.T) {
 			toValidate := makeValidRequest()
+			if tt.request.ImageName != "" {
+				toValidate.ImageName = tt.request.ImageName
+			}
 			toValidate.DataDisk = tt.request.DataDisk
 			toValidate.OS = tt.request.OS
 			toValidate.CustomWorkflow = tt.request.CustomWorkflow
 			toValidate.BYOL = tt.request.BYOL
 			toValidate.DataDisk = tt.request.DataDisk
+			toValidate.RootPartition = tt.request.RootPartition
+			toValidate.SelectOS = tt.request.SelectOS
+			toValidate.ConvertToUEFI = tt.request.ConvertToUEFI
+			toValidate.AdditionalSources = tt.request.AdditionalSources
+			toValidate.ConsolidateLVM = tt.request.ConsolidateLVM
+			toValidate.Licenses = tt.request.Licenses
+			toValidate.GuestOSFeatures = tt.request.GuestOSFeatures
 			err := toValidate.validate()
 			assert.EqualError(t, err, tt.expectedError)
 		})
@@ -290,3 +364,11 @@ func Test_EnvironmentSettings(t *testing.T) {
 	}
 	assert.Equal(t, expected, request.EnvironmentSettings())
 }
+
+func Test_validate_AllowsImageNameWhenAdditionalDiskNamesFit(t *testing.T) {
+	request := makeValidRequest()
+	request.ImageName = strings.Repeat("i", 56)
+	request.OS = "ubuntu-2204"
+	request.AdditionalSources = []Source{fileSource{}, fileSource{}}
+	assert.NoError(t, request.validate())
+}
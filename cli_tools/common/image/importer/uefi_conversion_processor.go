@@ -0,0 +1,119 @@
+//  Copyright 2026 Google Inc. All Rights Reserved.
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+package importer
+
+import (
+	daisy "github.com/GoogleCloudPlatform/compute-daisy"
+	daisyCompute "github.com/GoogleCloudPlatform/compute-daisy/compute"
+	"google.golang.org/api/compute/v1"
+
+	"github.com/GoogleCloudPlatform/compute-image-import/cli_tools/common/disk"
+	"github.com/GoogleCloudPlatform/compute-image-import/cli_tools/common/utils/daisyutils"
+	"github.com/GoogleCloudPlatform/compute-image-import/cli_tools/common/utils/logging"
+)
+
+// espHeadroomGb is the space added to the end of the disk prior to conversion. It holds
+// the EFI system partition and the backup GPT header.
+const espHeadroomGb = 1
+
+// uefiConversionProcessor converts a BIOS-booted disk to boot with UEFI. The disk is
+// grown to make room for an EFI system partition, converted by a worker, and then
+// re-inspected to verify that a UEFI bootloader is present.
+type uefiConversionProcessor struct {
+	project, zone     string
+	computeDiskClient daisyCompute.Client
+	worker            daisyutils.DaisyWorker
+	vars              map[string]string
+	inspector         disk.Inspector
+	logger            logging.Logger
+}
+
+func newUEFIConversionProcessor(request ImageImportRequest, workflowClients daisyutils.WorkflowClients,
+	wfPath string, rootDevice string, computeDiskClient daisyCompute.Client, inspector disk.Inspector, logger logging.Logger) processor {
+	vars := map[string]string{
+		"import_network": request.Network,
+		"import_subnet":  request.Subnet,
+	}
+	if request.ComputeServiceAccount != "" {
+		vars["compute_service_account"] = request.ComputeServiceAccount
+	}
+
+	workflowProvider := func() (*daisy.Workflow, error) {
+		wf, err := daisyutils.ParseWorkflow(wfPath, vars,
+			request.Project, request.Zone, request.ScratchBucketGcsPath, request.Oauth, request.Timeout.String(),
+			request.GcsLogsDisabled, request.CloudLogsDisabled, request.StdoutLogsDisabled)
+		if err != nil {
+			return nil, err
+		}
+		// Windows conversion finds the OS on its own.
+		if rootDevice != "" && !isWindowsWorkflow(wf) {
+			wf.AddVar("root_device", rootDevice)
+		}
+		return wf, nil
+	}
+
+	env := request.EnvironmentSettings()
+	env.WorkflowClients = workflowClients
+	if env.DaisyLogLinePrefix != "" {
+		env.DaisyLogLinePrefix += "-"
+	}
+	env.DaisyLogLinePrefix += "convert-to-uefi"
+	return &uefiConversionProcessor{
+		project:           request.Project,
+		zone:              request.Zone,
+		computeDiskClient: computeDiskClient,
+		worker:            daisyutils.NewDaisyWorker(workflowProvider, env, logger, createResourceLabeler(request)),
+		vars:              vars,
+		inspector:         inspector,
+		logger:            logger,
+	}
+}
+
+func (p *uefiConversionProcessor) process(pd persistentDisk) (persistentDisk, error) {
+	p.logger.User("Converting disk to boot with UEFI")
+	diskName := daisyutils.GetResourceID(pd.uri)
+	currentDisk, err := p.computeDiskClient.GetDisk(p.project, p.zone, diskName)
+	if err != nil {
+		return pd, daisy.Errf("Failed to get disk: %v", err)
+	}
+	newSizeGb := currentDisk.SizeGb + espHeadroomGb
+	if err = p.computeDiskClient.ResizeDisk(p.project, p.zone, diskName,
+		&compute.DisksResizeRequest{SizeGb: newSizeGb}); err != nil {
+		return pd, daisy.Errf("Failed to resize disk: %v", err)
+	}
+	pd.sizeGb = newSizeGb
+
+	p.vars["source_disk"] = pd.uri
+	if err = p.worker.Run(p.vars); err != nil {
+		return pd, err
+	}
+
+	// Inspect again to ensure the worker left a UEFI bootloader on the disk.
+	results, err := p.inspector.Inspect(pd.uri)
+	if err != nil {
+		return pd, daisy.Errf("Failed to verify UEFI conversion: %v", err)
+	}
+	if !results.GetUefiBootable() {
+		return pd, daisy.Errf("UEFI conversion finished, but a UEFI bootloader was not detected on the disk")
+	}
+	p.logger.User("Finished converting disk to boot with UEFI")
+	return pd, nil
+}
+
+func (p *uefiConversionProcessor) cancel(reason string) bool {
+	p.worker.Cancel(reason)
+	p.inspector.Cancel(reason)
+	return true
+}
@@ -0,0 +1,134 @@
+//  Copyright 2026 Google Inc. All Rights Reserved.
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+package importer
+
+import (
+	"errors"
+	"testing"
+
+	daisy "github.com/GoogleCloudPlatform/compute-daisy"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/api/compute/v1"
+
+	mock_disk "github.com/GoogleCloudPlatform/compute-image-import/cli_tools/common/disk/mocks"
+	"github.com/GoogleCloudPlatform/compute-image-import/cli_tools/common/utils/daisyutils"
+	"github.com/GoogleCloudPlatform/compute-image-import/cli_tools/common/utils/logging"
+	"github.com/GoogleCloudPlatform/compute-image-import/cli_tools/mocks"
+	"github.com/GoogleCloudPlatform/compute-image-import/proto/go/pb"
+)
+
+func TestUEFIConversionProcessor_Process(t *testing.T) {
+	diskURI := "projects/test-project/zones/test-zone/disks/disk-name"
+	for _, tt := range []struct {
+		name              string
+		workerError       error
+		inspectionResults *pb.InspectionResults
+		inspectionError   error
+		expectedError     string
+	}{
+		{
+			name:              "Succeeds when UEFI bootloader is detected",
+			inspectionResults: &pb.InspectionResults{UefiBootable: true},
+		},
+		{
+			name:          "Fails when worker fails",
+			workerError:   errors.New("worker failed"),
+			expectedError: "worker failed",
+		},
+		{
+			name:            "Fails when inspection fails",
+			inspectionError: errors.New("inspection failed"),
+			expectedError:   "Failed to verify UEFI conversion: inspection failed",
+		},
+		{
+			name:              "Fails when UEFI bootloader is not detected",
+			inspectionResults: &pb.InspectionResults{BiosBootable: true},
+			expectedError:     "UEFI conversion finished, but a UEFI bootloader was not detected on the disk",
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			computeClient := mocks.NewMockClient(ctrl)
+			computeClient.EXPECT().GetDisk("test-project", "test-zone", "disk-name").Return(&compute.Disk{SizeGb: 10}, nil)
+			computeClient.EXPECT().ResizeDisk("test-project", "test-zone", "disk-name",
+				&compute.DisksResizeRequest{SizeGb: 11}).Return(nil)
+			worker := mocks.NewMockDaisyWorker(ctrl)
+			worker.EXPECT().Run(map[string]string{"source_disk": diskURI}).Return(tt.workerError)
+			inspector := mock_disk.NewMockInspector(ctrl)
+			if tt.workerError == nil {
+				inspector.EXPECT().Inspect(diskURI).Return(tt.inspectionResults, tt.inspectionError)
+			}
+			processor := &uefiConversionProcessor{
+				project:           "test-project",
+				zone:              "test-zone",
+				computeDiskClient: computeClient,
+				worker:            worker,
+				vars:              map[string]string{},
+				inspector:         inspector,
+				logger:            logging.NewToolLogger("test"),
+			}
+			pd, err := processor.process(persistentDisk{uri: diskURI})
+			if tt.expectedError == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tt.expectedError)
+			}
+			assert.Equal(t, persistentDisk{uri: diskURI, sizeGb: 11}, pd)
+		})
+	}
+}
+
+func TestUEFIConversionProcessor_Process_FailsWhenResizeFails(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	computeClient := mocks.NewMockClient(ctrl)
+	computeClient.EXPECT().GetDisk("test-project", "test-zone", "disk-name").Return(&compute.Disk{SizeGb: 10}, nil)
+	computeClient.EXPECT().ResizeDisk("test-project", "test-zone", "disk-name", gomock.Any()).Return(errors.New("quota"))
+	processor := &uefiConversionProcessor{
+		project:           "test-project",
+		zone:              "test-zone",
+		computeDiskClient: computeClient,
+		logger:            logging.NewToolLogger("test"),
+	}
+	_, err := processor.process(persistentDisk{uri: "zones/test-zone/disks/disk-name"})
+	assert.EqualError(t, err, "Failed to resize disk: quota")
+}
+
+func TestUEFIConversionProcessor_PassesRootDeviceToLinuxWorkflow(t *testing.T) {
+	for _, tt := range []struct {
+		name        string
+		workflow    string
+		rootDevice  string
+		expectedVar string
+	}{
+		{"linux with root device", "convert_to_uefi_linux.wf.json", "/dev/sda3", "/dev/sda3"},
+		{"linux without root device", "convert_to_uefi_linux.wf.json", "", ""},
+		{"windows", "convert_to_uefi_windows.wf.json", "/dev/sda3", ""},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			processor := newUEFIConversionProcessor(defaultImportArgs(), daisyutils.WorkflowClients{},
+				"../../../../daisy_workflows/image_import/uefi/"+tt.workflow, tt.rootDevice,
+				nil, nil, logging.NewToolLogger(t.Name()))
+
+			realProcessor := processor.(*uefiConversionProcessor)
+			daisyutils.CheckWorkflow(realProcessor.worker, func(wf *daisy.Workflow, err error) {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expectedVar, wf.Vars["root_device"].Value)
+			})
+		})
+	}
+}
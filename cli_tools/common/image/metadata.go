@@ -0,0 +1,83 @@
+//  Copyright 2026 Google Inc. All Rights Reserved.
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+package image
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"google.golang.org/api/compute/v1"
+)
+
+// MetadataFileSuffix is appended to the URI of an exported file to name its metadata sidecar.
+const MetadataFileSuffix = ".metadata.json"
+
+// Metadata describes the fields of a Compute Engine image that aren't stored in its
+// disk file. The image exporter writes it next to the exported file, and the image
+// importer reads it to restore those fields.
+type Metadata struct {
+	SourceImage     string            `json:"sourceImage,omitempty"`
+	Description     string            `json:"description,omitempty"`
+	Family          string            `json:"family,omitempty"`
+	Labels          map[string]string `json:"labels,omitempty"`
+	Licenses        []string          `json:"licenses,omitempty"`
+	GuestOSFeatures []string          `json:"guestOsFeatures,omitempty"`
+	Architecture    string            `json:"architecture,omitempty"`
+}
+
+// NewMetadata returns the metadata of image. Licenses and the source image are
+// relative URIs, such as projects/debian-cloud/global/licenses/debian-12-bookworm.
+func NewMetadata(image *compute.Image) Metadata {
+	m := Metadata{
+		SourceImage:  relativeURI(image.SelfLink),
+		Description:  image.Description,
+		Family:       image.Family,
+		Labels:       image.Labels,
+		Architecture: image.Architecture,
+	}
+	for _, license := range image.Licenses {
+		m.Licenses = append(m.Licenses, relativeURI(license))
+	}
+	for _, feature := range image.GuestOsFeatures {
+		m.GuestOSFeatures = append(m.GuestOSFeatures, feature.Type)
+	}
+	return m
+}
+
+// ParseMetadata parses a metadata sidecar.
+func ParseMetadata(content []byte) (Metadata, error) {
+	var m Metadata
+	if err := json.Unmarshal(content, &m); err != nil {
+		return m, fmt.Errorf("invalid image metadata: %v", err)
+	}
+	return m, nil
+}
+
+// Marshal returns the metadata sidecar's content.
+func (m Metadata) Marshal() ([]byte, error) {
+	content, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(content, '\n'), nil
+}
+
+func relativeURI(uri string) string {
+	if i := strings.Index(uri, "projects/"); i > 0 {
+		return uri[i:]
+	}
+	return uri
+}
@@ -0,0 +1,63 @@
+//  Copyright 2026 Google Inc. All Rights Reserved.
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+package image
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/api/compute/v1"
+)
+
+func TestNewMetadata(t *testing.T) {
+	m := NewMetadata(&compute.Image{
+		SelfLink:        "https://www.googleapis.com/compute/v1/projects/project/global/images/image",
+		Description:     "description",
+		Family:          "family",
+		Labels:          map[string]string{"key": "value"},
+		Licenses:        []string{"https://www.googleapis.com/compute/v1/projects/debian-cloud/global/licenses/debian-12-bookworm"},
+		GuestOsFeatures: []*compute.GuestOsFeature{{Type: "UEFI_COMPATIBLE"}, {Type: "GVNIC"}},
+		Architecture:    "X86_64",
+	})
+
+	assert.Equal(t, Metadata{
+		SourceImage:     "projects/project/global/images/image",
+		Description:     "description",
+		Family:          "family",
+		Labels:          map[string]string{"key": "value"},
+		Licenses:        []string{"projects/debian-cloud/global/licenses/debian-12-bookworm"},
+		GuestOSFeatures: []string{"UEFI_COMPATIBLE", "GVNIC"},
+		Architecture:    "X86_64",
+	}, m)
+}
+
+func TestMetadata_RoundTrip(t *testing.T) {
+	m := Metadata{
+		Family:          "family",
+		Licenses:        []string{"projects/debian-cloud/global/licenses/debian-12-bookworm"},
+		GuestOSFeatures: []string{"UEFI_COMPATIBLE"},
+	}
+	content, err := m.Marshal()
+	assert.NoError(t, err)
+	parsed, err := ParseMetadata(content)
+	assert.NoError(t, err)
+	assert.Equal(t, m, parsed)
+}
+
+func TestParseMetadata_ReturnsError_WhenContentIsInvalid(t *testing.T) {
+	_, err := ParseMetadata([]byte("not json"))
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid image metadata")
+}
@@ -0,0 +1,90 @@
+//  Copyright 2026 Google Inc. All Rights Reserved.
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+package image
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	daisy "github.com/GoogleCloudPlatform/compute-daisy"
+)
+
+const (
+	// PortableWorkflow prepares a disk to boot on another hypervisor. The path is
+	// relative to daisy_workflows.
+	PortableWorkflow = "export/make_portable.wf.json"
+
+	// PortableChangesKey is the serial output key with which PortableWorkflow reports
+	// the changes it made.
+	PortableChangesKey = "portable-changes"
+
+	// PortableChangesFileSuffix is appended to the URI of an exported file to name the
+	// record of the changes made to prepare it for another hypervisor.
+	PortableChangesFileSuffix = ".portable.json"
+)
+
+// PortableTargets are the hypervisors that an exported image can be prepared for.
+var PortableTargets = []string{"vmware", "kvm", "hyperv"}
+
+// ValidatePortableFor returns an error when portableFor is neither empty nor one of
+// PortableTargets. flagKey is used in the error message.
+func ValidatePortableFor(portableFor, flagKey string) error {
+	if portableFor == "" {
+		return nil
+	}
+	for _, target := range PortableTargets {
+		if portableFor == target {
+			return nil
+		}
+	}
+	return daisy.Errf("-%v must be one of %v", flagKey, strings.Join(PortableTargets, ", "))
+}
+
+// PortableChanges records how a disk was prepared to boot on another hypervisor.
+// Notes are steps that are left to the user.
+type PortableChanges struct {
+	Target  string   `json:"target"`
+	Distro  string   `json:"distro,omitempty"`
+	Changes []string `json:"changes"`
+	Notes   []string `json:"notes,omitempty"`
+}
+
+// ParsePortableChanges parses the value that PortableWorkflow reported with
+// PortableChangesKey.
+func ParsePortableChanges(value string) (PortableChanges, error) {
+	var c PortableChanges
+	if value == "" {
+		return c, daisy.Errf("The worker didn't report the changes made to prepare the disk for another hypervisor")
+	}
+	content, err := base64.StdEncoding.DecodeString(value)
+	if err == nil {
+		err = json.Unmarshal(content, &c)
+	}
+	if err != nil {
+		return c, fmt.Errorf("invalid record of the changes made to prepare the disk for another hypervisor: %v", err)
+	}
+	return c, nil
+}
+
+// Marshal returns the content of the record.
+func (c PortableChanges) Marshal() ([]byte, error) {
+	content, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(content, '\n'), nil
+}
@@ -0,0 +1,60 @@
+//  Copyright 2026 Google Inc. All Rights Reserved.
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+package image
+
+import (
+	"encoding/base64"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidatePortableFor(t *testing.T) {
+	for _, portableFor := range []string{"", "vmware", "kvm", "hyperv"} {
+		assert.NoError(t, ValidatePortableFor(portableFor, "portable_for"))
+	}
+	assert.EqualError(t, ValidatePortableFor("xen", "portable_for"), "-portable_for must be one of vmware, kvm, hyperv")
+}
+
+func TestParsePortableChanges(t *testing.T) {
+	value := base64.StdEncoding.EncodeToString([]byte(
+		`{"target":"kvm","distro":"debian","changes":["Installed qemu-guest-agent."],"notes":[]}`))
+
+	c, err := ParsePortableChanges(value)
+	assert.NoError(t, err)
+	assert.Equal(t, PortableChanges{
+		Target:  "kvm",
+		Distro:  "debian",
+		Changes: []string{"Installed qemu-guest-agent."},
+		Notes:   []string{},
+	}, c)
+
+	content, err := c.Marshal()
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"target":"kvm","distro":"debian","changes":["Installed qemu-guest-agent."]}`, string(content))
+}
+
+func TestParsePortableChanges_ReturnsError(t *testing.T) {
+	_, err := ParsePortableChanges("")
+	assert.EqualError(t, err, "The worker didn't report the changes made to prepare the disk for another hypervisor")
+
+	_, err = ParsePortableChanges("not base64!")
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid record of the changes")
+
+	_, err = ParsePortableChanges(base64.StdEncoding.EncodeToString([]byte("not json")))
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid record of the changes")
+}
@@ -16,8 +16,10 @@ package imagefile
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"math"
+	"os/exec"
 	"path"
 	"time"
 
@@ -68,6 +70,10 @@ type gcsInspector struct {
 func (inspector gcsInspector) Inspect(ctx context.Context, gcsURI string) (metadata Metadata, err error) {
 	operation := func() error {
 		metadata, err = inspector.inspectOnce(ctx, gcsURI)
+		if errors.Is(err, exec.ErrNotFound) {
+			// Retrying won't help when gcsfuse or qemu-img isn't installed.
+			return backoff.Permanent(err)
+		}
 		return err
 	}
 	return metadata, backoff.Retry(operation,
@@ -18,6 +18,7 @@ import (
 	"context"
 	"errors"
 	"io/ioutil"
+	"os/exec"
 	"path"
 	"testing"
 
@@ -106,6 +107,13 @@ func TestGCSInspector_DontRetryInspect_IfContextCancelled(t *testing.T) {
 	assert.EqualError(t, err, inspectionError)
 }
 
+func TestGCSInspector_DontRetryMount_IfGCSFuseIsNotInstalled(t *testing.T) {
+	gcsURI, client := setupClient(t, 0, 0, ImageInfo{})
+	client.(gcsInspector).fuseClient.(*mockGCSFuse).err = &exec.Error{Name: "gcsfuse", Err: exec.ErrNotFound}
+	_, err := client.Inspect(context.Background(), gcsURI)
+	assert.ErrorIs(t, err, exec.ErrNotFound)
+}
+
 func TestGCSInspector_PerformRetry_WhenMountingFails(t *testing.T) {
 	// Fail mounting three times, and then successfully mount.
 	mountFailures := 3
@@ -157,6 +165,7 @@ func setupClient(t *testing.T, mountFailures, inspectFailures int, qemuResult Im
 }
 
 type mockGCSFuse struct {
+	err               error
 	failuresRemaining int
 	expectedBucket    string
 	t                 *testing.T
@@ -165,6 +174,9 @@ type mockGCSFuse struct {
 
 func (m *mockGCSFuse) MountToTemp(ctx context.Context, bucket string) (string, error) {
 	assert.Equal(m.t, m.expectedBucket, bucket)
+	if m.err != nil {
+		return "", m.err
+	}
 	if m.failuresRemaining > 0 {
 		m.failuresRemaining--
 		err := errors.New(mountError)
@@ -0,0 +1,1876 @@
+//  Copyright 2026 Google Inc. All Rights Reserved.
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License
+
+package compute
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	daisyCompute "github.com/GoogleCloudPlatform/compute-daisy/compute"
+	computeAlpha "google.golang.org/api/compute/v0.alpha"
+	computeBeta "google.golang.org/api/compute/v0.beta"
+	"google.golang.org/api/compute/v1"
+	"google.golang.org/api/googleapi"
+)
+
+const fakeBasePath = "https://compute.googleapis.com/compute/v1/"
+
+// defaultFakeMachineTypes are created in each zone that's added with AddProject.
+var defaultFakeMachineTypes = map[string]int64{
+	"n1-standard-1": 1, "n1-standard-2": 2, "n1-standard-4": 4, "n1-standard-8": 8,
+	"n1-highcpu-4":  4,
+	"n2-standard-2": 2, "n2-standard-4": 4, "n2-standard-8": 8,
+	"e2-standard-2": 2, "e2-standard-4": 4, "e2-standard-8": 8,
+}
+
+// InstanceScript describes how instances behave after they're created, since the fake
+// doesn't run their guest OS.
+type InstanceScript struct {
+	// SerialPortOutput contains the output of each serial port. Each call to
+	// GetSerialPortOutput reveals one more chunk, so callers observe the output
+	// as it's written.
+	SerialPortOutput map[int64][]string
+
+	// GuestAttributes maps keys, such as "namespace/key", to their values.
+	GuestAttributes map[string]string
+
+	// StopWhenDone stops the instance after all of its serial port output was read.
+	StopWhenDone bool
+}
+
+type fakeSerialPort struct {
+	chunks   []string
+	revealed int
+	contents string
+}
+
+type fakeInstance struct {
+	instance *compute.Instance
+	script   InstanceScript
+	ports    map[int64]*fakeSerialPort
+}
+
+type fakeScript struct {
+	namePrefix string
+	script     InstanceScript
+}
+
+// FakeClient is a stateful, in-memory implementation of daisyCompute.Client. Resources
+// are created, read, and deleted with the same sequencing rules as Compute Engine: for
+// example, a disk that's attached to an instance can't be deleted, and an image can
+// only be created from a disk that exists. Operations complete immediately.
+//
+// Instances don't run; their serial port output and guest attributes are scripted
+// with ScriptInstances.
+type FakeClient struct {
+	mu sync.Mutex
+
+	projects        map[string]*compute.Project
+	zones           map[string]*compute.Zone
+	regions         map[string]*compute.Region
+	machineTypes    map[string]*compute.MachineType
+	disks           map[string]*compute.Disk
+	images          map[string]*compute.Image
+	instances       map[string]*fakeInstance
+	snapshots       map[string]*compute.Snapshot
+	machineImages   map[string]*compute.MachineImage
+	licenses        map[string]*compute.License
+	networks        map[string]*compute.Network
+	subnetworks     map[string]*compute.Subnetwork
+	firewallRules   map[string]*compute.Firewall
+	forwardingRules map[string]*compute.ForwardingRule
+	targetInstances map[string]*compute.TargetInstance
+
+	scripts    []fakeScript
+	operations []*compute.Operation
+	failures   map[string][]error
+	nextID     uint64
+}
+
+var _ daisyCompute.Client = (*FakeClient)(nil)
+
+// NewFakeClient creates a FakeClient without any resources. Use AddProject to
+// create projects, zones, and machine types.
+func NewFakeClient() *FakeClient {
+	return &FakeClient{
+		projects:        map[string]*compute.Project{},
+		zones:           map[string]*compute.Zone{},
+		regions:         map[string]*compute.Region{},
+		machineTypes:    map[string]*compute.MachineType{},
+		disks:           map[string]*compute.Disk{},
+		images:          map[string]*compute.Image{},
+		instances:       map[string]*fakeInstance{},
+		snapshots:       map[string]*compute.Snapshot{},
+		machineImages:   map[string]*compute.MachineImage{},
+		licenses:        map[string]*compute.License{},
+		networks:        map[string]*compute.Network{},
+		subnetworks:     map[string]*compute.Subnetwork{},
+		firewallRules:   map[string]*compute.Firewall{},
+		forwardingRules: map[string]*compute.ForwardingRule{},
+		targetInstances: map[string]*compute.TargetInstance{},
+		failures:        map[string][]error{},
+	}
+}
+
+// AddProject creates a project, along with its default network, the given zones,
+// their regions, and a set of common machine types in each zone.
+func (c *FakeClient) AddProject(project string, zones ...string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, found := c.projects[project]; !found {
+		c.nextID++
+		c.projects[project] = &compute.Project{
+			Name:     project,
+			Id:       c.nextID,
+			SelfLink: selfLink(project),
+		}
+		c.networks[key(project, "default")] = &compute.Network{
+			Name:                  "default",
+			AutoCreateSubnetworks: true,
+			SelfLink:              selfLink(project, "global", "networks", "default"),
+		}
+	}
+	for _, zone := range zones {
+		region := zone[:strings.LastIndex(zone, "-")]
+		c.zones[key(project, zone)] = &compute.Zone{
+			Name:     zone,
+			Status:   "UP",
+			Region:   selfLink(project, "regions", region),
+			SelfLink: selfLink(project, "zones", zone),
+		}
+		r, found := c.regions[key(project, region)]
+		if !found {
+			r = &compute.Region{Name: region, Status: "UP", SelfLink: selfLink(project, "regions", region)}
+			c.regions[key(project, region)] = r
+		}
+		r.Zones = append(r.Zones, selfLink(project, "zones", zone))
+		for name, cpus := range defaultFakeMachineTypes {
+			c.machineTypes[key(project, zone, name)] = &compute.MachineType{
+				Name:      name,
+				GuestCpus: cpus,
+				MemoryMb:  cpus * 3840,
+				Zone:      zone,
+				SelfLink:  selfLink(project, "zones", zone, "machineTypes", name),
+			}
+		}
+	}
+}
+
+// AddImage creates an image that exists before the test starts, such as a worker image.
+func (c *FakeClient) AddImage(project string, image *compute.Image) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	image = copyResource(image, &compute.Image{}).(*compute.Image)
+	image.Status = "READY"
+	image.SelfLink = selfLink(project, "global", "images", image.Name)
+	c.images[key(project, image.Name)] = image
+}
+
+// AddDisk creates a disk that exists before the test starts.
+func (c *FakeClient) AddDisk(project, zone string, disk *compute.Disk) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	disk = copyResource(disk, &compute.Disk{}).(*compute.Disk)
+	disk.Status = "READY"
+	disk.Zone = selfLink(project, "zones", zone)
+	disk.SelfLink = selfLink(project, "zones", zone, "disks", disk.Name)
+	c.disks[key(project, zone, disk.Name)] = disk
+}
+
+// AddLicense creates a license in project.
+func (c *FakeClient) AddLicense(project, name string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.licenses[key(project, name)] = &compute.License{
+		Name:     name,
+		SelfLink: selfLink(project, "global", "licenses", name),
+	}
+}
+
+// ScriptInstances sets the script of instances whose names start with namePrefix. It
+// applies to instances that are created afterwards. When multiple prefixes match, the
+// longest one is used.
+func (c *FakeClient) ScriptInstances(namePrefix string, script InstanceScript) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.scripts = append(c.scripts, fakeScript{namePrefix: namePrefix, script: script})
+}
+
+// FailNext makes the next call to method, such as "CreateDisk", return err. Failures
+// for the same method are returned in the order in which they were added.
+func (c *FakeClient) FailNext(method string, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.failures[method] = append(c.failures[method], err)
+}
+
+// Operations returns the operations that modified resources, in the order in which
+// they were run.
+func (c *FakeClient) Operations() []*compute.Operation {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]*compute.Operation{}, c.operations...)
+}
+
+// AttachDisk attaches a disk to an instance.
+func (c *FakeClient) AttachDisk(project, zone, instance string, d *compute.AttachedDisk) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.nextFailure("AttachDisk"); err != nil {
+		return err
+	}
+	inst, err := c.instance(project, zone, instance)
+	if err != nil {
+		return err
+	}
+	disk, err := c.attach(project, zone, inst.instance, d)
+	if err != nil {
+		return err
+	}
+	inst.instance.Disks = append(inst.instance.Disks, disk)
+	c.recordOperation("attachDisk", inst.instance.SelfLink, zone)
+	return nil
+}
+
+// DetachDisk detaches a disk from an instance.
+func (c *FakeClient) DetachDisk(project, zone, instance, disk string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.nextFailure("DetachDisk"); err != nil {
+		return err
+	}
+	inst, err := c.instance(project, zone, instance)
+	if err != nil {
+		return err
+	}
+	for i, attached := range inst.instance.Disks {
+		if attached.DeviceName == disk {
+			c.removeUser(attached.Source, inst.instance.SelfLink)
+			inst.instance.Disks = append(inst.instance.Disks[:i], inst.instance.Disks[i+1:]...)
+			c.recordOperation("detachDisk", inst.instance.SelfLink, zone)
+			return nil
+		}
+	}
+	return badRequest(fmt.Sprintf("No attached disk found with device name '%s'", disk))
+}
+
+// CreateDisk creates a disk, optionally from an image or snapshot.
+func (c *FakeClient) CreateDisk(project, zone string, d *compute.Disk) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.nextFailure("CreateDisk"); err != nil {
+		return err
+	}
+	created, err := c.createDisk(project, zone, d)
+	if err != nil {
+		return err
+	}
+	*d = *copyResource(created, &compute.Disk{}).(*compute.Disk)
+	return nil
+}
+
+// CreateDiskAlpha creates a disk using the alpha API.
+func (c *FakeClient) CreateDiskAlpha(project, zone string, d *computeAlpha.Disk) error {
+	v1 := copyResource(d, &compute.Disk{}).(*compute.Disk)
+	if err := c.CreateDisk(project, zone, v1); err != nil {
+		return err
+	}
+	copyResource(v1, d)
+	return nil
+}
+
+// CreateDiskBeta creates a disk using the beta API.
+func (c *FakeClient) CreateDiskBeta(project, zone string, d *computeBeta.Disk) error {
+	v1 := copyResource(d, &compute.Disk{}).(*compute.Disk)
+	if err := c.CreateDisk(project, zone, v1); err != nil {
+		return err
+	}
+	copyResource(v1, d)
+	return nil
+}
+
+// CreateForwardingRule creates a forwarding rule.
+func (c *FakeClient) CreateForwardingRule(project, region string, fr *compute.ForwardingRule) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.nextFailure("CreateForwardingRule"); err != nil {
+		return err
+	}
+	k := key(project, region, fr.Name)
+	if _, found := c.forwardingRules[k]; found {
+		return alreadyExists("forwardingRule", k)
+	}
+	fr.Region = selfLink(project, "regions", region)
+	fr.SelfLink = selfLink(project, "regions", region, "forwardingRules", fr.Name)
+	c.forwardingRules[k] = copyResource(fr, &compute.ForwardingRule{}).(*compute.ForwardingRule)
+	c.recordOperation("insert", fr.SelfLink, "")
+	return nil
+}
+
+// CreateFirewallRule creates a firewall rule.
+func (c *FakeClient) CreateFirewallRule(project string, i *compute.Firewall) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.nextFailure("CreateFirewallRule"); err != nil {
+		return err
+	}
+	k := key(project, i.Name)
+	if _, found := c.firewallRules[k]; found {
+		return alreadyExists("firewall", k)
+	}
+	i.SelfLink = selfLink(project, "global", "firewalls", i.Name)
+	c.firewallRules[k] = copyResource(i, &compute.Firewall{}).(*compute.Firewall)
+	c.recordOperation("insert", i.SelfLink, "")
+	return nil
+}
+
+// CreateImage creates an image from a disk, image, or snapshot.
+func (c *FakeClient) CreateImage(project string, i *compute.Image) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.nextFailure("CreateImage"); err != nil {
+		return err
+	}
+	k := key(project, i.Name)
+	if _, found := c.images[k]; found {
+		return alreadyExists("image", k)
+	}
+	image := copyResource(i, &compute.Image{}).(*compute.Image)
+	switch {
+	case i.SourceDisk != "":
+		disk, err := c.diskByURL(project, i.SourceDisk)
+		if err != nil {
+			return err
+		}
+		image.DiskSizeGb = disk.SizeGb
+		image.SourceDisk = disk.SelfLink
+		image.Licenses = mergeLicenses(disk.Licenses, i.Licenses)
+	case i.SourceImage != "":
+		source, err := c.imageByURL(project, i.SourceImage)
+		if err != nil {
+			return err
+		}
+		image.DiskSizeGb = source.DiskSizeGb
+		image.Licenses = mergeLicenses(source.Licenses, i.Licenses)
+	case i.SourceSnapshot != "":
+		snapshot, err := c.snapshotByURL(project, i.SourceSnapshot)
+		if err != nil {
+			return err
+		}
+		image.DiskSizeGb = snapshot.DiskSizeGb
+	case i.RawDisk == nil || i.RawDisk.Source == "":
+		return badRequest("Image source is required.")
+	}
+	image.Status = "READY"
+	image.CreationTimestamp = time.Now().Format(time.RFC3339)
+	image.SelfLink = selfLink(project, "global", "images", i.Name)
+	c.images[k] = image
+	c.recordOperation("insert", image.SelfLink, "")
+	*i = *copyResource(image, &compute.Image{}).(*compute.Image)
+	return nil
+}
+
+// CreateImageAlpha creates an image using the alpha API.
+func (c *FakeClient) CreateImageAlpha(project string, i *computeAlpha.Image) error {
+	v1 := copyResource(i, &compute.Image{}).(*compute.Image)
+	if err := c.CreateImage(project, v1); err != nil {
+		return err
+	}
+	copyResource(v1, i)
+	return nil
+}
+
+// CreateImageBeta creates an image using the beta API.
+func (c *FakeClient) CreateImageBeta(project string, i *computeBeta.Image) error {
+	v1 := copyResource(i, &compute.Image{}).(*compute.Image)
+	if err := c.CreateImage(project, v1); err != nil {
+		return err
+	}
+	copyResource(v1, i)
+	return nil
+}
+
+// CreateInstance creates an instance, creating its boot disk when InitializeParams are
+// used, and attaching existing disks. The instance starts in the RUNNING state.
+func (c *FakeClient) CreateInstance(project, zone string, i *compute.Instance) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.nextFailure("CreateInstance"); err != nil {
+		return err
+	}
+	k := key(project, zone, i.Name)
+	if _, found := c.instances[k]; found {
+		return alreadyExists("instance", k)
+	}
+	if i.MachineType != "" {
+		machineType := lastSegment(i.MachineType)
+		if _, found := c.machineTypes[key(project, zone, machineType)]; !found && !strings.Contains(machineType, "custom") {
+			return notFound("machineType", key(project, zone, machineType))
+		}
+	}
+	instance := copyResource(i, &compute.Instance{}).(*compute.Instance)
+	instance.Zone = selfLink(project, "zones", zone)
+	instance.SelfLink = selfLink(project, "zones", zone, "instances", i.Name)
+	c.nextID++
+	instance.Id = c.nextID
+	instance.Disks = nil
+	for _, d := range i.Disks {
+		attached, err := c.attach(project, zone, instance, d)
+		if err != nil {
+			for _, a := range instance.Disks {
+				c.removeUser(a.Source, instance.SelfLink)
+			}
+			return err
+		}
+		instance.Disks = append(instance.Disks, attached)
+	}
+	instance.Status = "RUNNING"
+	instance.CreationTimestamp = time.Now().Format(time.RFC3339)
+	c.instances[k] = &fakeInstance{instance: instance, script: c.scriptFor(i.Name), ports: map[int64]*fakeSerialPort{}}
+	c.recordOperation("insert", instance.SelfLink, zone)
+	*i = *copyResource(instance, &compute.Instance{}).(*compute.Instance)
+	return nil
+}
+
+// CreateInstanceAlpha creates an instance using the alpha API.
+func (c *FakeClient) CreateInstanceAlpha(project, zone string, i *computeAlpha.Instance) error {
+	v1 := copyResource(i, &compute.Instance{}).(*compute.Instance)
+	if err := c.CreateInstance(project, zone, v1); err != nil {
+		return err
+	}
+	copyResource(v1, i)
+	return nil
+}
+
+// CreateInstanceBeta creates an instance using the beta API.
+func (c *FakeClient) CreateInstanceBeta(project, zone string, i *computeBeta.Instance) error {
+	v1 := copyResource(i, &compute.Instance{}).(*compute.Instance)
+	if err := c.CreateInstance(project, zone, v1); err != nil {
+		return err
+	}
+	copyResource(v1, i)
+	return nil
+}
+
+// CreateNetwork creates a network.
+func (c *FakeClient) CreateNetwork(project string, n *compute.Network) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.nextFailure("CreateNetwork"); err != nil {
+		return err
+	}
+	k := key(project, n.Name)
+	if _, found := c.networks[k]; found {
+		return alreadyExists("network", k)
+	}
+	n.SelfLink = selfLink(project, "global", "networks", n.Name)
+	c.networks[k] = copyResource(n, &compute.Network{}).(*compute.Network)
+	c.recordOperation("insert", n.SelfLink, "")
+	return nil
+}
+
+// CreateSnapshot creates a snapshot of a disk.
+func (c *FakeClient) CreateSnapshot(project, zone, disk string, s *compute.Snapshot) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.nextFailure("CreateSnapshot"); err != nil {
+		return err
+	}
+	source, found := c.disks[key(project, zone, disk)]
+	if !found {
+		return notFound("disk", key(project, zone, disk))
+	}
+	k := key(project, s.Name)
+	if _, found := c.snapshots[k]; found {
+		return alreadyExists("snapshot", k)
+	}
+	s.DiskSizeGb = source.SizeGb
+	s.SourceDisk = source.SelfLink
+	s.Licenses = source.Licenses
+	s.Status = "READY"
+	s.SelfLink = selfLink(project, "global", "snapshots", s.Name)
+	c.snapshots[k] = copyResource(s, &compute.Snapshot{}).(*compute.Snapshot)
+	c.recordOperation("createSnapshot", source.SelfLink, zone)
+	return nil
+}
+
+// CreateSubnetwork creates a subnetwork in an existing network.
+func (c *FakeClient) CreateSubnetwork(project, region string, n *compute.Subnetwork) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.nextFailure("CreateSubnetwork"); err != nil {
+		return err
+	}
+	if _, err := c.networkByURL(project, n.Network); err != nil {
+		return err
+	}
+	k := key(project, region, n.Name)
+	if _, found := c.subnetworks[k]; found {
+		return alreadyExists("subnetwork", k)
+	}
+	n.Region = selfLink(project, "regions", region)
+	n.SelfLink = selfLink(project, "regions", region, "subnetworks", n.Name)
+	c.subnetworks[k] = copyResource(n, &compute.Subnetwork{}).(*compute.Subnetwork)
+	c.recordOperation("insert", n.SelfLink, "")
+	return nil
+}
+
+// CreateTargetInstance creates a target instance.
+func (c *FakeClient) CreateTargetInstance(project, zone string, ti *compute.TargetInstance) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.nextFailure("CreateTargetInstance"); err != nil {
+		return err
+	}
+	k := key(project, zone, ti.Name)
+	if _, found := c.targetInstances[k]; found {
+		return alreadyExists("targetInstance", k)
+	}
+	ti.Zone = selfLink(project, "zones", zone)
+	ti.SelfLink = selfLink(project, "zones", zone, "targetInstances", ti.Name)
+	c.targetInstances[k] = copyResource(ti, &compute.TargetInstance{}).(*compute.TargetInstance)
+	c.recordOperation("insert", ti.SelfLink, zone)
+	return nil
+}
+
+// DeleteDisk deletes a disk. Disks that are attached to an instance can't be deleted.
+func (c *FakeClient) DeleteDisk(project, zone, name string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.nextFailure("DeleteDisk"); err != nil {
+		return err
+	}
+	k := key(project, zone, name)
+	disk, found := c.disks[k]
+	if !found {
+		return notFound("disk", k)
+	}
+	if len(disk.Users) > 0 {
+		return &googleapi.Error{
+			Code: http.StatusBadRequest,
+			Message: fmt.Sprintf("The disk resource '%s' is already being used by '%s'",
+				disk.SelfLink, disk.Users[0]),
+			Errors: []googleapi.ErrorItem{{Reason: "resourceInUseByAnotherResource"}},
+		}
+	}
+	delete(c.disks, k)
+	c.recordOperation("delete", disk.SelfLink, zone)
+	return nil
+}
+
+// DeleteForwardingRule deletes a forwarding rule.
+func (c *FakeClient) DeleteForwardingRule(project, region, name string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.nextFailure("DeleteForwardingRule"); err != nil {
+		return err
+	}
+	k := key(project, region, name)
+	fr, found := c.forwardingRules[k]
+	if !found {
+		return notFound("forwardingRule", k)
+	}
+	delete(c.forwardingRules, k)
+	c.recordOperation("delete", fr.SelfLink, "")
+	return nil
+}
+
+// DeleteFirewallRule deletes a firewall rule.
+func (c *FakeClient) DeleteFirewallRule(project, name string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.nextFailure("DeleteFirewallRule"); err != nil {
+		return err
+	}
+	k := key(project, name)
+	fw, found := c.firewallRules[k]
+	if !found {
+		return notFound("firewall", k)
+	}
+	delete(c.firewallRules, k)
+	c.recordOperation("delete", fw.SelfLink, "")
+	return nil
+}
+
+// DeleteImage deletes an image.
+func (c *FakeClient) DeleteImage(project, name string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.nextFailure("DeleteImage"); err != nil {
+		return err
+	}
+	k := key(project, name)
+	image, found := c.images[k]
+	if !found {
+		return notFound("image", k)
+	}
+	delete(c.images, k)
+	c.recordOperation("delete", image.SelfLink, "")
+	return nil
+}
+
+// DeleteInstance deletes an instance. Attached disks are deleted when their
+// AutoDelete is set, and detached otherwise.
+func (c *FakeClient) DeleteInstance(project, zone, name string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.nextFailure("DeleteInstance"); err != nil {
+		return err
+	}
+	inst, err := c.instance(project, zone, name)
+	if err != nil {
+		return err
+	}
+	for _, attached := range inst.instance.Disks {
+		c.removeUser(attached.Source, inst.instance.SelfLink)
+		if attached.AutoDelete {
+			if disk, err := c.diskByURL(project, attached.Source); err == nil && len(disk.Users) == 0 {
+				delete(c.disks, key(project, zone, disk.Name))
+			}
+		}
+	}
+	delete(c.instances, key(project, zone, name))
+	c.recordOperation("delete", inst.instance.SelfLink, zone)
+	return nil
+}
+
+// StartInstance starts a stopped instance.
+func (c *FakeClient) StartInstance(project, zone, name string) error {
+	return c.setInstanceStatus("StartInstance", "start", project, zone, name, "RUNNING")
+}
+
+// StopInstance stops a running instance.
+func (c *FakeClient) StopInstance(project, zone, name string) error {
+	return c.setInstanceStatus("StopInstance", "stop", project, zone, name, "TERMINATED")
+}
+
+// DeleteNetwork deletes a network. Networks with subnetworks can't be deleted.
+func (c *FakeClient) DeleteNetwork(project, name string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.nextFailure("DeleteNetwork"); err != nil {
+		return err
+	}
+	k := key(project, name)
+	network, found := c.networks[k]
+	if !found {
+		return notFound("network", k)
+	}
+	for _, subnetwork := range c.subnetworks {
+		if lastSegment(subnetwork.Network) == name && strings.Contains(subnetwork.SelfLink, "projects/"+project+"/") {
+			return badRequest(fmt.Sprintf("The network resource '%s' is already being used by '%s'",
+				network.SelfLink, subnetwork.SelfLink))
+		}
+	}
+	delete(c.networks, k)
+	c.recordOperation("delete", network.SelfLink, "")
+	return nil
+}
+
+// DeleteSubnetwork deletes a subnetwork.
+func (c *FakeClient) DeleteSubnetwork(project, region, name string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.nextFailure("DeleteSubnetwork"); err != nil {
+		return err
+	}
+	k := key(project, region, name)
+	subnetwork, found := c.subnetworks[k]
+	if !found {
+		return notFound("subnetwork", k)
+	}
+	delete(c.subnetworks, k)
+	c.recordOperation("delete", subnetwork.SelfLink, "")
+	return nil
+}
+
+// DeleteTargetInstance deletes a target instance.
+func (c *FakeClient) DeleteTargetInstance(project, zone, name string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.nextFailure("DeleteTargetInstance"); err != nil {
+		return err
+	}
+	k := key(project, zone, name)
+	ti, found := c.targetInstances[k]
+	if !found {
+		return notFound("targetInstance", k)
+	}
+	delete(c.targetInstances, k)
+	c.recordOperation("delete", ti.SelfLink, zone)
+	return nil
+}
+
+// DeprecateImage sets the deprecation status of an image.
+func (c *FakeClient) DeprecateImage(project, name string, deprecationstatus *compute.DeprecationStatus) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.nextFailure("DeprecateImage"); err != nil {
+		return err
+	}
+	k := key(project, name)
+	image, found := c.images[k]
+	if !found {
+		return notFound("image", k)
+	}
+	image.Deprecated = copyResource(deprecationstatus, &compute.DeprecationStatus{}).(*compute.DeprecationStatus)
+	c.recordOperation("deprecate", image.SelfLink, "")
+	return nil
+}
+
+// DeprecateImageAlpha sets the deprecation status of an image using the alpha API.
+func (c *FakeClient) DeprecateImageAlpha(project, name string, deprecationstatus *computeAlpha.DeprecationStatus) error {
+	return c.DeprecateImage(project, name,
+		copyResource(deprecationstatus, &compute.DeprecationStatus{}).(*compute.DeprecationStatus))
+}
+
+// GetMachineType gets a machine type.
+func (c *FakeClient) GetMachineType(project, zone, machineType string) (*compute.MachineType, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.nextFailure("GetMachineType"); err != nil {
+		return nil, err
+	}
+	k := key(project, zone, machineType)
+	mt, found := c.machineTypes[k]
+	if !found {
+		return nil, notFound("machineType", k)
+	}
+	return copyResource(mt, &compute.MachineType{}).(*compute.MachineType), nil
+}
+
+// GetProject gets a project.
+func (c *FakeClient) GetProject(project string) (*compute.Project, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.nextFailure("GetProject"); err != nil {
+		return nil, err
+	}
+	p, found := c.projects[project]
+	if !found {
+		return nil, notFound("project", project)
+	}
+	return copyResource(p, &compute.Project{}).(*compute.Project), nil
+}
+
+// GetSerialPortOutput returns the serial port output of an instance, starting at
+// start. Each call reveals the next chunk of scripted output.
+func (c *FakeClient) GetSerialPortOutput(project, zone, name string, port, start int64) (*compute.SerialPortOutput, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.nextFailure("GetSerialPortOutput"); err != nil {
+		return nil, err
+	}
+	inst, err := c.instance(project, zone, name)
+	if err != nil {
+		return nil, err
+	}
+	p := inst.port(port)
+	if inst.instance.Status == "RUNNING" && p.revealed < len(p.chunks) {
+		p.contents += p.chunks[p.revealed]
+		p.revealed++
+		if inst.script.StopWhenDone && inst.done() {
+			inst.instance.Status = "TERMINATED"
+		}
+	}
+	if start < 0 || start > int64(len(p.contents)) {
+		start = int64(len(p.contents))
+	}
+	return &compute.SerialPortOutput{
+		Contents: p.contents[start:],
+		Start:    start,
+		Next:     int64(len(p.contents)),
+		SelfLink: inst.instance.SelfLink + fmt.Sprintf("/serialPort?port=%d", port),
+	}, nil
+}
+
+// GetZone gets a zone.
+func (c *FakeClient) GetZone(project, zone string) (*compute.Zone, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.nextFailure("GetZone"); err != nil {
+		return nil, err
+	}
+	k := key(project, zone)
+	z, found := c.zones[k]
+	if !found {
+		return nil, notFound("zone", k)
+	}
+	return copyResource(z, &compute.Zone{}).(*compute.Zone), nil
+}
+
+// GetInstance gets an instance.
+func (c *FakeClient) GetInstance(project, zone, name string) (*compute.Instance, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.nextFailure("GetInstance"); err != nil {
+		return nil, err
+	}
+	inst, err := c.instance(project, zone, name)
+	if err != nil {
+		return nil, err
+	}
+	return copyResource(inst.instance, &compute.Instance{}).(*compute.Instance), nil
+}
+
+// GetInstanceAlpha gets an instance using the alpha API.
+func (c *FakeClient) GetInstanceAlpha(project, zone, name string) (*computeAlpha.Instance, error) {
+	i, err := c.GetInstance(project, zone, name)
+	if err != nil {
+		return nil, err
+	}
+	return copyResource(i, &computeAlpha.Instance{}).(*computeAlpha.Instance), nil
+}
+
+// GetInstanceBeta gets an instance using the beta API.
+func (c *FakeClient) GetInstanceBeta(project, zone, name string) (*computeBeta.Instance, error) {
+	i, err := c.GetInstance(project, zone, name)
+	if err != nil {
+		return nil, err
+	}
+	return copyResource(i, &computeBeta.Instance{}).(*computeBeta.Instance), nil
+}
+
+// GetDisk gets a disk.
+func (c *FakeClient) GetDisk(project, zone, name string) (*compute.Disk, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.nextFailure("GetDisk"); err != nil {
+		return nil, err
+	}
+	k := key(project, zone, name)
+	disk, found := c.disks[k]
+	if !found {
+		return nil, notFound("disk", k)
+	}
+	return copyResource(disk, &compute.Disk{}).(*compute.Disk), nil
+}
+
+// GetDiskAlpha gets a disk using the alpha API.
+func (c *FakeClient) GetDiskAlpha(project, zone, name string) (*computeAlpha.Disk, error) {
+	d, err := c.GetDisk(project, zone, name)
+	if err != nil {
+		return nil, err
+	}
+	return copyResource(d, &computeAlpha.Disk{}).(*computeAlpha.Disk), nil
+}
+
+// GetDiskBeta gets a disk using the beta API.
+func (c *FakeClient) GetDiskBeta(project, zone, name string) (*computeBeta.Disk, error) {
+	d, err := c.GetDisk(project, zone, name)
+	if err != nil {
+		return nil, err
+	}
+	return copyResource(d, &computeBeta.Disk{}).(*computeBeta.Disk), nil
+}
+
+// GetForwardingRule gets a forwarding rule.
+func (c *FakeClient) GetForwardingRule(project, region, name string) (*compute.ForwardingRule, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.nextFailure("GetForwardingRule"); err != nil {
+		return nil, err
+	}
+	k := key(project, region, name)
+	fr, found := c.forwardingRules[k]
+	if !found {
+		return nil, notFound("forwardingRule", k)
+	}
+	return copyResource(fr, &compute.ForwardingRule{}).(*compute.ForwardingRule), nil
+}
+
+// GetFirewallRule gets a firewall rule.
+func (c *FakeClient) GetFirewallRule(project, name string) (*compute.Firewall, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.nextFailure("GetFirewallRule"); err != nil {
+		return nil, err
+	}
+	k := key(project, name)
+	fw, found := c.firewallRules[k]
+	if !found {
+		return nil, notFound("firewall", k)
+	}
+	return copyResource(fw, &compute.Firewall{}).(*compute.Firewall), nil
+}
+
+// GetGuestAttributes gets a scripted guest attribute. As with Compute Engine, a
+// missing key results in a 404 error.
+func (c *FakeClient) GetGuestAttributes(project, zone, name, queryPath, variableKey string) (*compute.GuestAttributes, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.nextFailure("GetGuestAttributes"); err != nil {
+		return nil, err
+	}
+	inst, err := c.instance(project, zone, name)
+	if err != nil {
+		return nil, err
+	}
+	value, found := inst.script.GuestAttributes[variableKey]
+	if !found {
+		return nil, notFound("guestAttribute", variableKey)
+	}
+	return &compute.GuestAttributes{
+		QueryPath:     queryPath,
+		VariableKey:   variableKey,
+		VariableValue: value,
+	}, nil
+}
+
+// GetImage gets an image.
+func (c *FakeClient) GetImage(project, name string) (*compute.Image, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.nextFailure("GetImage"); err != nil {
+		return nil, err
+	}
+	k := key(project, name)
+	image, found := c.images[k]
+	if !found {
+		return nil, notFound("image", k)
+	}
+	return copyResource(image, &compute.Image{}).(*compute.Image), nil
+}
+
+// GetImageAlpha gets an image using the alpha API.
+func (c *FakeClient) GetImageAlpha(project, name string) (*computeAlpha.Image, error) {
+	i, err := c.GetImage(project, name)
+	if err != nil {
+		return nil, err
+	}
+	return copyResource(i, &computeAlpha.Image{}).(*computeAlpha.Image), nil
+}
+
+// GetImageBeta gets an image using the beta API.
+func (c *FakeClient) GetImageBeta(project, name string) (*computeBeta.Image, error) {
+	i, err := c.GetImage(project, name)
+	if err != nil {
+		return nil, err
+	}
+	return copyResource(i, &computeBeta.Image{}).(*computeBeta.Image), nil
+}
+
+// GetImageFromFamily gets the newest image in family that isn't deprecated.
+func (c *FakeClient) GetImageFromFamily(project, family string) (*compute.Image, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.nextFailure("GetImageFromFamily"); err != nil {
+		return nil, err
+	}
+	image, err := c.imageFromFamily(project, family)
+	if err != nil {
+		return nil, err
+	}
+	return copyResource(image, &compute.Image{}).(*compute.Image), nil
+}
+
+// GetLicense gets a license.
+func (c *FakeClient) GetLicense(project, name string) (*compute.License, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.nextFailure("GetLicense"); err != nil {
+		return nil, err
+	}
+	k := key(project, name)
+	license, found := c.licenses[k]
+	if !found {
+		return nil, notFound("license", k)
+	}
+	return copyResource(license, &compute.License{}).(*compute.License), nil
+}
+
+// GetNetwork gets a network.
+func (c *FakeClient) GetNetwork(project, name string) (*compute.Network, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.nextFailure("GetNetwork"); err != nil {
+		return nil, err
+	}
+	k := key(project, name)
+	network, found := c.networks[k]
+	if !found {
+		return nil, notFound("network", k)
+	}
+	return copyResource(network, &compute.Network{}).(*compute.Network), nil
+}
+
+// GetRegion gets a region.
+func (c *FakeClient) GetRegion(project, region string) (*compute.Region, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.nextFailure("GetRegion"); err != nil {
+		return nil, err
+	}
+	k := key(project, region)
+	r, found := c.regions[k]
+	if !found {
+		return nil, notFound("region", k)
+	}
+	return copyResource(r, &compute.Region{}).(*compute.Region), nil
+}
+
+// GetSubnetwork gets a subnetwork.
+func (c *FakeClient) GetSubnetwork(project, region, name string) (*compute.Subnetwork, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.nextFailure("GetSubnetwork"); err != nil {
+		return nil, err
+	}
+	k := key(project, region, name)
+	subnetwork, found := c.subnetworks[k]
+	if !found {
+		return nil, notFound("subnetwork", k)
+	}
+	return copyResource(subnetwork, &compute.Subnetwork{}).(*compute.Subnetwork), nil
+}
+
+// GetTargetInstance gets a target instance.
+func (c *FakeClient) GetTargetInstance(project, zone, name string) (*compute.TargetInstance, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.nextFailure("GetTargetInstance"); err != nil {
+		return nil, err
+	}
+	k := key(project, zone, name)
+	ti, found := c.targetInstances[k]
+	if !found {
+		return nil, notFound("targetInstance", k)
+	}
+	return copyResource(ti, &compute.TargetInstance{}).(*compute.TargetInstance), nil
+}
+
+// InstanceStatus returns the status of an instance.
+func (c *FakeClient) InstanceStatus(project, zone, name string) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.nextFailure("InstanceStatus"); err != nil {
+		return "", err
+	}
+	inst, err := c.instance(project, zone, name)
+	if err != nil {
+		return "", err
+	}
+	return inst.instance.Status, nil
+}
+
+// InstanceStopped returns whether an instance is stopped.
+func (c *FakeClient) InstanceStopped(project, zone, name string) (bool, error) {
+	status, err := c.InstanceStatus(project, zone, name)
+	if err != nil {
+		return false, err
+	}
+	return status == "TERMINATED" || status == "STOPPED", nil
+}
+
+// ListMachineTypes lists the machine types of a zone. List options are ignored.
+func (c *FakeClient) ListMachineTypes(project, zone string, opts ...daisyCompute.ListCallOption) ([]*compute.MachineType, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.nextFailure("ListMachineTypes"); err != nil {
+		return nil, err
+	}
+	var result []*compute.MachineType
+	for _, k := range sortedKeys(c.machineTypes) {
+		if strings.HasPrefix(k, key(project, zone)+"/") {
+			result = append(result, copyResource(c.machineTypes[k], &compute.MachineType{}).(*compute.MachineType))
+		}
+	}
+	return result, nil
+}
+
+// ListLicenses lists the licenses of a project. List options are ignored.
+func (c *FakeClient) ListLicenses(project string, opts ...daisyCompute.ListCallOption) ([]*compute.License, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.nextFailure("ListLicenses"); err != nil {
+		return nil, err
+	}
+	var result []*compute.License
+	for _, k := range sortedKeys(c.licenses) {
+		if strings.HasPrefix(k, project+"/") {
+			result = append(result, copyResource(c.licenses[k], &compute.License{}).(*compute.License))
+		}
+	}
+	return result, nil
+}
+
+// ListZones lists the zones of a project. List options are ignored.
+func (c *FakeClient) ListZones(project string, opts ...daisyCompute.ListCallOption) ([]*compute.Zone, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.nextFailure("ListZones"); err != nil {
+		return nil, err
+	}
+	var result []*compute.Zone
+	for _, k := range sortedKeys(c.zones) {
+		if strings.HasPrefix(k, project+"/") {
+			result = append(result, copyResource(c.zones[k], &compute.Zone{}).(*compute.Zone))
+		}
+	}
+	return result, nil
+}
+
+// ListRegions lists the regions of a project. List options are ignored.
+func (c *FakeClient) ListRegions(project string, opts ...daisyCompute.ListCallOption) ([]*compute.Region, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.nextFailure("ListRegions"); err != nil {
+		return nil, err
+	}
+	var result []*compute.Region
+	for _, k := range sortedKeys(c.regions) {
+		if strings.HasPrefix(k, project+"/") {
+			result = append(result, copyResource(c.regions[k], &compute.Region{}).(*compute.Region))
+		}
+	}
+	return result, nil
+}
+
+// AggregatedListInstances lists the instances in all zones of a project. List options are ignored.
+func (c *FakeClient) AggregatedListInstances(project string, opts ...daisyCompute.ListCallOption) ([]*compute.Instance, error) {
+	return c.listInstances("AggregatedListInstances", project+"/")
+}
+
+// ListInstances lists the instances of a zone. List options are ignored.
+func (c *FakeClient) ListInstances(project, zone string, opts ...daisyCompute.ListCallOption) ([]*compute.Instance, error) {
+	return c.listInstances("ListInstances", key(project, zone)+"/")
+}
+
+// AggregatedListDisks lists the disks in all zones of a project. List options are ignored.
+func (c *FakeClient) AggregatedListDisks(project string, opts ...daisyCompute.ListCallOption) ([]*compute.Disk, error) {
+	return c.listDisks("AggregatedListDisks", project+"/")
+}
+
+// ListDisks lists the disks of a zone. List options are ignored.
+func (c *FakeClient) ListDisks(project, zone string, opts ...daisyCompute.ListCallOption) ([]*compute.Disk, error) {
+	return c.listDisks("ListDisks", key(project, zone)+"/")
+}
+
+// ListForwardingRules lists the forwarding rules of a region. List options are ignored.
+func (c *FakeClient) ListForwardingRules(project, region string, opts ...daisyCompute.ListCallOption) ([]*compute.ForwardingRule, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.nextFailure("ListForwardingRules"); err != nil {
+		return nil, err
+	}
+	var result []*compute.ForwardingRule
+	for _, k := range sortedKeys(c.forwardingRules) {
+		if strings.HasPrefix(k, key(project, region)+"/") {
+			result = append(result, copyResource(c.forwardingRules[k], &compute.ForwardingRule{}).(*compute.ForwardingRule))
+		}
+	}
+	return result, nil
+}
+
+// ListFirewallRules lists the firewall rules of a project. List options are ignored.
+func (c *FakeClient) ListFirewallRules(project string, opts ...daisyCompute.ListCallOption) ([]*compute.Firewall, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.nextFailure("ListFirewallRules"); err != nil {
+		return nil, err
+	}
+	var result []*compute.Firewall
+	for _, k := range sortedKeys(c.firewallRules) {
+		if strings.HasPrefix(k, project+"/") {
+			result = append(result, copyResource(c.firewallRules[k], &compute.Firewall{}).(*compute.Firewall))
+		}
+	}
+	return result, nil
+}
+
+// ListImages lists the images of a project. List options are ignored.
+func (c *FakeClient) ListImages(project string, opts ...daisyCompute.ListCallOption) ([]*compute.Image, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.nextFailure("ListImages"); err != nil {
+		return nil, err
+	}
+	var result []*compute.Image
+	for _, k := range sortedKeys(c.images) {
+		if strings.HasPrefix(k, project+"/") {
+			result = append(result, copyResource(c.images[k], &compute.Image{}).(*compute.Image))
+		}
+	}
+	return result, nil
+}
+
+// ListImagesAlpha lists the images of a project using the alpha API.
+func (c *FakeClient) ListImagesAlpha(project string, opts ...daisyCompute.ListCallOption) ([]*computeAlpha.Image, error) {
+	images, err := c.ListImages(project, opts...)
+	if err != nil {
+		return nil, err
+	}
+	var result []*computeAlpha.Image
+	for _, image := range images {
+		result = append(result, copyResource(image, &computeAlpha.Image{}).(*computeAlpha.Image))
+	}
+	return result, nil
+}
+
+// GetSnapshot gets a snapshot.
+func (c *FakeClient) GetSnapshot(project, name string) (*compute.Snapshot, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.nextFailure("GetSnapshot"); err != nil {
+		return nil, err
+	}
+	k := key(project, name)
+	snapshot, found := c.snapshots[k]
+	if !found {
+		return nil, notFound("snapshot", k)
+	}
+	return copyResource(snapshot, &compute.Snapshot{}).(*compute.Snapshot), nil
+}
+
+// ListSnapshots lists the snapshots of a project. List options are ignored.
+func (c *FakeClient) ListSnapshots(project string, opts ...daisyCompute.ListCallOption) ([]*compute.Snapshot, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.nextFailure("ListSnapshots"); err != nil {
+		return nil, err
+	}
+	var result []*compute.Snapshot
+	for _, k := range sortedKeys(c.snapshots) {
+		if strings.HasPrefix(k, project+"/") {
+			result = append(result, copyResource(c.snapshots[k], &compute.Snapshot{}).(*compute.Snapshot))
+		}
+	}
+	return result, nil
+}
+
+// DeleteSnapshot deletes a snapshot.
+func (c *FakeClient) DeleteSnapshot(project, name string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.nextFailure("DeleteSnapshot"); err != nil {
+		return err
+	}
+	k := key(project, name)
+	snapshot, found := c.snapshots[k]
+	if !found {
+		return notFound("snapshot", k)
+	}
+	delete(c.snapshots, k)
+	c.recordOperation("delete", snapshot.SelfLink, "")
+	return nil
+}
+
+// ListNetworks lists the networks of a project. List options are ignored.
+func (c *FakeClient) ListNetworks(project string, opts ...daisyCompute.ListCallOption) ([]*compute.Network, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.nextFailure("ListNetworks"); err != nil {
+		return nil, err
+	}
+	var result []*compute.Network
+	for _, k := range sortedKeys(c.networks) {
+		if strings.HasPrefix(k, project+"/") {
+			result = append(result, copyResource(c.networks[k], &compute.Network{}).(*compute.Network))
+		}
+	}
+	return result, nil
+}
+
+// AggregatedListSubnetworks lists the subnetworks in all regions of a project. List options are ignored.
+func (c *FakeClient) AggregatedListSubnetworks(project string, opts ...daisyCompute.ListCallOption) ([]*compute.Subnetwork, error) {
+	return c.listSubnetworks("AggregatedListSubnetworks", project+"/")
+}
+
+// ListSubnetworks lists the subnetworks of a region. List options are ignored.
+func (c *FakeClient) ListSubnetworks(project, region string, opts ...daisyCompute.ListCallOption) ([]*compute.Subnetwork, error) {
+	return c.listSubnetworks("ListSubnetworks", key(project, region)+"/")
+}
+
+// ListTargetInstances lists the target instances of a zone. List options are ignored.
+func (c *FakeClient) ListTargetInstances(project, zone string, opts ...daisyCompute.ListCallOption) ([]*compute.TargetInstance, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.nextFailure("ListTargetInstances"); err != nil {
+		return nil, err
+	}
+	var result []*compute.TargetInstance
+	for _, k := range sortedKeys(c.targetInstances) {
+		if strings.HasPrefix(k, key(project, zone)+"/") {
+			result = append(result, copyResource(c.targetInstances[k], &compute.TargetInstance{}).(*compute.TargetInstance))
+		}
+	}
+	return result, nil
+}
+
+// ResizeDisk increases the size of a disk.
+func (c *FakeClient) ResizeDisk(project, zone, disk string, drr *compute.DisksResizeRequest) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.nextFailure("ResizeDisk"); err != nil {
+		return err
+	}
+	k := key(project, zone, disk)
+	d, found := c.disks[k]
+	if !found {
+		return notFound("disk", k)
+	}
+	if drr.SizeGb <= d.SizeGb {
+		return badRequest(fmt.Sprintf("Requested disk size cannot be smaller than the current size (%d GB < %d GB).",
+			drr.SizeGb, d.SizeGb))
+	}
+	d.SizeGb = drr.SizeGb
+	c.recordOperation("resizeDisk", d.SelfLink, zone)
+	return nil
+}
+
+// SetInstanceMetadata replaces the metadata of an instance.
+func (c *FakeClient) SetInstanceMetadata(project, zone, name string, md *compute.Metadata) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.nextFailure("SetInstanceMetadata"); err != nil {
+		return err
+	}
+	inst, err := c.instance(project, zone, name)
+	if err != nil {
+		return err
+	}
+	inst.instance.Metadata = copyResource(md, &compute.Metadata{}).(*compute.Metadata)
+	c.recordOperation("setMetadata", inst.instance.SelfLink, zone)
+	return nil
+}
+
+// SetCommonInstanceMetadata replaces the metadata of a project.
+func (c *FakeClient) SetCommonInstanceMetadata(project string, md *compute.Metadata) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.nextFailure("SetCommonInstanceMetadata"); err != nil {
+		return err
+	}
+	p, found := c.projects[project]
+	if !found {
+		return notFound("project", project)
+	}
+	p.CommonInstanceMetadata = copyResource(md, &compute.Metadata{}).(*compute.Metadata)
+	c.recordOperation("setCommonInstanceMetadata", p.SelfLink, "")
+	return nil
+}
+
+// SetDiskAutoDelete sets the auto-delete flag of a disk that's attached to an instance.
+func (c *FakeClient) SetDiskAutoDelete(project, zone, instance string, autoDelete bool, deviceName string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.nextFailure("SetDiskAutoDelete"); err != nil {
+		return err
+	}
+	inst, err := c.instance(project, zone, instance)
+	if err != nil {
+		return err
+	}
+	for _, attached := range inst.instance.Disks {
+		if attached.DeviceName == deviceName {
+			attached.AutoDelete = autoDelete
+			c.recordOperation("setDiskAutoDelete", inst.instance.SelfLink, zone)
+			return nil
+		}
+	}
+	return badRequest(fmt.Sprintf("No attached disk found with device name '%s'", deviceName))
+}
+
+// ListMachineImages lists the machine images of a project. List options are ignored.
+func (c *FakeClient) ListMachineImages(project string, opts ...daisyCompute.ListCallOption) ([]*compute.MachineImage, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.nextFailure("ListMachineImages"); err != nil {
+		return nil, err
+	}
+	var result []*compute.MachineImage
+	for _, k := range sortedKeys(c.machineImages) {
+		if strings.HasPrefix(k, project+"/") {
+			result = append(result, copyResource(c.machineImages[k], &compute.MachineImage{}).(*compute.MachineImage))
+		}
+	}
+	return result, nil
+}
+
+// DeleteMachineImage deletes a machine image.
+func (c *FakeClient) DeleteMachineImage(project, name string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.nextFailure("DeleteMachineImage"); err != nil {
+		return err
+	}
+	k := key(project, name)
+	mi, found := c.machineImages[k]
+	if !found {
+		return notFound("machineImage", k)
+	}
+	delete(c.machineImages, k)
+	c.recordOperation("delete", mi.SelfLink, "")
+	return nil
+}
+
+// CreateMachineImage creates a machine image from an instance.
+func (c *FakeClient) CreateMachineImage(project string, mi *compute.MachineImage) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.nextFailure("CreateMachineImage"); err != nil {
+		return err
+	}
+	k := key(project, mi.Name)
+	if _, found := c.machineImages[k]; found {
+		return alreadyExists("machineImage", k)
+	}
+	source, err := c.instanceByURL(project, mi.SourceInstance)
+	if err != nil {
+		return err
+	}
+	mi.SourceInstance = source.instance.SelfLink
+	mi.Status = "READY"
+	mi.SelfLink = selfLink(project, "global", "machineImages", mi.Name)
+	c.machineImages[k] = copyResource(mi, &compute.MachineImage{}).(*compute.MachineImage)
+	c.recordOperation("insert", mi.SelfLink, "")
+	return nil
+}
+
+// GetMachineImage gets a machine image.
+func (c *FakeClient) GetMachineImage(project, name string) (*compute.MachineImage, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.nextFailure("GetMachineImage"); err != nil {
+		return nil, err
+	}
+	k := key(project, name)
+	mi, found := c.machineImages[k]
+	if !found {
+		return nil, notFound("machineImage", k)
+	}
+	return copyResource(mi, &compute.MachineImage{}).(*compute.MachineImage), nil
+}
+
+// Retry calls f once, since operations of the fake don't fail transiently.
+func (c *FakeClient) Retry(f func(opts ...googleapi.CallOption) (*compute.Operation, error), opts ...googleapi.CallOption) (op *compute.Operation, err error) {
+	return f(opts...)
+}
+
+// RetryBeta calls f once, since operations of the fake don't fail transiently.
+func (c *FakeClient) RetryBeta(f func(opts ...googleapi.CallOption) (*computeBeta.Operation, error), opts ...googleapi.CallOption) (op *computeBeta.Operation, err error) {
+	return f(opts...)
+}
+
+// BasePath returns the base path of the Compute Engine API.
+func (c *FakeClient) BasePath() string {
+	return fakeBasePath
+}
+
+func (c *FakeClient) nextFailure(method string) error {
+	if len(c.failures[method]) == 0 {
+		return nil
+	}
+	err := c.failures[method][0]
+	c.failures[method] = c.failures[method][1:]
+	return err
+}
+
+func (c *FakeClient) recordOperation(operationType, targetLink, zone string) {
+	op := &compute.Operation{
+		Name:          fmt.Sprintf("operation-%d", len(c.operations)+1),
+		OperationType: operationType,
+		TargetLink:    targetLink,
+		Status:        "DONE",
+		Progress:      100,
+	}
+	if zone != "" {
+		op.Zone = zone
+	}
+	c.operations = append(c.operations, op)
+}
+
+func (c *FakeClient) setInstanceStatus(method, operationType, project, zone, name, status string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.nextFailure(method); err != nil {
+		return err
+	}
+	inst, err := c.instance(project, zone, name)
+	if err != nil {
+		return err
+	}
+	inst.instance.Status = status
+	c.recordOperation(operationType, inst.instance.SelfLink, zone)
+	return nil
+}
+
+func (c *FakeClient) createDisk(project, zone string, d *compute.Disk) (*compute.Disk, error) {
+	if _, found := c.zones[key(project, zone)]; !found {
+		return nil, notFound("zone", key(project, zone))
+	}
+	k := key(project, zone, d.Name)
+	if _, found := c.disks[k]; found {
+		return nil, alreadyExists("disk", k)
+	}
+	disk := copyResource(d, &compute.Disk{}).(*compute.Disk)
+	var sourceSize int64
+	switch {
+	case d.SourceImage != "":
+		image, err := c.imageByURL(project, d.SourceImage)
+		if err != nil {
+			return nil, err
+		}
+		sourceSize = image.DiskSizeGb
+		disk.SourceImage = image.SelfLink
+		disk.Licenses = mergeLicenses(image.Licenses, d.Licenses)
+	case d.SourceSnapshot != "":
+		snapshot, err := c.snapshotByURL(project, d.SourceSnapshot)
+		if err != nil {
+			return nil, err
+		}
+		sourceSize = snapshot.DiskSizeGb
+		disk.SourceSnapshot = snapshot.SelfLink
+		disk.Licenses = mergeLicenses(snapshot.Licenses, d.Licenses)
+	}
+	if disk.SizeGb == 0 {
+		disk.SizeGb = sourceSize
+	}
+	if disk.SizeGb == 0 {
+		disk.SizeGb = 10
+	}
+	if disk.SizeGb < sourceSize {
+		return nil, badRequest(fmt.Sprintf("Requested disk size cannot be smaller than the image size (%d GB)", sourceSize))
+	}
+	if disk.Type == "" {
+		disk.Type = selfLink(project, "zones", zone, "diskTypes", "pd-standard")
+	}
+	disk.Status = "READY"
+	disk.Zone = selfLink(project, "zones", zone)
+	disk.SelfLink = selfLink(project, "zones", zone, "disks", d.Name)
+	disk.CreationTimestamp = time.Now().Format(time.RFC3339)
+	c.disks[k] = disk
+	c.recordOperation("insert", disk.SelfLink, zone)
+	return disk, nil
+}
+
+// attach returns a copy of d that references an existing disk, creating the disk
+// when InitializeParams are set, and adds the instance to the disk's users.
+func (c *FakeClient) attach(project, zone string, instance *compute.Instance, d *compute.AttachedDisk) (*compute.AttachedDisk, error) {
+	attached := copyResource(d, &compute.AttachedDisk{}).(*compute.AttachedDisk)
+	var disk *compute.Disk
+	if d.Source != "" {
+		var err error
+		if disk, err = c.diskByURL(project, d.Source); err != nil {
+			return nil, err
+		}
+		if len(disk.Users) > 0 && d.Mode != "READ_ONLY" {
+			return nil, badRequest(fmt.Sprintf("The disk resource '%s' is already being used by '%s'",
+				disk.SelfLink, disk.Users[0]))
+		}
+	} else if d.InitializeParams != nil {
+		name := d.InitializeParams.DiskName
+		if name == "" {
+			name = instance.Name
+		}
+		var err error
+		disk, err = c.createDisk(project, zone, &compute.Disk{
+			Name:        name,
+			SizeGb:      d.InitializeParams.DiskSizeGb,
+			SourceImage: d.InitializeParams.SourceImage,
+			Type:        d.InitializeParams.DiskType,
+		})
+		if err != nil {
+			return nil, err
+		}
+		attached.InitializeParams = nil
+	} else {
+		return nil, badRequest("Source or InitializeParams is required for attached disks.")
+	}
+	disk.Users = append(disk.Users, instance.SelfLink)
+	attached.Source = disk.SelfLink
+	if attached.DeviceName == "" {
+		attached.DeviceName = disk.Name
+	}
+	attached.Licenses = disk.Licenses
+	return attached, nil
+}
+
+func (c *FakeClient) removeUser(diskURL, instanceURL string) {
+	for _, disk := range c.disks {
+		if disk.SelfLink != diskURL {
+			continue
+		}
+		for i, user := range disk.Users {
+			if user == instanceURL {
+				disk.Users = append(disk.Users[:i], disk.Users[i+1:]...)
+				break
+			}
+		}
+	}
+}
+
+func (c *FakeClient) scriptFor(name string) InstanceScript {
+	var match *fakeScript
+	for i, s := range c.scripts {
+		if strings.HasPrefix(name, s.namePrefix) && (match == nil || len(s.namePrefix) > len(match.namePrefix)) {
+			match = &c.scripts[i]
+		}
+	}
+	if match == nil {
+		return InstanceScript{}
+	}
+	return match.script
+}
+
+func (c *FakeClient) instance(project, zone, name string) (*fakeInstance, error) {
+	k := key(project, zone, name)
+	inst, found := c.instances[k]
+	if !found {
+		return nil, notFound("instance", k)
+	}
+	return inst, nil
+}
+
+func (c *FakeClient) listInstances(method, prefix string) ([]*compute.Instance, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.nextFailure(method); err != nil {
+		return nil, err
+	}
+	var result []*compute.Instance
+	for _, k := range sortedKeys(c.instances) {
+		if strings.HasPrefix(k, prefix) {
+			result = append(result, copyResource(c.instances[k].instance, &compute.Instance{}).(*compute.Instance))
+		}
+	}
+	return result, nil
+}
+
+func (c *FakeClient) listDisks(method, prefix string) ([]*compute.Disk, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.nextFailure(method); err != nil {
+		return nil, err
+	}
+	var result []*compute.Disk
+	for _, k := range sortedKeys(c.disks) {
+		if strings.HasPrefix(k, prefix) {
+			result = append(result, copyResource(c.disks[k], &compute.Disk{}).(*compute.Disk))
+		}
+	}
+	return result, nil
+}
+
+func (c *FakeClient) listSubnetworks(method, prefix string) ([]*compute.Subnetwork, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.nextFailure(method); err != nil {
+		return nil, err
+	}
+	var result []*compute.Subnetwork
+	for _, k := range sortedKeys(c.subnetworks) {
+		if strings.HasPrefix(k, prefix) {
+			result = append(result, copyResource(c.subnetworks[k], &compute.Subnetwork{}).(*compute.Subnetwork))
+		}
+	}
+	return result, nil
+}
+
+// diskByURL resolves a disk reference, such as "disk-1", "zones/z/disks/disk-1", or
+// a full URL.
+func (c *FakeClient) diskByURL(project, url string) (*compute.Disk, error) {
+	parts := resourcePath(url)
+	if zone, found := parts["zones"]; found {
+		k := key(projectOf(parts, project), zone, parts["disks"])
+		if disk, found := c.disks[k]; found {
+			return disk, nil
+		}
+		return nil, notFound("disk", k)
+	}
+	for _, k := range sortedKeys(c.disks) {
+		if strings.HasPrefix(k, project+"/") && lastSegment(k) == lastSegment(url) {
+			return c.disks[k], nil
+		}
+	}
+	return nil, notFound("disk", key(project, url))
+}
+
+// imageByURL resolves an image reference, such as "image-1",
+// "projects/p/global/images/image-1", or "projects/p/global/images/family/f".
+func (c *FakeClient) imageByURL(project, url string) (*compute.Image, error) {
+	parts := resourcePath(url)
+	project = projectOf(parts, project)
+	if family, found := parts["family"]; found {
+		return c.imageFromFamily(project, family)
+	}
+	k := key(project, lastSegment(url))
+	if image, found := c.images[k]; found {
+		return image, nil
+	}
+	return nil, notFound("image", k)
+}
+
+func (c *FakeClient) imageFromFamily(project, family string) (*compute.Image, error) {
+	var newest *compute.Image
+	for _, k := range sortedKeys(c.images) {
+		image := c.images[k]
+		if !strings.HasPrefix(k, project+"/") || image.Family != family ||
+			(image.Deprecated != nil && image.Deprecated.State != "" && image.Deprecated.State != "ACTIVE") {
+			continue
+		}
+		if newest == nil || image.CreationTimestamp >= newest.CreationTimestamp {
+			newest = image
+		}
+	}
+	if newest == nil {
+		return nil, notFound("image family", key(project, family))
+	}
+	return newest, nil
+}
+
+func (c *FakeClient) snapshotByURL(project, url string) (*compute.Snapshot, error) {
+	k := key(projectOf(resourcePath(url), project), lastSegment(url))
+	if snapshot, found := c.snapshots[k]; found {
+		return snapshot, nil
+	}
+	return nil, notFound("snapshot", k)
+}
+
+func (c *FakeClient) networkByURL(project, url string) (*compute.Network, error) {
+	k := key(projectOf(resourcePath(url), project), lastSegment(url))
+	if network, found := c.networks[k]; found {
+		return network, nil
+	}
+	return nil, notFound("network", k)
+}
+
+func (c *FakeClient) instanceByURL(project, url string) (*fakeInstance, error) {
+	parts := resourcePath(url)
+	if zone, found := parts["zones"]; found {
+		return c.instance(projectOf(parts, project), zone, parts["instances"])
+	}
+	for _, k := range sortedKeys(c.instances) {
+		if strings.HasPrefix(k, project+"/") && lastSegment(k) == lastSegment(url) {
+			return c.instances[k], nil
+		}
+	}
+	return nil, notFound("instance", key(project, url))
+}
+
+func (i *fakeInstance) port(port int64) *fakeSerialPort {
+	p, found := i.ports[port]
+	if !found {
+		p = &fakeSerialPort{chunks: i.script.SerialPortOutput[port]}
+		i.ports[port] = p
+	}
+	return p
+}
+
+// done returns whether all scripted serial port output was read.
+func (i *fakeInstance) done() bool {
+	for port := range i.script.SerialPortOutput {
+		if p := i.port(port); p.revealed < len(p.chunks) {
+			return false
+		}
+	}
+	return true
+}
+
+// resourcePath splits a resource URL, such as "projects/p/zones/z/disks/d", into a
+// map of collection names to resource names.
+func resourcePath(url string) map[string]string {
+	parts := strings.Split(strings.TrimPrefix(url, fakeBasePath), "/")
+	result := map[string]string{}
+	for i := 0; i+1 < len(parts); i++ {
+		switch parts[i] {
+		case "projects", "zones", "regions", "disks", "images", "family", "instances", "snapshots", "networks":
+			result[parts[i]] = parts[i+1]
+		}
+	}
+	return result
+}
+
+func projectOf(parts map[string]string, defaultProject string) string {
+	if project, found := parts["projects"]; found {
+		return project
+	}
+	return defaultProject
+}
+
+func mergeLicenses(inherited, requested []string) []string {
+	seen := map[string]bool{}
+	var result []string
+	for _, license := range append(append([]string{}, inherited...), requested...) {
+		if !seen[lastSegment(license)] {
+			seen[lastSegment(license)] = true
+			result = append(result, license)
+		}
+	}
+	return result
+}
+
+func key(parts ...string) string {
+	return strings.Join(parts, "/")
+}
+
+func lastSegment(url string) string {
+	return url[strings.LastIndex(url, "/")+1:]
+}
+
+func selfLink(project string, parts ...string) string {
+	return fakeBasePath + key(append([]string{"projects", project}, parts...)...)
+}
+
+// copyResource copies src to dst using their JSON representation, which also converts
+// between the alpha, beta, and GA versions of a resource. It returns dst.
+func copyResource(src, dst interface{}) interface{} {
+	b, err := json.Marshal(src)
+	if err == nil {
+		err = json.Unmarshal(b, dst)
+	}
+	if err != nil {
+		panic(fmt.Sprintf("failed to copy %T to %T: %v", src, dst, err))
+	}
+	return dst
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func notFound(resourceType, name string) error {
+	return &googleapi.Error{
+		Code:    http.StatusNotFound,
+		Message: fmt.Sprintf("The resource '%s %s' was not found", resourceType, name),
+		Errors:  []googleapi.ErrorItem{{Reason: "notFound"}},
+	}
+}
+
+func alreadyExists(resourceType, name string) error {
+	return &googleapi.Error{
+		Code:    http.StatusConflict,
+		Message: fmt.Sprintf("The resource '%s %s' already exists", resourceType, name),
+		Errors:  []googleapi.ErrorItem{{Reason: "alreadyExists"}},
+	}
+}
+
+func badRequest(message string) error {
+	return &googleapi.Error{
+		Code:    http.StatusBadRequest,
+		Message: message,
+		Errors:  []googleapi.ErrorItem{{Reason: "invalid"}},
+	}
+}
@@ -0,0 +1,201 @@
+//  Copyright 2026 Google Inc. All Rights Reserved.
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License
+
+package compute
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"cloud.google.com/go/storage"
+	daisy "github.com/GoogleCloudPlatform/compute-daisy"
+	"github.com/stretchr/testify/assert"
+	computeBeta "google.golang.org/api/compute/v0.beta"
+	"google.golang.org/api/compute/v1"
+	"google.golang.org/api/googleapi"
+	"google.golang.org/api/option"
+)
+
+func TestFakeClient_DiskLifecycle(t *testing.T) {
+	c := NewFakeClient()
+	c.AddProject("p", "us-west1-a")
+	c.AddImage("p", &compute.Image{Name: "source", DiskSizeGb: 20, Licenses: []string{"projects/p/global/licenses/l"}})
+
+	disk := &compute.Disk{Name: "disk", SourceImage: "projects/p/global/images/source"}
+	assert.NoError(t, c.CreateDisk("p", "us-west1-a", disk))
+	assert.Equal(t, int64(20), disk.SizeGb, "size is inherited from the image")
+	assert.Equal(t, []string{"projects/p/global/licenses/l"}, disk.Licenses)
+	assertErrorCode(t, http.StatusConflict, c.CreateDisk("p", "us-west1-a", &compute.Disk{Name: "disk"}))
+
+	assert.NoError(t, c.CreateInstance("p", "us-west1-a", &compute.Instance{
+		Name:        "inst",
+		MachineType: "zones/us-west1-a/machineTypes/n1-standard-1",
+		Disks:       []*compute.AttachedDisk{{Source: "zones/us-west1-a/disks/disk", AutoDelete: true}},
+	}))
+	assertErrorCode(t, http.StatusBadRequest, c.DeleteDisk("p", "us-west1-a", "disk"))
+
+	assert.NoError(t, c.DeleteInstance("p", "us-west1-a", "inst"))
+	_, err := c.GetDisk("p", "us-west1-a", "disk")
+	assertErrorCode(t, http.StatusNotFound, err)
+
+	var ops []string
+	for _, op := range c.Operations() {
+		ops = append(ops, op.OperationType+" "+lastSegment(op.TargetLink))
+	}
+	assert.Equal(t, []string{"insert disk", "insert inst", "delete inst"}, ops)
+}
+
+func TestFakeClient_CreateInstanceBeta_CreatesBootDisk(t *testing.T) {
+	c := NewFakeClient()
+	c.AddProject("p", "us-west1-a")
+	c.AddImage("worker", &compute.Image{Name: "worker-v1", Family: "worker", DiskSizeGb: 10})
+
+	instance := &computeBeta.Instance{
+		Name: "inst",
+		Disks: []*computeBeta.AttachedDisk{{
+			Boot:             true,
+			InitializeParams: &computeBeta.AttachedDiskInitializeParams{SourceImage: "projects/worker/global/images/family/worker"},
+		}},
+	}
+	assert.NoError(t, c.CreateInstanceBeta("p", "us-west1-a", instance))
+	assert.Equal(t, "RUNNING", instance.Status)
+
+	disk, err := c.GetDisk("p", "us-west1-a", "inst")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(10), disk.SizeGb)
+	assert.Equal(t, []string{instance.SelfLink}, disk.Users)
+}
+
+func TestFakeClient_ScriptedSerialPortOutput(t *testing.T) {
+	c := NewFakeClient()
+	c.AddProject("p", "us-west1-a")
+	c.ScriptInstances("inst-", InstanceScript{
+		SerialPortOutput: map[int64][]string{1: {"starting\n", "done\n"}},
+		GuestAttributes:  map[string]string{"daisy/status": "ok"},
+		StopWhenDone:     true,
+	})
+	assert.NoError(t, c.CreateInstance("p", "us-west1-a", &compute.Instance{Name: "inst-1"}))
+
+	out, err := c.GetSerialPortOutput("p", "us-west1-a", "inst-1", 1, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, "starting\n", out.Contents)
+	stopped, _ := c.InstanceStopped("p", "us-west1-a", "inst-1")
+	assert.False(t, stopped)
+
+	out, err = c.GetSerialPortOutput("p", "us-west1-a", "inst-1", 1, out.Next)
+	assert.NoError(t, err)
+	assert.Equal(t, "done\n", out.Contents)
+	stopped, _ = c.InstanceStopped("p", "us-west1-a", "inst-1")
+	assert.True(t, stopped)
+
+	attr, err := c.GetGuestAttributes("p", "us-west1-a", "inst-1", "", "daisy/status")
+	assert.NoError(t, err)
+	assert.Equal(t, "ok", attr.VariableValue)
+	_, err = c.GetGuestAttributes("p", "us-west1-a", "inst-1", "", "daisy/missing")
+	assertErrorCode(t, http.StatusNotFound, err)
+}
+
+func TestFakeClient_FailNext(t *testing.T) {
+	c := NewFakeClient()
+	c.AddProject("p", "us-west1-a")
+	c.FailNext("CreateDisk", errors.New("quota exceeded"))
+
+	assert.EqualError(t, c.CreateDisk("p", "us-west1-a", &compute.Disk{Name: "d"}), "quota exceeded")
+	assert.NoError(t, c.CreateDisk("p", "us-west1-a", &compute.Disk{Name: "d"}))
+}
+
+func TestFakeClient_RunsDaisyWorkflow(t *testing.T) {
+	c := NewFakeClient()
+	c.AddProject("p", "us-west1-a")
+	c.AddImage("p", &compute.Image{Name: "source", DiskSizeGb: 10})
+	c.ScriptInstances("inst-translate", InstanceScript{
+		SerialPortOutput: map[int64][]string{1: {"translating\n", "TranslateSuccess\n"}},
+	})
+
+	wf := daisy.New()
+	wf.Name = "translate"
+	wf.Project = "p"
+	wf.Zone = "us-west1-a"
+	wf.GCSPath = "gs://scratch"
+	wf.ComputeClient = c
+	wf.StorageClient = newFakeStorageClient(t)
+	wf.DisableGCSLogging()
+	wf.DisableCloudLogging()
+	wf.DisableStdoutLogging()
+	wf.Steps = map[string]*daisy.Step{
+		"create-disk": {CreateDisks: &daisy.CreateDisks{
+			{Disk: compute.Disk{Name: "disk-translate", SourceImage: "projects/p/global/images/source"}},
+		}},
+		"translate": {CreateInstances: &daisy.CreateInstances{Instances: []*daisy.Instance{{
+			Instance: compute.Instance{
+				Name:  "inst-translate",
+				Disks: []*compute.AttachedDisk{{Source: "disk-translate"}},
+			},
+		}}}},
+		"wait": {WaitForInstancesSignal: &daisy.WaitForInstancesSignal{{
+			Name:     "inst-translate",
+			Interval: "10ms",
+			SerialOutput: &daisy.SerialOutput{
+				Port:         1,
+				SuccessMatch: "TranslateSuccess",
+			},
+		}}},
+		"delete-instance": {DeleteResources: &daisy.DeleteResources{Instances: []string{"inst-translate"}}},
+		"create-image": {CreateImages: &daisy.CreateImages{Images: []*daisy.Image{{
+			Image:     compute.Image{Name: "imported", SourceDisk: "disk-translate"},
+			ImageBase: daisy.ImageBase{Resource: daisy.Resource{ExactName: true, NoCleanup: true}},
+		}}}},
+	}
+	wf.Dependencies = map[string][]string{
+		"translate":       {"create-disk"},
+		"wait":            {"translate"},
+		"delete-instance": {"wait"},
+		"create-image":    {"delete-instance"},
+	}
+
+	if !assert.NoError(t, wf.Run(context.Background())) {
+		return
+	}
+
+	image, err := c.GetImage("p", "imported")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(10), image.DiskSizeGb)
+	instances, _ := c.ListInstances("p", "us-west1-a")
+	assert.Empty(t, instances)
+	disks, _ := c.ListDisks("p", "us-west1-a")
+	assert.Empty(t, disks, "daisy cleans up the disk")
+}
+
+// newFakeStorageClient returns a storage client whose requests all succeed, for
+// workflows that write serial port logs to GCS.
+func newFakeStorageClient(t *testing.T) *storage.Client {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"bucket": "scratch", "name": "object"}`))
+	}))
+	t.Cleanup(ts.Close)
+	client, err := storage.NewClient(context.Background(), option.WithEndpoint(ts.URL), option.WithoutAuthentication())
+	assert.NoError(t, err)
+	return client
+}
+
+func assertErrorCode(t *testing.T, expected int, err error) {
+	apiErr, ok := err.(*googleapi.Error)
+	if assert.True(t, ok, "expected googleapi.Error, got %v", err) {
+		assert.Equal(t, expected, apiErr.Code)
+	}
+}
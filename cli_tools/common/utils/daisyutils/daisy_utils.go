@@ -16,15 +16,15 @@ package daisyutils
 
 import (
 	"context"
-	"errors"
 	"fmt"
 	"os"
 	"os/signal"
 	"regexp"
-	"sort"
 	"strings"
 
+	"cloud.google.com/go/storage"
 	daisy "github.com/GoogleCloudPlatform/compute-daisy"
+	daisyCompute "github.com/GoogleCloudPlatform/compute-daisy/compute"
 
 	stringutils "github.com/GoogleCloudPlatform/compute-image-import/cli_tools/common/utils/string"
 )
@@ -35,291 +35,13 @@ const (
 	translateFailedPrefix = "TranslateFailed"
 )
 
-// TranslationSettings includes information that needs to be added to a disk or image after it is imported,
-// for a particular OS and version.
-type TranslationSettings struct {
-	// GcloudOsFlag is the user-facing string corresponding to this OS, version, and licensing mode.
-	// It is passed as a value of the `--os` flag.
-	GcloudOsFlag string
-
-	// LicenseURI is the GCP Compute license corresponding to this OS, version, and licensing mode:
-	//  https://cloud.google.com/compute/docs/reference/rest/v1/licenses
-	LicenseURI string
-
-	// WorkflowPath is the path to a Daisy json workflow, relative to the
-	// `daisy_workflows/image_import` directory.
-	WorkflowPath string
-}
-
 var (
-	supportedOS = []TranslationSettings{
-		// Enterprise Linux
-		{
-			GcloudOsFlag: "centos-7",
-			WorkflowPath: "enterprise_linux/translate_centos_7.wf.json",
-			LicenseURI:   "projects/centos-cloud/global/licenses/centos-7",
-		}, {
-			GcloudOsFlag: "centos-stream-8",
-			WorkflowPath: "enterprise_linux/translate_centos_stream_8.wf.json",
-			LicenseURI:   "projects/centos-cloud/global/licenses/centos-stream",
-		}, {
-			GcloudOsFlag: "centos-stream-9",
-			WorkflowPath: "enterprise_linux/translate_centos_stream_9.wf.json",
-			LicenseURI:   "projects/centos-cloud/global/licenses/centos-stream-9",
-		}, {
-			GcloudOsFlag: "rhel-6",
-			WorkflowPath: "enterprise_linux/translate_rhel_6_licensed.wf.json",
-			LicenseURI:   "projects/rhel-cloud/global/licenses/rhel-6-server",
-		}, {
-			GcloudOsFlag: "rhel-6-byol",
-			WorkflowPath: "enterprise_linux/translate_rhel_6_byol.wf.json",
-			LicenseURI:   "projects/rhel-cloud/global/licenses/rhel-6-byol",
-		}, {
-			GcloudOsFlag: "rhel-7",
-			WorkflowPath: "enterprise_linux/translate_rhel_7_licensed.wf.json",
-			LicenseURI:   "projects/rhel-cloud/global/licenses/rhel-7-server",
-		}, {
-			GcloudOsFlag: "rhel-7-byol",
-			WorkflowPath: "enterprise_linux/translate_rhel_7_byol.wf.json",
-			LicenseURI:   "projects/rhel-cloud/global/licenses/rhel-7-byol",
-		}, {
-			GcloudOsFlag: "rhel-8",
-			WorkflowPath: "enterprise_linux/translate_rhel_8_licensed.wf.json",
-			LicenseURI:   "projects/rhel-cloud/global/licenses/rhel-8-server",
-		}, {
-			GcloudOsFlag: "rhel-8-byol",
-			WorkflowPath: "enterprise_linux/translate_rhel_8_byol.wf.json",
-			LicenseURI:   "projects/rhel-cloud/global/licenses/rhel-8-byos",
-		}, {
-			GcloudOsFlag: "rhel-9",
-			WorkflowPath: "enterprise_linux/translate_rhel_9_licensed.wf.json",
-			LicenseURI:   "projects/rhel-cloud/global/licenses/rhel-9-server",
-		}, {
-			GcloudOsFlag: "rhel-9-byol",
-			WorkflowPath: "enterprise_linux/translate_rhel_9_byol.wf.json",
-			LicenseURI:   "projects/rhel-cloud/global/licenses/rhel-9-byos",
-		}, {
-			GcloudOsFlag: "rocky-8",
-			WorkflowPath: "enterprise_linux/translate_rocky_8.wf.json",
-			LicenseURI:   "projects/rocky-linux-cloud/global/licenses/rocky-linux-8",
-		}, {
-			GcloudOsFlag: "rocky-9",
-			WorkflowPath: "enterprise_linux/translate_rocky_9.wf.json",
-			LicenseURI:   "projects/rocky-linux-cloud/global/licenses/rocky-linux-9",
-		},
-
-		// SUSE
-		{
-			GcloudOsFlag: "opensuse-15",
-			WorkflowPath: "suse/translate_opensuse_15.wf.json",
-			LicenseURI:   "projects/opensuse-cloud/global/licenses/opensuse-leap-42",
-		}, {
-			GcloudOsFlag: "sles-12",
-			WorkflowPath: "suse/translate_sles_12.wf.json",
-			LicenseURI:   "projects/suse-cloud/global/licenses/sles-12",
-		}, {
-			GcloudOsFlag: "sles-12-byol",
-			WorkflowPath: "suse/translate_sles_12_byol.wf.json",
-			LicenseURI:   "projects/suse-byos-cloud/global/licenses/sles-12-byos",
-		}, {
-			GcloudOsFlag: "sles-sap-12",
-			WorkflowPath: "suse/translate_sles_sap_12.wf.json",
-			LicenseURI:   "projects/suse-sap-cloud/global/licenses/sles-sap-12",
-		}, {
-			GcloudOsFlag: "sles-sap-12-byol",
-			WorkflowPath: "suse/translate_sles_sap_12_byol.wf.json",
-			LicenseURI:   "projects/suse-byos-cloud/global/licenses/sles-sap-12-byos",
-		}, {
-			GcloudOsFlag: "sles-15",
-			WorkflowPath: "suse/translate_sles_15.wf.json",
-			LicenseURI:   "projects/suse-cloud/global/licenses/sles-15",
-		}, {
-			GcloudOsFlag: "sles-15-byol",
-			WorkflowPath: "suse/translate_sles_15_byol.wf.json",
-			LicenseURI:   "projects/suse-byos-cloud/global/licenses/sles-15-byos",
-		}, {
-			GcloudOsFlag: "sles-sap-15",
-			WorkflowPath: "suse/translate_sles_sap_15.wf.json",
-			LicenseURI:   "projects/suse-sap-cloud/global/licenses/sles-sap-15",
-		}, {
-			GcloudOsFlag: "sles-sap-15-byol",
-			WorkflowPath: "suse/translate_sles_sap_15_byol.wf.json",
-			LicenseURI:   "projects/suse-byos-cloud/global/licenses/sles-sap-15-byos",
-		},
-
-		// Debian
-		{
-			GcloudOsFlag: "debian-8",
-			WorkflowPath: "debian/translate_debian_8.wf.json",
-			LicenseURI:   "projects/debian-cloud/global/licenses/debian-8-jessie",
-		}, {
-			GcloudOsFlag: "debian-9",
-			WorkflowPath: "debian/translate_debian_9.wf.json",
-			LicenseURI:   "projects/debian-cloud/global/licenses/debian-9-stretch",
-		}, {
-			GcloudOsFlag: "debian-10",
-			WorkflowPath: "debian/translate_debian_10.wf.json",
-			LicenseURI:   "projects/debian-cloud/global/licenses/debian-10-buster",
-		}, {
-			GcloudOsFlag: "debian-11",
-			WorkflowPath: "debian/translate_debian_11.wf.json",
-			LicenseURI:   "projects/debian-cloud/global/licenses/debian-11-bullseye",
-		},
-
-		// Ubuntu
-		{
-			GcloudOsFlag: "ubuntu-1404",
-			WorkflowPath: "ubuntu/translate_ubuntu_1404.wf.json",
-			LicenseURI:   "projects/ubuntu-os-cloud/global/licenses/ubuntu-1404-trusty",
-		}, {
-			GcloudOsFlag: "ubuntu-1604",
-			WorkflowPath: "ubuntu/translate_ubuntu_1604.wf.json",
-			LicenseURI:   "projects/ubuntu-os-cloud/global/licenses/ubuntu-1604-xenial",
-		}, {
-			GcloudOsFlag: "ubuntu-1804",
-			WorkflowPath: "ubuntu/translate_ubuntu_1804.wf.json",
-			LicenseURI:   "projects/ubuntu-os-cloud/global/licenses/ubuntu-1804-lts",
-		}, {
-			GcloudOsFlag: "ubuntu-2004",
-			WorkflowPath: "ubuntu/translate_ubuntu_2004.wf.json",
-			LicenseURI:   "projects/ubuntu-os-cloud/global/licenses/ubuntu-2004-lts",
-		}, {
-			GcloudOsFlag: "ubuntu-2204",
-			WorkflowPath: "ubuntu/translate_ubuntu_2204.wf.json",
-			LicenseURI:   "projects/ubuntu-os-cloud/global/licenses/ubuntu-2204-lts",
-		},
-
-		// Windows
-		{
-			GcloudOsFlag: "windows-7-x64-byol",
-			WorkflowPath: "windows/translate_windows_7_x64_byol.wf.json",
-			LicenseURI:   "projects/windows-cloud/global/licenses/windows-7-x64-byol",
-		}, {
-			GcloudOsFlag: "windows-7-x86-byol",
-			WorkflowPath: "windows/translate_windows_7_x86_byol.wf.json",
-			LicenseURI:   "projects/windows-cloud/global/licenses/windows-7-x86-byol",
-		}, {
-			GcloudOsFlag: "windows-8-x64-byol",
-			WorkflowPath: "windows/translate_windows_8_x64_byol.wf.json",
-			LicenseURI:   "projects/windows-cloud/global/licenses/windows-8-x64-byol",
-		}, {
-			GcloudOsFlag: "windows-8-x86-byol",
-			WorkflowPath: "windows/translate_windows_8_x86_byol.wf.json",
-			LicenseURI:   "projects/windows-cloud/global/licenses/windows-8-x86-byol",
-		}, {
-			GcloudOsFlag: "windows-10-x64-byol",
-			WorkflowPath: "windows/translate_windows_10_x64_byol.wf.json",
-			LicenseURI:   "projects/windows-cloud/global/licenses/windows-10-x64-byol",
-		}, {
-			GcloudOsFlag: "windows-10-x86-byol",
-			WorkflowPath: "windows/translate_windows_10_x86_byol.wf.json",
-			LicenseURI:   "projects/windows-cloud/global/licenses/windows-10-x86-byol",
-		}, {
-			GcloudOsFlag: "windows-2008r2",
-			WorkflowPath: "windows/translate_windows_2008_r2.wf.json",
-			LicenseURI:   "projects/windows-cloud/global/licenses/windows-server-2008-r2-dc",
-		}, {
-			GcloudOsFlag: "windows-2008r2-byol",
-			WorkflowPath: "windows/translate_windows_2008_r2_byol.wf.json",
-			LicenseURI:   "projects/windows-cloud/global/licenses/windows-server-2008-r2-byol",
-		}, {
-			GcloudOsFlag: "windows-2012",
-			WorkflowPath: "windows/translate_windows_2012.wf.json",
-			LicenseURI:   "projects/windows-cloud/global/licenses/windows-server-2012-dc",
-		}, {
-			GcloudOsFlag: "windows-2012-byol",
-			WorkflowPath: "windows/translate_windows_2012_byol.wf.json",
-			LicenseURI:   "projects/windows-cloud/global/licenses/windows-server-2012-byol",
-		}, {
-			GcloudOsFlag: "windows-2012r2",
-			WorkflowPath: "windows/translate_windows_2012_r2.wf.json",
-			LicenseURI:   "projects/windows-cloud/global/licenses/windows-server-2012-r2-dc",
-		}, {
-			GcloudOsFlag: "windows-2012r2-byol",
-			WorkflowPath: "windows/translate_windows_2012_r2_byol.wf.json",
-			LicenseURI:   "projects/windows-cloud/global/licenses/windows-server-2012-r2-byol",
-		}, {
-			GcloudOsFlag: "windows-2016",
-			WorkflowPath: "windows/translate_windows_2016.wf.json",
-			LicenseURI:   "projects/windows-cloud/global/licenses/windows-server-2016-dc",
-		}, {
-			GcloudOsFlag: "windows-2016-byol",
-			WorkflowPath: "windows/translate_windows_2016_byol.wf.json",
-			LicenseURI:   "projects/windows-cloud/global/licenses/windows-server-2016-byol",
-		}, {
-			GcloudOsFlag: "windows-2019",
-			WorkflowPath: "windows/translate_windows_2019.wf.json",
-			LicenseURI:   "projects/windows-cloud/global/licenses/windows-server-2019-dc",
-		}, {
-			GcloudOsFlag: "windows-2019-byol",
-			WorkflowPath: "windows/translate_windows_2019_byol.wf.json",
-			LicenseURI:   "projects/windows-cloud/global/licenses/windows-server-2019-byol",
-		}, {
-			GcloudOsFlag: "windows-2022",
-			WorkflowPath: "windows/translate_windows_2022.wf.json",
-			LicenseURI:   "projects/windows-cloud/global/licenses/windows-server-2022-dc",
-		}, {
-			GcloudOsFlag: "windows-2022-byol",
-			WorkflowPath: "windows/translate_windows_2022_byol.wf.json",
-			LicenseURI:   "projects/windows-cloud/global/licenses/windows-server-2022-byol",
-		},
-	}
-
-	// osIDsReplacements maps operating systems versions identifier to an internal used namas.
-	osIDsReplacements = map[string]string{
-		"windows-7-byol":       "windows-7-x64-byol",
-		"windows-8-1-x64-byol": "windows-8-x64-byol",
-		"windows-10-byol":      "windows-10-x64-byol",
-
-		// Windows 11 is genuinely Windows 10 with a new explorer.exe,
-		// So, we're triggering the same process for windows 11 as windows 10.
-		"windows-11-byol":     "windows-10-x64-byol",
-		"windows-11-x64-byol": "windows-10-x64-byol",
-	}
-
 	privacyRegex    = regexp.MustCompile(`\[Privacy\->.*?<\-Privacy\]`)
 	privacyTagRegex = regexp.MustCompile(`(\[Privacy\->)|(<\-Privacy\])`)
 
 	debianWorkerRegex = regexp.MustCompile("projects/compute-image-import/global/images/debian-\\d+-worker-v")
 )
 
-// GetSortedOSIDs returns the supported OS identifiers, sorted.
-func GetSortedOSIDs() []string {
-	choices := make([]string, 0, len(supportedOS))
-	for _, k := range supportedOS {
-		choices = append(choices, k.GcloudOsFlag)
-	}
-	sort.Strings(choices)
-	return choices
-}
-
-// ValidateOS validates that osID is supported by Daisy image import
-func ValidateOS(osID string) error {
-	_, err := GetTranslationSettings(osID)
-	return err
-}
-
-// GetTranslationSettings returns parameters required for translating a particular OS, version,
-// and licensing mode to run on GCE.
-//
-// An error is returned if the OS, version, and licensing mode is not supported for import.
-func GetTranslationSettings(osID string) (spec TranslationSettings, err error) {
-	if osID == "" {
-		return spec, errors.New("osID is empty")
-	}
-
-	if replacement := osIDsReplacements[osID]; replacement != "" {
-		osID = replacement
-	}
-	for _, choice := range supportedOS {
-		if choice.GcloudOsFlag == osID {
-			return choice, nil
-		}
-	}
-	allowedValuesMsg := fmt.Sprintf("Allowed values: %v", GetSortedOSIDs())
-	return spec, daisy.Errf("os `%v` is invalid. "+allowedValuesMsg, osID)
-}
-
 // UpdateToUEFICompatible marks workflow resources (disks and images) to be UEFI
 // compatible by adding "UEFI_COMPATIBLE" to GuestOSFeatures. Debian workers
 // are excluded until UEFI becomes the default boot method.
@@ -500,6 +222,14 @@ type EndpointsOverride struct {
 	CloudLogging string `json:",omitempty"`
 }
 
+// WorkflowClients are clients for daisy workflows. When ComputeClient or StorageClient
+// is set, workflows use it instead of creating a client. This allows workflows to run
+// offline, such as with compute.FakeClient.
+type WorkflowClients struct {
+	ComputeClient daisyCompute.Client
+	StorageClient *storage.Client
+}
+
 // EnvironmentSettings controls the resources that are used during tool execution.
 type EnvironmentSettings struct {
 	// Location of workflows
@@ -511,6 +241,9 @@ type EnvironmentSettings struct {
 
 	EndpointsOverride EndpointsOverride
 
+	// Clients that are shared by the workflows, if any.
+	WorkflowClients
+
 	// An optional prefix to include in the bracketed portion of daisy's stdout logs.
 	// Gcloud does a prefix match to determine whether to show a log line to a user.
 	//
@@ -528,6 +261,16 @@ type EnvironmentSettings struct {
 	Tool                        Tool
 	NestedVirtualizationEnabled bool
 	WorkerMachineSeries         []string
+
+	// QuotaBudget is shared by workflows that run in parallel, so that their
+	// combined resources are compared against quotas.
+	QuotaBudget *QuotaBudget
+
+	// When EmitWorkflowsDir is set, each workflow is written to the directory as JSON,
+	// after vars and pre-run hooks are applied. When EmitWorkflowsOnly is set, workflows
+	// aren't run, and DaisyWorker returns ErrWorkflowNotRun.
+	EmitWorkflowsDir  string
+	EmitWorkflowsOnly bool
 }
 
 // ApplyToWorkflow sets fields on daisy.Workflow from the environment settings.
@@ -96,7 +96,7 @@ func Test_GetTranslationSettings_ResolveSameWorkflowPathAsOldMap(t *testing.T) {
 
 func Test_ComputeServiceAccountVar_SupportedByAllOSes(t *testing.T) {
 	workflowDir := "../../../../daisy_workflows/image_import"
-	for _, o := range supportedOS {
+	for _, o := range registry.OS {
 		t.Run(o.GcloudOsFlag, func(t *testing.T) {
 			workflowPath := path.Join(workflowDir, o.WorkflowPath)
 			if _, err := os.Stat(workflowPath); os.IsNotExist(err) {
@@ -126,7 +126,9 @@ func Test_GetTranslationSettings_ReturnsSameLicenseAsContainedInJSON(t *testing.
 			settings, err := GetTranslationSettings(osID)
 			assert.NoError(t, err)
 			assert.NotEmpty(t, settings.WorkflowPath)
-			assert.Contains(t, settings.LicenseURI, "licenses/")
+			if settings.LicenseURI != "" {
+				assert.Contains(t, settings.LicenseURI, "licenses/")
+			}
 
 			workflowPath := path.Join(workflowDir, settings.WorkflowPath)
 			if _, err := os.Stat(workflowPath); os.IsNotExist(err) {
@@ -152,7 +154,11 @@ func Test_GetTranslationSettings_ReturnsSameLicenseAsContainedInJSON(t *testing.
 					}
 				}
 			}
-			assert.Contains(t, licensesInWorkflow, settings.LicenseURI)
+			if settings.LicenseURI == "" {
+				assert.Empty(t, licensesInWorkflow)
+			} else {
+				assert.Contains(t, licensesInWorkflow, settings.LicenseURI)
+			}
 		})
 	}
 }
@@ -173,9 +179,9 @@ func TestValidateOsInvalid(t *testing.T) {
 
 func TestGetSortedOSIDs(t *testing.T) {
 	actual := GetSortedOSIDs()
-	assert.Len(t, actual, len(supportedOS))
+	assert.Len(t, actual, len(registry.OS))
 	assert.True(t, sort.StringsAreSorted(actual))
-	for _, choice := range supportedOS {
+	for _, choice := range registry.OS {
 		assert.Contains(t, actual, choice.GcloudOsFlag)
 	}
 }
@@ -15,8 +15,11 @@
 package daisyutils
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
+	"os"
+	"path/filepath"
 	"sync"
 
 	daisy "github.com/GoogleCloudPlatform/compute-daisy"
@@ -36,6 +39,17 @@ type DaisyWorker interface {
 	Cancel(reason string) bool
 }
 
+// ErrWorkflowNotRun is returned by DaisyWorker when EnvironmentSettings.EmitWorkflowsOnly
+// is set, instead of running the workflow. It stops the tool before later steps, which
+// depend on the workflow's results, and is reported as success. See IsWorkflowNotRun.
+var ErrWorkflowNotRun = errors.New("workflow wasn't run, since only emitting workflows was requested")
+
+// IsWorkflowNotRun returns whether err is, or wraps, ErrWorkflowNotRun. Callers that
+// add context to a worker's errors must pass ErrWorkflowNotRun through, or wrap it with %w.
+func IsWorkflowNotRun(err error) bool {
+	return errors.Is(err, ErrWorkflowNotRun)
+}
+
 // WorkflowProvider returns a new instance of a Daisy workflow.
 type WorkflowProvider func() (*daisy.Workflow, error)
 
@@ -47,10 +61,12 @@ type WorkflowProvider func() (*daisy.Workflow, error)
 func NewDaisyWorker(wf WorkflowProvider, env EnvironmentSettings,
 	logger logging.Logger, hooks ...interface{}) DaisyWorker {
 
+	zoneFailover := &ZoneFailoverHook{logger: logger}
 	hooks = append(createResourceLabelerIfMissing(env, hooks),
 		&ApplyEnvToWorkflow{env},
 		&ConfigureDaisyLogging{env},
 		&FallbackToPDStandard{logger: logger},
+		zoneFailover,
 	)
 	if env.NoExternalIP {
 		hooks = append(hooks, &RemoveExternalIPHook{})
@@ -60,18 +76,23 @@ func NewDaisyWorker(wf WorkflowProvider, env EnvironmentSettings,
 	}
 
 	if len(env.WorkerMachineSeries) >= 1 {
-		updateMachineHook := &UpdateMachineTypesHook{logger: logger}
-		updateMachineHook.primaryMachineSeries = env.WorkerMachineSeries[0]
-
-		if len(env.WorkerMachineSeries) >= 2 {
-			updateMachineHook.secondaryMachineSeries = env.WorkerMachineSeries[1]
+		// The zone failover hook runs first, and leaves capacity errors to this
+		// hook while there's another machine series to try.
+		zoneFailover.machineTypes = &UpdateMachineTypesHook{
+			logger:        logger,
+			machineSeries: env.WorkerMachineSeries,
 		}
-
-		hooks = append(hooks, updateMachineHook)
+		hooks = append(hooks, zoneFailover.machineTypes)
 	} else {
 		logger.Debug("UpdateMachineTypesHook is not activated because machine series are not specified.")
 	}
 
+	// Resources are estimated after the other hooks have modified the workflow. Workflows
+	// that are only emitted don't create resources, so quotas aren't checked.
+	if !env.EmitWorkflowsOnly {
+		hooks = append(hooks, &QuotaPreflightHook{logger: logger, env: env, budget: env.QuotaBudget})
+	}
+
 	for _, hook := range hooks {
 		switch hook.(type) {
 		case WorkflowPreHook:
@@ -85,6 +106,33 @@ func NewDaisyWorker(wf WorkflowProvider, env EnvironmentSettings,
 	return &defaultDaisyWorker{workflowProvider: wf, cancel: make(chan string, 1), env: env, logger: logger, hooks: hooks}
 }
 
+// retryLimiter is implemented by post hooks that may request more than one retry.
+// Other hooks may request one retry.
+type retryLimiter interface {
+	// maxRetries returns how many retries the hook may request.
+	maxRetries() int
+}
+
+// retryBudget counts the retries requested by each post hook.
+type retryBudget map[WorkflowPostHook]int
+
+// allowRetry returns whether one of the hooks in requestedBy has retries left, and
+// counts the retry against each of them.
+func (b retryBudget) allowRetry(requestedBy []WorkflowPostHook) bool {
+	allowed := false
+	for _, hook := range requestedBy {
+		limit := 1
+		if limiter, ok := hook.(retryLimiter); ok {
+			limit = limiter.maxRetries()
+		}
+		if b[hook] < limit {
+			allowed = true
+		}
+		b[hook]++
+	}
+	return allowed
+}
+
 // createResourceLabelerIfMissing checks whether there is a resource labeler in hook.
 // If not, then it creates a new one.
 func createResourceLabelerIfMissing(env EnvironmentSettings, hooks []interface{}) []interface{} {
@@ -106,6 +154,7 @@ type defaultDaisyWorker struct {
 	logger           logging.Logger
 	env              EnvironmentSettings
 	hooks            []interface{}
+	runs             int
 
 	cancel      chan string
 	cancelGuard sync.Once
@@ -114,19 +163,25 @@ type defaultDaisyWorker struct {
 // Run runs the daisy workflow with the supplied vars.
 func (w *defaultDaisyWorker) Run(vars map[string]string) (err error) {
 	var wf *daisy.Workflow
-	for attempts := 0; attempts <= 1; attempts++ {
+	retries := retryBudget{}
+	for {
 		if wf, err = w.workflowProvider(); err != nil {
 			break
 		}
 		if err = w.checkIfCancelled(wf); err != nil {
 			break
 		}
-		var retryRequested bool
-		retryRequested, err = w.runOnce(wf, vars)
-		if err == nil || !retryRequested {
+		var retryRequestedBy []WorkflowPostHook
+		retryRequestedBy, err = w.runOnce(wf, vars)
+		if err == nil || !retries.allowRetry(retryRequestedBy) {
 			break
 		}
-		w.logger.Debug(fmt.Sprintf("retryRequested=%v. err=%v", retryRequested, err))
+		w.logger.Debug(fmt.Sprintf("retryRequested=true. err=%v", err))
+	}
+	for _, hook := range w.hooks {
+		if quotaHook, ok := hook.(*QuotaPreflightHook); ok {
+			quotaHook.release()
+		}
 	}
 	w.finishedWf = wf
 	return err
@@ -156,20 +211,34 @@ func (w *defaultDaisyWorker) checkIfCancelled(wf *daisy.Workflow) (err error) {
 	return err
 }
 
-// runOnce applies vars to the workflow, runs hooks, and runs the workflow. The retry
-// return value indicates whether a hook has requested a retry.
-func (w *defaultDaisyWorker) runOnce(wf *daisy.Workflow, vars map[string]string) (retry bool, err error) {
+// runOnce applies vars to the workflow, runs hooks, and runs the workflow. The
+// retryRequestedBy return value lists the hooks that have requested a retry.
+func (w *defaultDaisyWorker) runOnce(wf *daisy.Workflow, vars map[string]string) (
+	retryRequestedBy []WorkflowPostHook, err error) {
 	if err := (&ApplyAndValidateVars{w.env, vars}).PreRunHook(wf); err != nil {
-		return false, err
+		return nil, err
 	}
 	for _, hook := range w.hooks {
 		preHook, isPreHook := hook.(WorkflowPreHook)
 		if isPreHook {
 			if err := preHook.PreRunHook(wf); err != nil {
-				return false, err
+				return nil, err
 			}
 		}
 	}
+	w.runs++
+	if w.env.EmitWorkflowsDir != "" {
+		filename, err := emitWorkflow(wf, w.env.EmitWorkflowsDir,
+			fmt.Sprintf("%s-%s-%d", w.env.ExecutionID, wf.Name, w.runs))
+		if err != nil {
+			return nil, err
+		}
+		w.logger.User(fmt.Sprintf("Wrote workflow %q to %s.", wf.Name, filename))
+	}
+	if w.env.EmitWorkflowsOnly {
+		w.logger.User(fmt.Sprintf("Workflow %q wasn't run, since only emitting workflows was requested.", wf.Name))
+		return nil, ErrWorkflowNotRun
+	}
 	err = RunWorkflowWithCancelSignal(wf, w.cancel)
 	if wf.Logger != nil {
 		for _, trace := range wf.Logger.ReadSerialPortLogs() {
@@ -183,11 +252,31 @@ func (w *defaultDaisyWorker) runOnce(wf *daisy.Workflow, vars map[string]string)
 			if isPostHook {
 				wantRetry := false
 				wantRetry, err = postHook.PostRunHook(err)
-				retry = retry || wantRetry
+				if wantRetry {
+					retryRequestedBy = append(retryRequestedBy, postHook)
+				}
 			}
 		}
 	}
-	return retry, err
+	return retryRequestedBy, err
+}
+
+// emitWorkflow writes wf as JSON to a file named basename.json in dir, and returns the
+// file's path. Variables aren't substituted into the workflow's steps; their values are
+// included in the workflow's Vars field.
+func emitWorkflow(wf *daisy.Workflow, dir, basename string) (string, error) {
+	content, err := json.MarshalIndent(wf, "", "  ")
+	if err != nil {
+		return "", daisy.Errf("failed to serialize workflow %q: %v", wf.Name, err)
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", daisy.Errf("failed to create directory %s: %v", dir, err)
+	}
+	filename := filepath.Join(dir, basename+".json")
+	if err := os.WriteFile(filename, append(content, '\n'), 0644); err != nil {
+		return "", daisy.Errf("failed to write workflow %q: %v", wf.Name, err)
+	}
+	return filename, nil
 }
 
 // RunAndReadSerialValue runs the daisy workflow with the supplied vars, and returns the serial
@@ -15,7 +15,11 @@
 package daisyutils
 
 import (
+	"encoding/json"
 	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
 	"testing"
 
 	"cloud.google.com/go/storage"
@@ -46,6 +50,7 @@ func Test_NewDaisyWorker_IncludesStandardHooks(t *testing.T) {
 		removeExternalIPHook:  false,
 		resourceLabeler:       true,
 		fallbackToPDStandard:  true,
+		zoneFailover:          true,
 	}, findWhichHooksApplied(worker))
 	rl := getResourceLabeler(t, worker)
 	assert.Equal(t, env.Labels, rl.UserLabels)
@@ -84,6 +89,7 @@ func Test_NewDaisyWorker_IncludesNoExternalIPHook_WhenRequestedByUser(t *testing
 		removeExternalIPHook:  true,
 		resourceLabeler:       true,
 		fallbackToPDStandard:  true,
+		zoneFailover:          true,
 	}, findWhichHooksApplied(worker))
 }
 
@@ -296,6 +302,79 @@ func Test_DaisyWorkerRun_DoesntRunWorkflowMoreThanTwiceEventWhenRequested(t *tes
 	assert.Equal(t, 2, numWorkflowInvocations)
 }
 
+func Test_DaisyWorkerRun_RunsWorkflowOncePerMachineSeriesWhenRequested(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	numWorkflowInvocations := 0
+	postHook := mocks.NewMockWorkflowPostHook(mockCtrl)
+	postHook.EXPECT().PostRunHook(gomock.Any()).Return(false, errors.New("Quota 'C3_CPUS' exceeded. Limit: 0.0 in region us-west1.")).Times(1)
+	postHook.EXPECT().PostRunHook(gomock.Any()).Return(false, errors.New("Quota 'N2_CPUS' exceeded. Limit: 0.0 in region us-west1.")).Times(1)
+	postHook.EXPECT().PostRunHook(gomock.Any()).Return(false, errors.New("Quota 'N1_CPUS' exceeded. Limit: 0.0 in region us-west1.")).Times(1)
+	postHook.EXPECT().PostRunHook(gomock.Any()).Return(false, errors.New("Quota 'E2_CPUS' exceeded. Limit: 0.0 in region us-west1.")).Times(1)
+	worker := NewDaisyWorker(func() (*daisy.Workflow, error) {
+		numWorkflowInvocations++
+		return daisy.New(), nil
+	}, EnvironmentSettings{
+		ExecutionID:         "b1234",
+		Tool:                Tool{ResourceLabelName: "unit-test"},
+		WorkerMachineSeries: []string{"c3", "n2", "n1", "e2"},
+	}, logging.NewToolLogger("test"), postHook)
+	assert.Error(t, worker.Run(map[string]string{}))
+	assert.Equal(t, 4, numWorkflowInvocations)
+}
+
+func Test_DaisyWorkerRun_TriesMachineSeriesBeforeFailingOverToOtherZones(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	// Each run fails due to insufficient zonal capacity.
+	postHook := mocks.NewMockWorkflowPostHook(mockCtrl)
+	postHook.EXPECT().PostRunHook(gomock.Any()).Return(false, errZoneExhausted).AnyTimes()
+	retriever := mocks.NewMockResourceLocationRetrieverInterface(mockCtrl)
+	retriever.EXPECT().GetZonesInRegion("us-west1", "p").Return(
+		[]string{"us-west1-a", "us-west1-b", "us-west1-c", "us-west1-d", "us-west1-e"}, nil)
+
+	var workflows []*daisy.Workflow
+	worker := NewDaisyWorker(func() (*daisy.Workflow, error) {
+		wf := daisy.New()
+		wf.Steps = map[string]*daisy.Step{"create-instance": {CreateInstances: &daisy.CreateInstances{
+			Instances: []*daisy.Instance{{Instance: compute.Instance{MachineType: "n2-standard-2"}}},
+		}}}
+		workflows = append(workflows, wf)
+		return wf, nil
+	}, EnvironmentSettings{
+		Project:             "p",
+		Zone:                "us-west1-a",
+		ExecutionID:         "b1234",
+		Tool:                Tool{ResourceLabelName: "unit-test"},
+		WorkerMachineSeries: []string{"n2", "n1"},
+	}, logging.NewToolLogger("test"), postHook)
+	for _, hook := range worker.(*defaultDaisyWorker).hooks {
+		if zoneFailover, ok := hook.(*ZoneFailoverHook); ok {
+			zoneFailover.zoneRetriever = retriever
+		}
+	}
+
+	assert.Error(t, worker.Run(map[string]string{}))
+	var runs []string
+	for _, wf := range workflows {
+		runs = append(runs, wf.Zone+" "+wf.Steps["create-instance"].CreateInstances.Instances[0].MachineType)
+	}
+	// The machine series is changed first. Then the zone is changed, up to maxZoneFailovers times,
+	// and each zone starts again with the first machine series.
+	assert.Equal(t, []string{
+		"us-west1-a n2-standard-2",
+		"us-west1-a n1-standard-2",
+		"us-west1-b n2-standard-2",
+		"us-west1-b n1-standard-2",
+		"us-west1-c n2-standard-2",
+		"us-west1-c n1-standard-2",
+		"us-west1-d n2-standard-2",
+		"us-west1-d n1-standard-2",
+	}, runs)
+}
+
 func Test_DaisyWorkerRun_DoesntReRunFailedWorkflowIfNotRequested(t *testing.T) {
 	expectedError := "error validating workflow: must provide workflow field 'Name'"
 	mockCtrl := gomock.NewController(t)
@@ -371,7 +450,7 @@ func Test_DaisyWorkerRunAndReadSerialValue_HappyCase(t *testing.T) {
 }
 
 type appliedHooks struct {
-	applyEnvToWorkflow, configureDaisyLogging, removeExternalIPHook, resourceLabeler, fallbackToPDStandard bool
+	applyEnvToWorkflow, configureDaisyLogging, removeExternalIPHook, resourceLabeler, fallbackToPDStandard, zoneFailover bool
 }
 
 func findWhichHooksApplied(worker DaisyWorker) (t appliedHooks) {
@@ -392,6 +471,9 @@ func findWhichHooksApplied(worker DaisyWorker) (t appliedHooks) {
 		if _, ok := hook.(*FallbackToPDStandard); ok {
 			t.fallbackToPDStandard = true
 		}
+		if _, ok := hook.(*ZoneFailoverHook); ok {
+			t.zoneFailover = true
+		}
 	}
 	return t
 }
@@ -427,3 +509,122 @@ func configWorkflowForUnitTesting(t *testing.T, wf *daisy.Workflow, mockCtrl *go
 	wf.DisableCloudLogging()
 	wf.DisableGCSLogging()
 }
+
+func Test_DaisyWorkerRun_EmitsWorkflowAfterPreHooks(t *testing.T) {
+	dir := t.TempDir()
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	preHook := mocks.NewMockWorkflowPreHook(mockCtrl)
+	preHook.EXPECT().PreRunHook(gomock.Any()).DoAndReturn(func(wf *daisy.Workflow) error {
+		wf.Zone = "zone-from-hook"
+		return nil
+	})
+	worker := NewDaisyWorker(func() (*daisy.Workflow, error) {
+		wf := daisy.New()
+		wf.Name = "wf-name"
+		wf.Vars = map[string]daisy.Var{"var": {}}
+		return wf, nil
+	}, EnvironmentSettings{
+		ExecutionID:       "b1234",
+		Tool:              Tool{ResourceLabelName: "unit-test"},
+		EmitWorkflowsDir:  dir,
+		EmitWorkflowsOnly: true,
+	}, logging.NewToolLogger("test"), preHook)
+
+	err := worker.Run(map[string]string{"var": "value"})
+	assert.Equal(t, ErrWorkflowNotRun, err)
+	content, err := os.ReadFile(filepath.Join(dir, "b1234-wf-name-1.json"))
+	assert.NoError(t, err)
+	var emitted map[string]interface{}
+	assert.NoError(t, json.Unmarshal(content, &emitted))
+	assert.Equal(t, "wf-name", emitted["Name"])
+	assert.Equal(t, "zone-from-hook", emitted["Zone"])
+	assert.Equal(t, "value", emitted["Vars"].(map[string]interface{})["var"].(map[string]interface{})["Value"])
+}
+
+func Test_DaisyWorkerRun_DoesntRunWorkflowWhenOnlyEmittingWorkflows_WithoutDirectory(t *testing.T) {
+	worker := NewDaisyWorker(func() (*daisy.Workflow, error) {
+		// The workflow would fail if it were run, since it doesn't have steps.
+		return daisy.New(), nil
+	}, EnvironmentSettings{
+		ExecutionID:       "b1234",
+		Tool:              Tool{ResourceLabelName: "unit-test"},
+		EmitWorkflowsOnly: true,
+	}, logging.NewToolLogger("test"))
+
+	assert.Equal(t, ErrWorkflowNotRun, worker.Run(map[string]string{}))
+}
+
+func Test_DaisyWorkerRun_ReleasesQuotaBudgetWhenFinished(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	mockComputeClient := mocks.NewMockClient(mockCtrl)
+	// Quotas are read by each workflow, since the previous workflow released its estimate.
+	mockComputeClient.EXPECT().GetRegion("p", "us-west1").Return(&compute.Region{
+		Quotas: []*compute.Quota{{Metric: "CPUS", Limit: 20}}}, nil).Times(2)
+	mockComputeClient.EXPECT().GetProject("p").Return(&compute.Project{}, nil).Times(2)
+	env := EnvironmentSettings{
+		ExecutionID:     "b1234",
+		Tool:            Tool{ResourceLabelName: "unit-test"},
+		Project:         "p",
+		Zone:            "us-west1-a",
+		QuotaBudget:     NewQuotaBudget(),
+		WorkflowClients: WorkflowClients{ComputeClient: mockComputeClient},
+	}
+
+	for i := 0; i < 2; i++ {
+		worker := NewDaisyWorker(func() (*daisy.Workflow, error) {
+			wf := createWorkflowForEstimate()
+			wf.Name = "wf-name"
+			return wf, nil
+		}, env, logging.NewToolLogger("test"))
+		// The workflow fails, since its steps aren't complete.
+		assert.Error(t, worker.Run(map[string]string{}))
+	}
+	assert.Empty(t, env.QuotaBudget.reserved)
+}
+
+func Test_DaisyWorkerRun_SkipsQuotaPreflightWhenOnlyEmittingWorkflows(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	env := EnvironmentSettings{
+		ExecutionID:       "b1234",
+		Tool:              Tool{ResourceLabelName: "unit-test"},
+		Project:           "p",
+		Zone:              "us-west1-a",
+		QuotaBudget:       NewQuotaBudget(),
+		WorkflowClients:   WorkflowClients{ComputeClient: mocks.NewMockClient(mockCtrl)},
+		EmitWorkflowsDir:  t.TempDir(),
+		EmitWorkflowsOnly: true,
+	}
+
+	worker := NewDaisyWorker(func() (*daisy.Workflow, error) {
+		return createWorkflowForEstimate(), nil
+	}, env, logging.NewToolLogger("test"))
+	assert.Equal(t, ErrWorkflowNotRun, worker.Run(map[string]string{}))
+}
+
+func Test_IsWorkflowNotRun(t *testing.T) {
+	assert.True(t, IsWorkflowNotRun(ErrWorkflowNotRun))
+	assert.True(t, IsWorkflowNotRun(fmt.Errorf("failed to inflate disk: %w", ErrWorkflowNotRun)))
+	assert.False(t, IsWorkflowNotRun(errors.New("workflow failed")))
+	assert.False(t, IsWorkflowNotRun(nil))
+}
+
+func Test_DaisyWorkerRun_FailsWhenWorkflowCantBeEmitted(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "file")
+	assert.NoError(t, os.WriteFile(file, []byte{}, 0644))
+
+	worker := NewDaisyWorker(func() (*daisy.Workflow, error) {
+		return daisy.New(), nil
+	}, EnvironmentSettings{
+		ExecutionID:      "b1234",
+		Tool:             Tool{ResourceLabelName: "unit-test"},
+		EmitWorkflowsDir: file,
+	}, logging.NewToolLogger("test"))
+
+	err := worker.Run(map[string]string{})
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to create directory")
+}
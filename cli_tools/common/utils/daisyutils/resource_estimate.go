@@ -0,0 +1,195 @@
+//  Copyright 2026 Google Inc. All Rights Reserved.
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+package daisyutils
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	daisy "github.com/GoogleCloudPlatform/compute-daisy"
+)
+
+const defaultDiskType = "pd-standard"
+
+var workflowVarRegex = regexp.MustCompile(`\$\{([^}]+)\}`)
+
+// ResourceEstimate is the amount of quota-limited resources that a workflow creates.
+// Resources are summed across all of the workflow's steps, so the estimate is an upper
+// bound for resources that are deleted before others are created.
+type ResourceEstimate struct {
+	Instances int64
+	Images    int64
+
+	// CPUsBySeries is the number of worker CPUs for each machine series, such as "n1".
+	CPUsBySeries map[string]int64
+
+	// DiskGBByType is the total size of disks for each disk type, such as "pd-ssd".
+	DiskGBByType map[string]int64
+}
+
+// EstimateResources computes the resources that wf creates. Values that reference a
+// workflow variable are resolved using wf's variables; values that can't be resolved,
+// such as the size of a disk created from an image, aren't counted.
+func EstimateResources(wf *daisy.Workflow) ResourceEstimate {
+	estimate := ResourceEstimate{CPUsBySeries: map[string]int64{}, DiskGBByType: map[string]int64{}}
+	addDisk := func(diskType string, sizeGB int64) {
+		if sizeGB <= 0 {
+			return
+		}
+		diskType = path.Base(substituteWorkflowVars(wf, diskType))
+		if diskType == "" || diskType == "." {
+			diskType = defaultDiskType
+		}
+		estimate.DiskGBByType[diskType] += sizeGB
+	}
+	addInstance := func(machineType string) {
+		estimate.Instances++
+		if series, cpus, ok := parseMachineType(substituteWorkflowVars(wf, machineType)); ok {
+			estimate.CPUsBySeries[series] += cpus
+		}
+	}
+
+	wf.IterateWorkflowSteps(func(step *daisy.Step) {
+		if step.CreateDisks != nil {
+			for _, disk := range *step.CreateDisks {
+				size, _ := strconv.ParseInt(substituteWorkflowVars(wf, disk.SizeGb), 10, 64)
+				addDisk(disk.Disk.Type, size)
+			}
+		}
+		if step.CreateInstances != nil {
+			for _, instance := range step.CreateInstances.Instances {
+				addInstance(instance.MachineType)
+				for _, disk := range instance.Disks {
+					if disk.InitializeParams != nil {
+						addDisk(disk.InitializeParams.DiskType, disk.InitializeParams.DiskSizeGb)
+					}
+				}
+			}
+			for _, instance := range step.CreateInstances.InstancesBeta {
+				addInstance(instance.MachineType)
+				for _, disk := range instance.Disks {
+					if disk.InitializeParams != nil {
+						addDisk(disk.InitializeParams.DiskType, disk.InitializeParams.DiskSizeGb)
+					}
+				}
+			}
+		}
+		if step.CreateImages != nil {
+			estimate.Images += int64(len(step.CreateImages.Images) + len(step.CreateImages.ImagesBeta) +
+				len(step.CreateImages.ImagesAlpha))
+		}
+	})
+	return estimate
+}
+
+// IsEmpty returns whether the estimate doesn't include any resources.
+func (e ResourceEstimate) IsEmpty() bool {
+	return e.Instances == 0 && e.Images == 0 && len(e.CPUsBySeries) == 0 && len(e.DiskGBByType) == 0
+}
+
+// CPUs returns the number of CPUs across all machine series.
+func (e ResourceEstimate) CPUs() int64 {
+	var total int64
+	for _, cpus := range e.CPUsBySeries {
+		total += cpus
+	}
+	return total
+}
+
+// Add returns the sum of e and other.
+func (e ResourceEstimate) Add(other ResourceEstimate) ResourceEstimate {
+	sum := ResourceEstimate{
+		Instances:    e.Instances + other.Instances,
+		Images:       e.Images + other.Images,
+		CPUsBySeries: map[string]int64{},
+		DiskGBByType: map[string]int64{},
+	}
+	for _, m := range []map[string]int64{e.CPUsBySeries, other.CPUsBySeries} {
+		for series, cpus := range m {
+			sum.CPUsBySeries[series] += cpus
+		}
+	}
+	for _, m := range []map[string]int64{e.DiskGBByType, other.DiskGBByType} {
+		for diskType, size := range m {
+			sum.DiskGBByType[diskType] += size
+		}
+	}
+	return sum
+}
+
+// String formats the estimate for logging, for example:
+//
+//	instances=1 images=1 cpus=[n1:4] disks=[pd-ssd:10GB pd-standard:200GB]
+func (e ResourceEstimate) String() string {
+	var cpus, disks []string
+	for _, series := range sortedMapKeys(e.CPUsBySeries) {
+		cpus = append(cpus, fmt.Sprintf("%s:%d", series, e.CPUsBySeries[series]))
+	}
+	for _, diskType := range sortedMapKeys(e.DiskGBByType) {
+		disks = append(disks, fmt.Sprintf("%s:%dGB", diskType, e.DiskGBByType[diskType]))
+	}
+	return fmt.Sprintf("instances=%d images=%d cpus=[%s] disks=[%s]",
+		e.Instances, e.Images, strings.Join(cpus, " "), strings.Join(disks, " "))
+}
+
+// parseMachineType returns the machine series and number of CPUs of a predefined or
+// custom machine type, such as "n1-standard-4" or "n2-custom-8-16384". N1 custom
+// machine types, such as "custom-8-16384", don't include their series. Shared-core
+// machine types, such as "e2-small", are counted as one CPU.
+func parseMachineType(machineType string) (series string, cpus int64, ok bool) {
+	parts := strings.Split(path.Base(machineType), "-")
+	if len(parts) < 2 || parts[0] == "" || strings.Contains(parts[0], "$") {
+		return "", 0, false
+	}
+	series = parts[0]
+	if series == "custom" {
+		series = "n1"
+	}
+	for i, part := range parts {
+		if part == "custom" && i+1 < len(parts) {
+			if cpus, err := strconv.ParseInt(parts[i+1], 10, 64); err == nil {
+				return series, cpus, true
+			}
+			return "", 0, false
+		}
+	}
+	if cpus, err := strconv.ParseInt(parts[len(parts)-1], 10, 64); err == nil {
+		return series, cpus, true
+	}
+	return series, 1, true
+}
+
+// substituteWorkflowVars replaces references to wf's variables in s with their values.
+func substituteWorkflowVars(wf *daisy.Workflow, s string) string {
+	return workflowVarRegex.ReplaceAllStringFunc(s, func(match string) string {
+		if v, found := wf.Vars[match[2:len(match)-1]]; found {
+			return v.Value
+		}
+		return match
+	})
+}
+
+func sortedMapKeys(m map[string]int64) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
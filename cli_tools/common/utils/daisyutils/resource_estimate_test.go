@@ -0,0 +1,115 @@
+//  Copyright 2026 Google Inc. All Rights Reserved.
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+package daisyutils
+
+import (
+	"testing"
+
+	daisy "github.com/GoogleCloudPlatform/compute-daisy"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/api/compute/v1"
+)
+
+func TestEstimateResources(t *testing.T) {
+	wf := createWorkflowForEstimate()
+
+	estimate := EstimateResources(wf)
+
+	assert.Equal(t, int64(2), estimate.Instances)
+	assert.Equal(t, int64(1), estimate.Images)
+	assert.Equal(t, map[string]int64{"n1": 4, "n2": 8}, estimate.CPUsBySeries)
+	assert.Equal(t, int64(12), estimate.CPUs())
+	assert.Equal(t, map[string]int64{"pd-ssd": 10, "pd-standard": 220}, estimate.DiskGBByType)
+	assert.Equal(t, "instances=2 images=1 cpus=[n1:4 n2:8] disks=[pd-ssd:10GB pd-standard:220GB]", estimate.String())
+}
+
+func TestEstimateResources_EmptyWorkflow(t *testing.T) {
+	assert.True(t, EstimateResources(daisy.New()).IsEmpty())
+}
+
+func TestResourceEstimate_Add(t *testing.T) {
+	estimate := EstimateResources(createWorkflowForEstimate())
+
+	sum := estimate.Add(estimate)
+
+	assert.Equal(t, int64(4), sum.Instances)
+	assert.Equal(t, int64(2), sum.Images)
+	assert.Equal(t, map[string]int64{"n1": 8, "n2": 16}, sum.CPUsBySeries)
+	assert.Equal(t, map[string]int64{"pd-ssd": 20, "pd-standard": 440}, sum.DiskGBByType)
+	assert.Equal(t, int64(2), estimate.Instances, "Add shouldn't modify its receiver")
+}
+
+func Test_parseMachineType(t *testing.T) {
+	for _, tt := range []struct {
+		machineType    string
+		expectedSeries string
+		expectedCPUs   int64
+		expectedOK     bool
+	}{
+		{"n1-standard-4", "n1", 4, true},
+		{"zones/us-west1-a/machineTypes/n2-highcpu-16", "n2", 16, true},
+		{"n2-custom-6-16384", "n2", 6, true},
+		{"n2-custom-6-16384-ext", "n2", 6, true},
+		{"custom-4-16384", "n1", 4, true},
+		{"zones/us-west1-a/machineTypes/custom-4-16384-ext", "n1", 4, true},
+		{"e2-small", "e2", 1, true},
+		{"${machine_type}", "", 0, false},
+		{"", "", 0, false},
+	} {
+		t.Run(tt.machineType, func(t *testing.T) {
+			series, cpus, ok := parseMachineType(tt.machineType)
+			assert.Equal(t, tt.expectedSeries, series)
+			assert.Equal(t, tt.expectedCPUs, cpus)
+			assert.Equal(t, tt.expectedOK, ok)
+		})
+	}
+}
+
+func createWorkflowForEstimate() *daisy.Workflow {
+	wf := daisy.New()
+	wf.Vars = map[string]daisy.Var{
+		"machine_type": {Value: "n2-standard-8"},
+		"disk_size":    {Value: "200"},
+	}
+	wf.Steps = map[string]*daisy.Step{
+		"cd": {
+			CreateDisks: &daisy.CreateDisks{
+				{Disk: compute.Disk{Type: "pd-ssd"}, SizeGb: "10"},
+				{SizeGb: "${disk_size}"},
+				{Disk: compute.Disk{SourceImage: "image"}},
+			},
+		},
+		"ci": {
+			CreateInstances: &daisy.CreateInstances{
+				Instances: []*daisy.Instance{
+					{Instance: compute.Instance{MachineType: "n1-standard-4"}},
+					{Instance: compute.Instance{
+						MachineType: "${machine_type}",
+						Disks: []*compute.AttachedDisk{{
+							InitializeParams: &compute.AttachedDiskInitializeParams{
+								DiskType:   "zones/us-west1-a/diskTypes/pd-standard",
+								DiskSizeGb: 20,
+							},
+						}},
+					}},
+				},
+			},
+		},
+		"cimg": {
+			CreateImages: &daisy.CreateImages{Images: []*daisy.Image{{}}},
+		},
+	}
+	return wf
+}
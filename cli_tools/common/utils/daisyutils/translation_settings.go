@@ -0,0 +1,226 @@
+//  Copyright 2026 Google Inc. All Rights Reserved.
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+package daisyutils
+
+import (
+	"bytes"
+	_ "embed"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"sort"
+	"time"
+
+	daisy "github.com/GoogleCloudPlatform/compute-daisy"
+	"gopkg.in/yaml.v3"
+
+	"github.com/GoogleCloudPlatform/compute-image-import/cli_tools/common/distro"
+)
+
+// TranslationSettingsOSEnvVarName is the os env var name of a YAML file that replaces
+// the embedded translation settings.
+const TranslationSettingsOSEnvVarName = "TRANSLATION_SETTINGS_FILE"
+
+// translationWorkflowDir is the directory, relative to `daisy_workflows`, that
+// TranslationSettings.WorkflowPath is resolved against.
+const translationWorkflowDir = "image_import"
+
+var supportedArchitectures = map[string]bool{"x86_64": true, "i386": true, "arm64": true}
+
+//go:embed translation_settings.yaml
+var embeddedTranslationSettings []byte
+
+// TranslationSettings includes information that needs to be added to a disk or image after it is imported,
+// for a particular OS and version.
+type TranslationSettings struct {
+	// GcloudOsFlag is the user-facing string corresponding to this OS, version, and licensing mode.
+	// It is passed as a value of the `--os` flag.
+	GcloudOsFlag string `yaml:"os_flag"`
+
+	// LicenseURI is the GCP Compute license corresponding to this OS, version, and licensing mode:
+	//  https://cloud.google.com/compute/docs/reference/rest/v1/licenses
+	// Empty when Compute Engine doesn't publish a license for the OS, such as Oracle Linux.
+	LicenseURI string `yaml:"license"`
+
+	// WorkflowPath is the path to a Daisy json workflow, relative to the
+	// `daisy_workflows/image_import` directory.
+	WorkflowPath string `yaml:"workflow"`
+
+	// Architecture is the CPU architecture of the OS: x86_64, i386, or arm64.
+	Architecture string `yaml:"architecture"`
+
+	// EOLDate is the date, formatted as YYYY-MM-DD, when the vendor stops supporting
+	// this OS and version. Empty when unknown.
+	EOLDate string `yaml:"eol_date"`
+
+	// BYOLPair is the GcloudOsFlag of the same OS and version using the other
+	// licensing mode, such as `rhel-8-byol` for `rhel-8`. Empty when there isn't one.
+	BYOLPair string `yaml:"byol_pair"`
+}
+
+// translationRegistry is the format of translation_settings.yaml.
+type translationRegistry struct {
+	// Aliases maps additional `--os` values to the GcloudOsFlag of an entry in OS.
+	Aliases map[string]string     `yaml:"aliases"`
+	OS      []TranslationSettings `yaml:"os"`
+}
+
+var registry, registryErr = loadTranslationRegistry(os.Getenv(TranslationSettingsOSEnvVarName))
+
+// loadTranslationRegistry parses the embedded translation settings, or the file at
+// overridePath when it's non-empty. When the override can't be used, the embedded
+// settings are returned along with the error.
+func loadTranslationRegistry(overridePath string) (*translationRegistry, error) {
+	embedded, err := parseTranslationRegistry(embeddedTranslationSettings)
+	if err != nil {
+		panic(fmt.Sprintf("embedded translation settings are invalid: %v", err))
+	}
+	if overridePath == "" {
+		return embedded, nil
+	}
+	content, err := os.ReadFile(overridePath)
+	if err != nil {
+		return embedded, daisy.Errf("failed to read translation settings from %s: %v", overridePath, err)
+	}
+	override, err := parseTranslationRegistry(content)
+	if err != nil {
+		return embedded, daisy.Errf("translation settings in %s are invalid: %v", overridePath, err)
+	}
+	return override, nil
+}
+
+// parseTranslationRegistry parses and validates a YAML translation registry.
+func parseTranslationRegistry(content []byte) (*translationRegistry, error) {
+	decoder := yaml.NewDecoder(bytes.NewReader(content))
+	decoder.KnownFields(true)
+	r := &translationRegistry{}
+	if err := decoder.Decode(r); err != nil {
+		return nil, err
+	}
+	if len(r.OS) == 0 {
+		return nil, errors.New("no operating systems are defined")
+	}
+
+	byFlag := make(map[string]TranslationSettings, len(r.OS))
+	for _, settings := range r.OS {
+		if settings.GcloudOsFlag == "" {
+			return nil, errors.New("os_flag is required")
+		}
+		if _, found := byFlag[settings.GcloudOsFlag]; found {
+			return nil, fmt.Errorf("os `%s` is defined more than once", settings.GcloudOsFlag)
+		}
+		byFlag[settings.GcloudOsFlag] = settings
+		if err := validateTranslationSettings(settings); err != nil {
+			return nil, fmt.Errorf("os `%s`: %v", settings.GcloudOsFlag, err)
+		}
+	}
+	for _, settings := range r.OS {
+		if settings.BYOLPair == "" {
+			continue
+		}
+		pair, found := byFlag[settings.BYOLPair]
+		if !found {
+			return nil, fmt.Errorf("os `%s`: byol_pair `%s` is not defined", settings.GcloudOsFlag, settings.BYOLPair)
+		}
+		if pair.BYOLPair != settings.GcloudOsFlag {
+			return nil, fmt.Errorf("os `%s`: byol_pair of `%s` should be `%s`. Actual: `%s`",
+				settings.GcloudOsFlag, pair.GcloudOsFlag, settings.GcloudOsFlag, pair.BYOLPair)
+		}
+	}
+	for alias, target := range r.Aliases {
+		if _, found := byFlag[alias]; found {
+			return nil, fmt.Errorf("alias `%s` is also defined as an os", alias)
+		}
+		if _, found := byFlag[target]; !found {
+			return nil, fmt.Errorf("alias `%s` refers to `%s`, which is not defined", alias, target)
+		}
+	}
+	return r, nil
+}
+
+func validateTranslationSettings(settings TranslationSettings) error {
+	if settings.WorkflowPath == "" {
+		return errors.New("workflow is required")
+	}
+	if !supportedArchitectures[settings.Architecture] {
+		return fmt.Errorf("architecture `%s` is not supported", settings.Architecture)
+	}
+	if settings.EOLDate != "" {
+		if _, err := time.Parse("2006-01-02", settings.EOLDate); err != nil {
+			return fmt.Errorf("eol_date `%s` is not formatted as YYYY-MM-DD", settings.EOLDate)
+		}
+	}
+	if _, err := distro.FromGcloudOSArgument(settings.GcloudOsFlag); err != nil {
+		return err
+	}
+	return nil
+}
+
+// ValidateTranslationSettings returns an error if the translation settings couldn't be
+// loaded, or if a translation workflow is missing from workflowDir, the filesystem
+// path to `daisy_workflows`.
+func ValidateTranslationSettings(workflowDir string) error {
+	if registryErr != nil {
+		return registryErr
+	}
+	for _, settings := range registry.OS {
+		workflow := path.Join(workflowDir, translationWorkflowDir, settings.WorkflowPath)
+		if _, err := os.Stat(workflow); err != nil {
+			return daisy.Errf("translation workflow for os `%s` not found: %v", settings.GcloudOsFlag, err)
+		}
+	}
+	return nil
+}
+
+// GetSortedOSIDs returns the supported OS identifiers, sorted.
+func GetSortedOSIDs() []string {
+	choices := make([]string, 0, len(registry.OS))
+	for _, k := range registry.OS {
+		choices = append(choices, k.GcloudOsFlag)
+	}
+	sort.Strings(choices)
+	return choices
+}
+
+// ValidateOS validates that osID is supported by Daisy image import
+func ValidateOS(osID string) error {
+	_, err := GetTranslationSettings(osID)
+	return err
+}
+
+// GetTranslationSettings returns parameters required for translating a particular OS, version,
+// and licensing mode to run on GCE.
+//
+// An error is returned if the OS, version, and licensing mode is not supported for import.
+func GetTranslationSettings(osID string) (spec TranslationSettings, err error) {
+	if registryErr != nil {
+		return spec, registryErr
+	}
+	if osID == "" {
+		return spec, errors.New("osID is empty")
+	}
+
+	if replacement := registry.Aliases[osID]; replacement != "" {
+		osID = replacement
+	}
+	for _, choice := range registry.OS {
+		if choice.GcloudOsFlag == osID {
+			return choice, nil
+		}
+	}
+	allowedValuesMsg := fmt.Sprintf("Allowed values: %v", GetSortedOSIDs())
+	return spec, daisy.Errf("os `%v` is invalid. "+allowedValuesMsg, osID)
+}
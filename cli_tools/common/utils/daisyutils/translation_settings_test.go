@@ -0,0 +1,107 @@
+//  Copyright 2026 Google Inc. All Rights Reserved.
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+package daisyutils
+
+import (
+	"os"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateTranslationSettings_EmbeddedSettingsMatchWorkflows(t *testing.T) {
+	assert.NoError(t, ValidateTranslationSettings("../../../../daisy_workflows"))
+}
+
+func TestValidateTranslationSettings_MissingWorkflow(t *testing.T) {
+	err := ValidateTranslationSettings(t.TempDir())
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "translation workflow for os")
+}
+
+func TestTranslationSettings_EmbeddedBYOLPairs(t *testing.T) {
+	settings, err := GetTranslationSettings("rhel-8")
+	assert.NoError(t, err)
+	assert.Equal(t, "rhel-8-byol", settings.BYOLPair)
+	assert.Equal(t, "x86_64", settings.Architecture)
+	assert.Equal(t, "2029-05-31", settings.EOLDate)
+
+	settings, err = GetTranslationSettings("windows-7-x86-byol")
+	assert.NoError(t, err)
+	assert.Equal(t, "i386", settings.Architecture)
+}
+
+func TestLoadTranslationRegistry_Override(t *testing.T) {
+	overridePath := path.Join(t.TempDir(), "settings.yaml")
+	assert.NoError(t, os.WriteFile(overridePath, []byte(`
+aliases:
+  centos-8: rocky-8
+os:
+  - os_flag: rocky-8
+    workflow: enterprise_linux/translate_rocky_8.wf.json
+    license: projects/custom/global/licenses/rocky-8
+    architecture: x86_64
+`), 0644))
+
+	r, err := loadTranslationRegistry(overridePath)
+	assert.NoError(t, err)
+	assert.Len(t, r.OS, 1)
+	assert.Equal(t, "projects/custom/global/licenses/rocky-8", r.OS[0].LicenseURI)
+	assert.Equal(t, "rocky-8", r.Aliases["centos-8"])
+}
+
+func TestLoadTranslationRegistry_InvalidOverrideFallsBackToEmbedded(t *testing.T) {
+	r, err := loadTranslationRegistry(path.Join(t.TempDir(), "missing.yaml"))
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to read translation settings")
+	assert.Len(t, r.OS, len(registry.OS))
+}
+
+func TestParseTranslationRegistry_Errors(t *testing.T) {
+	const valid = `
+  - os_flag: rhel-8
+    workflow: enterprise_linux/translate_rhel_8_licensed.wf.json
+    license: projects/rhel-cloud/global/licenses/rhel-8-server
+    architecture: x86_64
+`
+	for _, tt := range []struct {
+		name, content, expectedError string
+	}{
+		{"empty", "aliases: {}", "no operating systems are defined"},
+		{"unknown field", "os:" + valid + "    license_uri: x\n", "field license_uri not found"},
+		{"duplicate", "os:" + valid + valid, "os `rhel-8` is defined more than once"},
+		{"missing workflow", "os:\n  - os_flag: rhel-8\n    license: l\n    architecture: x86_64\n",
+			"os `rhel-8`: workflow is required"},
+		{"unparseable flag", "os:\n  - os_flag: rhel\n    workflow: w\n    license: l\n    architecture: x86_64\n",
+			"os `rhel`: expected pattern of `distro-version`"},
+		{"architecture", "os:\n  - os_flag: rhel-8\n    workflow: w\n    license: l\n    architecture: sparc\n",
+			"architecture `sparc` is not supported"},
+		{"eol date", "os:" + valid + "    eol_date: May 2029\n", "eol_date `May 2029` is not formatted as YYYY-MM-DD"},
+		{"missing byol pair", "os:" + valid + "    byol_pair: rhel-8-byol\n", "byol_pair `rhel-8-byol` is not defined"},
+		{"one-sided byol pair", "os:" + valid + "    byol_pair: rhel-8-byol\n" +
+			"  - os_flag: rhel-8-byol\n    workflow: w\n    license: l\n    architecture: x86_64\n",
+			"byol_pair of `rhel-8-byol` should be `rhel-8`. Actual: ``"},
+		{"alias shadows os", "aliases:\n  rhel-8: rhel-8\nos:" + valid, "alias `rhel-8` is also defined as an os"},
+		{"alias target", "aliases:\n  rhel-eight: rhel-9\nos:" + valid, "alias `rhel-eight` refers to `rhel-9`, which is not defined"},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parseTranslationRegistry([]byte(tt.content))
+			if assert.Error(t, err) {
+				assert.Contains(t, err.Error(), tt.expectedError)
+			}
+		})
+	}
+}
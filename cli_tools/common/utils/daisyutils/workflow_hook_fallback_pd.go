@@ -51,20 +51,44 @@ func (f *FallbackToPDStandard) PostRunHook(err error) (wantRetry bool, wrapped e
 }
 
 func useStandardDisks(workflow *daisy.Workflow) {
+	setDiskTypes(workflow, func(string) string {
+		return "pd-standard"
+	})
+}
+
+// setDiskTypes updates the type of the disks created by workflow, using
+// newType to map from the disk's current type to its new type.
+func setDiskTypes(workflow *daisy.Workflow, newType func(diskType string) string) {
+	for _, diskType := range diskTypeFields(workflow) {
+		*diskType = newType(*diskType)
+	}
+}
+
+// diskTypeFields returns the fields that hold the type of the disks created by workflow.
+func diskTypeFields(workflow *daisy.Workflow) []*string {
+	var fields []*string
 	workflow.IterateWorkflowSteps(func(step *daisy.Step) {
 		if step.CreateDisks != nil {
 			for _, disk := range *step.CreateDisks {
-				disk.Disk.Type = "pd-standard"
+				fields = append(fields, &disk.Disk.Type)
 			}
 		}
 		if step.CreateInstances != nil {
 			for _, instance := range (*step.CreateInstances).Instances {
 				for _, disk := range instance.Disks {
 					if disk.InitializeParams != nil {
-						disk.InitializeParams.DiskType = "pd-standard"
+						fields = append(fields, &disk.InitializeParams.DiskType)
+					}
+				}
+			}
+			for _, instance := range (*step.CreateInstances).InstancesBeta {
+				for _, disk := range instance.Disks {
+					if disk.InitializeParams != nil {
+						fields = append(fields, &disk.InitializeParams.DiskType)
 					}
 				}
 			}
 		}
 	})
+	return fields
 }
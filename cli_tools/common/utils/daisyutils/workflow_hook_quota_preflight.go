@@ -0,0 +1,218 @@
+//  Copyright 2026 Google Inc. All Rights Reserved.
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+package daisyutils
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	daisy "github.com/GoogleCloudPlatform/compute-daisy"
+
+	"github.com/GoogleCloudPlatform/compute-image-import/cli_tools/common/utils/logging"
+	"github.com/GoogleCloudPlatform/compute-image-import/cli_tools/common/utils/paramhelper"
+)
+
+// diskQuotaMetrics maps disk types to the regional quota that limits their total size.
+// Workers only use hyperdisk-balanced on machine series that require Hyperdisk; the
+// sizes of other Hyperdisk types aren't checked.
+var diskQuotaMetrics = map[string]string{
+	"pd-standard":        "DISKS_TOTAL_GB",
+	"pd-balanced":        "SSD_TOTAL_GB",
+	"pd-ssd":             "SSD_TOTAL_GB",
+	"pd-extreme":         "SSD_TOTAL_GB",
+	"hyperdisk-balanced": "HDB_TOTAL_GB",
+}
+
+// QuotaBudget combines the resource estimates of workflows that run in parallel, such
+// as the disk imports of an OVF import, so that their total usage is compared against
+// quotas. Quotas are read once, before the first workflow starts, so that resources
+// created by workflows that are already running aren't counted twice.
+type QuotaBudget struct {
+	mu       sync.Mutex
+	reserved map[*QuotaPreflightHook]ResourceEstimate
+	quotas   map[string]float64
+}
+
+// NewQuotaBudget creates an empty QuotaBudget.
+func NewQuotaBudget() *QuotaBudget {
+	return &QuotaBudget{reserved: map[*QuotaPreflightHook]ResourceEstimate{}}
+}
+
+// reserve records the estimate of hook's workflow, replacing the estimate from a previous
+// run, and returns the total estimate of all workflows, along with the available quotas.
+func (b *QuotaBudget) reserve(hook *QuotaPreflightHook, estimate ResourceEstimate,
+	readQuotas func() (map[string]float64, error)) (ResourceEstimate, map[string]float64, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.quotas == nil {
+		quotas, err := readQuotas()
+		if err != nil {
+			return ResourceEstimate{}, nil, err
+		}
+		b.quotas = quotas
+	}
+	b.reserved[hook] = estimate
+	total := ResourceEstimate{}
+	for _, reserved := range b.reserved {
+		total = total.Add(reserved)
+	}
+	return total, b.quotas, nil
+}
+
+// release removes the estimate of hook's workflow, after the workflow has finished and
+// deleted its workers. When no workflow has a reservation, quotas are read again by the
+// next workflow, so that they include the images created by the finished workflows.
+func (b *QuotaBudget) release(hook *QuotaPreflightHook) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	delete(b.reserved, hook)
+	if len(b.reserved) == 0 {
+		b.quotas = nil
+	}
+}
+
+// QuotaPreflightHook estimates the resources that a workflow will create, after all
+// other hooks have modified it, and compares the estimate against the quotas that are
+// available in the workflow's region and project. Workflows create and delete resources
+// as they run, so the estimate is an upper bound; when it exceeds a quota, a warning is
+// logged and the workflow is started anyway. Quotas are read with the compute client
+// from EnvironmentSettings.WorkflowClients; without one, the check is skipped.
+type QuotaPreflightHook struct {
+	logger logging.Logger
+	env    EnvironmentSettings
+
+	// budget is shared by workflows that run in parallel. When nil, only this
+	// workflow's resources are compared against quotas.
+	budget *QuotaBudget
+}
+
+// PreRunHook compares the workflow's resource estimate against quotas.
+func (h *QuotaPreflightHook) PreRunHook(wf *daisy.Workflow) error {
+	if h.env.ComputeClient == nil {
+		return nil
+	}
+	estimate := EstimateResources(wf)
+	if estimate.IsEmpty() {
+		return nil
+	}
+	h.logger.Debug(fmt.Sprintf("Resource estimate for workflow %q: %s", wf.Name, estimate))
+
+	budget := h.budget
+	if budget == nil {
+		budget = NewQuotaBudget()
+	}
+	total, quotas, err := budget.reserve(h, estimate, func() (map[string]float64, error) {
+		return h.readAvailableQuotas(wf)
+	})
+	if err != nil {
+		h.logger.Debug(fmt.Sprintf("Skipping quota preflight, since quotas can't be read: %v", err))
+		return nil
+	}
+	if total.Instances > estimate.Instances {
+		h.logger.Debug(fmt.Sprintf("Combined resource estimate of parallel workflows: %s", total))
+	}
+	h.compare(total, quotas, wf)
+	return nil
+}
+
+// release removes the workflow's estimate from the shared budget. It's called by the
+// worker when the workflow won't be run again.
+func (h *QuotaPreflightHook) release() {
+	if h.budget != nil {
+		h.budget.release(h)
+	}
+}
+
+// compare logs a warning for each quota that the total estimate exceeds.
+func (h *QuotaPreflightHook) compare(total ResourceEstimate, quotas map[string]float64, wf *daisy.Workflow) {
+	var exceeded []string
+	check := func(metric string, required int64) bool {
+		available, found := quotas[metric]
+		if !found || float64(required) <= available {
+			return false
+		}
+		exceeded = append(exceeded, fmt.Sprintf("%s: requires %d, %v available", metric, required, available))
+		return true
+	}
+
+	var generalCPUs int64
+	for _, series := range sortedMapKeys(total.CPUsBySeries) {
+		cpus := total.CPUsBySeries[series]
+		seriesMetric := strings.ToUpper(series) + "_CPUS"
+		if _, found := quotas[seriesMetric]; !found {
+			generalCPUs += cpus
+			continue
+		}
+		if available := quotas[seriesMetric]; float64(cpus) > available {
+			h.logger.User(fmt.Sprintf("Warning: Workers require %d %s CPUs, but only %v are available in quota %s. "+
+				"Another machine series will be used if workers can't be created.",
+				cpus, strings.ToUpper(series), available, seriesMetric))
+		}
+	}
+	check("CPUS", generalCPUs)
+	check("INSTANCES", total.Instances)
+	diskGB := map[string]int64{}
+	for diskType, size := range total.DiskGBByType {
+		if metric, found := diskQuotaMetrics[diskType]; found {
+			diskGB[metric] += size
+		}
+	}
+	for _, metric := range sortedMapKeys(diskGB) {
+		check(metric, diskGB[metric])
+	}
+	check("IMAGES", total.Images)
+
+	if len(exceeded) == 0 {
+		return
+	}
+	h.logger.User(fmt.Sprintf("Warning: The %s may exceed quotas in project %s: %s. "+
+		"If the %s fails, request a quota increase, or free resources that use the quotas, "+
+		"and then run the %s again.", h.toolName(), wf.Project, strings.Join(exceeded, "; "),
+		h.toolName(), h.toolName()))
+}
+
+func (h *QuotaPreflightHook) toolName() string {
+	if h.env.Tool.HumanReadableName != "" {
+		return h.env.Tool.HumanReadableName
+	}
+	return "tool"
+}
+
+// readAvailableQuotas returns the unused amount of each regional quota in the
+// workflow's region, and of each project quota.
+func (h *QuotaPreflightHook) readAvailableQuotas(wf *daisy.Workflow) (map[string]float64, error) {
+	client := h.env.ComputeClient
+	region, err := paramhelper.GetRegion(wf.Zone)
+	if err != nil {
+		return nil, err
+	}
+	regionResponse, err := client.GetRegion(wf.Project, region)
+	if err != nil {
+		return nil, err
+	}
+	quotas := map[string]float64{}
+	for _, quota := range regionResponse.Quotas {
+		quotas[quota.Metric] = quota.Limit - quota.Usage
+	}
+	if project, err := client.GetProject(wf.Project); err == nil && project != nil {
+		for _, quota := range project.Quotas {
+			if quota.Metric == "IMAGES" {
+				quotas[quota.Metric] = quota.Limit - quota.Usage
+			}
+		}
+	}
+	return quotas, nil
+}
@@ -0,0 +1,198 @@
+//  Copyright 2026 Google Inc. All Rights Reserved.
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+package daisyutils
+
+import (
+	"errors"
+	"testing"
+
+	daisy "github.com/GoogleCloudPlatform/compute-daisy"
+	daisyCompute "github.com/GoogleCloudPlatform/compute-daisy/compute"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/api/compute/v1"
+
+	"github.com/GoogleCloudPlatform/compute-image-import/cli_tools/common/utils/logging"
+	"github.com/GoogleCloudPlatform/compute-image-import/cli_tools/mocks"
+)
+
+func Test_QuotaPreflightHook_PreRunHook_PassesWhenQuotasAreAvailable(t *testing.T) {
+	client := createClientWithQuotas(t, []*compute.Quota{
+		{Metric: "CPUS", Limit: 24, Usage: 8},
+		{Metric: "N2_CPUS", Limit: 8},
+		{Metric: "INSTANCES", Limit: 10},
+		{Metric: "SSD_TOTAL_GB", Limit: 500},
+		{Metric: "DISKS_TOTAL_GB", Limit: 4096, Usage: 1000},
+	}, []*compute.Quota{{Metric: "IMAGES", Limit: 100, Usage: 10}})
+	hook := &QuotaPreflightHook{logger: createLoggerExpectingWarnings(t), env: envWithClient(client)}
+
+	assert.NoError(t, hook.PreRunHook(createWorkflowInRegion()))
+}
+
+func Test_QuotaPreflightHook_PreRunHook_WarnsWhenQuotaMayBeExceeded(t *testing.T) {
+	client := createClientWithQuotas(t, []*compute.Quota{
+		{Metric: "CPUS", Limit: 24, Usage: 22},
+		{Metric: "DISKS_TOTAL_GB", Limit: 4096, Usage: 4000},
+	}, []*compute.Quota{{Metric: "IMAGES", Limit: 100, Usage: 100}})
+	env := envWithClient(client)
+	env.Tool = Tool{HumanReadableName: "image import"}
+	hook := &QuotaPreflightHook{logger: createLoggerExpectingWarnings(t,
+		"Warning: The image import may exceed quotas in project p: CPUS: requires 12, 2 available; "+
+			"DISKS_TOTAL_GB: requires 220, 96 available; IMAGES: requires 1, 0 available. "+
+			"If the image import fails, request a quota increase, or free resources that use the quotas, "+
+			"and then run the image import again."), env: env}
+
+	assert.NoError(t, hook.PreRunHook(createWorkflowInRegion()))
+}
+
+func Test_QuotaPreflightHook_PreRunHook_ChecksHyperdiskQuota(t *testing.T) {
+	client := createClientWithQuotas(t, []*compute.Quota{
+		{Metric: "SSD_TOTAL_GB", Limit: 500},
+		{Metric: "HDB_TOTAL_GB", Limit: 100, Usage: 95},
+	}, nil)
+	wf := createWorkflowInRegion()
+	(*wf.Steps["cd"].CreateDisks)[0].Type = "hyperdisk-balanced"
+	hook := &QuotaPreflightHook{logger: createLoggerExpectingWarnings(t,
+		"Warning: The tool may exceed quotas in project p: HDB_TOTAL_GB: requires 10, 5 available. "+
+			"If the tool fails, request a quota increase, or free resources that use the quotas, "+
+			"and then run the tool again."), env: envWithClient(client)}
+
+	assert.NoError(t, hook.PreRunHook(wf))
+}
+
+func Test_QuotaPreflightHook_PreRunHook_WarnsForSeriesCPUQuota(t *testing.T) {
+	client := createClientWithQuotas(t, []*compute.Quota{
+		{Metric: "CPUS", Limit: 24},
+		{Metric: "N2_CPUS", Limit: 4},
+	}, nil)
+	hook := &QuotaPreflightHook{logger: createLoggerExpectingWarnings(t,
+		"Warning: Workers require 8 N2 CPUs, but only 4 are available in quota N2_CPUS. "+
+			"Another machine series will be used if workers can't be created."), env: envWithClient(client)}
+
+	assert.NoError(t, hook.PreRunHook(createWorkflowInRegion()))
+}
+
+func Test_QuotaPreflightHook_PreRunHook_CombinesParallelWorkflows(t *testing.T) {
+	budget := NewQuotaBudget()
+	quotas := []*compute.Quota{{Metric: "CPUS", Limit: 20}}
+	warning := "Warning: The tool may exceed quotas in project p: CPUS: requires 24, 20 available. " +
+		"If the tool fails, request a quota increase, or free resources that use the quotas, " +
+		"and then run the tool again."
+	env := envWithClient(createClientWithQuotas(t, quotas, nil))
+	first := &QuotaPreflightHook{logger: createLoggerExpectingWarnings(t, warning), env: env, budget: budget}
+	// The second workflow doesn't read quotas, since they include resources
+	// created by the first workflow.
+	second := &QuotaPreflightHook{logger: createLoggerExpectingWarnings(t, warning), env: env, budget: budget}
+
+	assert.NoError(t, first.PreRunHook(createWorkflowInRegion()))
+	assert.NoError(t, second.PreRunHook(createWorkflowInRegion()))
+	// A re-run replaces the workflow's previous estimate.
+	assert.NoError(t, first.PreRunHook(createWorkflowInRegion()))
+}
+
+func Test_QuotaPreflightHook_PreRunHook_ReleasesSequentialWorkflows(t *testing.T) {
+	budget := NewQuotaBudget()
+	quotas := []*compute.Quota{{Metric: "CPUS", Limit: 20}}
+
+	for i := 0; i < 3; i++ {
+		// Each workflow reads quotas again, since the previous workflow released its estimate.
+		hook := &QuotaPreflightHook{logger: createLoggerExpectingWarnings(t),
+			env: envWithClient(createClientWithQuotas(t, quotas, nil)), budget: budget}
+		assert.NoError(t, hook.PreRunHook(createWorkflowInRegion()))
+		hook.release()
+	}
+	assert.Empty(t, budget.reserved)
+}
+
+func Test_QuotaPreflightHook_Release_KeepsQuotasWhileWorkflowsAreRunning(t *testing.T) {
+	budget := NewQuotaBudget()
+	quotas := []*compute.Quota{{Metric: "CPUS", Limit: 20}}
+	first := &QuotaPreflightHook{logger: createLoggerExpectingWarnings(t),
+		env: envWithClient(createClientWithQuotas(t, quotas, nil)), budget: budget}
+	second := &QuotaPreflightHook{logger: createLoggerExpectingWarnings(t),
+		env: envWithClient(createClientWithQuotas(t, quotas, nil)), budget: budget}
+	// The third workflow runs in parallel with the second, so it uses the same quotas,
+	// and only their estimates are combined.
+	third := &QuotaPreflightHook{logger: createLoggerExpectingWarnings(t,
+		"Warning: The tool may exceed quotas in project p: CPUS: requires 24, 20 available. "+
+			"If the tool fails, request a quota increase, or free resources that use the quotas, "+
+			"and then run the tool again."),
+		env: envWithClient(mocks.NewMockClient(gomock.NewController(t))), budget: budget}
+
+	assert.NoError(t, first.PreRunHook(createWorkflowInRegion()))
+	first.release()
+	assert.NoError(t, second.PreRunHook(createWorkflowInRegion()))
+	assert.NoError(t, third.PreRunHook(createWorkflowInRegion()))
+}
+
+func Test_QuotaPreflightHook_PreRunHook_SkipsWhenQuotasCantBeRead(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	mockComputeClient := mocks.NewMockClient(mockCtrl)
+	mockComputeClient.EXPECT().GetRegion("p", "us-west1").Return(nil, errors.New("forbidden"))
+	hook := &QuotaPreflightHook{logger: createLoggerExpectingWarnings(t), env: envWithClient(mockComputeClient)}
+
+	assert.NoError(t, hook.PreRunHook(createWorkflowInRegion()))
+}
+
+func Test_QuotaPreflightHook_PreRunHook_SkipsWithoutComputeClient(t *testing.T) {
+	hook := &QuotaPreflightHook{logger: createLoggerExpectingWarnings(t)}
+	wf := createWorkflowInRegion()
+
+	assert.NoError(t, hook.PreRunHook(wf))
+	assert.Nil(t, wf.ComputeClient, "The workflow's client shouldn't be replaced.")
+}
+
+func Test_QuotaPreflightHook_PreRunHook_SkipsWorkflowsWithoutResources(t *testing.T) {
+	hook := &QuotaPreflightHook{logger: createLoggerExpectingWarnings(t),
+		env: envWithClient(mocks.NewMockClient(gomock.NewController(t)))}
+
+	assert.NoError(t, hook.PreRunHook(daisy.New()))
+}
+
+// createClientWithQuotas returns a compute client that returns regionQuotas for us-west1,
+// and projectQuotas for project p.
+func createClientWithQuotas(t *testing.T, regionQuotas, projectQuotas []*compute.Quota) daisyCompute.Client {
+	mockCtrl := gomock.NewController(t)
+	mockComputeClient := mocks.NewMockClient(mockCtrl)
+	mockComputeClient.EXPECT().GetRegion("p", "us-west1").Return(&compute.Region{Quotas: regionQuotas}, nil)
+	mockComputeClient.EXPECT().GetProject("p").Return(&compute.Project{Quotas: projectQuotas}, nil)
+	return mockComputeClient
+}
+
+// createWorkflowInRegion returns the workflow from createWorkflowForEstimate, in project p
+// and zone us-west1-a.
+func createWorkflowInRegion() *daisy.Workflow {
+	wf := createWorkflowForEstimate()
+	wf.Project = "p"
+	wf.Zone = "us-west1-a"
+	return wf
+}
+
+// createLoggerExpectingWarnings returns a logger that fails the test unless it receives
+// exactly the warnings in expected.
+func createLoggerExpectingWarnings(t *testing.T, expected ...string) logging.Logger {
+	mockCtrl := gomock.NewController(t)
+	mockLogger := mocks.NewMockLogger(mockCtrl)
+	mockLogger.EXPECT().Debug(gomock.Any()).AnyTimes()
+	for _, warning := range expected {
+		mockLogger.EXPECT().User(warning)
+	}
+	return mockLogger
+}
+
+func envWithClient(client daisyCompute.Client) EnvironmentSettings {
+	return EnvironmentSettings{WorkflowClients: WorkflowClients{ComputeClient: client}}
+}
@@ -16,6 +16,8 @@ package daisyutils
 
 import (
 	"fmt"
+	"path"
+	"regexp"
 	"strings"
 
 	daisy "github.com/GoogleCloudPlatform/compute-daisy"
@@ -23,48 +25,144 @@ import (
 	"github.com/GoogleCloudPlatform/compute-image-import/cli_tools/common/utils/logging"
 )
 
-// UpdateMachineTypesHook updates the workflow to use the machine series specified as primary.
-// If the workflow fails due to the usage quota then it falls back to secondary machine series.
-// See cli_tools/common/utils/param/machine_series_detector.go for details.
+var (
+	// machineSeriesCapacityErrorRegex matches errors that occur when a zone can't provide
+	// instances of the requested machine series.
+	machineSeriesCapacityErrorRegex = regexp.MustCompile(
+		`ZONE_RESOURCE_POOL_EXHAUSTED|does not have enough resources available`)
+
+	// machineSeriesUnsupportedErrorRegex matches errors that occur when a machine series
+	// isn't available, or isn't compatible with the disks that are attached to its instances.
+	machineSeriesUnsupportedErrorRegex = regexp.MustCompile(
+		`(?i)disk type .*not supported|not supported for disk type|does not support .*disks|features are not compatible|Invalid value for field 'resource\.machineType'`)
+
+	// hyperdiskMachineSeries are the machine series whose instances only support Hyperdisk.
+	// See https://cloud.google.com/compute/docs/disks/hyperdisks#machine-type-support
+	hyperdiskMachineSeries = map[string]bool{
+		"c3":  true,
+		"c3d": true,
+		"c4":  true,
+		"c4a": true,
+		"c4d": true,
+		"n4":  true,
+	}
+)
+
+// UpdateMachineTypesHook updates the workflow to use the first machine series in machineSeries.
+// If the workflow fails due to insufficient CPU quota, insufficient zonal capacity, or because
+// the series is not compatible with the workflow's disks, then it falls back to the next
+// machine series in the list. Disks are updated to use a type that's compatible with the
+// machine series. See cli_tools/common/utils/param/machine_series_detector.go for details.
 type UpdateMachineTypesHook struct {
-	logger                 logging.Logger
-	shouldFallback         bool
-	primaryMachineSeries   string
-	secondaryMachineSeries string
+	logger        logging.Logger
+	machineSeries []string
+	// current is the index of the machine series to use in the next run.
+	current int
+
+	// resets counts the calls to reset. Each reset allows every machine series to be tried again.
+	resets int
+	// originalDiskTypes holds the disk types of the workflow's first run, keyed by their field,
+	// for workflow providers that return the same workflow on each run.
+	originalDiskTypes map[*string]string
+	// restoreDiskTypes is set by reset, so that the next run starts from originalDiskTypes.
+	restoreDiskTypes bool
 }
 
-// PreRunHook modifies the workflow to use machine series specified as primary or
-// falls back to secondary machine series when needed.
+// PreRunHook modifies the workflow to use the current machine series, and
+// disk types that are compatible with it.
 func (f *UpdateMachineTypesHook) PreRunHook(wf *daisy.Workflow) error {
-	if f.shouldFallback && f.secondaryMachineSeries != "" {
-		f.updateWorkflowMachineSeries(wf, f.secondaryMachineSeries)
-		return nil
+	series := f.machineSeries[f.current]
+	f.updateWorkflowMachineSeries(wf, series)
+	if f.originalDiskTypes == nil {
+		f.originalDiskTypes = map[*string]string{}
 	}
-
-	f.updateWorkflowMachineSeries(wf, f.primaryMachineSeries)
+	for _, diskType := range diskTypeFields(wf) {
+		if original, found := f.originalDiskTypes[diskType]; !found {
+			f.originalDiskTypes[diskType] = *diskType
+		} else if f.restoreDiskTypes {
+			*diskType = original
+		}
+		*diskType = compatibleDiskType(series, *diskType)
+	}
+	f.restoreDiskTypes = false
 	return nil
 }
 
-// PostRunHook inspects the workflow error to see if it's related to an insufficient primary machine series CPUs quota.
-// If so, it requests a retry that will re-run the workflow using the secondary machine series.
+// reset starts the machine series list again, with the workflow's original disk types.
+// It's called by ZoneFailoverHook when the workflow moves to another zone.
+func (f *UpdateMachineTypesHook) reset() {
+	f.current = 0
+	f.resets++
+	f.restoreDiskTypes = true
+}
+
+// PostRunHook inspects the workflow error to see if it's related to the current machine series.
+// If so, it requests a retry that will re-run the workflow using the next machine series.
 func (f *UpdateMachineTypesHook) PostRunHook(err error) (wantRetry bool, wrapped error) {
-	if f.secondaryMachineSeries == "" {
-		// We cannot fall back if second machine series is not set.
+	if !f.hasNextSeries() {
+		// We cannot fall back if there isn't a next machine series.
 		return false, err
 	}
 
-	if f.shouldFallback {
-		// A fallback has already occurred; don't request retry.
-		return false, err
-	} else if err != nil && strings.Contains(err.Error(), fmt.Sprintf("%s_CPUS", strings.ToUpper(f.primaryMachineSeries))) {
-		msg := fmt.Sprintf("Workflow failed with an insufficient %s CPUs quota. Requesting retry with %s CPUs. See %s for details.",
-			f.primaryMachineSeries,
-			f.secondaryMachineSeries,
+	if reason := f.fallbackReason(err); reason != "" {
+		msg := fmt.Sprintf("Workflow failed with %s. Requesting retry with %s. See %s for details.",
+			reason,
+			f.machineSeries[f.current+1],
 			"https://cloud.google.com/compute/docs/troubleshooting/troubleshooting-import-export-images")
 		f.logger.Debug(msg)
-		f.shouldFallback = true
+		f.current++
+		return true, err
+	}
+	return false, err
+}
+
+// hasNextSeries returns whether there's a machine series to fall back to.
+func (f *UpdateMachineTypesHook) hasNextSeries() bool {
+	return f.current+1 < len(f.machineSeries)
+}
+
+// maxRetries allows each machine series to be tried once in each zone.
+func (f *UpdateMachineTypesHook) maxRetries() int {
+	return (len(f.machineSeries) - 1) * (f.resets + 1)
+}
+
+// fallbackReason returns a description of why err requires falling back to the next
+// machine series, or an empty string if it doesn't.
+func (f *UpdateMachineTypesHook) fallbackReason(err error) string {
+	if err == nil {
+		return ""
+	}
+	series := f.machineSeries[f.current]
+	switch {
+	case strings.Contains(err.Error(), fmt.Sprintf("%s_CPUS", strings.ToUpper(series))):
+		return fmt.Sprintf("an insufficient %s CPUs quota", series)
+	case machineSeriesCapacityErrorRegex.MatchString(err.Error()):
+		return fmt.Sprintf("insufficient %s capacity", series)
+	case machineSeriesUnsupportedErrorRegex.MatchString(err.Error()):
+		return fmt.Sprintf("%s not supported by the workflow", series)
+	}
+	return ""
+}
+
+// compatibleDiskType returns a disk type that can be attached to instances of machineSeries.
+// diskType is returned if it's already compatible. An empty diskType is pd-standard,
+// which daisy uses by default.
+func compatibleDiskType(machineSeries, diskType string) string {
+	if diskType == "" {
+		if hyperdiskMachineSeries[machineSeries] {
+			return "hyperdisk-balanced"
+		}
+		return diskType
+	}
+	base := path.Base(diskType)
+	isHyperdisk := strings.HasPrefix(base, "hyperdisk-")
+	if hyperdiskMachineSeries[machineSeries] && !isHyperdisk {
+		return strings.TrimSuffix(diskType, base) + "hyperdisk-balanced"
+	}
+	if !hyperdiskMachineSeries[machineSeries] && isHyperdisk {
+		return strings.TrimSuffix(diskType, base) + "pd-balanced"
 	}
-	return f.shouldFallback, err
+	return diskType
 }
 
 func (f *UpdateMachineTypesHook) updateWorkflowMachineSeries(wf *daisy.Workflow, newSeries string) {
@@ -31,55 +31,82 @@ var errNotN2CpusQuota = errors.New("failed to start workflow")
 
 func Test_UpdateMachineTypesHook_PostRunHook_WhenQuotaErrorAndSecondaryIsSpecified_ThenRetry(t *testing.T) {
 	hook := UpdateMachineTypesHook{
-		logger:                 logging.NewToolLogger("test"),
-		primaryMachineSeries:   "n2",
-		secondaryMachineSeries: "n1",
+		logger:        logging.NewToolLogger("test"),
+		machineSeries: []string{"n2", "n1"},
 	}
 	wantRetry, wrapped := hook.PostRunHook(errN2CpusQuota)
 	assert.Equal(t, errN2CpusQuota, wrapped)
 	assert.True(t, wantRetry)
-	assert.True(t, hook.shouldFallback)
+	assert.Equal(t, 1, hook.current)
 }
 
 func Test_UpdateMachineTypesHook_PostRunHook_WhenQuotaErrorAndSecondaryIsNotSpecified_ThenNoRetry(t *testing.T) {
 	hook := UpdateMachineTypesHook{
-		logger:               logging.NewToolLogger("test"),
-		primaryMachineSeries: "n2",
+		logger:        logging.NewToolLogger("test"),
+		machineSeries: []string{"n2"},
 	}
 	wantRetry, wrapped := hook.PostRunHook(errN2CpusQuota)
 	assert.Equal(t, errN2CpusQuota, wrapped)
 	assert.False(t, wantRetry)
-	assert.False(t, hook.shouldFallback)
+	assert.Equal(t, 0, hook.current)
 }
 
 func Test_UpdateMachineTypesHook_PostRunHook_WhenNotQuotaError_ThenNoRetry(t *testing.T) {
 	hook := UpdateMachineTypesHook{
-		shouldFallback:         true,
-		logger:                 logging.NewToolLogger("test"),
-		primaryMachineSeries:   "n2",
-		secondaryMachineSeries: "n1",
+		logger:        logging.NewToolLogger("test"),
+		machineSeries: []string{"n2", "n1"},
 	}
 	wantRetry, wrapped := hook.PostRunHook(errNotN2CpusQuota)
 	assert.Equal(t, errNotN2CpusQuota, wrapped)
 	assert.False(t, wantRetry)
 }
 
-func Test_UpdateMachineTypesHook_PostRunHook_OnlyFallsBackOnce(t *testing.T) {
+func Test_UpdateMachineTypesHook_PostRunHook_OnlyFallsBackOncePerSeries(t *testing.T) {
 	hook := UpdateMachineTypesHook{
-		shouldFallback:         true,
-		logger:                 logging.NewToolLogger("test"),
-		primaryMachineSeries:   "n2",
-		secondaryMachineSeries: "n1",
+		current:       1,
+		logger:        logging.NewToolLogger("test"),
+		machineSeries: []string{"n2", "n1"},
 	}
 	wantRetry, wrapped := hook.PostRunHook(errN2CpusQuota)
 	assert.Equal(t, errN2CpusQuota, wrapped)
 	assert.False(t, wantRetry)
 }
 
+func Test_UpdateMachineTypesHook_PostRunHook_WorksThroughChain(t *testing.T) {
+	hook := UpdateMachineTypesHook{
+		logger:        logging.NewToolLogger("test"),
+		machineSeries: []string{"c3", "n2", "n1", "e2"},
+	}
+	for i, err := range []error{
+		errors.New("Quota 'C3_CPUS' exceeded. Limit: 0.0 in region us-central1."),
+		errors.New("ZONE_RESOURCE_POOL_EXHAUSTED: The zone does not have enough resources available to fulfill the request."),
+		errors.New("[n1-standard-4] features are not compatible for creating instance."),
+	} {
+		wantRetry, wrapped := hook.PostRunHook(err)
+		assert.Equal(t, err, wrapped)
+		assert.True(t, wantRetry)
+		assert.Equal(t, i+1, hook.current)
+	}
+	wantRetry, _ := hook.PostRunHook(errors.New("ZONE_RESOURCE_POOL_EXHAUSTED"))
+	assert.False(t, wantRetry)
+	assert.Equal(t, 3, hook.current)
+}
+
+func Test_UpdateMachineTypesHook_PostRunHook_WhenQuotaErrorForOtherSeries_ThenNoRetry(t *testing.T) {
+	hook := UpdateMachineTypesHook{
+		current:       1,
+		logger:        logging.NewToolLogger("test"),
+		machineSeries: []string{"n2", "n1", "e2"},
+	}
+	wantRetry, _ := hook.PostRunHook(errN2CpusQuota)
+	assert.False(t, wantRetry)
+	assert.Equal(t, 1, hook.current)
+}
+
 func Test_UpdateMachineTypesHook_PreRunHook_WhenFirstRun_UpdatesWorkflowMachineSeriesToPrimary(t *testing.T) {
 	hook := UpdateMachineTypesHook{
-		logger:               logging.NewToolLogger("test"),
-		primaryMachineSeries: "n2",
+		logger:        logging.NewToolLogger("test"),
+		machineSeries: []string{"n2"},
 	}
 
 	wf := createUpdateMachineTypesTestWorkflow()
@@ -87,14 +114,14 @@ func Test_UpdateMachineTypesHook_PreRunHook_WhenFirstRun_UpdatesWorkflowMachineS
 
 	assert.Equal(t, "n2-standard-2", (*wf.Steps["ci"].CreateInstances).Instances[0].MachineType)
 	assert.Equal(t, "n2-standard-2", (*wf.Steps["ci"].CreateInstances).InstancesBeta[0].MachineType)
+	assert.Equal(t, "pd-ssd", (*wf.Steps["cd"].CreateDisks)[0].Type)
 }
 
 func Test_UpdateMachineTypesHook_PreRunHook_WhenShouldFallback_UpdatesWorkflowMachineSeriesToSecondary(t *testing.T) {
 	hook := UpdateMachineTypesHook{
-		logger:                 logging.NewToolLogger("test"),
-		primaryMachineSeries:   "n2",
-		secondaryMachineSeries: "n1",
-		shouldFallback:         true,
+		logger:        logging.NewToolLogger("test"),
+		machineSeries: []string{"n2", "n1"},
+		current:       1,
 	}
 
 	wf := createUpdateMachineTypesTestWorkflow()
@@ -104,15 +131,82 @@ func Test_UpdateMachineTypesHook_PreRunHook_WhenShouldFallback_UpdatesWorkflowMa
 	assert.Equal(t, "n1-standard-2", (*wf.Steps["ci"].CreateInstances).InstancesBeta[0].MachineType)
 }
 
+func Test_UpdateMachineTypesHook_PreRunHook_PairsHyperdiskSeriesWithHyperdisk(t *testing.T) {
+	hook := UpdateMachineTypesHook{
+		logger:        logging.NewToolLogger("test"),
+		machineSeries: []string{"c3", "n2"},
+	}
+
+	wf := createUpdateMachineTypesTestWorkflow()
+	assert.NoError(t, hook.PreRunHook(wf))
+
+	assert.Equal(t, "c3-standard-2", (*wf.Steps["ci"].CreateInstances).Instances[0].MachineType)
+	assert.Equal(t, "hyperdisk-balanced", (*wf.Steps["cd"].CreateDisks)[0].Type)
+	assert.Equal(t, "hyperdisk-balanced",
+		(*wf.Steps["ci"].CreateInstances).Instances[0].Disks[0].InitializeParams.DiskType)
+	assert.Equal(t, "hyperdisk-balanced",
+		(*wf.Steps["ci"].CreateInstances).InstancesBeta[0].Disks[0].InitializeParams.DiskType)
+}
+
+func Test_UpdateMachineTypesHook_PreRunHook_PairsPersistentDiskSeriesWithPersistentDisk(t *testing.T) {
+	hook := UpdateMachineTypesHook{
+		logger:        logging.NewToolLogger("test"),
+		machineSeries: []string{"n4", "n2"},
+		current:       1,
+	}
+
+	wf := createUpdateMachineTypesTestWorkflow()
+	(*wf.Steps["cd"].CreateDisks)[0].Type = "hyperdisk-balanced"
+	assert.NoError(t, hook.PreRunHook(wf))
+
+	assert.Equal(t, "n2-standard-2", (*wf.Steps["ci"].CreateInstances).Instances[0].MachineType)
+	assert.Equal(t, "pd-balanced", (*wf.Steps["cd"].CreateDisks)[0].Type)
+	assert.Equal(t, "pd-ssd",
+		(*wf.Steps["ci"].CreateInstances).Instances[0].Disks[0].InitializeParams.DiskType)
+}
+
+func Test_compatibleDiskType(t *testing.T) {
+	for _, tt := range []struct {
+		series, diskType, expected string
+	}{
+		{"n2", "", ""},
+		{"n2", "pd-ssd", "pd-ssd"},
+		{"n2", "hyperdisk-balanced", "pd-balanced"},
+		{"n2", "zones/z/diskTypes/hyperdisk-extreme", "zones/z/diskTypes/pd-balanced"},
+		{"c3", "pd-ssd", "hyperdisk-balanced"},
+		{"c4", "", "hyperdisk-balanced"},
+		{"n4", "hyperdisk-throughput", "hyperdisk-throughput"},
+		{"n4", "zones/z/diskTypes/pd-standard", "zones/z/diskTypes/hyperdisk-balanced"},
+	} {
+		t.Run(tt.series+"/"+tt.diskType, func(t *testing.T) {
+			assert.Equal(t, tt.expected, compatibleDiskType(tt.series, tt.diskType))
+		})
+	}
+}
+
 func createUpdateMachineTypesTestWorkflow() *daisy.Workflow {
 	w := daisy.New()
 	w.Steps = map[string]*daisy.Step{
+		"cd": {
+			CreateDisks: &daisy.CreateDisks{
+				{
+					Disk: compute.Disk{
+						Type: "pd-ssd",
+					},
+				},
+			},
+		},
 		"ci": {
 			CreateInstances: &daisy.CreateInstances{
 				Instances: []*daisy.Instance{
 					{
 						Instance: compute.Instance{
 							MachineType: "e2-standard-2",
+							Disks: []*compute.AttachedDisk{{
+								InitializeParams: &compute.AttachedDiskInitializeParams{
+									DiskType: "pd-ssd",
+								},
+							}},
 						},
 					},
 				},
@@ -120,6 +214,11 @@ func createUpdateMachineTypesTestWorkflow() *daisy.Workflow {
 					{
 						Instance: computeBeta.Instance{
 							MachineType: "e2-standard-2",
+							Disks: []*computeBeta.AttachedDisk{{
+								InitializeParams: &computeBeta.AttachedDiskInitializeParams{
+									DiskType: "pd-ssd",
+								},
+							}},
 						},
 					},
 				},
@@ -56,7 +56,9 @@ func updateWorkflowClientsIfneeded(env EnvironmentSettings, wf *daisy.Workflow)
 	// It will need to cleaned up resources before terminating the clients.
 	ctx := context.Background()
 
-	if env.EndpointsOverride.Compute != "" {
+	if env.ComputeClient != nil {
+		wf.ComputeClient = env.ComputeClient
+	} else if env.EndpointsOverride.Compute != "" {
 		daisyComputeClient, err := param.CreateComputeClient(&ctx, env.OAuth, env.EndpointsOverride.Compute)
 		if err != nil {
 			return err
@@ -64,7 +66,9 @@ func updateWorkflowClientsIfneeded(env EnvironmentSettings, wf *daisy.Workflow)
 		wf.ComputeClient = daisyComputeClient
 	}
 
-	if env.EndpointsOverride.Storage != "" {
+	if env.StorageClient != nil {
+		wf.StorageClient = env.StorageClient
+	} else if env.EndpointsOverride.Storage != "" {
 		storageOptions := []option.ClientOption{option.WithEndpoint(env.EndpointsOverride.Storage)}
 		if env.OAuth != "" {
 			storageOptions = append(storageOptions, option.WithCredentialsFile(env.OAuth))
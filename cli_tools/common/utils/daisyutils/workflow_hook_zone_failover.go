@@ -0,0 +1,324 @@
+//  Copyright 2026 Google Inc. All Rights Reserved.
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+package daisyutils
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"sort"
+
+	daisy "github.com/GoogleCloudPlatform/compute-daisy"
+	daisyCompute "github.com/GoogleCloudPlatform/compute-daisy/compute"
+
+	"github.com/GoogleCloudPlatform/compute-image-import/cli_tools/common/utils/logging"
+	"github.com/GoogleCloudPlatform/compute-image-import/cli_tools/common/utils/paramhelper"
+	"github.com/GoogleCloudPlatform/compute-image-import/cli_tools/common/utils/storage"
+)
+
+// maxZoneFailovers is the most zones that a workflow is moved to.
+const maxZoneFailovers = 3
+
+var (
+	// zonalCapacityErrorRegex matches errors that are specific to the zone that a
+	// workflow runs in, and that may not occur in a different zone of the same region.
+	zonalCapacityErrorRegex = regexp.MustCompile(
+		`ZONE_RESOURCE_POOL_EXHAUSTED|does not have enough resources available|exceeded\. Limit: [0-9.]+ in zone`)
+
+	// zonalDiskURIRegex matches the URI of a zonal disk, with or without its project.
+	zonalDiskURIRegex = regexp.MustCompile(
+		`^(?:https://www\.googleapis\.com/compute/[^/]+/)?(?:projects/([^/]+)/)?zones/([^/]+)/disks/([^/]+)$`)
+
+	// diskNameRegex matches the name of a disk in the workflow's zone.
+	diskNameRegex = regexp.MustCompile(`^[a-z]([-a-z0-9]*[a-z0-9])?$`)
+)
+
+// zoneRetriever lists the zones that are up in a region.
+type zoneRetriever interface {
+	GetZonesInRegion(region string, project string) ([]string, error)
+}
+
+// ZoneFailoverHook detects if a workflow fails because its zone doesn't have
+// enough resources to run the workflow's workers. If so, it re-runs the workflow
+// in the next zone that is up in the same region.
+//
+// Failover stays within the region of the original zone so that images keep
+// their default storage location; a storage location specified by the user is
+// re-applied by ResourceLabeler on each run. Zonal disks that were created
+// outside of the workflow, and that the workflow refers to, are copied to the
+// new zone using a snapshot.
+//
+// When machineTypes is set, capacity errors are left to it while it has another
+// machine series to try, since that doesn't require copying disks; the workflow
+// moves to another zone once all machine series have been tried, and machineTypes
+// starts again from the first machine series in that zone.
+type ZoneFailoverHook struct {
+	logger        logging.Logger
+	zoneRetriever zoneRetriever
+	machineTypes  *UpdateMachineTypesHook
+
+	// wf is the most recently run workflow. Its compute client is used
+	// to find zones when a retriever wasn't provided.
+	wf             *daisy.Workflow
+	originalZone   string
+	failoverZone   string
+	candidateZones []string
+
+	// zoneChanged is set when a failover zone is selected, until the next run starts.
+	zoneChanged bool
+}
+
+// PreRunHook moves the workflow to the failover zone, if one was selected
+// after a previous run.
+func (h *ZoneFailoverHook) PreRunHook(wf *daisy.Workflow) error {
+	h.wf = wf
+	if h.originalZone == "" {
+		h.originalZone = wf.Zone
+	}
+	if h.failoverZone == "" {
+		return nil
+	}
+	if h.zoneChanged && h.machineTypes != nil {
+		// Runs before machineTypes' pre-run hook, so that the first series is used.
+		h.machineTypes.reset()
+	}
+	h.zoneChanged = false
+	wf.Zone = h.failoverZone
+	return copyZonalDisksToZone(wf, h.originalZone, h.failoverZone)
+}
+
+// PostRunHook inspects the workflow error to see if it's caused by a lack of
+// resources in the workflow's zone. If so, it requests a retry that will re-run
+// the workflow in the next zone that is up in the same region.
+func (h *ZoneFailoverHook) PostRunHook(err error) (wantRetry bool, wrapped error) {
+	if err == nil || !zonalCapacityErrorRegex.MatchString(err.Error()) {
+		return false, err
+	}
+	if h.machineTypes != nil && h.machineTypes.hasNextSeries() && h.machineTypes.fallbackReason(err) != "" {
+		return false, err
+	}
+	nextZone, zoneErr := h.nextZone()
+	if zoneErr != nil {
+		h.logger.Debug(fmt.Sprintf("Zone failover is not possible: %v", zoneErr))
+		return false, err
+	}
+	if nextZone == "" {
+		h.logger.Debug("Workflow failed due to insufficient zonal resources, and all zones in the region have been tried. error=" + err.Error())
+		return false, err
+	}
+	h.logger.User(fmt.Sprintf("Zone %s doesn't have enough resources to complete the request. Retrying in zone %s.",
+		h.currentZone(), nextZone))
+	h.failoverZone = nextZone
+	h.zoneChanged = true
+	return true, err
+}
+
+// maxRetries allows the workflow to be moved to each of the other zones in the
+// region, up to maxZoneFailovers.
+func (h *ZoneFailoverHook) maxRetries() int {
+	if len(h.candidateZones) <= 1 {
+		return 1
+	}
+	if len(h.candidateZones)-1 > maxZoneFailovers {
+		return maxZoneFailovers
+	}
+	return len(h.candidateZones) - 1
+}
+
+func (h *ZoneFailoverHook) currentZone() string {
+	if h.failoverZone != "" {
+		return h.failoverZone
+	}
+	return h.originalZone
+}
+
+// nextZone returns the zone after the current zone in the list of candidate zones.
+// An empty string is returned when all candidates have been tried.
+func (h *ZoneFailoverHook) nextZone() (string, error) {
+	if h.candidateZones == nil {
+		if err := h.populateCandidateZones(); err != nil {
+			return "", err
+		}
+	}
+	current := h.currentZone()
+	for i, zone := range h.candidateZones {
+		if zone == current {
+			if i+1 < len(h.candidateZones) {
+				return h.candidateZones[i+1], nil
+			}
+			return "", nil
+		}
+	}
+	return "", nil
+}
+
+// populateCandidateZones lists the zones in the original zone's region, starting with
+// the original zone, and followed by the other zones in the region in name order.
+func (h *ZoneFailoverHook) populateCandidateZones() error {
+	if h.wf == nil || h.originalZone == "" {
+		return fmt.Errorf("workflow zone is unknown")
+	}
+	region, err := paramhelper.GetRegion(h.originalZone)
+	if err != nil {
+		return err
+	}
+	retriever := h.zoneRetriever
+	if retriever == nil {
+		if h.wf.ComputeClient == nil {
+			return fmt.Errorf("compute client is not initialized")
+		}
+		retriever = storage.NewResourceLocationRetriever(nil, h.wf.ComputeClient)
+	}
+	zones, err := retriever.GetZonesInRegion(region, h.wf.Project)
+	if err != nil {
+		return err
+	}
+	h.candidateZones = []string{h.originalZone}
+	for _, zone := range zones {
+		if zone != h.originalZone {
+			h.candidateZones = append(h.candidateZones, zone)
+		}
+	}
+	return nil
+}
+
+// copyZonalDisksToZone updates wf so that zonal disks in fromZone that are referenced
+// by the workflow's variables, or attached to its instances, are copied to toZone
+// before the workflow's other steps run. Disks are referenced by URI, or by name when
+// attached to an instance and not created by the workflow. The copies are created by
+// the workflow, keep the type of the original disk, and are deleted when it finishes.
+func copyZonalDisksToZone(wf *daisy.Workflow, fromZone, toZone string) error {
+	createdDisks := map[string]bool{}
+	wf.IterateWorkflowSteps(func(step *daisy.Step) {
+		if step.CreateDisks != nil {
+			for _, disk := range *step.CreateDisks {
+				createdDisks[disk.Name] = true
+			}
+		}
+	})
+
+	copies := map[string]string{}
+	copyName := func(uri string, allowName bool) string {
+		project, name := wf.Project, ""
+		if match := zonalDiskURIRegex.FindStringSubmatch(uri); match != nil {
+			if match[2] != fromZone {
+				return ""
+			}
+			if match[1] != "" {
+				project = match[1]
+			}
+			name = match[3]
+		} else if allowName && diskNameRegex.MatchString(uri) && !createdDisks[uri] {
+			name = uri
+		} else {
+			return ""
+		}
+		uri = fmt.Sprintf("projects/%s/zones/%s/disks/%s", project, fromZone, name)
+		if _, found := copies[uri]; !found {
+			copies[uri] = GenerateValidDisksImagesName(fmt.Sprintf("%s-%s", name, toZone))
+		}
+		return copies[uri]
+	}
+
+	for key, v := range wf.Vars {
+		if name := copyName(v.Value, false); name != "" {
+			v.Value = name
+			wf.Vars[key] = v
+		}
+	}
+	wf.IterateWorkflowSteps(func(step *daisy.Step) {
+		if step.CreateInstances != nil {
+			for _, instance := range step.CreateInstances.Instances {
+				for _, disk := range instance.Disks {
+					if name := copyName(disk.Source, true); name != "" {
+						disk.Source = name
+					}
+				}
+			}
+			for _, instance := range step.CreateInstances.InstancesBeta {
+				for _, disk := range instance.Disks {
+					if name := copyName(disk.Source, true); name != "" {
+						disk.Source = name
+					}
+				}
+			}
+		}
+		if step.AttachDisks != nil {
+			for _, disk := range *step.AttachDisks {
+				if name := copyName(disk.Source, true); name != "" {
+					disk.Source = name
+				}
+			}
+		}
+	})
+	if len(copies) == 0 {
+		return nil
+	}
+
+	var rootSteps []string
+	for name := range wf.Steps {
+		if len(wf.Dependencies[name]) == 0 {
+			rootSteps = append(rootSteps, name)
+		}
+	}
+	snapshotStep, err := NewStep(wf, "zone-failover-snapshot-disks")
+	if err != nil {
+		return err
+	}
+	copyStep, err := NewStep(wf, "zone-failover-copy-disks", snapshotStep)
+	if err != nil {
+		return err
+	}
+	snapshots := daisy.CreateSnapshots{}
+	disks := daisy.CreateDisks{}
+	uris := make([]string, 0, len(copies))
+	for uri := range copies {
+		uris = append(uris, uri)
+	}
+	sort.Strings(uris)
+	for _, uri := range uris {
+		snapshot := &daisy.Snapshot{}
+		snapshot.Name = copies[uri]
+		snapshot.SourceDisk = uri
+		snapshots = append(snapshots, snapshot)
+
+		disk := &daisy.Disk{}
+		disk.Name = copies[uri]
+		disk.SourceSnapshot = copies[uri]
+		disk.Type = sourceDiskType(wf.ComputeClient, uri)
+		disks = append(disks, disk)
+	}
+	snapshotStep.CreateSnapshots = &snapshots
+	copyStep.CreateDisks = &disks
+	for _, name := range rootSteps {
+		wf.Dependencies[name] = append(wf.Dependencies[name], "zone-failover-copy-disks")
+	}
+	return nil
+}
+
+// sourceDiskType returns the name of the type of the disk at uri, so that it can be used
+// in another zone. An empty string is returned if the disk can't be read, in which case
+// the copy is created with daisy's default disk type.
+func sourceDiskType(client daisyCompute.Client, uri string) string {
+	match := zonalDiskURIRegex.FindStringSubmatch(uri)
+	if client == nil || match == nil {
+		return ""
+	}
+	disk, err := client.GetDisk(match[1], match[2], match[3])
+	if err != nil || disk.Type == "" {
+		return ""
+	}
+	return path.Base(disk.Type)
+}
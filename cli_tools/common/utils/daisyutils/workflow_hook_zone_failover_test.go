@@ -0,0 +1,320 @@
+//  Copyright 2026 Google Inc. All Rights Reserved.
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+package daisyutils
+
+import (
+	"errors"
+	"testing"
+
+	daisy "github.com/GoogleCloudPlatform/compute-daisy"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/api/compute/v1"
+
+	"github.com/GoogleCloudPlatform/compute-image-import/cli_tools/common/utils/logging"
+	"github.com/GoogleCloudPlatform/compute-image-import/cli_tools/mocks"
+)
+
+var errZoneExhausted = errors.New("ZONE_RESOURCE_POOL_EXHAUSTED: The zone 'projects/p/zones/us-west1-a' does not have enough resources available to fulfill the request")
+
+func Test_ZoneFailoverHook_PostRunHook_RequestsRetryInNextZone(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	retriever := mocks.NewMockResourceLocationRetrieverInterface(mockCtrl)
+	retriever.EXPECT().GetZonesInRegion("us-west1", "p").Return([]string{"us-west1-a", "us-west1-b", "us-west1-c"}, nil)
+	hook := ZoneFailoverHook{logger: logging.NewToolLogger("test"), zoneRetriever: retriever}
+
+	wf := daisy.New()
+	wf.Project = "p"
+	wf.Zone = "us-west1-a"
+	assert.NoError(t, hook.PreRunHook(wf))
+	wantRetry, wrapped := hook.PostRunHook(errZoneExhausted)
+	assert.Equal(t, errZoneExhausted, wrapped)
+	assert.True(t, wantRetry)
+	assert.Equal(t, "us-west1-b", hook.failoverZone)
+
+	wf = daisy.New()
+	wf.Project = "p"
+	wf.Zone = "us-west1-a"
+	assert.NoError(t, hook.PreRunHook(wf))
+	assert.Equal(t, "us-west1-b", wf.Zone)
+	wantRetry, _ = hook.PostRunHook(errZoneExhausted)
+	assert.True(t, wantRetry)
+	assert.Equal(t, "us-west1-c", hook.failoverZone)
+}
+
+func Test_ZoneFailoverHook_PostRunHook_DoesntRequestRetryWhenAllZonesTried(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	retriever := mocks.NewMockResourceLocationRetrieverInterface(mockCtrl)
+	retriever.EXPECT().GetZonesInRegion("us-west1", "p").Return([]string{"us-west1-a"}, nil)
+	hook := ZoneFailoverHook{logger: logging.NewToolLogger("test"), zoneRetriever: retriever}
+
+	wf := daisy.New()
+	wf.Project = "p"
+	wf.Zone = "us-west1-a"
+	assert.NoError(t, hook.PreRunHook(wf))
+	wantRetry, wrapped := hook.PostRunHook(errZoneExhausted)
+	assert.Equal(t, errZoneExhausted, wrapped)
+	assert.False(t, wantRetry)
+}
+
+func Test_ZoneFailoverHook_PostRunHook_LeavesCapacityErrorsToMachineTypesHook(t *testing.T) {
+	machineTypes := &UpdateMachineTypesHook{logger: logging.NewToolLogger("test"), machineSeries: []string{"n2", "n1"}}
+	hook := ZoneFailoverHook{logger: logging.NewToolLogger("test"), machineTypes: machineTypes}
+
+	wf := daisy.New()
+	wf.Project = "p"
+	wf.Zone = "us-west1-a"
+	assert.NoError(t, hook.PreRunHook(wf))
+	wantRetry, wrapped := hook.PostRunHook(errZoneExhausted)
+	assert.Equal(t, errZoneExhausted, wrapped)
+	assert.False(t, wantRetry)
+	assert.Empty(t, hook.failoverZone)
+}
+
+func Test_ZoneFailoverHook_PreRunHook_RestartsMachineSeriesInFailoverZone(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	retriever := mocks.NewMockResourceLocationRetrieverInterface(mockCtrl)
+	retriever.EXPECT().GetZonesInRegion("us-west1", "p").Return([]string{"us-west1-a", "us-west1-b"}, nil)
+	machineTypes := &UpdateMachineTypesHook{logger: logging.NewToolLogger("test"), machineSeries: []string{"c3", "n2"}}
+	hook := ZoneFailoverHook{logger: logging.NewToolLogger("test"), zoneRetriever: retriever, machineTypes: machineTypes}
+	// The same workflow is run each time, as with workflow providers that don't create a new one.
+	wf := createUpdateMachineTypesTestWorkflow()
+	wf.Project = "p"
+	wf.Zone = "us-west1-a"
+	run := func() (wantRetry bool) {
+		assert.NoError(t, hook.PreRunHook(wf))
+		assert.NoError(t, machineTypes.PreRunHook(wf))
+		wantRetry, _ = hook.PostRunHook(errZoneExhausted)
+		machineTypeRetry, _ := machineTypes.PostRunHook(errZoneExhausted)
+		return wantRetry || machineTypeRetry
+	}
+
+	assert.True(t, run())
+	assert.Equal(t, "c3-standard-2", (*wf.Steps["ci"].CreateInstances).Instances[0].MachineType)
+	assert.True(t, run())
+	assert.Equal(t, "n2-standard-2", (*wf.Steps["ci"].CreateInstances).Instances[0].MachineType)
+	assert.Equal(t, "pd-balanced", (*wf.Steps["cd"].CreateDisks)[0].Type)
+	assert.Equal(t, 1, machineTypes.maxRetries())
+
+	// Once all series are tried, the workflow moves to the next zone, and starts again with the
+	// first series, and disk types that are derived from the original types.
+	assert.True(t, run())
+	assert.Equal(t, "us-west1-b", wf.Zone)
+	assert.Equal(t, "c3-standard-2", (*wf.Steps["ci"].CreateInstances).Instances[0].MachineType)
+	assert.Equal(t, "hyperdisk-balanced", (*wf.Steps["cd"].CreateDisks)[0].Type)
+	assert.Equal(t, 2, machineTypes.maxRetries())
+	// All series and zones have been tried after the last run.
+	assert.False(t, run())
+	assert.Equal(t, "n2-standard-2", (*wf.Steps["ci"].CreateInstances).Instances[0].MachineType)
+	assert.Equal(t, "pd-balanced", (*wf.Steps["cd"].CreateDisks)[0].Type)
+}
+
+func Test_ZoneFailoverHook_MaxRetries(t *testing.T) {
+	hook := ZoneFailoverHook{}
+	assert.Equal(t, 1, hook.maxRetries())
+	hook.candidateZones = []string{"us-west1-a", "us-west1-b", "us-west1-c"}
+	assert.Equal(t, 2, hook.maxRetries())
+	hook.candidateZones = []string{"us-west1-a", "us-west1-b", "us-west1-c", "us-west1-d", "us-west1-e"}
+	assert.Equal(t, maxZoneFailovers, hook.maxRetries())
+}
+
+func Test_ZoneFailoverHook_PostRunHook_DoesntRequestRetryIfNotZonalError(t *testing.T) {
+	for _, err := range []error{
+		nil,
+		errors.New("failed to start workflow"),
+		errors.New("Quota 'CPUS' exceeded. Limit: 24.0 in region us-west1."),
+	} {
+		hook := ZoneFailoverHook{logger: logging.NewToolLogger("test")}
+		wantRetry, wrapped := hook.PostRunHook(err)
+		assert.Equal(t, err, wrapped)
+		assert.False(t, wantRetry)
+	}
+}
+
+func Test_ZoneFailoverHook_PostRunHook_RequestsRetryForZonalQuotaError(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	retriever := mocks.NewMockResourceLocationRetrieverInterface(mockCtrl)
+	retriever.EXPECT().GetZonesInRegion("us-west1", "p").Return([]string{"us-west1-b", "us-west1-a"}, nil)
+	hook := ZoneFailoverHook{logger: logging.NewToolLogger("test"), zoneRetriever: retriever}
+
+	wf := daisy.New()
+	wf.Project = "p"
+	wf.Zone = "us-west1-a"
+	assert.NoError(t, hook.PreRunHook(wf))
+	wantRetry, _ := hook.PostRunHook(errors.New("Quota 'LOCAL_SSD_TOTAL_GB' exceeded. Limit: 0.0 in zone us-west1-a."))
+	assert.True(t, wantRetry)
+	assert.Equal(t, "us-west1-b", hook.failoverZone)
+}
+
+func Test_ZoneFailoverHook_PostRunHook_DoesntRequestRetryIfZonesCantBeListed(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	retriever := mocks.NewMockResourceLocationRetrieverInterface(mockCtrl)
+	retriever.EXPECT().GetZonesInRegion("us-west1", "p").Return(nil, errors.New("failed to list zones"))
+	hook := ZoneFailoverHook{logger: logging.NewToolLogger("test"), zoneRetriever: retriever}
+
+	wf := daisy.New()
+	wf.Project = "p"
+	wf.Zone = "us-west1-a"
+	assert.NoError(t, hook.PreRunHook(wf))
+	wantRetry, wrapped := hook.PostRunHook(errZoneExhausted)
+	assert.Equal(t, errZoneExhausted, wrapped)
+	assert.False(t, wantRetry)
+}
+
+func Test_ZoneFailoverHook_PreRunHook_DoesntModifyWorkflowOnFirstRun(t *testing.T) {
+	hook := ZoneFailoverHook{logger: logging.NewToolLogger("test")}
+	wf := createWorkflowWithZonalDisks()
+
+	assert.NoError(t, hook.PreRunHook(wf))
+
+	assert.Equal(t, "us-west1-a", wf.Zone)
+	assert.Equal(t, "projects/p/zones/us-west1-a/disks/source", wf.Vars["source_disk"].Value)
+	assert.Len(t, wf.Steps, 2)
+}
+
+func Test_ZoneFailoverHook_PreRunHook_CopiesZonalDisksToFailoverZone(t *testing.T) {
+	hook := ZoneFailoverHook{
+		logger:       logging.NewToolLogger("test"),
+		originalZone: "us-west1-a",
+		failoverZone: "us-west1-b",
+	}
+	wf := createWorkflowWithZonalDisks()
+
+	assert.NoError(t, hook.PreRunHook(wf))
+
+	assert.Equal(t, "us-west1-b", wf.Zone)
+	assert.Equal(t, "source-us-west1-b", wf.Vars["source_disk"].Value)
+	assert.Equal(t, "image-name", wf.Vars["image_name"].Value)
+	instanceDisks := wf.Steps["ci"].CreateInstances.Instances[0].Disks
+	assert.Equal(t, "data-us-west1-b", instanceDisks[0].Source)
+	assert.Equal(t, "projects/p/zones/us-west1-c/disks/other", instanceDisks[1].Source)
+	assert.Equal(t, "source-us-west1-b", (*wf.Steps["ad"].AttachDisks)[0].Source)
+
+	snapshots := *wf.Steps["zone-failover-snapshot-disks"].CreateSnapshots
+	assert.Len(t, snapshots, 2)
+	assert.Equal(t, "data-us-west1-b", snapshots[0].Name)
+	assert.Equal(t, "projects/p/zones/us-west1-a/disks/data", snapshots[0].SourceDisk)
+	assert.Equal(t, "source-us-west1-b", snapshots[1].Name)
+	assert.Equal(t, "projects/p/zones/us-west1-a/disks/source", snapshots[1].SourceDisk)
+
+	disks := *wf.Steps["zone-failover-copy-disks"].CreateDisks
+	assert.Len(t, disks, 2)
+	assert.Equal(t, "data-us-west1-b", disks[0].Name)
+	assert.Equal(t, "data-us-west1-b", disks[0].SourceSnapshot)
+	assert.Equal(t, "source-us-west1-b", disks[1].Name)
+	assert.Equal(t, "source-us-west1-b", disks[1].SourceSnapshot)
+
+	assert.Equal(t, []string{"zone-failover-snapshot-disks"}, wf.Dependencies["zone-failover-copy-disks"])
+	assert.Equal(t, []string{"zone-failover-copy-disks"}, wf.Dependencies["ci"])
+	assert.Equal(t, []string{"ci"}, wf.Dependencies["ad"])
+}
+
+func Test_ZoneFailoverHook_PreRunHook_CopiesDisksReferencedByZonalURIAndName(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	mockComputeClient := mocks.NewMockClient(mockCtrl)
+	mockComputeClient.EXPECT().GetDisk("p", "us-west1-a", "disk-1234").Return(
+		&compute.Disk{Type: "https://www.googleapis.com/compute/v1/projects/p/zones/us-west1-a/diskTypes/pd-ssd"}, nil)
+	mockComputeClient.EXPECT().GetDisk("p", "us-west1-a", "data-disk").Return(nil, errors.New("not found"))
+	hook := ZoneFailoverHook{
+		logger:       logging.NewToolLogger("test"),
+		originalZone: "us-west1-a",
+		failoverZone: "us-west1-b",
+	}
+	wf := daisy.New()
+	wf.Project = "p"
+	wf.Zone = "us-west1-a"
+	wf.ComputeClient = mockComputeClient
+	wf.Vars = map[string]daisy.Var{
+		"source_disk": {Value: "zones/us-west1-a/disks/disk-1234"},
+		"image_name":  {Value: "image-name"},
+	}
+	wf.Steps = map[string]*daisy.Step{
+		"cd": {
+			CreateDisks: &daisy.CreateDisks{{Disk: compute.Disk{Name: "disk-translator"}}},
+		},
+		"ci": {
+			CreateInstances: &daisy.CreateInstances{
+				Instances: []*daisy.Instance{{
+					Instance: compute.Instance{
+						Disks: []*compute.AttachedDisk{
+							{Source: "disk-translator"},
+							{Source: "${source_disk}"},
+							{Source: "data-disk"},
+						},
+					},
+				}},
+			},
+		},
+	}
+	wf.Dependencies = map[string][]string{"ci": {"cd"}}
+
+	assert.NoError(t, hook.PreRunHook(wf))
+
+	assert.Equal(t, "disk-1234-us-west1-b", wf.Vars["source_disk"].Value)
+	instanceDisks := wf.Steps["ci"].CreateInstances.Instances[0].Disks
+	assert.Equal(t, "disk-translator", instanceDisks[0].Source)
+	assert.Equal(t, "${source_disk}", instanceDisks[1].Source)
+	assert.Equal(t, "data-disk-us-west1-b", instanceDisks[2].Source)
+
+	snapshots := *wf.Steps["zone-failover-snapshot-disks"].CreateSnapshots
+	assert.Len(t, snapshots, 2)
+	assert.Equal(t, "projects/p/zones/us-west1-a/disks/data-disk", snapshots[0].SourceDisk)
+	assert.Equal(t, "projects/p/zones/us-west1-a/disks/disk-1234", snapshots[1].SourceDisk)
+
+	disks := *wf.Steps["zone-failover-copy-disks"].CreateDisks
+	assert.Len(t, disks, 2)
+	assert.Equal(t, "data-disk-us-west1-b", disks[0].Name)
+	assert.Equal(t, "", disks[0].Type)
+	assert.Equal(t, "disk-1234-us-west1-b", disks[1].Name)
+	assert.Equal(t, "pd-ssd", disks[1].Type)
+	assert.Equal(t, []string{"zone-failover-copy-disks"}, wf.Dependencies["cd"])
+}
+
+func createWorkflowWithZonalDisks() *daisy.Workflow {
+	wf := daisy.New()
+	wf.Project = "p"
+	wf.Zone = "us-west1-a"
+	wf.Vars = map[string]daisy.Var{
+		"source_disk": {Value: "projects/p/zones/us-west1-a/disks/source"},
+		"image_name":  {Value: "image-name"},
+	}
+	wf.Steps = map[string]*daisy.Step{
+		"ci": {
+			CreateInstances: &daisy.CreateInstances{
+				Instances: []*daisy.Instance{{
+					Instance: compute.Instance{
+						Disks: []*compute.AttachedDisk{
+							{Source: "https://www.googleapis.com/compute/v1/projects/p/zones/us-west1-a/disks/data"},
+							{Source: "projects/p/zones/us-west1-c/disks/other"},
+						},
+					},
+				}},
+			},
+		},
+		"ad": {
+			AttachDisks: &daisy.AttachDisks{{
+				AttachedDisk: compute.AttachedDisk{Source: "projects/p/zones/us-west1-a/disks/source"},
+			}},
+		},
+	}
+	wf.Dependencies = map[string][]string{"ad": {"ci"}}
+	return wf
+}
@@ -32,3 +32,25 @@ func (i *StringArrayFlag) String() string {
 	}
 	return strings.Join(*i, ",")
 }
+
+// StringListFlag represents a CLI flag with an ordered list of string values. Values
+// may be specified by repeating the flag, as a comma-separated list, or both.
+type StringListFlag []string
+
+// Set adds the comma-separated values in value to StringListFlag
+func (i *StringListFlag) Set(value string) error {
+	for _, v := range strings.Split(value, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			*i = append(*i, v)
+		}
+	}
+	return nil
+}
+
+// String returns string representation of StringListFlag
+func (i *StringListFlag) String() string {
+	if i == nil {
+		return ""
+	}
+	return strings.Join(*i, ",")
+}
@@ -0,0 +1,35 @@
+//  Copyright 2026 Google Inc. All Rights Reserved.
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License
+
+package flags
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStringListFlagSetSplitsOnCommasAndAppends(t *testing.T) {
+	var s StringListFlag
+	assert.Nil(t, s.Set("c3, n2"))
+	assert.Nil(t, s.Set("n1"))
+	assert.Nil(t, s.Set("e2,"))
+	assert.Equal(t, StringListFlag{"c3", "n2", "n1", "e2"}, s)
+	assert.Equal(t, "c3,n2,n1,e2", s.String())
+}
+
+func TestStringListFlagStringReturnsEmptyForNil(t *testing.T) {
+	var s *StringListFlag
+	assert.Equal(t, "", s.String())
+}
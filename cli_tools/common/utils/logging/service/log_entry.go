@@ -123,6 +123,8 @@ type ImageExportParams struct {
 	Format                string `json:"format,omitempty"`
 	ComputeServiceAccount string `json:"compute_service_account,omitempty"`
 	SourceDiskSnapshot    string `json:"source_disk_snapshot,omitempty"`
+	SourceDisk            string `json:"source_disk,omitempty"`
+	PortableFor           string `json:"portable_for,omitempty"`
 }
 
 // OnestepImageImportParams contains all input params for onestep image import
@@ -15,21 +15,29 @@
 package param
 
 import (
+	"fmt"
+
 	daisyCompute "github.com/GoogleCloudPlatform/compute-daisy/compute"
 )
 
+// defaultMachineSeries are the machine series that are detected when the user
+// doesn't request any, in order of preference.
+var defaultMachineSeries = []string{"n2", "n1", "e2"}
+
 // To rebuild the mock for MachineSeriesDetector, run `go generate ./...`
 //go:generate go run github.com/golang/mock/mockgen -package mocks -source $GOFILE -destination ../../../mocks/mock_machine_series_detector.go
 
-// MachineSeriesDetector detects which of N2, N1, E2 series are available
-// in the execution context and are compatible with the import / export tools.
+// MachineSeriesDetector builds the ordered list of machine series that workers fall back
+// through, from the series that are available in the execution context and are
+// compatible with the import / export tools. By default, N2, N1 and E2 are considered.
 // N2 https://cloud.google.com/compute/docs/general-purpose-machines#n2_machines
 // N1 https://cloud.google.com/compute/docs/general-purpose-machines#n1_machines
 // E2 https://cloud.google.com/compute/docs/general-purpose-machines#e2_machines
 type MachineSeriesDetector interface {
-	// Detects which of N2, N1, E2 series are available in the specified project and zone
-	// and are compatible with the import / export tools.
-	Detect(project, zone string) ([]string, error)
+	// Detect returns the machine series in requested, or N2, N1 and E2 when requested
+	// is empty, that are available in the specified project and zone and are compatible
+	// with the import / export tools. The order of requested is kept.
+	Detect(project, zone string, requested []string) ([]string, error)
 }
 
 // NewMachineSeriesDetector returns a MachineSeriesDetector implementation that uses the Compute API.
@@ -42,20 +50,34 @@ type computeMachineSeriesDetector struct {
 	client daisyCompute.Client
 }
 
-func (cm *computeMachineSeriesDetector) Detect(project, zone string) ([]string, error) {
+func (cm *computeMachineSeriesDetector) Detect(project, zone string, requested []string) ([]string, error) {
+	candidates := requested
+	if len(candidates) == 0 {
+		candidates = defaultMachineSeries
+	}
 	machineTypes, err := cm.getMachineTypes(project, zone)
 	if err != nil {
+		if len(requested) > 0 {
+			// The requested series are used as they are, and unavailable series are
+			// skipped by UpdateMachineTypesHook when workers can't be created.
+			return requested, nil
+		}
 		return []string{}, err
 	}
 
 	res := []string{}
 
-	for _, ms := range []string{"n2", "n1", "e2"} {
+	for _, ms := range candidates {
 		if cm.isMachineSeriesCompatible(machineTypes, ms) {
 			res = append(res, ms)
 		}
 	}
 
+	if len(requested) > 0 && len(res) == 0 {
+		return nil, fmt.Errorf("none of the worker machine series %v are available in zone %s. "+
+			"Workers require the machine types <series>-standard-2, -standard-4, -standard-8 and -highcpu-4",
+			requested, zone)
+	}
 	return res, nil
 }
 
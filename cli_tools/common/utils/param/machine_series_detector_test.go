@@ -15,6 +15,7 @@
 package param
 
 import (
+	"errors"
 	"testing"
 
 	"github.com/golang/mock/gomock"
@@ -57,8 +58,56 @@ func Test_RetrievingSupportedMachineSeries(t *testing.T) {
 
 	machineSeriesDetector := NewMachineSeriesDetector(mockComputeClient)
 
-	actual, err := machineSeriesDetector.Detect(project, zone)
+	actual, err := machineSeriesDetector.Detect(project, zone, nil)
 	assert.NoError(t, err)
 
 	assert.Equal(t, []string{"n2", "e2"}, actual)
 }
+
+func Test_RetrievingRequestedMachineSeriesInOrder(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	mockComputeClient := mocks.NewMockClient(mockCtrl)
+	mockComputeClient.EXPECT().ListMachineTypes("a-project", "a-zone").Return(machineTypesOf("n4", "n2", "n1"), nil)
+
+	// c3 doesn't have the machine type c3-standard-2, and therefore is skipped.
+	actual, err := NewMachineSeriesDetector(mockComputeClient).Detect("a-project", "a-zone", []string{"c3", "n1", "n4", "n2"})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"n1", "n4", "n2"}, actual)
+}
+
+func Test_RetrievingRequestedMachineSeries_FailsWhenNoneAreAvailable(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	mockComputeClient := mocks.NewMockClient(mockCtrl)
+	mockComputeClient.EXPECT().ListMachineTypes("a-project", "a-zone").Return(machineTypesOf("n1"), nil)
+
+	_, err := NewMachineSeriesDetector(mockComputeClient).Detect("a-project", "a-zone", []string{"c4", "n4"})
+	assert.EqualError(t, err, "none of the worker machine series [c4 n4] are available in zone a-zone. "+
+		"Workers require the machine types <series>-standard-2, -standard-4, -standard-8 and -highcpu-4")
+}
+
+func Test_RetrievingRequestedMachineSeries_KeepsRequestWhenMachineTypesCantBeListed(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	mockComputeClient := mocks.NewMockClient(mockCtrl)
+	mockComputeClient.EXPECT().ListMachineTypes("a-project", "a-zone").Return(nil, errors.New("forbidden"))
+
+	actual, err := NewMachineSeriesDetector(mockComputeClient).Detect("a-project", "a-zone", []string{"n4", "n2"})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"n4", "n2"}, actual)
+}
+
+// machineTypesOf returns the machine types that workers use, for each of machineSeries.
+func machineTypesOf(machineSeries ...string) []*compute.MachineType {
+	var machineTypes []*compute.MachineType
+	for _, series := range machineSeries {
+		for _, suffix := range []string{"-standard-2", "-standard-4", "-standard-8", "-highcpu-4"} {
+			machineTypes = append(machineTypes, &compute.MachineType{Name: series + suffix})
+		}
+	}
+	return machineTypes
+}
@@ -0,0 +1,386 @@
+//  Copyright 2026 Google Inc. All Rights Reserved.
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+package param
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+
+	daisy "github.com/GoogleCloudPlatform/compute-daisy"
+	daisyCompute "github.com/GoogleCloudPlatform/compute-daisy/compute"
+	"google.golang.org/api/cloudresourcemanager/v1"
+	"google.golang.org/api/compute/v1"
+	"google.golang.org/api/option"
+
+	"github.com/GoogleCloudPlatform/compute-image-import/cli_tools/common/utils/paramhelper"
+)
+
+const (
+	externalIPConstraint = "constraints/compute.vmExternalIpAccess"
+	noExternalIPDocsURL  = "https://cloud.google.com/compute/docs/import/importing-virtual-disks#no-external-ip"
+)
+
+// googleAPIsDestinations are the destination ranges that worker instances use to reach
+// Google APIs. "0.0.0.0/0" represents the public googleapis.com addresses, which are
+// used by Private Google Access and Cloud NAT. The others are the virtual IPs of
+// private.googleapis.com and restricted.googleapis.com.
+var googleAPIsDestinations = []string{"0.0.0.0/0", "199.36.153.8/30", "199.36.153.4/30"}
+
+// NetworkPreflight checks, before any worker instance is created, that worker
+// instances on a network will be able to reach Google APIs such as Cloud Storage.
+// Without this check, a misconfigured network causes workers to hang until the
+// workflow times out.
+type NetworkPreflight interface {
+	// Check returns an error when workers using network and subnet won't be able
+	// to reach Google APIs. network and subnet are expected to be values returned
+	// by NetworkResolver.Resolve.
+	Check(network, subnet, region, project string, noExternalIP bool) error
+}
+
+// RouterClient lists the Cloud Routers in a region.
+type RouterClient interface {
+	ListRouters(project, region string) ([]*compute.Router, error)
+}
+
+// OrgPolicyClient reads the list policy for a constraint that is in effect for a project.
+type OrgPolicyClient interface {
+	GetEffectiveListPolicy(project, constraint string) (*cloudresourcemanager.ListPolicy, error)
+}
+
+// NewNetworkPreflight returns a NetworkPreflight that uses the Compute API to inspect the
+// network, and the Resource Manager API to inspect organization policies.
+func NewNetworkPreflight(client daisyCompute.Client, routerClient RouterClient,
+	orgPolicyClient OrgPolicyClient) NetworkPreflight {
+	return &computeNetworkPreflight{client, routerClient, orgPolicyClient}
+}
+
+// CreateNetworkPreflight creates the API clients that are required by NetworkPreflight.
+func CreateNetworkPreflight(ctx context.Context, oauth string, ce string,
+	client daisyCompute.Client) (NetworkPreflight, error) {
+	computeOptions := []option.ClientOption{option.WithCredentialsFile(oauth)}
+	if ce != "" {
+		computeOptions = append(computeOptions, option.WithEndpoint(ce))
+	}
+	computeService, err := compute.NewService(ctx, computeOptions...)
+	if err != nil {
+		return nil, daisy.Errf("failed to create compute client: %v", err)
+	}
+	resourceManagerService, err := cloudresourcemanager.NewService(ctx, option.WithCredentialsFile(oauth))
+	if err != nil {
+		return nil, daisy.Errf("failed to create resource manager client: %v", err)
+	}
+	return NewNetworkPreflight(client, &apiRouterClient{computeService},
+		&apiOrgPolicyClient{resourceManagerService}), nil
+}
+
+// computeNetworkPreflight implements NetworkPreflight. When the APIs can't be used
+// to determine whether a setting is compatible, the setting is assumed to be
+// compatible, so that the preflight only fails when it is certain that workers
+// won't work.
+type computeNetworkPreflight struct {
+	client          daisyCompute.Client
+	routerClient    RouterClient
+	orgPolicyClient OrgPolicyClient
+}
+
+func (p *computeNetworkPreflight) Check(network, subnet, region, project string, noExternalIP bool) error {
+	if err := p.checkExternalIPPolicy(project, noExternalIP); err != nil {
+		return err
+	}
+
+	networkResponse, subnetResponse := p.getNetworkAndSubnet(network, subnet, region, project)
+	if networkResponse == nil {
+		return nil
+	}
+	if err := p.checkEgressFirewall(networkResponse); err != nil {
+		return err
+	}
+	if noExternalIP && subnetResponse != nil {
+		return p.checkPrivateAccess(subnetResponse, region)
+	}
+	return nil
+}
+
+// checkExternalIPPolicy returns an error when workers are expected to have an external IP,
+// and the compute.vmExternalIpAccess constraint doesn't allow it. Worker instance names are
+// generated at runtime, so any allow-list prevents workers from having an external IP.
+func (p *computeNetworkPreflight) checkExternalIPPolicy(project string, noExternalIP bool) error {
+	if noExternalIP || p.orgPolicyClient == nil {
+		return nil
+	}
+	policy, err := p.orgPolicyClient.GetEffectiveListPolicy(project, externalIPConstraint)
+	if err != nil || policy == nil {
+		return nil
+	}
+	if policy.AllValues == "DENY" || len(policy.AllowedValues) > 0 {
+		return daisy.Errf("The organization policy constraint %s doesn't allow worker instances "+
+			"in project %s to have external IP addresses. Re-run using the no external IP flag. "+
+			"For more information about importing disks using networks that don't allow "+
+			"external IP addresses, see %s", externalIPConstraint, project, noExternalIPDocsURL)
+	}
+	return nil
+}
+
+// getNetworkAndSubnet fetches the network and the subnet that workers will use. When
+// subnet is empty, the subnet is inferred the same way as instances.insert: by choosing
+// the network's subnet in region.
+func (p *computeNetworkPreflight) getNetworkAndSubnet(network, subnet, region, project string) (
+	*compute.Network, *compute.Subnetwork) {
+	var subnetResponse *compute.Subnetwork
+	if subnet != "" {
+		subnetResource, err := paramhelper.SplitSubnetResource(subnet)
+		if err != nil {
+			return nil, nil
+		}
+		if subnetResponse, err = p.client.GetSubnetwork(
+			subnetResource.Project, subnetResource.Region, subnetResource.Name); err != nil {
+			return nil, nil
+		}
+		network = subnetResponse.Network
+	}
+	networkResource, err := paramhelper.SplitNetworkResource(network)
+	if err != nil || networkResource.Name == "" {
+		return nil, nil
+	}
+	if networkResource.Project == "" {
+		networkResource.Project = project
+	}
+	networkResponse, err := p.client.GetNetwork(networkResource.Project, networkResource.Name)
+	if err != nil {
+		return nil, nil
+	}
+	if subnetResponse == nil {
+		for _, uri := range networkResponse.Subnetworks {
+			subnetResource, err := paramhelper.SplitSubnetResource(uri)
+			if err != nil || subnetResource.Region != region {
+				continue
+			}
+			if subnetResponse, err = p.client.GetSubnetwork(
+				subnetResource.Project, subnetResource.Region, subnetResource.Name); err == nil {
+				break
+			}
+		}
+	}
+	return networkResponse, subnetResponse
+}
+
+// checkPrivateAccess returns an error when workers without an external IP can't reach
+// Google APIs, since the subnet doesn't have Private Google Access, and isn't
+// served by a Cloud NAT gateway.
+func (p *computeNetworkPreflight) checkPrivateAccess(subnet *compute.Subnetwork, region string) error {
+	if subnet.PrivateIpGoogleAccess {
+		return nil
+	}
+	if p.routerClient == nil {
+		return nil
+	}
+	subnetResource, err := paramhelper.SplitSubnetResource(subnet.SelfLink)
+	if err != nil {
+		return nil
+	}
+	routers, err := p.routerClient.ListRouters(subnetResource.Project, region)
+	if err != nil {
+		return nil
+	}
+	for _, router := range routers {
+		if isSameResource(router.Network, subnet.Network) && hasNatForSubnet(router, subnet) {
+			return nil
+		}
+	}
+	return daisy.Errf("Subnet %q doesn't have Private Google Access enabled, and isn't served by a "+
+		"Cloud NAT gateway, so worker instances without external IP addresses can't reach Cloud Storage. "+
+		"To enable Private Google Access, run `gcloud compute networks subnets update %s --region=%s "+
+		"--enable-private-ip-google-access`. For more information, see %s",
+		subnet.Name, subnet.Name, region, noExternalIPDocsURL)
+}
+
+func hasNatForSubnet(router *compute.Router, subnet *compute.Subnetwork) bool {
+	for _, nat := range router.Nats {
+		switch nat.SourceSubnetworkIpRangesToNat {
+		case "ALL_SUBNETWORKS_ALL_IP_RANGES", "ALL_SUBNETWORKS_ALL_PRIMARY_IP_RANGES":
+			return true
+		case "LIST_OF_SUBNETWORKS":
+			for _, natSubnet := range nat.Subnetworks {
+				if isSameResource(natSubnet.Name, subnet.SelfLink) {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// checkEgressFirewall returns an error when the network's egress firewall rules deny
+// HTTPS traffic to all of the addresses that workers can use to reach Google APIs.
+// Rules that target specific tags or service accounts are ignored, since it
+// isn't known whether they apply to workers.
+func (p *computeNetworkPreflight) checkEgressFirewall(network *compute.Network) error {
+	rules, err := p.client.ListFirewallRules(projectOf(network.SelfLink))
+	if err != nil {
+		return nil
+	}
+	var egressRules []*compute.Firewall
+	for _, rule := range rules {
+		if rule.Direction == "EGRESS" && !rule.Disabled && isSameResource(rule.Network, network.SelfLink) &&
+			len(rule.TargetTags) == 0 && len(rule.TargetServiceAccounts) == 0 {
+			egressRules = append(egressRules, rule)
+		}
+	}
+	// Rules with a lower priority value take precedence. When priorities are equal, deny rules
+	// take precedence.
+	sort.SliceStable(egressRules, func(i, j int) bool {
+		if egressRules[i].Priority != egressRules[j].Priority {
+			return egressRules[i].Priority < egressRules[j].Priority
+		}
+		return len(egressRules[i].Denied) > 0 && len(egressRules[j].Denied) == 0
+	})
+
+	var blockingRule string
+	for _, destination := range googleAPIsDestinations {
+		rule := firstMatchingRule(egressRules, destination)
+		if rule == nil || len(rule.Denied) == 0 {
+			return nil
+		}
+		if blockingRule == "" {
+			blockingRule = rule.Name
+		}
+	}
+	return daisy.Errf("Egress firewall rule %q on network %q blocks worker instances from reaching "+
+		"Google APIs on tcp:443. Add an egress rule with a higher priority that allows tcp:443 "+
+		"to Google APIs. For more information, see %s", blockingRule, network.Name, noExternalIPDocsURL)
+}
+
+// firstMatchingRule returns the first rule in rules that applies to HTTPS traffic
+// sent to destination. A public destination of "0.0.0.0/0" is only matched by deny
+// rules that cover all addresses, while any allow rule is considered to match it,
+// since it may cover the public addresses of Google APIs.
+func firstMatchingRule(rules []*compute.Firewall, destination string) *compute.Firewall {
+	_, destinationNet, err := net.ParseCIDR(destination)
+	if err != nil {
+		return nil
+	}
+	public := destination == "0.0.0.0/0"
+	for _, rule := range rules {
+		deny := len(rule.Denied) > 0
+		if deny && !matchesHTTPS(deniedAsAllowed(rule.Denied)) || !deny && !matchesHTTPS(rule.Allowed) {
+			continue
+		}
+		if public && !deny {
+			return rule
+		}
+		for _, ranges := range rule.DestinationRanges {
+			_, ruleNet, err := net.ParseCIDR(ranges)
+			if err != nil {
+				continue
+			}
+			ruleSize, _ := ruleNet.Mask.Size()
+			destinationSize, _ := destinationNet.Mask.Size()
+			if ruleNet.Contains(destinationNet.IP) && ruleSize <= destinationSize {
+				return rule
+			}
+		}
+	}
+	return nil
+}
+
+func deniedAsAllowed(denied []*compute.FirewallDenied) []*compute.FirewallAllowed {
+	var allowed []*compute.FirewallAllowed
+	for _, d := range denied {
+		allowed = append(allowed, &compute.FirewallAllowed{IPProtocol: d.IPProtocol, Ports: d.Ports})
+	}
+	return allowed
+}
+
+// matchesHTTPS returns whether the protocols and ports of a firewall rule include tcp:443.
+func matchesHTTPS(protocols []*compute.FirewallAllowed) bool {
+	for _, protocol := range protocols {
+		if protocol.IPProtocol != "all" && protocol.IPProtocol != "tcp" && protocol.IPProtocol != "6" {
+			continue
+		}
+		if len(protocol.Ports) == 0 {
+			return true
+		}
+		for _, ports := range protocol.Ports {
+			bounds := strings.SplitN(ports, "-", 2)
+			low, err := strconv.Atoi(bounds[0])
+			if err != nil {
+				continue
+			}
+			high := low
+			if len(bounds) == 2 {
+				if high, err = strconv.Atoi(bounds[1]); err != nil {
+					continue
+				}
+			}
+			if low <= 443 && 443 <= high {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// isSameResource returns whether two URIs refer to the same resource, ignoring
+// differences in their URL prefixes.
+func isSameResource(a, b string) bool {
+	return a != "" && resourcePath(a) == resourcePath(b)
+}
+
+func resourcePath(uri string) string {
+	if i := strings.Index(uri, "projects/"); i >= 0 {
+		return uri[i:]
+	}
+	return uri
+}
+
+func projectOf(uri string) string {
+	parts := strings.Split(resourcePath(uri), "/")
+	if len(parts) < 2 {
+		return ""
+	}
+	return parts[1]
+}
+
+// apiRouterClient implements RouterClient using the Compute API.
+type apiRouterClient struct {
+	service *compute.Service
+}
+
+func (c *apiRouterClient) ListRouters(project, region string) ([]*compute.Router, error) {
+	var routers []*compute.Router
+	err := c.service.Routers.List(project, region).Pages(context.Background(), func(list *compute.RouterList) error {
+		routers = append(routers, list.Items...)
+		return nil
+	})
+	return routers, err
+}
+
+// apiOrgPolicyClient implements OrgPolicyClient using the Resource Manager API.
+type apiOrgPolicyClient struct {
+	service *cloudresourcemanager.Service
+}
+
+func (c *apiOrgPolicyClient) GetEffectiveListPolicy(project, constraint string) (*cloudresourcemanager.ListPolicy, error) {
+	policy, err := c.service.Projects.GetEffectiveOrgPolicy(fmt.Sprintf("projects/%s", project),
+		&cloudresourcemanager.GetEffectiveOrgPolicyRequest{Constraint: constraint}).Do()
+	if err != nil {
+		return nil, err
+	}
+	return policy.ListPolicy, nil
+}
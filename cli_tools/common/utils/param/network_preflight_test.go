@@ -0,0 +1,255 @@
+//  Copyright 2026 Google Inc. All Rights Reserved.
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+package param
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/api/cloudresourcemanager/v1"
+	"google.golang.org/api/compute/v1"
+
+	"github.com/GoogleCloudPlatform/compute-image-import/cli_tools/mocks"
+)
+
+const (
+	testNetworkURI = "https://www.googleapis.com/compute/v1/projects/project-id/global/networks/network-id"
+	testSubnetURI  = "https://www.googleapis.com/compute/v1/projects/project-id/regions/us-west1/subnetworks/subnet-id"
+)
+
+type fakeRouterClient struct {
+	routers []*compute.Router
+	err     error
+}
+
+func (c *fakeRouterClient) ListRouters(project, region string) ([]*compute.Router, error) {
+	return c.routers, c.err
+}
+
+type fakeOrgPolicyClient struct {
+	policy *cloudresourcemanager.ListPolicy
+	err    error
+}
+
+func (c *fakeOrgPolicyClient) GetEffectiveListPolicy(project, constraint string) (*cloudresourcemanager.ListPolicy, error) {
+	return c.policy, c.err
+}
+
+func TestNetworkPreflight_ExternalIPPolicy(t *testing.T) {
+	tests := []struct {
+		name         string
+		policy       *cloudresourcemanager.ListPolicy
+		policyErr    error
+		noExternalIP bool
+		expectErr    bool
+	}{
+		{name: "no policy", policy: &cloudresourcemanager.ListPolicy{}},
+		{name: "allow all", policy: &cloudresourcemanager.ListPolicy{AllValues: "ALLOW"}},
+		{name: "deny all", policy: &cloudresourcemanager.ListPolicy{AllValues: "DENY"}, expectErr: true},
+		{name: "allow list", policy: &cloudresourcemanager.ListPolicy{
+			AllowedValues: []string{"projects/project-id/zones/us-west1-a/instances/vm"}}, expectErr: true},
+		{name: "deny all without external IP", policy: &cloudresourcemanager.ListPolicy{AllValues: "DENY"}, noExternalIP: true},
+		{name: "policy can't be read", policyErr: errors.New("permission denied")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+			mockComputeClient := mocks.NewMockClient(mockCtrl)
+			mockComputeClient.EXPECT().GetNetwork(gomock.Any(), gomock.Any()).Return(nil, errors.New("not found")).AnyTimes()
+
+			err := NewNetworkPreflight(mockComputeClient, &fakeRouterClient{},
+				&fakeOrgPolicyClient{tt.policy, tt.policyErr}).Check(
+				"projects/project-id/global/networks/network-id", "", "us-west1", "project-id", tt.noExternalIP)
+			if tt.expectErr {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), "constraints/compute.vmExternalIpAccess")
+				assert.Contains(t, err.Error(), "project-id")
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestNetworkPreflight_PrivateGoogleAccess(t *testing.T) {
+	tests := []struct {
+		name      string
+		pga       bool
+		routers   []*compute.Router
+		routerErr error
+		expectErr bool
+	}{
+		{name: "private google access enabled", pga: true},
+		{name: "no access to google APIs", expectErr: true},
+		{name: "cloud NAT for all subnets", routers: []*compute.Router{{
+			Network: testNetworkURI,
+			Nats:    []*compute.RouterNat{{SourceSubnetworkIpRangesToNat: "ALL_SUBNETWORKS_ALL_IP_RANGES"}},
+		}}},
+		{name: "cloud NAT for subnet", routers: []*compute.Router{{
+			Network: testNetworkURI,
+			Nats: []*compute.RouterNat{{
+				SourceSubnetworkIpRangesToNat: "LIST_OF_SUBNETWORKS",
+				Subnetworks:                   []*compute.RouterNatSubnetworkToNat{{Name: testSubnetURI}},
+			}},
+		}}},
+		{name: "cloud NAT for other subnet", expectErr: true, routers: []*compute.Router{{
+			Network: testNetworkURI,
+			Nats: []*compute.RouterNat{{
+				SourceSubnetworkIpRangesToNat: "LIST_OF_SUBNETWORKS",
+				Subnetworks: []*compute.RouterNatSubnetworkToNat{{
+					Name: "https://www.googleapis.com/compute/v1/projects/project-id/regions/us-west1/subnetworks/other"}},
+			}},
+		}}},
+		{name: "cloud NAT on other network", expectErr: true, routers: []*compute.Router{{
+			Network: "https://www.googleapis.com/compute/v1/projects/project-id/global/networks/other",
+			Nats:    []*compute.RouterNat{{SourceSubnetworkIpRangesToNat: "ALL_SUBNETWORKS_ALL_IP_RANGES"}},
+		}}},
+		{name: "routers can't be listed", routerErr: errors.New("permission denied")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+			mockComputeClient := mocks.NewMockClient(mockCtrl)
+			mockComputeClient.EXPECT().GetSubnetwork("project-id", "us-west1", "subnet-id").Return(&compute.Subnetwork{
+				Name:                  "subnet-id",
+				SelfLink:              testSubnetURI,
+				Network:               testNetworkURI,
+				PrivateIpGoogleAccess: tt.pga,
+			}, nil)
+			mockComputeClient.EXPECT().GetNetwork("project-id", "network-id").Return(&compute.Network{
+				Name:     "network-id",
+				SelfLink: testNetworkURI,
+			}, nil)
+			mockComputeClient.EXPECT().ListFirewallRules("project-id").Return(nil, nil)
+
+			err := NewNetworkPreflight(mockComputeClient, &fakeRouterClient{tt.routers, tt.routerErr}, nil).Check(
+				"", "projects/project-id/regions/us-west1/subnetworks/subnet-id", "us-west1", "project-id", true)
+			if tt.expectErr {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), "--enable-private-ip-google-access")
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestNetworkPreflight_InfersSubnetFromNetworkAndRegion(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	mockComputeClient := mocks.NewMockClient(mockCtrl)
+	mockComputeClient.EXPECT().GetNetwork("project-id", "network-id").Return(&compute.Network{
+		Name:     "network-id",
+		SelfLink: testNetworkURI,
+		Subnetworks: []string{
+			"https://www.googleapis.com/compute/v1/projects/project-id/regions/us-east1/subnetworks/network-id",
+			testSubnetURI,
+		},
+	}, nil)
+	mockComputeClient.EXPECT().GetSubnetwork("project-id", "us-west1", "subnet-id").Return(&compute.Subnetwork{
+		Name:     "subnet-id",
+		SelfLink: testSubnetURI,
+		Network:  testNetworkURI,
+	}, nil)
+	mockComputeClient.EXPECT().ListFirewallRules("project-id").Return(nil, nil)
+
+	err := NewNetworkPreflight(mockComputeClient, &fakeRouterClient{}, nil).Check(
+		"projects/project-id/global/networks/network-id", "", "us-west1", "project-id", true)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), `Subnet "subnet-id" doesn't have Private Google Access enabled`)
+}
+
+func TestNetworkPreflight_EgressFirewall(t *testing.T) {
+	denyAll := &compute.Firewall{
+		Name: "deny-all-egress", Network: testNetworkURI, Direction: "EGRESS", Priority: 1000,
+		DestinationRanges: []string{"0.0.0.0/0"},
+		Denied:            []*compute.FirewallDenied{{IPProtocol: "all"}},
+	}
+	tests := []struct {
+		name      string
+		rules     []*compute.Firewall
+		expectErr bool
+	}{
+		{name: "no rules"},
+		{name: "deny all egress", rules: []*compute.Firewall{denyAll}, expectErr: true},
+		{name: "deny https egress", expectErr: true, rules: []*compute.Firewall{{
+			Name: "deny-https", Network: testNetworkURI, Direction: "EGRESS", Priority: 1000,
+			DestinationRanges: []string{"0.0.0.0/0"},
+			Denied:            []*compute.FirewallDenied{{IPProtocol: "tcp", Ports: []string{"400-500"}}},
+		}}},
+		{name: "deny other port", rules: []*compute.Firewall{{
+			Name: "deny-ssh", Network: testNetworkURI, Direction: "EGRESS", Priority: 1000,
+			DestinationRanges: []string{"0.0.0.0/0"},
+			Denied:            []*compute.FirewallDenied{{IPProtocol: "tcp", Ports: []string{"22"}}},
+		}}},
+		{name: "allow private googleapis with higher priority", rules: []*compute.Firewall{denyAll, {
+			Name: "allow-private-googleapis", Network: testNetworkURI, Direction: "EGRESS", Priority: 900,
+			DestinationRanges: []string{"199.36.153.8/30"},
+			Allowed:           []*compute.FirewallAllowed{{IPProtocol: "tcp", Ports: []string{"443"}}},
+		}}},
+		{name: "allow with lower priority", expectErr: true, rules: []*compute.Firewall{denyAll, {
+			Name: "allow-private-googleapis", Network: testNetworkURI, Direction: "EGRESS", Priority: 2000,
+			DestinationRanges: []string{"199.36.153.8/30"},
+			Allowed:           []*compute.FirewallAllowed{{IPProtocol: "tcp", Ports: []string{"443"}}},
+		}}},
+		{name: "deny wins when priorities are equal", expectErr: true, rules: []*compute.Firewall{{
+			Name: "allow-all", Network: testNetworkURI, Direction: "EGRESS", Priority: 1000,
+			DestinationRanges: []string{"0.0.0.0/0"},
+			Allowed:           []*compute.FirewallAllowed{{IPProtocol: "all"}},
+		}, denyAll}},
+		{name: "ignore disabled rule", rules: []*compute.Firewall{{
+			Name: "deny-all-egress", Network: testNetworkURI, Direction: "EGRESS", Priority: 1000, Disabled: true,
+			DestinationRanges: []string{"0.0.0.0/0"},
+			Denied:            []*compute.FirewallDenied{{IPProtocol: "all"}},
+		}}},
+		{name: "ignore targeted rule", rules: []*compute.Firewall{{
+			Name: "deny-all-egress", Network: testNetworkURI, Direction: "EGRESS", Priority: 1000,
+			TargetTags:        []string{"web"},
+			DestinationRanges: []string{"0.0.0.0/0"},
+			Denied:            []*compute.FirewallDenied{{IPProtocol: "all"}},
+		}}},
+		{name: "ignore other network", rules: []*compute.Firewall{{
+			Name: "deny-all-egress", Direction: "EGRESS", Priority: 1000,
+			Network:           "https://www.googleapis.com/compute/v1/projects/project-id/global/networks/other",
+			DestinationRanges: []string{"0.0.0.0/0"},
+			Denied:            []*compute.FirewallDenied{{IPProtocol: "all"}},
+		}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+			mockComputeClient := mocks.NewMockClient(mockCtrl)
+			mockComputeClient.EXPECT().GetNetwork("project-id", "network-id").Return(&compute.Network{
+				Name:     "network-id",
+				SelfLink: testNetworkURI,
+			}, nil)
+			mockComputeClient.EXPECT().ListFirewallRules("project-id").Return(tt.rules, nil)
+
+			err := NewNetworkPreflight(mockComputeClient, nil, nil).Check(
+				"projects/project-id/global/networks/network-id", "", "us-west1", "project-id", false)
+			if tt.expectErr {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), "blocks worker instances from reaching Google APIs")
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,353 @@
+//  Copyright 2026 Google Inc. All Rights Reserved.
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+package param
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"sort"
+	"text/tabwriter"
+
+	daisy "github.com/GoogleCloudPlatform/compute-daisy"
+	daisyCompute "github.com/GoogleCloudPlatform/compute-daisy/compute"
+	"google.golang.org/api/cloudresourcemanager/v1"
+	"google.golang.org/api/iam/v1"
+	"google.golang.org/api/option"
+
+	"github.com/GoogleCloudPlatform/compute-image-import/cli_tools/common/domain"
+	"github.com/GoogleCloudPlatform/compute-image-import/cli_tools/common/utils/storage"
+)
+
+// Flow identifies what a tool does, and therefore which IAM permissions it requires.
+type Flow int
+
+const (
+	// ImageImportFlow imports a disk file or image to a GCE image.
+	ImageImportFlow Flow = iota
+	// ImageExportFlow exports a GCE image to a disk file.
+	ImageExportFlow
+	// OVFImportFlow imports an OVF package to an instance or machine image.
+	OVFImportFlow
+	// OVFExportFlow exports an instance or machine image to an OVF package.
+	OVFExportFlow
+)
+
+// Resource types that are reported by PermissionPreflight.
+const (
+	projectResource        = "project"
+	bucketResource         = "bucket"
+	serviceAccountResource = "service account"
+)
+
+var (
+	// workerProjectPermissions are required by all flows, since they all run daisy workers.
+	workerProjectPermissions = []string{
+		"compute.disks.create",
+		"compute.disks.delete",
+		"compute.disks.get",
+		"compute.disks.setLabels",
+		"compute.disks.use",
+		"compute.images.get",
+		"compute.images.useReadOnly",
+		"compute.instances.create",
+		"compute.instances.delete",
+		"compute.instances.get",
+		"compute.instances.getSerialPortOutput",
+		"compute.instances.setLabels",
+		"compute.instances.setMetadata",
+		"compute.instances.setServiceAccount",
+		"compute.networks.get",
+		"compute.projects.get",
+		"compute.subnetworks.use",
+		"compute.zones.list",
+	}
+
+	flowProjectPermissions = map[Flow][]string{
+		ImageImportFlow: {
+			"compute.images.create",
+			"compute.images.delete",
+			"compute.images.setLabels",
+		},
+		ImageExportFlow: {
+			"compute.snapshots.useReadOnly",
+		},
+		OVFImportFlow: {
+			"compute.images.create",
+			"compute.images.delete",
+			"compute.images.setLabels",
+			"compute.instances.attachDisk",
+			"compute.instances.detachDisk",
+			"compute.instances.stop",
+			"compute.machineImages.create",
+		},
+		OVFExportFlow: {
+			"compute.images.create",
+			"compute.images.delete",
+			"compute.instances.attachDisk",
+			"compute.instances.detachDisk",
+			"compute.instances.start",
+			"compute.instances.stop",
+			"compute.machineImages.get",
+			"compute.machineImages.useReadOnly",
+		},
+	}
+
+	// sourceDiskPermissions are required to export a disk, which is exported from a
+	// temporary snapshot. rootfs-tar's temporary disk is created from the snapshot
+	// with compute.disks.create and compute.snapshots.useReadOnly.
+	sourceDiskPermissions = []string{
+		"compute.disks.createSnapshot",
+		"compute.snapshots.create",
+		"compute.snapshots.delete",
+		"compute.snapshots.get",
+	}
+
+	scratchBucketPermissions = []string{
+		"storage.objects.create",
+		"storage.objects.delete",
+		"storage.objects.get",
+		"storage.objects.list",
+	}
+	sourceBucketPermissions      = []string{"storage.objects.get", "storage.objects.list"}
+	destinationBucketPermissions = []string{"storage.objects.create", "storage.objects.delete"}
+	serviceAccountPermissions    = []string{"iam.serviceAccounts.actAs"}
+)
+
+// PermissionRequest describes a tool invocation, whose IAM permissions are checked by PermissionPreflight.
+type PermissionRequest struct {
+	Flow    Flow
+	Project string
+
+	// ComputeServiceAccount is the service account used by workers. When empty,
+	// the Compute Engine default service account is used.
+	ComputeServiceAccount string
+	NoExternalIP          bool
+
+	// SourceDisk is the disk that's exported, when a disk is exported rather than an
+	// image or snapshot.
+	SourceDisk string
+
+	// ScratchBucketGcsPath, SourceGcsPaths, and DestinationGcsPaths are GCS paths
+	// that the flow writes temporary files to, reads from, and writes results to.
+	ScratchBucketGcsPath string
+	SourceGcsPaths       []string
+	DestinationGcsPaths  []string
+}
+
+// MissingPermission is an IAM permission that is required but not granted on a resource.
+type MissingPermission struct {
+	ResourceType, Resource, Permission string
+}
+
+// PermissionPreflight checks, before any resource is created, that the caller has the
+// IAM permissions required by a flow. Otherwise, permission failures show up late in the
+// flow, for example when a worker writes to the scratch bucket.
+type PermissionPreflight interface {
+	// Check returns an error listing the missing permissions, if any.
+	Check(request PermissionRequest) error
+}
+
+// PermissionTester returns the subset of permissions that the caller has on a resource.
+type PermissionTester interface {
+	TestProjectPermissions(project string, permissions []string) ([]string, error)
+	TestBucketPermissions(bucket string, permissions []string) ([]string, error)
+	TestServiceAccountPermissions(email string, permissions []string) ([]string, error)
+}
+
+// NewPermissionPreflight returns a PermissionPreflight that uses tester to check permissions.
+// client is used to find the Compute Engine default service account.
+func NewPermissionPreflight(client daisyCompute.Client, tester PermissionTester) PermissionPreflight {
+	return &testIamPermissionPreflight{client, tester}
+}
+
+// CreatePermissionPreflight creates the API clients that are required by PermissionPreflight.
+func CreatePermissionPreflight(ctx context.Context, oauth string, client daisyCompute.Client,
+	storageClient domain.StorageClientInterface) (PermissionPreflight, error) {
+	resourceManagerService, err := cloudresourcemanager.NewService(ctx, option.WithCredentialsFile(oauth))
+	if err != nil {
+		return nil, daisy.Errf("failed to create resource manager client: %v", err)
+	}
+	iamService, err := iam.NewService(ctx, option.WithCredentialsFile(oauth))
+	if err != nil {
+		return nil, daisy.Errf("failed to create IAM client: %v", err)
+	}
+	return NewPermissionPreflight(client, &apiPermissionTester{
+		ctx:                    ctx,
+		resourceManagerService: resourceManagerService,
+		iamService:             iamService,
+		storageClient:          storageClient,
+	}), nil
+}
+
+// testIamPermissionPreflight implements PermissionPreflight. Resources whose permissions
+// can't be tested, for example since the resource doesn't exist yet, are skipped.
+type testIamPermissionPreflight struct {
+	client daisyCompute.Client
+	tester PermissionTester
+}
+
+func (p *testIamPermissionPreflight) Check(request PermissionRequest) error {
+	var missing []MissingPermission
+	collect := func(resourceType, resource string, required []string,
+		test func(string, []string) ([]string, error)) {
+		granted, err := test(resource, required)
+		if err != nil {
+			return
+		}
+		for _, permission := range subtract(required, granted) {
+			missing = append(missing, MissingPermission{resourceType, resource, permission})
+		}
+	}
+
+	collect(projectResource, request.Project, projectPermissions(request), p.tester.TestProjectPermissions)
+	buckets := bucketPermissions(request)
+	bucketNames := make([]string, 0, len(buckets))
+	for bucket := range buckets {
+		bucketNames = append(bucketNames, bucket)
+	}
+	sort.Strings(bucketNames)
+	for _, bucket := range bucketNames {
+		collect(bucketResource, bucket, buckets[bucket], p.tester.TestBucketPermissions)
+	}
+	if serviceAccount := p.workerServiceAccount(request); serviceAccount != "" {
+		collect(serviceAccountResource, serviceAccount, serviceAccountPermissions,
+			p.tester.TestServiceAccountPermissions)
+	}
+
+	if len(missing) == 0 {
+		return nil
+	}
+	return daisy.Errf("The caller doesn't have the IAM permissions that are required to run the "+
+		"tool. Grant the following permissions, and then run the tool again:\n\n%s", formatMissingPermissions(missing))
+}
+
+// workerServiceAccount returns the email of the service account used by workers.
+// An empty string is returned when it can't be determined.
+func (p *testIamPermissionPreflight) workerServiceAccount(request PermissionRequest) string {
+	if request.ComputeServiceAccount != "" && request.ComputeServiceAccount != "default" {
+		return request.ComputeServiceAccount
+	}
+	project, err := p.client.GetProject(request.Project)
+	if err != nil || project.Id == 0 {
+		return ""
+	}
+	return fmt.Sprintf("%d-compute@developer.gserviceaccount.com", project.Id)
+}
+
+func projectPermissions(request PermissionRequest) []string {
+	permissions := append([]string{}, workerProjectPermissions...)
+	permissions = append(permissions, flowProjectPermissions[request.Flow]...)
+	if request.SourceDisk != "" {
+		permissions = append(permissions, sourceDiskPermissions...)
+	}
+	if !request.NoExternalIP {
+		permissions = append(permissions, "compute.subnetworks.useExternalIp")
+	}
+	return dedupe(permissions)
+}
+
+// bucketPermissions returns the permissions required on each bucket that the request uses.
+func bucketPermissions(request PermissionRequest) map[string][]string {
+	permissions := map[string][]string{}
+	add := func(gcsPath string, required []string) {
+		bucket, err := storage.GetBucketNameFromGCSPath(gcsPath)
+		if err != nil || bucket == "" {
+			return
+		}
+		permissions[bucket] = dedupe(append(permissions[bucket], required...))
+	}
+	add(request.ScratchBucketGcsPath, scratchBucketPermissions)
+	for _, path := range request.SourceGcsPaths {
+		add(path, sourceBucketPermissions)
+	}
+	for _, path := range request.DestinationGcsPaths {
+		add(path, destinationBucketPermissions)
+	}
+	return permissions
+}
+
+// formatMissingPermissions formats missing permissions as a table with one row per permission.
+func formatMissingPermissions(missing []MissingPermission) string {
+	var buf bytes.Buffer
+	w := tabwriter.NewWriter(&buf, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "RESOURCE TYPE\tRESOURCE\tMISSING PERMISSION")
+	for _, m := range missing {
+		fmt.Fprintf(w, "%s\t%s\t%s\n", m.ResourceType, m.Resource, m.Permission)
+	}
+	w.Flush()
+	return buf.String()
+}
+
+// subtract returns the elements of required that aren't in granted.
+func subtract(required, granted []string) []string {
+	grantedSet := map[string]bool{}
+	for _, permission := range granted {
+		grantedSet[permission] = true
+	}
+	var result []string
+	for _, permission := range required {
+		if !grantedSet[permission] {
+			result = append(result, permission)
+		}
+	}
+	return result
+}
+
+// dedupe returns the unique elements of values in sorted order.
+func dedupe(values []string) []string {
+	set := map[string]bool{}
+	var result []string
+	for _, v := range values {
+		if !set[v] {
+			set[v] = true
+			result = append(result, v)
+		}
+	}
+	sort.Strings(result)
+	return result
+}
+
+// apiPermissionTester implements PermissionTester using the testIamPermissions
+// methods of the Resource Manager, Cloud Storage, and IAM APIs.
+type apiPermissionTester struct {
+	ctx                    context.Context
+	resourceManagerService *cloudresourcemanager.Service
+	iamService             *iam.Service
+	storageClient          domain.StorageClientInterface
+}
+
+func (t *apiPermissionTester) TestProjectPermissions(project string, permissions []string) ([]string, error) {
+	response, err := t.resourceManagerService.Projects.TestIamPermissions(project,
+		&cloudresourcemanager.TestIamPermissionsRequest{Permissions: permissions}).Do()
+	if err != nil {
+		return nil, err
+	}
+	return response.Permissions, nil
+}
+
+func (t *apiPermissionTester) TestBucketPermissions(bucket string, permissions []string) ([]string, error) {
+	return t.storageClient.GetBucket(bucket).IAM().TestPermissions(t.ctx, permissions)
+}
+
+func (t *apiPermissionTester) TestServiceAccountPermissions(email string, permissions []string) ([]string, error) {
+	response, err := t.iamService.Projects.ServiceAccounts.TestIamPermissions(
+		fmt.Sprintf("projects/-/serviceAccounts/%s", email),
+		&iam.TestIamPermissionsRequest{Permissions: permissions}).Do()
+	if err != nil {
+		return nil, err
+	}
+	return response.Permissions, nil
+}
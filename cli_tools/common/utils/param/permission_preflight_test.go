@@ -0,0 +1,157 @@
+//  Copyright 2026 Google Inc. All Rights Reserved.
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+package param
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/api/compute/v1"
+
+	"github.com/GoogleCloudPlatform/compute-image-import/cli_tools/mocks"
+)
+
+// fakePermissionTester grants all permissions, except for those in missing.
+type fakePermissionTester struct {
+	missing map[string][]string
+	err     map[string]error
+	tested  map[string][]string
+}
+
+func (t *fakePermissionTester) test(resource string, permissions []string) ([]string, error) {
+	if t.tested == nil {
+		t.tested = map[string][]string{}
+	}
+	t.tested[resource] = permissions
+	if err := t.err[resource]; err != nil {
+		return nil, err
+	}
+	return subtract(permissions, t.missing[resource]), nil
+}
+
+func (t *fakePermissionTester) TestProjectPermissions(project string, permissions []string) ([]string, error) {
+	return t.test(project, permissions)
+}
+
+func (t *fakePermissionTester) TestBucketPermissions(bucket string, permissions []string) ([]string, error) {
+	return t.test(bucket, permissions)
+}
+
+func (t *fakePermissionTester) TestServiceAccountPermissions(email string, permissions []string) ([]string, error) {
+	return t.test(email, permissions)
+}
+
+func TestPermissionPreflight_PassesWhenAllPermissionsGranted(t *testing.T) {
+	tester := &fakePermissionTester{}
+	preflight := NewPermissionPreflight(nil, tester)
+
+	assert.NoError(t, preflight.Check(PermissionRequest{
+		Flow:                  ImageImportFlow,
+		Project:               "project-id",
+		ComputeServiceAccount: "sa@project-id.iam.gserviceaccount.com",
+		ScratchBucketGcsPath:  "gs://scratch/dir",
+		SourceGcsPaths:        []string{"gs://source/disk.vmdk"},
+	}))
+	assert.Contains(t, tester.tested["project-id"], "compute.images.create")
+	assert.Contains(t, tester.tested["project-id"], "compute.subnetworks.useExternalIp")
+	assert.Equal(t, []string{"storage.objects.create", "storage.objects.delete", "storage.objects.get",
+		"storage.objects.list"}, tester.tested["scratch"])
+	assert.Equal(t, []string{"storage.objects.get", "storage.objects.list"}, tester.tested["source"])
+	assert.Equal(t, []string{"iam.serviceAccounts.actAs"}, tester.tested["sa@project-id.iam.gserviceaccount.com"])
+}
+
+func TestPermissionPreflight_ReportsMissingPermissionsAsTable(t *testing.T) {
+	tester := &fakePermissionTester{missing: map[string][]string{
+		"project-id":                            {"compute.snapshots.useReadOnly"},
+		"dest":                                  {"storage.objects.create"},
+		"sa@project-id.iam.gserviceaccount.com": {"iam.serviceAccounts.actAs"},
+	}}
+	preflight := NewPermissionPreflight(nil, tester)
+
+	err := preflight.Check(PermissionRequest{
+		Flow:                  ImageExportFlow,
+		Project:               "project-id",
+		ComputeServiceAccount: "sa@project-id.iam.gserviceaccount.com",
+		ScratchBucketGcsPath:  "gs://scratch/dir",
+		DestinationGcsPaths:   []string{"gs://dest/disk.vmdk"},
+		NoExternalIP:          true,
+	})
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "RESOURCE TYPE    RESOURCE                               MISSING PERMISSION\n"+
+		"project          project-id                             compute.snapshots.useReadOnly\n"+
+		"bucket           dest                                   storage.objects.create\n"+
+		"service account  sa@project-id.iam.gserviceaccount.com  iam.serviceAccounts.actAs\n")
+	assert.NotContains(t, tester.tested["project-id"], "compute.subnetworks.useExternalIp")
+}
+
+func TestPermissionPreflight_ChecksDefaultComputeServiceAccount(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	mockComputeClient := mocks.NewMockClient(mockCtrl)
+	mockComputeClient.EXPECT().GetProject("project-id").Return(&compute.Project{Id: 1234}, nil)
+	tester := &fakePermissionTester{missing: map[string][]string{
+		"1234-compute@developer.gserviceaccount.com": {"iam.serviceAccounts.actAs"},
+	}}
+
+	err := NewPermissionPreflight(mockComputeClient, tester).Check(PermissionRequest{
+		Flow:    OVFImportFlow,
+		Project: "project-id",
+	})
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "1234-compute@developer.gserviceaccount.com  iam.serviceAccounts.actAs")
+}
+
+func TestPermissionPreflight_SkipsResourcesThatCantBeTested(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	mockComputeClient := mocks.NewMockClient(mockCtrl)
+	mockComputeClient.EXPECT().GetProject("project-id").Return(nil, errors.New("forbidden"))
+	tester := &fakePermissionTester{
+		missing: map[string][]string{"scratch": {"storage.objects.create"}},
+		err:     map[string]error{"scratch": errors.New("bucket not found")},
+	}
+
+	assert.NoError(t, NewPermissionPreflight(mockComputeClient, tester).Check(PermissionRequest{
+		Flow:                 OVFExportFlow,
+		Project:              "project-id",
+		ScratchBucketGcsPath: "gs://scratch/dir",
+	}))
+}
+
+func TestPermissionPreflight_FlowPermissions(t *testing.T) {
+	for flow, expected := range map[Flow]string{
+		ImageImportFlow: "compute.images.create",
+		ImageExportFlow: "compute.snapshots.useReadOnly",
+		OVFImportFlow:   "compute.machineImages.create",
+		OVFExportFlow:   "compute.instances.stop",
+	} {
+		permissions := projectPermissions(PermissionRequest{Flow: flow})
+		assert.Contains(t, permissions, expected)
+		assert.Contains(t, permissions, "compute.instances.create")
+	}
+}
+
+func TestPermissionPreflight_SourceDiskPermissions(t *testing.T) {
+	permissions := projectPermissions(PermissionRequest{Flow: ImageExportFlow})
+	assert.NotContains(t, permissions, "compute.disks.createSnapshot")
+
+	permissions = projectPermissions(PermissionRequest{Flow: ImageExportFlow, SourceDisk: "zones/us-west1-a/disks/disk"})
+	for _, expected := range []string{"compute.disks.createSnapshot", "compute.snapshots.create",
+		"compute.snapshots.delete", "compute.snapshots.get", "compute.disks.create"} {
+		assert.Contains(t, permissions, expected)
+	}
+}
@@ -85,8 +85,8 @@ func (p *populator) PopulateMissingParameters(project *string, clientID string,
 		return err
 	}
 
-	if workerMachineSeries == nil || len(*workerMachineSeries) == 0 {
-		*workerMachineSeries, err = p.workerMachineSeriesDetector.Detect(*project, *zone)
+	if workerMachineSeries != nil {
+		*workerMachineSeries, err = p.workerMachineSeriesDetector.Detect(*project, *zone, *workerMachineSeries)
 		if err != nil {
 			return err
 		}
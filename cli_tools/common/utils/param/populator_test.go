@@ -620,6 +620,6 @@ func newNoOpNetworkResolver(ctrl *gomock.Controller) NetworkResolver {
 
 func newMockN2N1MachineSeriesDetector(ctrl *gomock.Controller) MachineSeriesDetector {
 	m := mocks.NewMockMachineSeriesDetector(ctrl)
-	m.EXPECT().Detect(gomock.Any(), gomock.Any()).AnyTimes().Return([]string{"n2", "n1"}, nil)
+	m.EXPECT().Detect(gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes().Return([]string{"n2", "n1"}, nil)
 	return m
 }
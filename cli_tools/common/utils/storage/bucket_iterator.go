@@ -25,9 +25,18 @@ import (
 type BucketIteratorCreator struct {
 }
 
+// bucketIteratorProvider is implemented by storage clients that can't create a
+// storage.BucketIterator, such as LocalClient.
+type bucketIteratorProvider interface {
+	BucketIterator(projectID string) domain.BucketIteratorInterface
+}
+
 // CreateBucketIterator creates GCS bucket iterator
 func (bic *BucketIteratorCreator) CreateBucketIterator(ctx context.Context,
 	storageClient domain.StorageClientInterface, projectID string) domain.BucketIteratorInterface {
+	if provider, ok := storageClient.(bucketIteratorProvider); ok {
+		return provider.BucketIterator(projectID)
+	}
 	return &BucketIterator{storageClient.Buckets(projectID)}
 }
 
@@ -0,0 +1,573 @@
+//  Copyright 2026 Google Inc. All Rights Reserved.
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"cloud.google.com/go/storage"
+	daisy "github.com/GoogleCloudPlatform/compute-daisy"
+	"google.golang.org/api/iterator"
+	"google.golang.org/api/option"
+
+	"github.com/GoogleCloudPlatform/compute-image-import/cli_tools/common/domain"
+	"github.com/GoogleCloudPlatform/compute-image-import/cli_tools/common/utils/logging"
+)
+
+const (
+	// LocalStorageBackendPrefix is the prefix of storage backends that map buckets and
+	// objects onto a local directory, for example: file:///tmp/gcs
+	LocalStorageBackendPrefix = "file://"
+
+	// localBucketAttrsDir holds the attributes of each bucket. Bucket names that start
+	// with a period are rejected, so it doesn't conflict with a bucket's directory.
+	localBucketAttrsDir = ".buckets"
+)
+
+// LocalClient implements domain.StorageClientInterface using a local directory. Each
+// bucket is a directory in the root directory, and each object is a file whose path
+// relative to the bucket's directory is the object's name.
+//
+// Methods that return handles from cloud.google.com/go/storage, such as GetBucket,
+// return handles that identify the bucket or object, but that can't be used to call
+// the Cloud Storage API.
+type LocalClient struct {
+	root   string
+	logger logging.Logger
+	ctx    context.Context
+
+	// handles creates bucket and object handles. It's never used to send requests.
+	handles *storage.Client
+}
+
+// NewLocalStorageClient creates a LocalClient that stores buckets in root. The root
+// directory is created if it doesn't exist.
+func NewLocalStorageClient(ctx context.Context, logger logging.Logger, root string) (*LocalClient, error) {
+	if err := os.MkdirAll(root, 0755); err != nil {
+		return nil, daisy.Errf("error creating storage directory `%v`: %v", root, err)
+	}
+	handles, err := storage.NewClient(ctx, option.WithoutAuthentication(), option.WithEndpoint("http://localhost:0/"))
+	if err != nil {
+		return nil, daisy.Errf("error creating storage client: %v", err)
+	}
+	return &LocalClient{root: root, logger: logger, ctx: ctx, handles: handles}, nil
+}
+
+// NewStorageClientForBackend creates a storage client for backend. When backend is
+// empty or "gs://", a Cloud Storage client is created using storageOptions. When
+// backend starts with "file://", a LocalClient is created in the directory that follows.
+func NewStorageClientForBackend(ctx context.Context, logger logging.Logger, backend string,
+	storageOptions ...option.ClientOption) (domain.StorageClientInterface, error) {
+	switch {
+	case backend == "" || backend == "gs://":
+		return NewStorageClient(ctx, logger, storageOptions...)
+	case strings.HasPrefix(backend, LocalStorageBackendPrefix) && len(backend) > len(LocalStorageBackendPrefix):
+		return NewLocalStorageClient(ctx, logger, strings.TrimPrefix(backend, LocalStorageBackendPrefix))
+	default:
+		return nil, daisy.Errf("storage backend %q is not supported. Use gs:// or file://<directory>.", backend)
+	}
+}
+
+// IsLocalStorageBackend returns whether backend maps buckets and objects onto a
+// local directory. Daisy workflows always use Cloud Storage, so tools only accept a
+// local backend when workflows are written but not run.
+func IsLocalStorageBackend(backend string) bool {
+	return strings.HasPrefix(backend, LocalStorageBackendPrefix)
+}
+
+// CreateBucket creates a bucket directory, and records attrs.
+func (c *LocalClient) CreateBucket(bucketName string, project string, attrs *storage.BucketAttrs) error {
+	if err := c.checkBucketName(bucketName); err != nil {
+		return err
+	}
+	if _, err := os.Stat(c.bucketDir(bucketName)); err == nil {
+		return daisy.Errf("Error creating bucket `%v` in project `%v`: bucket already exists", bucketName, project)
+	}
+	if err := os.MkdirAll(c.bucketDir(bucketName), 0755); err != nil {
+		return daisy.Errf("Error creating bucket `%v` in project `%v`: %v", bucketName, project, err)
+	}
+	stored := storage.BucketAttrs{}
+	if attrs != nil {
+		stored = *attrs
+	}
+	stored.Name = bucketName
+	content, err := json.Marshal(localBucketAttrs{Project: project, Attrs: stored})
+	if err == nil {
+		err = os.MkdirAll(filepath.Join(c.root, localBucketAttrsDir), 0755)
+	}
+	if err == nil {
+		err = os.WriteFile(c.bucketAttrsFile(bucketName), content, 0644)
+	}
+	if err != nil {
+		return daisy.Errf("Error creating bucket `%v` in project `%v`: %v", bucketName, project, err)
+	}
+	return nil
+}
+
+// localBucketAttrs is stored in localBucketAttrsDir for each bucket.
+type localBucketAttrs struct {
+	Project string
+	Attrs   storage.BucketAttrs
+}
+
+// Buckets returns an iterator from the Cloud Storage client, since
+// storage.BucketIterator can't be created for local buckets. Use BucketIterator instead.
+func (c *LocalClient) Buckets(projectID string) *storage.BucketIterator {
+	return c.handles.Buckets(c.ctx, projectID)
+}
+
+// BucketIterator returns an iterator over the buckets of projectID. Buckets that were
+// created outside of CreateBucket are included in every project.
+func (c *LocalClient) BucketIterator(projectID string) domain.BucketIteratorInterface {
+	entries, err := os.ReadDir(c.root)
+	if err != nil {
+		return &localBucketIterator{err: err}
+	}
+	it := &localBucketIterator{}
+	for _, entry := range entries {
+		if !entry.IsDir() || strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+		stored, err := c.readBucketAttrs(entry.Name())
+		if err != nil {
+			return &localBucketIterator{err: err}
+		}
+		if stored.Project == "" || stored.Project == projectID {
+			attrs := stored.Attrs
+			it.buckets = append(it.buckets, &attrs)
+		}
+	}
+	return it
+}
+
+// GetBucket returns a handle for bucket.
+func (c *LocalClient) GetBucket(bucket string) *storage.BucketHandle {
+	return c.handles.Bucket(bucket)
+}
+
+// GetBucketAttrs returns the attributes of a bucket.
+func (c *LocalClient) GetBucketAttrs(bucket string) (*storage.BucketAttrs, error) {
+	if err := c.checkBucketName(bucket); err != nil {
+		return nil, err
+	}
+	if _, err := os.Stat(c.bucketDir(bucket)); err != nil {
+		return nil, daisy.Errf("Error getting bucket attributes for bucket `%v`: %v", bucket, storage.ErrBucketNotExist)
+	}
+	stored, err := c.readBucketAttrs(bucket)
+	if err != nil {
+		return nil, daisy.Errf("Error getting bucket attributes for bucket `%v`: %v", bucket, err)
+	}
+	return &stored.Attrs, nil
+}
+
+// GetObject returns the object at objectPath in bucket.
+func (c *LocalClient) GetObject(bucket string, objectPath string) domain.StorageObject {
+	return &localObject{client: c, bucket: bucket, name: objectPath}
+}
+
+// GetObjects returns an iterator over the objects in bucket whose names start
+// with objectPath, in lexicographical order.
+func (c *LocalClient) GetObjects(bucket string, objectPath string) domain.ObjectIteratorInterface {
+	if err := c.checkBucketName(bucket); err != nil {
+		return &localObjectIterator{err: err}
+	}
+	bucketDir := c.bucketDir(bucket)
+	if _, err := os.Stat(bucketDir); err != nil {
+		return &localObjectIterator{err: storage.ErrBucketNotExist}
+	}
+	it := &localObjectIterator{}
+	err := filepath.Walk(bucketDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() || strings.HasPrefix(info.Name(), localTempPrefix) {
+			return nil
+		}
+		rel, err := filepath.Rel(bucketDir, path)
+		if err != nil {
+			return err
+		}
+		name := filepath.ToSlash(rel)
+		if strings.HasPrefix(name, objectPath) {
+			it.objects = append(it.objects, objectAttrs(bucket, name, info))
+		}
+		return nil
+	})
+	if err != nil {
+		return &localObjectIterator{err: err}
+	}
+	sort.Slice(it.objects, func(i, j int) bool { return it.objects[i].Name < it.objects[j].Name })
+	return it
+}
+
+// GetObjectAttrs returns the attributes of an object.
+func (c *LocalClient) GetObjectAttrs(bucket string, objectPath string) (*storage.ObjectAttrs, error) {
+	file, err := c.objectFile(bucket, objectPath)
+	if err != nil {
+		return nil, err
+	}
+	info, err := os.Stat(file)
+	if err != nil || info.IsDir() {
+		return nil, daisy.Errf("Error getting object attributes for object `%v\\%v`: %v", bucket, objectPath, storage.ErrObjectNotExist)
+	}
+	return objectAttrs(bucket, objectPath, info), nil
+}
+
+// FindGcsFile finds a file in a directory path for given file extension. File extension can
+// be a file name as well. The lookup is done recursively.
+func (c *LocalClient) FindGcsFile(gcsDirectoryPath string, fileExtension string) (*storage.ObjectHandle, error) {
+	return c.FindGcsFileDepthLimited(gcsDirectoryPath, fileExtension, -1)
+}
+
+// FindGcsFileDepthLimited finds a file in a directory path for given file
+// extension up to lookupDepth deep. See Client.FindGcsFileDepthLimited.
+func (c *LocalClient) FindGcsFileDepthLimited(gcsDirectoryPath string, fileExtension string, lookupDepth int) (*storage.ObjectHandle, error) {
+	bucketName, lookupPath, err := SplitGCSPath(gcsDirectoryPath)
+	if err != nil {
+		return nil, err
+	}
+	it := c.GetObjects(bucketName, lookupPath)
+	for {
+		attrs, err := it.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, daisy.Errf("Error finding file with extension `%v` in Cloud Storage directory `%v`: %v", fileExtension, gcsDirectoryPath, err)
+		}
+		if !isDepthValid(lookupDepth, lookupPath, attrs.Name) || !strings.HasSuffix(attrs.Name, fileExtension) {
+			continue
+		}
+		c.logger.User(fmt.Sprintf("Found gs://%v/%v", bucketName, attrs.Name))
+		return c.GetBucket(bucketName).Object(attrs.Name), nil
+	}
+	return nil, daisy.Errf(
+		"path %v doesn't contain a file with %v extension", gcsDirectoryPath, fileExtension)
+}
+
+// GetGcsFileContent returns the content of the object that gcsObject identifies.
+func (c *LocalClient) GetGcsFileContent(gcsObject *storage.ObjectHandle) ([]byte, error) {
+	file, err := c.objectFile(gcsObject.BucketName(), gcsObject.ObjectName())
+	if err != nil {
+		return nil, err
+	}
+	content, err := os.ReadFile(file)
+	if err != nil {
+		return nil, daisy.Errf("Error getting Cloud Storage file content: %v", storage.ErrObjectNotExist)
+	}
+	return content, nil
+}
+
+// WriteToGCS writes content from a reader to destination bucket and path.
+func (c *LocalClient) WriteToGCS(destinationBucketName string, destinationObjectPath string, reader io.Reader) error {
+	writer := &localObjectWriter{object: &localObject{client: c, bucket: destinationBucketName, name: destinationObjectPath}}
+	if _, err := io.Copy(writer, reader); err != nil {
+		writer.abort()
+		return daisy.Errf("Error writing to Cloud Storage file path `%v` in bucket `%v`: %v", destinationObjectPath, destinationBucketName, err)
+	}
+	return writer.Close()
+}
+
+// DeleteGcsPath deletes all objects whose names start with the path's object prefix.
+func (c *LocalClient) DeleteGcsPath(gcsPath string) error {
+	bucketName, objectPath, err := SplitGCSPath(gcsPath)
+	if err != nil {
+		return err
+	}
+	it := c.GetObjects(bucketName, objectPath)
+	for {
+		attrs, err := it.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return daisy.Errf("Error deleting Cloud Storage path `%v`: %v", gcsPath, err)
+		}
+		c.logger.User(fmt.Sprintf("Deleting gs://%v/%v", bucketName, attrs.Name))
+		if err := c.GetObject(bucketName, attrs.Name).Delete(); err != nil {
+			return daisy.Errf("Error deleting Cloud Storage object `%v` in bucket `%v`: %v", attrs.Name, bucketName, err)
+		}
+	}
+	return nil
+}
+
+// DeleteObject deletes the object with the path `gcsPath`.
+func (c *LocalClient) DeleteObject(gcsPath string) error {
+	bucketName, objectPath, err := SplitGCSPath(gcsPath)
+	if err != nil {
+		return daisy.Errf("Error deleting `%v`: `%v`", gcsPath, err)
+	}
+	if err := c.GetObject(bucketName, objectPath).Delete(); err != nil {
+		return daisy.Errf("Error deleting `%v`: `%v`", gcsPath, err)
+	}
+	return nil
+}
+
+// Close closes the LocalClient.
+func (c *LocalClient) Close() error {
+	return c.handles.Close()
+}
+
+func (c *LocalClient) bucketDir(bucket string) string {
+	return filepath.Join(c.root, bucket)
+}
+
+func (c *LocalClient) bucketAttrsFile(bucket string) string {
+	return filepath.Join(c.root, localBucketAttrsDir, bucket+".json")
+}
+
+// checkBucketName rejects bucket names that aren't a single directory in the root
+// directory, and names that start with a period, which could collide with
+// localBucketAttrsDir or the root directory itself.
+func (c *LocalClient) checkBucketName(bucket string) error {
+	if bucket == "" || strings.HasPrefix(bucket, ".") ||
+		filepath.Dir(c.bucketDir(bucket)) != filepath.Clean(c.root) {
+		return daisy.Errf("invalid bucket name `%v`", bucket)
+	}
+	return nil
+}
+
+// objectFile returns the file that holds object. Names that resolve to a file outside
+// of the bucket's directory, such as those containing "../", are rejected.
+func (c *LocalClient) objectFile(bucket, object string) (string, error) {
+	if err := c.checkBucketName(bucket); err != nil {
+		return "", err
+	}
+	bucketDir := c.bucketDir(bucket)
+	file := filepath.Join(bucketDir, filepath.FromSlash(object))
+	if !strings.HasPrefix(file, bucketDir+string(filepath.Separator)) {
+		return "", daisy.Errf("invalid object name `%v` in bucket `%v`", object, bucket)
+	}
+	return file, nil
+}
+
+// readBucketAttrs returns the attributes that were stored when bucket was created, or
+// only its name when bucket's directory was created outside of CreateBucket.
+func (c *LocalClient) readBucketAttrs(bucket string) (localBucketAttrs, error) {
+	content, err := os.ReadFile(c.bucketAttrsFile(bucket))
+	if errors.Is(err, os.ErrNotExist) {
+		return localBucketAttrs{Attrs: storage.BucketAttrs{Name: bucket}}, nil
+	}
+	if err != nil {
+		return localBucketAttrs{}, err
+	}
+	var stored localBucketAttrs
+	if err := json.Unmarshal(content, &stored); err != nil {
+		return localBucketAttrs{}, err
+	}
+	return stored, nil
+}
+
+func objectAttrs(bucket, name string, info os.FileInfo) *storage.ObjectAttrs {
+	return &storage.ObjectAttrs{
+		Bucket:  bucket,
+		Name:    name,
+		Size:    info.Size(),
+		Created: info.ModTime(),
+		Updated: info.ModTime(),
+	}
+}
+
+// localTempPrefix is the prefix of files that hold objects while they're written.
+const localTempPrefix = ".writing-"
+
+// localObject implements domain.StorageObject for LocalClient.
+type localObject struct {
+	client *LocalClient
+	bucket string
+	name   string
+}
+
+// Delete deletes the object, along with directories that become empty.
+func (o *localObject) Delete() error {
+	file, err := o.client.objectFile(o.bucket, o.name)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(file); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return storage.ErrObjectNotExist
+		}
+		return err
+	}
+	bucketDir := o.client.bucketDir(o.bucket)
+	for dir := filepath.Dir(file); dir != bucketDir && strings.HasPrefix(dir, bucketDir); dir = filepath.Dir(dir) {
+		if os.Remove(dir) != nil {
+			break
+		}
+	}
+	return nil
+}
+
+// GetObjectHandle returns a handle that identifies the object.
+func (o *localObject) GetObjectHandle() *storage.ObjectHandle {
+	return o.client.GetBucket(o.bucket).Object(o.name)
+}
+
+// NewReader creates a new Reader to read the contents of the object.
+func (o *localObject) NewReader() (io.ReadCloser, error) {
+	file, err := o.client.objectFile(o.bucket, o.name)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(file)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, storage.ErrObjectNotExist
+	}
+	return f, err
+}
+
+// NewWriter creates a new Writer to write to the object. As with Cloud Storage, the
+// object is replaced when the writer is closed.
+func (o *localObject) NewWriter() io.WriteCloser {
+	return &localObjectWriter{object: o}
+}
+
+// ObjectName returns the name of the object.
+func (o *localObject) ObjectName() string {
+	return o.name
+}
+
+// Compose concatenates srcs into the object. Sources can be from any backend.
+func (o *localObject) Compose(srcs ...domain.StorageObject) (*storage.ObjectAttrs, error) {
+	writer := &localObjectWriter{object: o}
+	for _, src := range srcs {
+		reader, err := src.NewReader()
+		if err != nil {
+			writer.abort()
+			return nil, err
+		}
+		_, err = io.Copy(writer, reader)
+		reader.Close()
+		if err != nil {
+			writer.abort()
+			return nil, err
+		}
+	}
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+	return o.client.GetObjectAttrs(o.bucket, o.name)
+}
+
+// CopyFrom copies src into the object.
+func (o *localObject) CopyFrom(src domain.StorageObject) (*storage.ObjectAttrs, error) {
+	return o.Compose(src)
+}
+
+// localObjectWriter writes to a temporary file, which replaces the object when the
+// writer is closed.
+type localObjectWriter struct {
+	object *localObject
+	file   *os.File
+	err    error
+}
+
+func (w *localObjectWriter) Write(p []byte) (int, error) {
+	if w.file == nil && w.err == nil {
+		var dest string
+		if dest, w.err = w.object.client.objectFile(w.object.bucket, w.object.name); w.err != nil {
+			return 0, w.err
+		}
+		if _, err := os.Stat(w.object.client.bucketDir(w.object.bucket)); err != nil {
+			w.err = storage.ErrBucketNotExist
+		} else if w.err = os.MkdirAll(filepath.Dir(dest), 0755); w.err == nil {
+			w.file, w.err = os.CreateTemp(filepath.Dir(dest), localTempPrefix)
+		}
+	}
+	if w.err != nil {
+		return 0, w.err
+	}
+	return w.file.Write(p)
+}
+
+func (w *localObjectWriter) Close() error {
+	if w.file == nil && w.err == nil {
+		// Empty objects are created on close.
+		if _, err := w.Write(nil); err != nil {
+			return err
+		}
+	}
+	if w.err != nil {
+		return w.err
+	}
+	if err := w.file.Close(); err != nil {
+		os.Remove(w.file.Name())
+		return err
+	}
+	dest, err := w.object.client.objectFile(w.object.bucket, w.object.name)
+	if err == nil {
+		err = os.Rename(w.file.Name(), dest)
+	}
+	if err != nil {
+		os.Remove(w.file.Name())
+	}
+	return err
+}
+
+// abort closes the writer and removes its temporary file, leaving the object unchanged.
+func (w *localObjectWriter) abort() {
+	if w.file != nil {
+		w.file.Close()
+		os.Remove(w.file.Name())
+	}
+	w.err = errors.New("writer is aborted")
+}
+
+// localObjectIterator implements domain.ObjectIteratorInterface for LocalClient.
+type localObjectIterator struct {
+	objects []*storage.ObjectAttrs
+	err     error
+}
+
+// Next returns the next object, or iterator.Done when there are no more objects.
+func (it *localObjectIterator) Next() (*storage.ObjectAttrs, error) {
+	if it.err != nil {
+		return nil, it.err
+	}
+	if len(it.objects) == 0 {
+		return nil, iterator.Done
+	}
+	next := it.objects[0]
+	it.objects = it.objects[1:]
+	return next, nil
+}
+
+// localBucketIterator implements domain.BucketIteratorInterface for LocalClient.
+type localBucketIterator struct {
+	buckets []*storage.BucketAttrs
+	err     error
+}
+
+// Next returns the next bucket, or iterator.Done when there are no more buckets.
+func (it *localBucketIterator) Next() (*storage.BucketAttrs, error) {
+	if it.err != nil {
+		return nil, it.err
+	}
+	if len(it.buckets) == 0 {
+		return nil, iterator.Done
+	}
+	next := it.buckets[0]
+	it.buckets = it.buckets[1:]
+	return next, nil
+}
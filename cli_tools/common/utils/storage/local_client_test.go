@@ -0,0 +1,244 @@
+//  Copyright 2026 Google Inc. All Rights Reserved.
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+package storage
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"cloud.google.com/go/storage"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/api/iterator"
+
+	"github.com/GoogleCloudPlatform/compute-image-import/cli_tools/common/domain"
+	"github.com/GoogleCloudPlatform/compute-image-import/cli_tools/common/utils/logging"
+)
+
+func TestLocalClient_WriteReadAndIterate(t *testing.T) {
+	c := newLocalClient(t)
+	assert.NoError(t, c.CreateBucket("bucket", "project", &storage.BucketAttrs{Location: "US"}))
+	writeLocalObject(t, c, "bucket", "dir/b.txt", "b")
+	writeLocalObject(t, c, "bucket", "dir/a.txt", "a")
+	writeLocalObject(t, c, "bucket", "dir-2/c.txt", "c")
+
+	assert.Equal(t, []string{"dir-2/c.txt", "dir/a.txt", "dir/b.txt"}, objectNames(t, c.GetObjects("bucket", "dir")))
+	assert.Equal(t, []string{"dir/a.txt", "dir/b.txt"}, objectNames(t, c.GetObjects("bucket", "dir/")))
+
+	attrs, err := c.GetObjectAttrs("bucket", "dir/a.txt")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), attrs.Size)
+	_, err = c.GetObjectAttrs("bucket", "dir/missing.txt")
+	assert.Error(t, err)
+
+	bucketAttrs, err := c.GetBucketAttrs("bucket")
+	assert.NoError(t, err)
+	assert.Equal(t, "US", bucketAttrs.Location)
+	assert.Error(t, c.CreateBucket("bucket", "project", nil))
+}
+
+func TestLocalClient_ComposeAndCopy(t *testing.T) {
+	c := newLocalClient(t)
+	assert.NoError(t, c.CreateBucket("bucket", "project", nil))
+	writeLocalObject(t, c, "bucket", "part-1", "hello ")
+	writeLocalObject(t, c, "bucket", "part-2", "world")
+
+	attrs, err := c.GetObject("bucket", "composed").Compose(c.GetObject("bucket", "part-1"), c.GetObject("bucket", "part-2"))
+	assert.NoError(t, err)
+	assert.Equal(t, int64(11), attrs.Size)
+	_, err = c.GetObject("bucket", "copy").CopyFrom(c.GetObject("bucket", "composed"))
+	assert.NoError(t, err)
+	assert.Equal(t, "hello world", readLocalObject(t, c, "bucket", "copy"))
+}
+
+func TestLocalClient_FailedWritesLeaveNoTemporaryFiles(t *testing.T) {
+	c := newLocalClient(t)
+	assert.NoError(t, c.CreateBucket("bucket", "project", nil))
+	writeLocalObject(t, c, "bucket", "part-1", "hello ")
+	writeLocalObject(t, c, "bucket", "composed", "original")
+
+	_, err := c.GetObject("bucket", "composed").Compose(c.GetObject("bucket", "part-1"), c.GetObject("bucket", "missing"))
+	assert.Error(t, err)
+	assert.Error(t, c.WriteToGCS("bucket", "composed", io.MultiReader(strings.NewReader("partial"), failingReader{})))
+
+	assert.Equal(t, "original", readLocalObject(t, c, "bucket", "composed"))
+	temporaryFiles, err := filepath.Glob(filepath.Join(c.root, "bucket", localTempPrefix+"*"))
+	assert.NoError(t, err)
+	assert.Empty(t, temporaryFiles)
+}
+
+func TestLocalClient_RejectsBucketNamesStartingWithPeriod(t *testing.T) {
+	c := newLocalClient(t)
+	for _, bucket := range []string{localBucketAttrsDir, ".bucket", ""} {
+		t.Run(bucket, func(t *testing.T) {
+			assert.Error(t, c.CreateBucket(bucket, "project", nil))
+			_, err := c.GetBucketAttrs(bucket)
+			assert.Error(t, err)
+			_, err = c.GetObjects(bucket, "").Next()
+			assert.Error(t, err)
+			assert.NotEqual(t, iterator.Done, err)
+			assert.Error(t, c.WriteToGCS(bucket, "object", strings.NewReader("content")))
+		})
+	}
+}
+
+func TestLocalClient_FindGcsFileAndContent(t *testing.T) {
+	c := newLocalClient(t)
+	assert.NoError(t, c.CreateBucket("bucket", "project", nil))
+	writeLocalObject(t, c, "bucket", "ovf/nested/other.ovf", "nested")
+	writeLocalObject(t, c, "bucket", "ovf/vm.ovf", "descriptor")
+
+	handle, err := c.FindGcsFileDepthLimited("gs://bucket/ovf/", ".ovf", 0)
+	assert.NoError(t, err)
+	assert.Equal(t, "ovf/vm.ovf", handle.ObjectName())
+	content, err := c.GetGcsFileContent(handle)
+	assert.NoError(t, err)
+	assert.Equal(t, "descriptor", string(content))
+
+	_, err = c.FindGcsFileDepthLimited("gs://bucket/ovf/", ".vmdk", -1)
+	assert.Error(t, err)
+}
+
+func TestLocalClient_DeleteGcsPath(t *testing.T) {
+	c := newLocalClient(t)
+	assert.NoError(t, c.CreateBucket("bucket", "project", nil))
+	writeLocalObject(t, c, "bucket", "scratch/a/1", "1")
+	writeLocalObject(t, c, "bucket", "scratch/b/2", "2")
+	writeLocalObject(t, c, "bucket", "keep", "3")
+
+	assert.NoError(t, c.DeleteGcsPath("gs://bucket/scratch"))
+	assert.Equal(t, []string{"keep"}, objectNames(t, c.GetObjects("bucket", "")))
+	_, err := os.Stat(filepath.Join(c.root, "bucket", "scratch"))
+	assert.True(t, os.IsNotExist(err), "empty directories are removed")
+	assert.Equal(t, storage.ErrObjectNotExist, c.GetObject("bucket", "missing").Delete())
+}
+
+func TestLocalClient_RejectsObjectsOutsideOfBucket(t *testing.T) {
+	c := newLocalClient(t)
+	assert.NoError(t, c.CreateBucket("bucket", "project", nil))
+	assert.NoError(t, c.CreateBucket("other", "project", nil))
+	writeLocalObject(t, c, "other", "secret", "secret")
+	outside := filepath.Join(filepath.Dir(c.root), "outside")
+
+	for _, name := range []string{"../other/secret", "dir/../../other/secret", "../../outside", "..", ""} {
+		t.Run(name, func(t *testing.T) {
+			assert.Error(t, c.WriteToGCS("bucket", name, strings.NewReader("overwritten")))
+			_, err := c.GetObjectAttrs("bucket", name)
+			assert.Error(t, err)
+			_, err = c.GetObject("bucket", name).NewReader()
+			assert.Error(t, err)
+			_, err = c.GetGcsFileContent(c.GetBucket("bucket").Object(name))
+			assert.Error(t, err)
+			assert.Error(t, c.GetObject("bucket", name).Delete())
+		})
+	}
+	assert.Equal(t, "secret", readLocalObject(t, c, "other", "secret"))
+	_, err := os.Stat(outside)
+	assert.True(t, os.IsNotExist(err))
+
+	_, err = c.GetObjectAttrs("..", "secret")
+	assert.Error(t, err)
+	assert.Error(t, c.WriteToGCS("../bucket", "object", strings.NewReader("content")))
+}
+
+func TestLocalClient_BucketIteratorFiltersByProject(t *testing.T) {
+	c := newLocalClient(t)
+	assert.NoError(t, c.CreateBucket("mine", "project", nil))
+	assert.NoError(t, c.CreateBucket("theirs", "other-project", nil))
+
+	it := (&BucketIteratorCreator{}).CreateBucketIterator(context.Background(), c, "project")
+	first, err := it.Next()
+	assert.NoError(t, err)
+	assert.Equal(t, "mine", first.Name)
+	_, err = it.Next()
+	assert.Equal(t, iterator.Done, err)
+}
+
+func TestLocalClient_TarGcsExtractor(t *testing.T) {
+	c := newLocalClient(t)
+	assert.NoError(t, c.CreateBucket("bucket", "project", nil))
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	for name, content := range map[string]string{"vm.ovf": "descriptor", "disk.vmdk": "disk"} {
+		assert.NoError(t, tw.WriteHeader(&tar.Header{Name: name, Mode: 0644, Size: int64(len(content))}))
+		_, err := tw.Write([]byte(content))
+		assert.NoError(t, err)
+	}
+	assert.NoError(t, tw.Close())
+	assert.NoError(t, c.WriteToGCS("bucket", "vm.ova", &buf))
+
+	assert.NoError(t, NewTarGcsExtractor(context.Background(), c, logging.NewToolLogger("[test]")).
+		ExtractTarToGcs("gs://bucket/vm.ova", "gs://bucket/extracted/"))
+	assert.Equal(t, "descriptor", readLocalObject(t, c, "bucket", "extracted/vm.ovf"))
+	assert.Equal(t, "disk", readLocalObject(t, c, "bucket", "extracted/disk.vmdk"))
+}
+
+func TestNewStorageClientForBackend(t *testing.T) {
+	dir := t.TempDir()
+	client, err := NewStorageClientForBackend(context.Background(), logging.NewToolLogger("[test]"), "file://"+dir)
+	assert.NoError(t, err)
+	assert.IsType(t, &LocalClient{}, client)
+
+	_, err = NewStorageClientForBackend(context.Background(), logging.NewToolLogger("[test]"), "s3://bucket")
+	assert.EqualError(t, err, `storage backend "s3://bucket" is not supported. Use gs:// or file://<directory>.`)
+}
+
+func newLocalClient(t *testing.T) *LocalClient {
+	c, err := NewLocalStorageClient(context.Background(), logging.NewToolLogger("[test]"), t.TempDir())
+	assert.NoError(t, err)
+	return c
+}
+
+// failingReader is an io.Reader whose reads fail.
+type failingReader struct{}
+
+func (failingReader) Read([]byte) (int, error) {
+	return 0, errors.New("read failed")
+}
+
+func writeLocalObject(t *testing.T, c *LocalClient, bucket, name, content string) {
+	assert.NoError(t, c.WriteToGCS(bucket, name, strings.NewReader(content)))
+}
+
+func readLocalObject(t *testing.T, c *LocalClient, bucket, name string) string {
+	reader, err := c.GetObject(bucket, name).NewReader()
+	if !assert.NoError(t, err) {
+		return ""
+	}
+	defer reader.Close()
+	content, err := io.ReadAll(reader)
+	assert.NoError(t, err)
+	return string(content)
+}
+
+func objectNames(t *testing.T, it domain.ObjectIteratorInterface) []string {
+	var names []string
+	for {
+		attrs, err := it.Next()
+		if err == iterator.Done {
+			return names
+		}
+		if !assert.NoError(t, err) {
+			return names
+		}
+		names = append(names, attrs.Name)
+	}
+}
@@ -16,6 +16,7 @@ package storage
 
 import (
 	"fmt"
+	"sort"
 	"strings"
 
 	daisy "github.com/GoogleCloudPlatform/compute-daisy"
@@ -102,9 +103,30 @@ func (rlr *ResourceLocationRetriever) getZoneFromStorageLocation(location string
 	return rlr.getZoneForRegion(location, zones)
 }
 
+// GetZonesInRegion returns the names of the zones in region that are up, sorted by name.
+// It's used to pick an alternative zone when a zone can't provide the resources
+// that a workflow requires.
+func (rlr *ResourceLocationRetriever) GetZonesInRegion(region string, project string) ([]string, error) {
+	if project == "" {
+		return nil, daisy.Errf("project cannot be empty in order to list zones in a region")
+	}
+	zones, err := rlr.ComputeGCEService.ListZones(project)
+	if err != nil {
+		return nil, daisy.Errf("Failed to list zones: %v", err)
+	}
+	var zonesInRegion []string
+	for _, zone := range zones {
+		if isZoneUp(zone) && isZoneInRegion(zone, region) {
+			zonesInRegion = append(zonesInRegion, zone.Name)
+		}
+	}
+	sort.Strings(zonesInRegion)
+	return zonesInRegion, nil
+}
+
 func (rlr *ResourceLocationRetriever) getZoneForRegion(region string, zones []*compute.Zone) (string, daisy.DError) {
 	for _, zone := range zones {
-		if isZoneUp(zone) && strings.HasSuffix(strings.ToLower(zone.Region), strings.ToLower(region)) {
+		if isZoneUp(zone) && isZoneInRegion(zone, region) {
 			return zone.Name, nil
 		}
 	}
@@ -145,6 +167,10 @@ func isZoneUp(zone *compute.Zone) bool {
 	return zone != nil && zone.Status == "UP"
 }
 
+func isZoneInRegion(zone *compute.Zone, region string) bool {
+	return strings.HasSuffix(strings.ToLower(zone.Region), strings.ToLower(region))
+}
+
 // GetLargestStorageLocation returns the largest storage location that includes provided argument.
 // If argument is a multi-region, the argument is returned. If argument is a region within a multi-region,
 // the multi-region is returned. If argument is a region not within a multi-region, argument is returned.
@@ -314,6 +314,43 @@ func TestGetZoneErrorWhenProjectNotSetAndStorageRegionSetAndNotOnGCE(t *testing.
 	assert.Equal(t, "", zone)
 }
 
+func TestGetZonesInRegionReturnsUpZonesSorted(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	projectID := "a_project"
+	zones := []*compute.Zone{
+		createUpZone("us-west2", "c"),
+		createUpZone("us-west1", "b"),
+		createDownZone("us-west2", "b"),
+		createUpZone("us-west2", "a"),
+		createUpZone("us-west23", "a"),
+	}
+	mockComputeService := mocks.NewMockClient(mockCtrl)
+	mockComputeService.EXPECT().ListZones(projectID).Return(zones, nil)
+
+	rlr := ResourceLocationRetriever{mocks.NewMockMetadataGCEInterface(mockCtrl), mockComputeService}
+	zonesInRegion, err := rlr.GetZonesInRegion("us-west2", projectID)
+
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"us-west2-a", "us-west2-c"}, zonesInRegion)
+}
+
+func TestGetZonesInRegionErrorWhenListZonesFails(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	projectID := "a_project"
+	mockComputeService := mocks.NewMockClient(mockCtrl)
+	mockComputeService.EXPECT().ListZones(projectID).Return(nil, fmt.Errorf("err"))
+
+	rlr := ResourceLocationRetriever{mocks.NewMockMetadataGCEInterface(mockCtrl), mockComputeService}
+	zonesInRegion, err := rlr.GetZonesInRegion("us-west2", projectID)
+
+	assert.NotNil(t, err)
+	assert.Nil(t, zonesInRegion)
+}
+
 func createUpZone(region string, zoneSuffix string) *compute.Zone {
 	return createZone(region, zoneSuffix, "UP")
 }
@@ -43,6 +43,7 @@ type awsImportArguments struct {
 	gcsSubnet           string
 	gcsScratchBucket    string
 	gcsStorageLocation  string
+	noExternalIP        bool
 	region              string
 	secretAccessKey     string
 	sessionToken        string
@@ -88,6 +89,7 @@ func newAWSImportArguments(args *OneStepImportArguments) *awsImportArguments {
 		gcsSubnet:          args.Subnet,
 		gcsScratchBucket:   args.ScratchBucketGcsPath,
 		gcsStorageLocation: args.StorageLocation,
+		noExternalIP:       args.NoExternalIP,
 		region:             args.AWSRegion,
 		secretAccessKey:    args.AWSSecretAccessKey,
 		sessionToken:       args.AWSSessionToken,
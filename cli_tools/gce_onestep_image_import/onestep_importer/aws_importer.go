@@ -55,13 +55,14 @@ const (
 
 // awsImporter is responsible for importing image from AWS.
 type awsImporter struct {
-	args           *awsImportArguments
-	gcsClient      domain.StorageClientInterface
-	ctx            context.Context
-	oauth          string
-	paramPopulator param.Populator
-	timeoutChan    chan struct{}
-	uploader       *uploader
+	args             *awsImportArguments
+	gcsClient        domain.StorageClientInterface
+	ctx              context.Context
+	oauth            string
+	paramPopulator   param.Populator
+	networkPreflight param.NetworkPreflight
+	timeoutChan      chan struct{}
+	uploader         *uploader
 
 	// AWS clients for SDK
 	ec2Client ec2iface.EC2API
@@ -102,20 +103,26 @@ func newAWSImporter(oauth string, timeoutChan chan struct{}, args *awsImportArgu
 		param.NewMachineSeriesDetector(computeClient),
 	)
 
+	networkPreflight, err := param.CreateNetworkPreflight(ctx, oauth, args.gcsComputeEndpoint, computeClient)
+	if err != nil {
+		return nil, err
+	}
+
 	awsSession, err := createAWSSession(args.region, args.accessKeyID, args.secretAccessKey, args.sessionToken)
 	if err != nil {
 		return nil, err
 	}
 
 	importer := &awsImporter{
-		args:           args,
-		gcsClient:      client,
-		s3Client:       s3.New(awsSession),
-		ec2Client:      ec2.New(awsSession),
-		ctx:            ctx,
-		oauth:          oauth,
-		paramPopulator: paramPopulator,
-		timeoutChan:    timeoutChan,
+		args:             args,
+		gcsClient:        client,
+		s3Client:         s3.New(awsSession),
+		ec2Client:        ec2.New(awsSession),
+		ctx:              ctx,
+		oauth:            oauth,
+		paramPopulator:   paramPopulator,
+		networkPreflight: networkPreflight,
+		timeoutChan:      timeoutChan,
 	}
 
 	return importer, nil
@@ -173,6 +180,11 @@ func (importer *awsImporter) run(importArgs *OneStepImportArguments) error {
 	if err != nil {
 		return err
 	}
+	err = importer.networkPreflight.Check(importer.args.gcsNetwork, importer.args.gcsSubnet,
+		importer.args.gcsRegion, *importer.args.gcsProjectPtr, importer.args.noExternalIP)
+	if err != nil {
+		return err
+	}
 
 	// 2. export AMI to AWS S3 if user did not specify an exported AMI path.
 	if needsExport {
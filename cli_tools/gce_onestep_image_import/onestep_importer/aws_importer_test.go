@@ -132,6 +132,23 @@ func TestRunImporterFailWhenValidateFail(t *testing.T) {
 	assert.Error(t, err)
 }
 
+func TestRunImporterFailWhenNetworkPreflightFails(t *testing.T) {
+	args := setUpAWSArgs("", true)
+	importer, err := NewOneStepImportArguments(args)
+	assert.Nil(t, err)
+	awsImporter := &awsImporter{
+		args:             getAWSImportArgs(args),
+		paramPopulator:   mockPopulator{},
+		networkPreflight: mockNetworkPreflight{err: fmt.Errorf("network check failed")},
+		exportAWSImageFn: func() error {
+			t.Fatal("AMI shouldn't be exported when the network check fails")
+			return nil
+		},
+	}
+	err = awsImporter.run(importer)
+	assert.EqualError(t, err, "network check failed")
+}
+
 func TestRunImporterExportAMI(t *testing.T) {
 	args := setUpAWSArgs("", true)
 	awsImporter := getAWSImporter(t, args)
@@ -695,6 +712,7 @@ func getAWSImporter(t *testing.T, args []string) *awsImporter {
 	awsImporter.ec2Client = &mockEC2Client{}
 	awsImporter.s3Client = &mockS3Client{}
 	awsImporter.paramPopulator = mockPopulator{}
+	awsImporter.networkPreflight = mockNetworkPreflight{}
 
 	awsImporter.exportAWSImageFn = func() error { return nil }
 	awsImporter.monitorAWSExportImageTaskFn = func() error { return nil }
@@ -216,11 +216,13 @@ func (args *OneStepImportArguments) registerFlags(flagSet *flag.FlagSet) {
 		"When enabled, temporary worker VMs will be created with enabled nested virtualization. "+
 			"See https://cloud.google.com/compute/docs/instances/nested-virtualization/enabling for details.")
 
-	flagSet.Var((*flags.StringArrayFlag)(&args.WorkerMachineSeries), "worker_machine_series",
+	flagSet.Var((*flags.StringListFlag)(&args.WorkerMachineSeries), "worker_machine_series",
 		"The import tool automatically selects the machine series for temporary worker VMs based on the execution context. "+
 			"The argument overrides this behavior and specifies the machine series to use for worker VMs. "+
-			"Additionally it is possible to specify fallback machine series by setting this argument twice. "+
-			"For example, -worker_machine_series n1 -worker_machine_series n2")
+			"Additionally it is possible to specify an ordered list of fallback machine series, either as a comma-separated "+
+			"list or by repeating the argument. A fallback is used when a series is out of quota or capacity, "+
+			"or isn't compatible with the worker's disks. "+
+			"For example, -worker_machine_series c3,n2,n1")
 
 	flagSet.DurationVar(&args.Timeout, "timeout", time.Hour*2,
 		"Maximum time a build can last before it is failed as TIMEOUT. For example, "+
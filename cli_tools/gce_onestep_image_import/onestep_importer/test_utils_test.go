@@ -64,6 +64,14 @@ func (m mockPopulator) PopulateMissingParameters(project *string, clientID strin
 	return nil
 }
 
+type mockNetworkPreflight struct {
+	err error
+}
+
+func (m mockNetworkPreflight) Check(network, subnet, region, project string, noExternalIP bool) error {
+	return m.err
+}
+
 func expectSuccessfulParse(t *testing.T, input ...string) *OneStepImportArguments {
 	args := setUpArgs("", input...)
 	importArgs, err := NewOneStepImportArguments(args)
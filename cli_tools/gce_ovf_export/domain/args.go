@@ -45,9 +45,18 @@ const (
 	// ReleaseTrackFlagKey is key for release track flag
 	ReleaseTrackFlagKey = "release-track"
 
+	// PortableForFlagKey is key for the flag that prepares the boot disk for another hypervisor
+	PortableForFlagKey = "portable-for"
+
 	// OvfFormatFlagKey is key for OVF format flag
 	OvfFormatFlagKey = "ovf-format"
 
+	// EmitWorkflowsDirFlagKey is key for the directory to which workflows are written
+	EmitWorkflowsDirFlagKey = "emit-workflows-dir"
+
+	// EmitWorkflowsOnlyFlagKey is key to write workflows without running them
+	EmitWorkflowsOnlyFlagKey = "emit-workflows-only"
+
 	mainWorkflowDir = "daisy_workflows/"
 
 	//Alpha represents alpha release track
@@ -74,6 +83,7 @@ type OVFExportArgs struct {
 	ClientVersion               string
 	DestinationURI              string
 	DiskExportFormat            string
+	PortableFor                 string
 	OsID                        string
 	Network                     string
 	Subnet                      string
@@ -92,6 +102,8 @@ type OVFExportArgs struct {
 	ComputeServiceAccount       string
 	NestedVirtualizationEnabled bool
 	WorkerMachineSeries         []string
+	EmitWorkflowsDir            string
+	EmitWorkflowsOnly           bool
 
 	// Non-args
 	WorkflowDir string
@@ -175,6 +187,8 @@ func (args *OVFExportArgs) EnvironmentSettings(daisyLogLinePrefix string) daisyu
 		ExecutionID:                 args.BuildID,
 		NestedVirtualizationEnabled: args.NestedVirtualizationEnabled,
 		WorkerMachineSeries:         args.WorkerMachineSeries,
+		EmitWorkflowsDir:            args.EmitWorkflowsDir,
+		EmitWorkflowsOnly:           args.EmitWorkflowsOnly,
 		StorageLocation:             "",
 		Tool: daisyutils.Tool{
 			HumanReadableName: "ovf export",
@@ -203,6 +217,8 @@ func (args *OVFExportArgs) registerFlags(cliArgs []string) error {
 		"Google Cloud Storage URI of the OVF descriptor or directory to export to. For example: `gs://my-bucket/my-vm.ovf` or `gs://my-bucket/my-ovf/`.")
 	flagSet.Var((*flags.LowerTrimmedString)(&args.DiskExportFormat), "disk-export-format",
 		"format for disks in OVF, such as vmdk, vhdx, vpc, or qcow2. Any format supported by qemu-img is supported by OVF export. Defaults to `vmdk`.")
+	flagSet.Var((*flags.LowerTrimmedString)(&args.PortableFor), PortableForFlagKey,
+		"Prepare a Linux boot disk to boot on another hypervisor: vmware, kvm, or hyperv. A copy of the boot disk is exported, from which the GCE guest environment is removed, GCE-specific cloud-init and network configuration is replaced, and the hypervisor's guest tools and drivers are installed. The changes are written to <ovf name>.portable.json.")
 	flagSet.Var((*flags.TrimmedString)(&args.Network), "network",
 		"Name of the network in your project to use for the image export. The network must have access to Google Cloud Storage. If not specified, the network named default is used. If -subnet is also specified subnet must be a subnetwork of network specified by -network.")
 	flagSet.Var((*flags.TrimmedString)(&args.Subnet), "subnet",
@@ -231,6 +247,8 @@ func (args *OVFExportArgs) registerFlags(cliArgs []string) error {
 		"Cloud Build ID override. This flag should be used if auto-generated or build ID provided by Cloud Build is not appropriate. For example, if running multiple exports in parallel in a single Cloud Build run, sharing build ID could cause premature temporary resource clean-up resulting in export failures.")
 	flagSet.Var((*flags.TrimmedString)(&args.ComputeServiceAccount), "compute-service-account", "Compute service account to be used by exporter Virtual Machine. When empty, the Compute Engine default service account is used.")
 	flagSet.BoolVar(&args.NestedVirtualizationEnabled, "enable-nested-virtualization", true, "When enabled, temporary worker VMs will be created with enabled nested virtualization. See https://cloud.google.com/compute/docs/instances/nested-virtualization/enabling for details.")
-	flagSet.Var((*flags.StringArrayFlag)(&args.WorkerMachineSeries), "worker-machine-series", "The export tool automatically selects the machine series for temporary worker VMs based on the execution context. The argument overrides this behavior and specifies the machine series to use for worker VMs. Additionally it is possible to specify fallback machine series by setting this argument twice. For example, -worker-machine-series n1 -worker-machine-series n2")
+	flagSet.Var((*flags.StringListFlag)(&args.WorkerMachineSeries), "worker-machine-series", "The export tool automatically selects the machine series for temporary worker VMs based on the execution context. The argument overrides this behavior and specifies the machine series to use for worker VMs. Additionally it is possible to specify an ordered list of fallback machine series, either as a comma-separated list or by repeating the argument. A fallback is used when a series is out of quota or capacity, or isn't compatible with the worker's disks. For example, -worker-machine-series c3,n2,n1")
+	flagSet.Var((*flags.TrimmedString)(&args.EmitWorkflowsDir), EmitWorkflowsDirFlagKey, "A local directory to which each daisy workflow is written as JSON, after all variables and overrides are applied. Useful for debugging and auditing which resources are created.")
+	flagSet.BoolVar(&args.EmitWorkflowsOnly, EmitWorkflowsOnlyFlagKey, false, "Writes workflows to -emit-workflows-dir without running them. Requires -emit-workflows-dir. Later workflows depend on the results of earlier ones, so the export stops after the first workflow is written.")
 	return flagSet.Parse(cliArgs)
 }
@@ -27,8 +27,10 @@ import (
 	commondisk "github.com/GoogleCloudPlatform/compute-image-import/cli_tools/common/disk"
 	"github.com/GoogleCloudPlatform/compute-image-import/cli_tools/common/domain"
 	computeutils "github.com/GoogleCloudPlatform/compute-image-import/cli_tools/common/utils/compute"
+	"github.com/GoogleCloudPlatform/compute-image-import/cli_tools/common/utils/daisyutils"
 	"github.com/GoogleCloudPlatform/compute-image-import/cli_tools/common/utils/logging"
 	"github.com/GoogleCloudPlatform/compute-image-import/cli_tools/common/utils/logging/service"
+	"github.com/GoogleCloudPlatform/compute-image-import/cli_tools/common/utils/param"
 	storageutils "github.com/GoogleCloudPlatform/compute-image-import/cli_tools/common/utils/storage"
 	ovfexportdomain "github.com/GoogleCloudPlatform/compute-image-import/cli_tools/gce_ovf_export/domain"
 	"github.com/GoogleCloudPlatform/compute-image-import/proto/go/pb"
@@ -86,14 +88,40 @@ func NewOVFExporter(params *ovfexportdomain.OVFExportArgs, logger logging.ToolLo
 	if err := validateAndPopulateParams(params, paramValidator, paramPopulator); err != nil {
 		return nil, err
 	}
-	inspector, err := commondisk.NewInspector(params.EnvironmentSettings("ovf-export-disk-inspect"), logger)
+	permissionPreflight, err := param.CreatePermissionPreflight(ctx, params.Oauth, computeClient, storageClient)
+	if err != nil {
+		return nil, err
+	}
+	if err := permissionPreflight.Check(param.PermissionRequest{
+		Flow:                  param.OVFExportFlow,
+		Project:               params.Project,
+		ComputeServiceAccount: params.ComputeServiceAccount,
+		NoExternalIP:          params.NoExternalIP,
+		ScratchBucketGcsPath:  params.ScratchBucketGcsPath,
+		DestinationGcsPaths:   []string{params.DestinationURI},
+	}); err != nil {
+		return nil, err
+	}
+	// Workflows share the compute client, which the quota preflight uses to read quotas.
+	return newOVFExporter(params, computeClient, storageClient,
+		daisyutils.WorkflowClients{ComputeClient: computeClient}, logger)
+}
+
+// newOVFExporter creates an OVF exporter that uses the given clients. Its workflows use
+// workflowClients, or create their own clients when they're unset.
+func newOVFExporter(params *ovfexportdomain.OVFExportArgs, computeClient daisyCompute.Client,
+	storageClient domain.StorageClientInterface, workflowClients daisyutils.WorkflowClients,
+	logger logging.ToolLogger) (*OVFExporter, error) {
+	env := params.EnvironmentSettings("ovf-export-disk-inspect")
+	env.WorkflowClients = workflowClients
+	inspector, err := commondisk.NewInspector(env, logger)
 	if err != nil {
 		return nil, daisy.Errf("Error creating disk inspector: %v", err)
 	}
 	return &OVFExporter{
 		storageClient:          storageClient,
 		computeClient:          computeClient,
-		mgce:                   metadataGCE,
+		mgce:                   &computeutils.MetadataGCE{},
 		bucketIteratorCreator:  &storageutils.BucketIteratorCreator{},
 		Logger:                 logger,
 		params:                 params,
@@ -101,9 +129,9 @@ func NewOVFExporter(params *ovfexportdomain.OVFExportArgs, logger logging.ToolLo
 		ovfDescriptorGenerator: NewOvfDescriptorGenerator(computeClient, storageClient, params.Project, params.Zone),
 		manifestFileGenerator:  NewManifestFileGenerator(storageClient),
 		inspector:              inspector,
-		instanceDisksExporter:  NewInstanceDisksExporter(computeClient, storageClient, logger),
-		instanceExportPreparer: NewInstanceExportPreparer(logger),
-		instanceExportCleaner:  NewInstanceExportCleaner(logger),
+		instanceDisksExporter:  NewInstanceDisksExporter(computeClient, storageClient, workflowClients, logger),
+		instanceExportPreparer: NewInstanceExportPreparer(workflowClients, logger),
+		instanceExportCleaner:  NewInstanceExportCleaner(workflowClients, logger),
 	}, nil
 }
 
@@ -0,0 +1,168 @@
+//  Copyright 2026 Google Inc. All Rights Reserved.
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+package ovfexporter
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"cloud.google.com/go/storage"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/api/compute/v1"
+	"google.golang.org/api/option"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/GoogleCloudPlatform/compute-image-import/cli_tools/common/domain"
+	computeutils "github.com/GoogleCloudPlatform/compute-image-import/cli_tools/common/utils/compute"
+	"github.com/GoogleCloudPlatform/compute-image-import/cli_tools/common/utils/daisyutils"
+	"github.com/GoogleCloudPlatform/compute-image-import/cli_tools/common/utils/logging"
+	storageutils "github.com/GoogleCloudPlatform/compute-image-import/cli_tools/common/utils/storage"
+	ovfexportdomain "github.com/GoogleCloudPlatform/compute-image-import/cli_tools/gce_ovf_export/domain"
+	"github.com/GoogleCloudPlatform/compute-image-import/proto/go/pb"
+)
+
+// TestOVFExporter_FakeClient exports a running instance with a boot and a data disk,
+// using the in-memory compute client and a local storage backend. The export and
+// inspection workers have scripted serial port output.
+func TestOVFExporter_FakeClient(t *testing.T) {
+	client := computeutils.NewFakeClient()
+	client.AddProject("project", "us-west1-a")
+	client.AddProject("compute-image-import")
+	client.AddImage("compute-image-import", &compute.Image{Name: "debian-11-worker-v20241212", Family: "debian-11-worker", DiskSizeGb: 10})
+	client.AddDisk("project", "us-west1-a", &compute.Disk{Name: "boot", SizeGb: 10})
+	client.AddDisk("project", "us-west1-a", &compute.Disk{Name: "data", SizeGb: 20})
+	assert.NoError(t, client.CreateInstance("project", "us-west1-a", &compute.Instance{
+		Name:        "vm",
+		MachineType: "zones/us-west1-a/machineTypes/n1-standard-4",
+		Disks: []*compute.AttachedDisk{
+			{Source: "zones/us-west1-a/disks/boot", Boot: true, Mode: "READ_WRITE"},
+			{Source: "zones/us-west1-a/disks/data", Mode: "READ_WRITE"},
+		},
+	}))
+	client.ScriptInstances("inst-export-disk", computeutils.InstanceScript{
+		SerialPortOutput: map[int64][]string{1: {"export success\n"}},
+	})
+	client.ScriptInstances("run-inspection", computeutils.InstanceScript{
+		SerialPortOutput: map[int64][]string{1: {
+			"Status: inspection started.\n" +
+				fmt.Sprintf("Status: <serial-output key:'inspect_pb' value:'%s'>\n", encodedInspectionResults(t)) +
+				"Success: Done!\n",
+		}},
+	})
+
+	storageClient, err := storageutils.NewLocalStorageClient(context.Background(), logging.NewToolLogger("[test]"), t.TempDir())
+	assert.NoError(t, err)
+	assert.NoError(t, storageClient.CreateBucket("bucket", "project", nil))
+	// The export workers write the disk files, so they're created here.
+	for _, disk := range []string{"vm-boot.vmdk", "vm-data.vmdk"} {
+		assert.NoError(t, storageClient.WriteToGCS("bucket", "export/"+disk, strings.NewReader("KDMV")))
+	}
+
+	params := &ovfexportdomain.OVFExportArgs{
+		InstanceName:         "vm",
+		DestinationURI:       "gs://bucket/export/vm.ovf",
+		DestinationDirectory: "gs://bucket/export/",
+		OvfName:              "vm",
+		DiskExportFormat:     "vmdk",
+		Network:              "global/networks/default",
+		Project:              "project",
+		Zone:                 "us-west1-a",
+		Timeout:              time.Hour,
+		ScratchBucketGcsPath: "gs://bucket/scratch",
+		GcsLogsDisabled:      true,
+		CloudLogsDisabled:    true,
+		StdoutLogsDisabled:   true,
+		BuildID:              "abcde",
+		WorkflowDir:          "../../../daisy_workflows",
+	}
+	workflowClients := daisyutils.WorkflowClients{ComputeClient: client, StorageClient: newFakeStorageClient(t)}
+	oe, err := newOVFExporter(params, client, storageClient, workflowClients, logging.NewToolLogger("[test]"))
+	if !assert.NoError(t, err) {
+		return
+	}
+	if !assert.NoError(t, oe.Run(context.Background())) {
+		return
+	}
+
+	descriptor := string(readObject(t, storageClient, "bucket", "export/vm.ovf"))
+	assert.Contains(t, descriptor, `href="vm-boot.vmdk"`)
+	assert.Contains(t, descriptor, `href="vm-data.vmdk"`)
+	assert.Contains(t, descriptor, "Ubuntu 22.04 (64-bit)")
+	manifest := string(readObject(t, storageClient, "bucket", "export/vm.mf"))
+	for _, file := range []string{"vm.ovf", "vm-boot.vmdk", "vm-data.vmdk"} {
+		assert.Contains(t, manifest, "SHA1("+file+")=")
+	}
+
+	instance, err := client.GetInstance("project", "us-west1-a", "vm")
+	if assert.NoError(t, err) {
+		assert.Equal(t, "RUNNING", instance.Status, "the instance is restarted")
+		assert.Len(t, instance.Disks, 2, "the disks are reattached")
+	}
+	instances, _ := client.ListInstances("project", "us-west1-a")
+	assert.Len(t, instances, 1, "workers are deleted")
+	disks, _ := client.ListDisks("project", "us-west1-a")
+	assert.Len(t, disks, 2, "the workers' disks are deleted")
+}
+
+// encodedInspectionResults returns the inspection worker's result for a BIOS-bootable
+// Ubuntu 22.04 disk.
+func encodedInspectionResults(t *testing.T) string {
+	bytes, err := proto.Marshal(&pb.InspectionResults{
+		OsCount:      1,
+		BiosBootable: true,
+		OsRelease: &pb.OsRelease{
+			MajorVersion: "22",
+			MinorVersion: "04",
+			Architecture: pb.Architecture_X64,
+			DistroId:     pb.Distro_UBUNTU,
+		},
+	})
+	assert.NoError(t, err)
+	return base64.StdEncoding.EncodeToString(bytes)
+}
+
+// readObject returns the content of a GCS object from storageClient.
+func readObject(t *testing.T, storageClient domain.StorageClientInterface, bucket, object string) []byte {
+	reader, err := storageClient.GetObject(bucket, object).NewReader()
+	if !assert.NoError(t, err) {
+		return nil
+	}
+	defer reader.Close()
+	content, err := io.ReadAll(reader)
+	assert.NoError(t, err)
+	return content
+}
+
+// newFakeStorageClient returns a storage client whose requests all succeed, for
+// workflows that validate their scratch bucket, upload their sources, and copy the
+// exported disk files.
+func newFakeStorageClient(t *testing.T) *storage.Client {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		// "done" and "resource" complete the rewrite calls that copy GCS objects.
+		w.Write([]byte(`{"bucket": "scratch", "name": "object", "done": true, "resource": {"bucket": "scratch", "name": "object"}}`))
+	}))
+	t.Cleanup(ts.Close)
+	client, err := storage.NewClient(context.Background(), option.WithEndpoint(ts.URL), option.WithoutAuthentication())
+	assert.NoError(t, err)
+	return client
+}
@@ -15,6 +15,7 @@
 package ovfexporter
 
 import (
+	"bytes"
 	"fmt"
 	"path"
 	"strconv"
@@ -25,6 +26,7 @@ import (
 	"google.golang.org/api/compute/v1"
 
 	"github.com/GoogleCloudPlatform/compute-image-import/cli_tools/common/domain"
+	"github.com/GoogleCloudPlatform/compute-image-import/cli_tools/common/image"
 	"github.com/GoogleCloudPlatform/compute-image-import/cli_tools/common/utils/daisyutils"
 	"github.com/GoogleCloudPlatform/compute-image-import/cli_tools/common/utils/logging"
 	storageutils "github.com/GoogleCloudPlatform/compute-image-import/cli_tools/common/utils/storage"
@@ -36,17 +38,20 @@ type instanceDisksExporterImpl struct {
 	worker           daisyutils.DaisyWorker
 	computeClient    daisyCompute.Client
 	storageClient    domain.StorageClientInterface
+	workflowClients  daisyutils.WorkflowClients
 	exportedDisks    []*ovfexportdomain.ExportedDisk
 	logger           logging.Logger
 	wfPreRunCallback wfCallback
 }
 
 // NewInstanceDisksExporter creates a new instance disk exporter
-func NewInstanceDisksExporter(computeClient daisyCompute.Client, storageClient domain.StorageClientInterface, logger logging.Logger) ovfexportdomain.InstanceDisksExporter {
+func NewInstanceDisksExporter(computeClient daisyCompute.Client, storageClient domain.StorageClientInterface,
+	workflowClients daisyutils.WorkflowClients, logger logging.Logger) ovfexportdomain.InstanceDisksExporter {
 	return &instanceDisksExporterImpl{
-		computeClient: computeClient,
-		storageClient: storageClient,
-		logger:        logger,
+		computeClient:   computeClient,
+		storageClient:   storageClient,
+		workflowClients: workflowClients,
+		logger:          logger,
 	}
 }
 
@@ -63,10 +68,18 @@ func (ide *instanceDisksExporterImpl) Export(instance *compute.Instance, params
 		return wf, err
 	}
 
-	ide.worker = daisyutils.NewDaisyWorker(workflowProvider, params.EnvironmentSettings(wfName), ide.logger)
-	if err := ide.worker.Run(map[string]string{}); err != nil {
+	env := params.EnvironmentSettings(wfName)
+	env.WorkflowClients = ide.workflowClients
+	ide.worker = daisyutils.NewDaisyWorker(workflowProvider, env, ide.logger)
+	values, err := ide.worker.RunAndReadSerialValues(map[string]string{}, image.PortableChangesKey)
+	if err != nil {
 		return nil, err
 	}
+	if params.PortableFor != "" {
+		if err := ide.writePortableChanges(values[image.PortableChangesKey], params); err != nil {
+			return nil, err
+		}
+	}
 	if err := ide.populateExportedDisksMetadata(params); err != nil {
 		return nil, err
 	}
@@ -139,8 +152,15 @@ func (ide *instanceDisksExporterImpl) addExportDisksSteps(w *daisy.Workflow, ins
 		exportDiskStepName = strings.Trim(exportDiskStepName, "-")
 		exportDiskStep := daisy.NewStepDefaultTimeout(exportDiskStepName, w)
 
+		sourceDisk := diskPath
+		if attachedDisk.Boot && params.PortableFor != "" {
+			var err error
+			if sourceDisk, err = ide.addPortableSteps(w, diskPath, exportDiskStepName, params); err != nil {
+				return nil, err
+			}
+		}
 		varMap := map[string]string{
-			"source_disk":                diskPath,
+			"source_disk":                sourceDisk,
 			"destination":                exportedDiskGCSPath,
 			"format":                     params.DiskExportFormat,
 			"export_instance_disk_image": "projects/compute-image-import/global/images/family/debian-11-worker",
@@ -163,6 +183,79 @@ func (ide *instanceDisksExporterImpl) addExportDisksSteps(w *daisy.Workflow, ins
 	return exportedDisks, nil
 }
 
+// addPortableSteps adds steps to w that copy the boot disk at diskPath, and prepare the copy
+// to boot on params.PortableFor. The copy is exported by exportDiskStepName in place of the
+// boot disk, so that the instance's disk isn't modified. It returns the name of the copy.
+func (ide *instanceDisksExporterImpl) addPortableSteps(w *daisy.Workflow, diskPath, exportDiskStepName string,
+	params *ovfexportdomain.OVFExportArgs) (string, error) {
+	bootDisk, err := ide.computeClient.GetDisk(params.Project, params.Zone, daisyutils.GetResourceID(diskPath))
+	if err != nil {
+		return "", daisy.Errf("Error retrieving boot disk `%v`: %v", diskPath, err)
+	}
+	const (
+		copyName         = "disk-portable-boot"
+		copyStepName     = "copy-boot-disk"
+		portableStepName = "make-portable"
+		deleteStepName   = "delete-boot-disk-copy"
+	)
+	copyStep := daisy.NewStepDefaultTimeout(copyStepName, w)
+	copyStep.CreateDisks = &daisy.CreateDisks{{
+		Disk: compute.Disk{
+			Name:       copyName,
+			SourceDisk: diskPath,
+			Type:       "pd-ssd",
+		},
+		SizeGb: strconv.FormatInt(bootDisk.SizeGb, 10),
+	}}
+	w.Steps[copyStepName] = copyStep
+
+	vars := map[string]string{
+		"source_disk":    copyName,
+		"portable_for":   params.PortableFor,
+		"export_network": params.Network,
+		"export_subnet":  params.Subnet,
+	}
+	if params.ComputeServiceAccount != "" {
+		vars["compute_service_account"] = params.ComputeServiceAccount
+	}
+	portableStep := daisy.NewStepDefaultTimeout(portableStepName, w)
+	var derr daisy.DError
+	if portableStep.IncludeWorkflow, derr = instantiateIncludedWorkflow(w, path.Join(params.WorkflowDir, image.PortableWorkflow), vars); derr != nil {
+		return "", derr
+	}
+	w.Steps[portableStepName] = portableStep
+
+	deleteStep := daisy.NewStepDefaultTimeout(deleteStepName, w)
+	deleteStep.DeleteResources = &daisy.DeleteResources{Disks: []string{copyName}}
+	w.Steps[deleteStepName] = deleteStep
+
+	w.Dependencies[portableStepName] = []string{copyStepName}
+	w.Dependencies[exportDiskStepName] = []string{portableStepName}
+	w.Dependencies[deleteStepName] = []string{exportDiskStepName}
+	return copyName, nil
+}
+
+// writePortableChanges writes the changes reported by the portable step next to the OVF
+// descriptor, so that they're included in the manifest.
+func (ide *instanceDisksExporterImpl) writePortableChanges(value string, params *ovfexportdomain.OVFExportArgs) error {
+	changes, err := image.ParsePortableChanges(value)
+	if err != nil {
+		return err
+	}
+	content, err := changes.Marshal()
+	if err != nil {
+		return err
+	}
+	ide.logger.User(fmt.Sprintf("Prepared the %v boot disk for %v: %v", changes.Distro, changes.Target,
+		strings.Join(changes.Changes, " ")))
+	bucketName, objectPath, err := storageutils.SplitGCSPath(
+		params.DestinationDirectory + params.OvfName + image.PortableChangesFileSuffix)
+	if err != nil {
+		return err
+	}
+	return ide.storageClient.WriteToGCS(bucketName, objectPath, bytes.NewReader(content))
+}
+
 // instantiateIncludedWorkflow creates an included workflow from the JSON file includedWorkflowPath,
 // using the workflow w as its parent, and applying varMap as the variables.
 func instantiateIncludedWorkflow(w *daisy.Workflow, includedWorkflowPath string,
@@ -15,6 +15,7 @@
 package ovfexporter
 
 import (
+	"encoding/base64"
 	"fmt"
 	"strings"
 	"testing"
@@ -25,6 +26,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"google.golang.org/api/compute/v1"
 
+	"github.com/GoogleCloudPlatform/compute-image-import/cli_tools/common/utils/logging"
 	ovfexportdomain "github.com/GoogleCloudPlatform/compute-image-import/cli_tools/gce_ovf_export/domain"
 	"github.com/GoogleCloudPlatform/compute-image-import/cli_tools/mocks"
 )
@@ -85,6 +87,7 @@ func TestDiskExporter_HappyPath(t *testing.T) {
 	mockComputeClient.EXPECT().ListSubnetworks(project, region).Return([]*compute.Subnetwork{{Name: "a-subnet", Region: region, SelfLink: params.Subnet}}, nil).AnyTimes()
 	mockComputeClient.EXPECT().CreateDisk(project, params.Zone, gomock.Any()).Return(nil).AnyTimes()
 	mockComputeClient.EXPECT().CreateInstance(project, params.Zone, gomock.Any()).Return(nil).AnyTimes()
+	mockComputeClient.EXPECT().GetRegion(project, region).Return(&compute.Region{}, nil).AnyTimes()
 
 	for diskIndex, disk := range disks {
 		exporterInstanceNamePrefix := fmt.Sprintf("inst-export-disk-%v-%v", diskIndex, instance.Disks[diskIndex].DeviceName)
@@ -107,6 +110,7 @@ func TestDiskExporter_HappyPath(t *testing.T) {
 	diskExporter := &instanceDisksExporterImpl{
 		computeClient: mockComputeClient,
 		storageClient: mockStorageClient,
+		logger:        logging.NewToolLogger("test"),
 	}
 	diskExporter.wfPreRunCallback = mockClientSetter
 
@@ -130,3 +134,56 @@ func diskFileName(ovfName, deviceName, fileFormat string) string {
 	}
 	return fmt.Sprintf("%v-%v.%v", ovfName, deviceName, fileFormat)
 }
+
+func TestDiskExporter_ExportsAPortableCopyOfTheBootDisk(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	params := ovfexportdomain.GetAllInstanceExportArgs()
+	params.WorkflowDir = "../../../daisy_workflows/"
+	params.PortableFor = "kvm"
+	bootDiskPath := fmt.Sprintf("projects/%v/zones/us-central1-c/disks/boot", params.Project)
+	dataDiskPath := fmt.Sprintf("projects/%v/zones/us-central1-c/disks/data", params.Project)
+	instance := &compute.Instance{
+		Disks: []*compute.AttachedDisk{
+			{Source: bootDiskPath, DeviceName: "boot", Boot: true},
+			{Source: dataDiskPath, DeviceName: "data"},
+		},
+	}
+	mockComputeClient := mocks.NewMockClient(mockCtrl)
+	mockComputeClient.EXPECT().GetDisk(params.Project, params.Zone, "boot").Return(&compute.Disk{SizeGb: 20}, nil)
+	diskExporter := &instanceDisksExporterImpl{computeClient: mockComputeClient, logger: logging.NewToolLogger("test")}
+
+	w := daisy.New()
+	_, err := diskExporter.addExportDisksSteps(w, instance, params)
+
+	assert.NoError(t, err)
+	copyDisk := (*w.Steps["copy-boot-disk"].CreateDisks)[0]
+	assert.Equal(t, bootDiskPath, copyDisk.SourceDisk)
+	assert.Equal(t, "20", copyDisk.SizeGb)
+	portableVars := w.Steps["make-portable"].IncludeWorkflow.Vars
+	assert.Equal(t, "disk-portable-boot", portableVars["source_disk"])
+	assert.Equal(t, "kvm", portableVars["portable_for"])
+	assert.Equal(t, "disk-portable-boot", w.Steps["export-disk-0-boot"].IncludeWorkflow.Vars["source_disk"])
+	assert.Equal(t, dataDiskPath, w.Steps["export-disk-1-data"].IncludeWorkflow.Vars["source_disk"])
+	assert.Equal(t, []string{"disk-portable-boot"}, w.Steps["delete-boot-disk-copy"].DeleteResources.Disks)
+	assert.Equal(t, map[string][]string{
+		"make-portable":         {"copy-boot-disk"},
+		"export-disk-0-boot":    {"make-portable"},
+		"delete-boot-disk-copy": {"export-disk-0-boot"},
+	}, w.Dependencies)
+}
+
+func TestDiskExporter_WritesPortableChanges(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	params := ovfexportdomain.GetAllInstanceExportArgs()
+	mockStorageClient := mocks.NewMockStorageClientInterface(mockCtrl)
+	mockStorageClient.EXPECT().WriteToGCS("ovfbucket", "OVFpath/ovfinst.portable.json", gomock.Any()).Return(nil)
+	diskExporter := &instanceDisksExporterImpl{storageClient: mockStorageClient, logger: logging.NewToolLogger("test")}
+
+	assert.NoError(t, diskExporter.writePortableChanges(base64.StdEncoding.EncodeToString(
+		[]byte(`{"target":"kvm","changes":["Installed qemu-guest-agent."]}`)), params))
+	assert.Error(t, diskExporter.writePortableChanges("", params))
+}
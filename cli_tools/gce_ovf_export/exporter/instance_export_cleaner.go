@@ -30,14 +30,15 @@ type instanceExportCleanerImpl struct {
 	wf               *daisy.Workflow
 	attachDiskWfs    []*daisy.Workflow
 	startInstanceWf  *daisy.Workflow
+	workflowClients  daisyutils.WorkflowClients
 	logger           logging.Logger
 	wfPreRunCallback wfCallback
 }
 
 // NewInstanceExportCleaner creates a new instance export cleaner which is
 // responsible for bringing the exported VM back to its pre-export state
-func NewInstanceExportCleaner(logger logging.Logger) ovfexportdomain.InstanceExportCleaner {
-	return &instanceExportCleanerImpl{logger: logger}
+func NewInstanceExportCleaner(workflowClients daisyutils.WorkflowClients, logger logging.Logger) ovfexportdomain.InstanceExportCleaner {
+	return &instanceExportCleanerImpl{workflowClients: workflowClients, logger: logger}
 }
 
 func (iec *instanceExportCleanerImpl) init(instance *compute.Instance, params *ovfexportdomain.OVFExportArgs) error {
@@ -117,7 +118,7 @@ func (iec *instanceExportCleanerImpl) Clean(instance *compute.Instance, params *
 		// ignore errors as these will be due to instance being already started or disks already attached
 		_ = daisyutils.NewDaisyWorker(func() (*daisy.Workflow, error) {
 			return attachDiskWf, nil
-		}, params.EnvironmentSettings(attachDiskWf.Name), iec.logger).Run(map[string]string{})
+		}, iec.environmentSettings(params, attachDiskWf.Name), iec.logger).Run(map[string]string{})
 	}
 	if iec.startInstanceWf != nil {
 		if iec.wfPreRunCallback != nil {
@@ -125,11 +126,19 @@ func (iec *instanceExportCleanerImpl) Clean(instance *compute.Instance, params *
 		}
 		_ = daisyutils.NewDaisyWorker(func() (*daisy.Workflow, error) {
 			return iec.startInstanceWf, nil
-		}, params.EnvironmentSettings(iec.startInstanceWf.Name), iec.logger).Run(map[string]string{})
+		}, iec.environmentSettings(params, iec.startInstanceWf.Name), iec.logger).Run(map[string]string{})
 	}
 	return err
 }
 
+// environmentSettings returns the settings of the cleanup workflow named wfName.
+func (iec *instanceExportCleanerImpl) environmentSettings(params *ovfexportdomain.OVFExportArgs,
+	wfName string) daisyutils.EnvironmentSettings {
+	env := params.EnvironmentSettings(wfName)
+	env.WorkflowClients = iec.workflowClients
+	return env
+}
+
 func (iec *instanceExportCleanerImpl) Cancel(reason string) bool {
 	// cleaner is not cancelable
 	return false
@@ -27,14 +27,15 @@ import (
 
 type instanceExportPreparerImpl struct {
 	worker           daisyutils.DaisyWorker
+	workflowClients  daisyutils.WorkflowClients
 	instance         *compute.Instance
 	logger           logging.Logger
 	wfPreRunCallback wfCallback
 }
 
 // NewInstanceExportPreparer creates a new instance export preparer
-func NewInstanceExportPreparer(logger logging.Logger) ovfexportdomain.InstanceExportPreparer {
-	return &instanceExportPreparerImpl{logger: logger}
+func NewInstanceExportPreparer(workflowClients daisyutils.WorkflowClients, logger logging.Logger) ovfexportdomain.InstanceExportPreparer {
+	return &instanceExportPreparerImpl{workflowClients: workflowClients, logger: logger}
 }
 
 func (iep *instanceExportPreparerImpl) Prepare(instance *compute.Instance, params *ovfexportdomain.OVFExportArgs) error {
@@ -51,7 +52,9 @@ func (iep *instanceExportPreparerImpl) Prepare(instance *compute.Instance, param
 		return wf, nil
 	}
 
-	iep.worker = daisyutils.NewDaisyWorker(workflowProvider, params.EnvironmentSettings(wfName), iep.logger)
+	env := params.EnvironmentSettings(wfName)
+	env.WorkflowClients = iep.workflowClients
+	iep.worker = daisyutils.NewDaisyWorker(workflowProvider, env, iep.logger)
 	return iep.worker.Run(map[string]string{})
 }
 
@@ -21,6 +21,7 @@ import (
 	daisyCompute "github.com/GoogleCloudPlatform/compute-daisy/compute"
 
 	"github.com/GoogleCloudPlatform/compute-image-import/cli_tools/common/domain"
+	"github.com/GoogleCloudPlatform/compute-image-import/cli_tools/common/image"
 	computeutils "github.com/GoogleCloudPlatform/compute-image-import/cli_tools/common/utils/compute"
 	"github.com/GoogleCloudPlatform/compute-image-import/cli_tools/common/utils/storage"
 	"github.com/GoogleCloudPlatform/compute-image-import/cli_tools/common/utils/validation"
@@ -75,6 +76,14 @@ func (validator *ovfExportParamValidatorImpl) ValidateAndParseParams(params *ovf
 		}
 	}
 
+	if err := image.ValidatePortableFor(params.PortableFor, ovfexportdomain.PortableForFlagKey); err != nil {
+		return err
+	}
+
+	if params.EmitWorkflowsOnly && params.EmitWorkflowsDir == "" {
+		return daisy.Errf("-%v requires -%v", ovfexportdomain.EmitWorkflowsOnlyFlagKey, ovfexportdomain.EmitWorkflowsDirFlagKey)
+	}
+
 	if err := validator.zoneValidator.ZoneValid(params.Project, params.Zone); err != nil {
 		return err
 	}
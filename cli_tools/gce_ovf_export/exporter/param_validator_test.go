@@ -94,6 +94,24 @@ func TestInstanceExportFlagsInvalidReleaseTrack(t *testing.T) {
 	assertErrorOnValidate(t, params, createDefaultParamValidator(mockCtrl, false))
 }
 
+func TestInstanceExportFlagsInvalidPortableFor(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	params := ovfexportdomain.GetAllInstanceExportArgs()
+	params.PortableFor = "xen"
+	assert.EqualError(t, createDefaultParamValidator(mockCtrl, false).ValidateAndParseParams(params),
+		"-portable-for must be one of vmware, kvm, hyperv")
+}
+
+func TestInstanceExportFlagsEmitWorkflowsOnlyWithoutDirectory(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	params := ovfexportdomain.GetAllInstanceExportArgs()
+	params.EmitWorkflowsOnly = true
+	assert.EqualError(t, createDefaultParamValidator(mockCtrl, false).ValidateAndParseParams(params),
+		"-emit-workflows-only requires -emit-workflows-dir")
+}
+
 func TestInstanceExportFlagsAllValid(t *testing.T) {
 	mockCtrl := gomock.NewController(t)
 	defer mockCtrl.Finish()
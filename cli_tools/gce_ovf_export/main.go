@@ -20,6 +20,7 @@ import (
 	"log"
 	"os"
 
+	"github.com/GoogleCloudPlatform/compute-image-import/cli_tools/common/utils/daisyutils"
 	"github.com/GoogleCloudPlatform/compute-image-import/cli_tools/common/utils/logging"
 	"github.com/GoogleCloudPlatform/compute-image-import/cli_tools/common/utils/logging/service"
 	ovfexportdomain "github.com/GoogleCloudPlatform/compute-image-import/cli_tools/gce_ovf_export/domain"
@@ -108,6 +109,10 @@ func runExport(args []string) error {
 
 	exporterClosure := func() (service.Loggable, error) {
 		err := oe.Run(ctx)
+		if daisyutils.IsWorkflowNotRun(err) {
+			// The workflows were written, and not run, as requested.
+			err = nil
+		}
 		return service.NewOutputInfoLoggable(logger.ReadOutputInfo()), err
 	}
 	action, inputParams := createInputParams(*exportArgs)
@@ -90,6 +90,14 @@ type OVFImportParams struct {
 	NestedVirtualizationEnabled bool
 	WorkerMachineSeries         []string
 	EndpointsOverride           daisyutils.EndpointsOverride
+	EmitWorkflowsDir            string
+	EmitWorkflowsOnly           bool
+	StorageBackend              string
+
+	// BootDiskFile and DataDiskFiles are set when disk files are imported
+	// directly, without an OVF descriptor. In that case MachineType is required.
+	BootDiskFile  string
+	DataDiskFiles []string
 
 	// Non-flags
 
@@ -124,6 +132,12 @@ func (oip *OVFImportParams) IsMachineImageImport() bool {
 	return !oip.IsInstanceImport()
 }
 
+// IsDiskFileImport returns true if the disk files are specified directly, rather
+// than read from an OVF descriptor.
+func (oip *OVFImportParams) IsDiskFileImport() bool {
+	return oip.BootDiskFile != ""
+}
+
 // GetTool returns a description of the tool being run that can be used for logging and messaging.
 func (oip *OVFImportParams) GetTool() daisyutils.Tool {
 	if oip.IsInstanceImport() {
@@ -162,6 +176,8 @@ func (oip *OVFImportParams) EnvironmentSettings() daisyutils.EnvironmentSettings
 		StorageLocation:             oip.Region,
 		NestedVirtualizationEnabled: oip.NestedVirtualizationEnabled,
 		WorkerMachineSeries:         oip.WorkerMachineSeries,
+		EmitWorkflowsDir:            oip.EmitWorkflowsDir,
+		EmitWorkflowsOnly:           oip.EmitWorkflowsOnly,
 		Tool:                        tool,
 		DaisyLogLinePrefix:          tool.ResourceLabelName,
 	}
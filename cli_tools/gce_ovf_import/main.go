@@ -78,15 +78,18 @@ var (
 	hostname                    = flag.String(ovfimporter.HostnameFlagKey, "", "Specify the hostname of the instance to be created. The specified hostname must be RFC1035 compliant.")
 	machineImageStorageLocation = flag.String(ovfimporter.MachineImageStorageLocationFlagKey, "", "GCS bucket storage location of the machine image being imported (regional or multi-regional)")
 	buildID                     = flag.String("build-id", "", "Cloud Build ID override. This flag should be used if auto-generated or build ID provided by Cloud Build is not appropriate. For example, if running multiple imports in parallel in a single Cloud Build run, sharing build ID could cause premature temporary resource clean-up resulting in import failures.")
-	workerMachineSeries         flags.StringArrayFlag
+	workerMachineSeries         flags.StringListFlag
 	nestedVirtualizationEnabled = flag.Bool(ovfimporter.EnableNestedVirtualizationFlagKey, true, "When enabled, temporary worker VMs will be created with enabled nested virtualization. See https://cloud.google.com/compute/docs/instances/nested-virtualization/enabling for details.")
+	emitWorkflowsDir            = flag.String(ovfimporter.EmitWorkflowsDirFlagKey, "", "A local directory to which each daisy workflow is written as JSON, after all variables and overrides are applied. Useful for debugging and auditing which resources are created.")
+	emitWorkflowsOnly           = flag.Bool(ovfimporter.EmitWorkflowsOnlyFlagKey, false, "Writes workflows to -emit-workflows-dir without running them. Requires -emit-workflows-dir. Later workflows depend on the results of earlier ones, so the import stops after the first workflow is written, or, when there are data disks, after the workflows that import them in parallel are written.")
+	storageBackend              = flag.String("storage-backend", "", "Where Cloud Storage buckets and objects are read and written. Either gs:// (default), or file://<directory> to map buckets and objects onto a local directory, file:// requires -emit-workflows-only and -emit-workflows-dir, since workflows always use Cloud Storage.")
 	nodeAffinityLabelsFlag      flags.StringArrayFlag
 	currentExecutablePath       string
 )
 
 func init() {
 	currentExecutablePath = string(os.Args[0])
-	flag.Var(&workerMachineSeries, "worker-machine-series", "The import tool automatically selects the machine series for temporary worker VMs based on the execution context. The argument overrides this behavior and specifies the machine series to use for worker VMs. Additionally it is possible to specify fallback machine series by setting this argument twice. For example, -worker-machine-series n1 -worker-machine-series n2")
+	flag.Var(&workerMachineSeries, "worker-machine-series", "The import tool automatically selects the machine series for temporary worker VMs based on the execution context. The argument overrides this behavior and specifies the machine series to use for worker VMs. Additionally it is possible to specify an ordered list of fallback machine series, either as a comma-separated list or by repeating the argument. A fallback is used when a series is out of quota or capacity, or isn't compatible with the worker's disks. For example, -worker-machine-series c3,n2,n1")
 	flag.Var(&nodeAffinityLabelsFlag, "node-affinity-label", "Node affinity label used to determine sole tenant node to schedule this instance on. Label is of the format: <key>,<operator>,<value>,<value2>... where <operator> can be one of: IN, NOT. For example: workload,IN,prod,test is a label with key 'workload' and values 'prod' and 'test'. This flag can be specified multiple times for multiple labels.")
 }
 
@@ -120,6 +123,8 @@ func buildOVFImportParams() *domain.OVFImportParams {
 		UefiCompatible: *uefiCompatible, Hostname: *hostname,
 		MachineImageStorageLocation: *machineImageStorageLocation, BuildID: *buildID, NestedVirtualizationEnabled: *nestedVirtualizationEnabled,
 		WorkflowDir: workflowDir, WorkerMachineSeries: workerMachineSeries,
+		EmitWorkflowsDir: *emitWorkflowsDir, EmitWorkflowsOnly: *emitWorkflowsOnly,
+		StorageBackend: *storageBackend,
 	}
 }
 
@@ -137,6 +142,10 @@ func runImport() (service.Loggable, error) {
 		return nil, err
 	}
 	err = ovfImporter.Import()
+	if daisyutils.IsWorkflowNotRun(err) {
+		// The workflows were written, and not run, as requested.
+		err = nil
+	}
 	return service.NewOutputInfoLoggable(logger.ReadOutputInfo()), err
 }
 
@@ -22,18 +22,21 @@ import (
 	"github.com/GoogleCloudPlatform/compute-image-import/cli_tools/common/domain"
 	"github.com/GoogleCloudPlatform/compute-image-import/cli_tools/common/image/importer"
 	"github.com/GoogleCloudPlatform/compute-image-import/cli_tools/common/imagefile"
+	"github.com/GoogleCloudPlatform/compute-image-import/cli_tools/common/utils/daisyutils"
 	"github.com/GoogleCloudPlatform/compute-image-import/cli_tools/common/utils/logging"
 	ovfdomain "github.com/GoogleCloudPlatform/compute-image-import/cli_tools/gce_ovf_import/domain"
 )
 
 // NewMultiDiskImporter constructs an implementation of NewMultiDiskImporterInterface that can
-// import disk files from GCS.
+// import disk files from GCS. Its workflows use workflowClients, or create their own clients
+// when they're unset.
 func NewMultiDiskImporter(workflowDir string, computeClient daisyCompute.Client,
-	storageClient domain.StorageClientInterface, logger logging.ToolLogger) ovfdomain.MultiDiskImporterInterface {
+	storageClient domain.StorageClientInterface, workflowClients daisyutils.WorkflowClients,
+	logger logging.ToolLogger) ovfdomain.MultiDiskImporterInterface {
 	return &multiDiskImporter{
 		builder: &requestBuilder{workflowDir, importer.NewSourceFactory(storageClient)},
 		executor: &requestExecutor{
-			&importAdapter{computeClient, storageClient},
+			&importAdapter{computeClient, storageClient, workflowClients},
 			computeClient,
 			logger,
 		},
@@ -55,12 +58,14 @@ func (m *multiDiskImporter) Import(ctx context.Context, params *ovfdomain.OVFImp
 
 // importAdapter exposes a simplified interface for disk import to facilitate testing.
 type importAdapter struct {
-	computeClient daisyCompute.Client
-	storageClient domain.StorageClientInterface
+	computeClient   daisyCompute.Client
+	storageClient   domain.StorageClientInterface
+	workflowClients daisyutils.WorkflowClients
 }
 
 func (adapter *importAdapter) Import(ctx context.Context, request importer.ImageImportRequest, logger logging.Logger) (string, error) {
-	inflater, err := importer.NewInflater(request, adapter.computeClient, adapter.storageClient, imagefile.NewGCSInspector(), logger)
+	inflater, err := importer.NewInflater(request, adapter.computeClient, adapter.storageClient, adapter.workflowClients,
+		imagefile.NewGCSInspector(), logger)
 	if err != nil {
 		return "", err
 	}
@@ -65,6 +65,8 @@ func (r *requestBuilder) buildRequests(params *ovfdomain.OVFImportParams, dataDi
 			Zone:                        params.Zone,
 			WorkerMachineSeries:         params.WorkerMachineSeries,
 			NestedVirtualizationEnabled: params.NestedVirtualizationEnabled,
+			EmitWorkflowsDir:            params.EmitWorkflowsDir,
+			EmitWorkflowsOnly:           params.EmitWorkflowsOnly,
 			DataDisk:                    true,
 		}
 		requests = append(requests, request)
@@ -25,6 +25,7 @@ import (
 	"github.com/GoogleCloudPlatform/compute-image-import/cli_tools/common/disk"
 	"github.com/GoogleCloudPlatform/compute-image-import/cli_tools/common/domain"
 	"github.com/GoogleCloudPlatform/compute-image-import/cli_tools/common/image/importer"
+	"github.com/GoogleCloudPlatform/compute-image-import/cli_tools/common/utils/daisyutils"
 	"github.com/GoogleCloudPlatform/compute-image-import/cli_tools/common/utils/logging"
 	ovfdomain "github.com/GoogleCloudPlatform/compute-image-import/cli_tools/gce_ovf_import/domain"
 )
@@ -43,8 +44,11 @@ func (r *requestExecutor) executeRequests(parentContext context.Context, request
 	group, ctx := errgroup.WithContext(parentContext)
 	disks = make([]domain.Disk, len(requests))
 
+	// The imports run in parallel, so their combined resources are compared against quotas.
+	quotaBudget := daisyutils.NewQuotaBudget()
 	for i, request := range requests {
 		req := request
+		req.QuotaBudget = quotaBudget
 		diskIdx := i
 		logPrefix := fmt.Sprintf("[import-%s]", req.DaisyLogLinePrefix)
 		group.Go(func() error {
@@ -23,6 +23,7 @@ import (
 	"github.com/GoogleCloudPlatform/compute-image-import/cli_tools/common/disk"
 	"github.com/GoogleCloudPlatform/compute-image-import/cli_tools/common/domain"
 	"github.com/GoogleCloudPlatform/compute-image-import/cli_tools/common/image/importer"
+	"github.com/GoogleCloudPlatform/compute-image-import/cli_tools/common/utils/daisyutils"
 	"github.com/GoogleCloudPlatform/compute-image-import/cli_tools/common/utils/logging"
 	ovfdomainmocks "github.com/GoogleCloudPlatform/compute-image-import/cli_tools/gce_ovf_import/domain/mocks"
 	"github.com/GoogleCloudPlatform/compute-image-import/cli_tools/mocks"
@@ -52,9 +53,14 @@ func TestExecuteRequests_HappyCase(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	mockCompute := mocks.NewMockClient(ctrl)
 
+	// All requests share a quota budget, since they run in parallel.
+	var quotaBudget *daisyutils.QuotaBudget
+	withQuotaBudget := func(request importer.ImageImportRequest) gomock.Matcher {
+		return requestMatcher{request, &quotaBudget}
+	}
 	mockSingleImporter := ovfdomainmocks.NewMockDiskImporterInterface(ctrl)
-	mockSingleImporter.EXPECT().Import(gomock.Any(), requests[0], gomock.Any()).Return(dataDisks[0].GetURI(), nil)
-	mockSingleImporter.EXPECT().Import(gomock.Any(), requests[1], gomock.Any()).Return(dataDisks[1].GetURI(), nil)
+	mockSingleImporter.EXPECT().Import(gomock.Any(), withQuotaBudget(requests[0]), gomock.Any()).Return(dataDisks[0].GetURI(), nil)
+	mockSingleImporter.EXPECT().Import(gomock.Any(), withQuotaBudget(requests[1]), gomock.Any()).Return(dataDisks[1].GetURI(), nil)
 
 	mockLogger := mocks.NewMockToolLogger(ctrl)
 	mockLogger.EXPECT().NewLogger("[import-img-name-1]").Return(logging.NewToolLogger("test"))
@@ -73,6 +79,32 @@ func TestExecuteRequests_HappyCase(t *testing.T) {
 	assert.Equal(t, dataDisks, actualDisks)
 }
 
+// requestMatcher matches a request that is equal to expected, except for its quota budget,
+// which must be non-nil and the same for all matched requests.
+type requestMatcher struct {
+	expected    importer.ImageImportRequest
+	quotaBudget **daisyutils.QuotaBudget
+}
+
+func (m requestMatcher) Matches(x interface{}) bool {
+	actual, ok := x.(importer.ImageImportRequest)
+	if !ok || actual.QuotaBudget == nil {
+		return false
+	}
+	if *m.quotaBudget == nil {
+		*m.quotaBudget = actual.QuotaBudget
+	}
+	if actual.QuotaBudget != *m.quotaBudget {
+		return false
+	}
+	actual.QuotaBudget = nil
+	return gomock.Eq(m.expected).Matches(actual)
+}
+
+func (m requestMatcher) String() string {
+	return fmt.Sprintf("%+v with a shared quota budget", m.expected)
+}
+
 func makeRequest(imgName string) importer.ImageImportRequest {
 	return importer.ImageImportRequest{
 		ExecutionID:        imgName,
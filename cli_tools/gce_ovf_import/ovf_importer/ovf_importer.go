@@ -75,6 +75,7 @@ type OVFImporter struct {
 	ctx                 context.Context
 	storageClient       domain.StorageClientInterface
 	computeClient       daisyCompute.Client
+	workflowClients     daisyutils.WorkflowClients
 	multiDiskImporter   ovfdomain.MultiDiskImporterInterface
 	imageImporter       importer.Importer
 	tarGcsExtractor     domain.TarGcsExtractorInterface
@@ -86,6 +87,8 @@ type OVFImporter struct {
 	params              *ovfdomain.OVFImportParams
 	imageLocation       string
 	paramValidator      *ParamValidatorAndPopulator
+	permissionPreflight param.PermissionPreflight
+	networkPreflight    param.NetworkPreflight
 
 	// Populated when disk file import finishes.
 	images []domain.Image
@@ -100,8 +103,12 @@ type OVFImporter struct {
 // NewOVFImporter creates an OVF importer, including automatically populating dependencies,
 // such as compute/storage clients. workflowDir is the filesystem path to `daisy_workflows`.
 func NewOVFImporter(params *ovfdomain.OVFImportParams, logger logging.ToolLogger) (*OVFImporter, error) {
+	if err := daisyutils.ValidateTranslationSettings(params.WorkflowDir); err != nil {
+		return nil, err
+	}
 	ctx := context.Background()
-	storageClient, err := storageutils.NewStorageClient(ctx, logger, option.WithCredentialsFile(params.Oauth))
+	storageClient, err := storageutils.NewStorageClientForBackend(ctx, logger, params.StorageBackend,
+		option.WithCredentialsFile(params.Oauth))
 	if err != nil {
 		return nil, err
 	}
@@ -109,17 +116,36 @@ func NewOVFImporter(params *ovfdomain.OVFImportParams, logger logging.ToolLogger
 	if err != nil {
 		return nil, err
 	}
-	tarGcsExtractor := storageutils.NewTarGcsExtractor(ctx, storageClient, logger)
-	workingDirOVFImportWorkflow := toWorkingDir(getImportWorkflowPath(params), params)
-	ovfImporter := &OVFImporter{
-		ctx:                 ctx,
-		storageClient:       storageClient,
-		computeClient:       computeClient,
-		multiDiskImporter:   multidiskimporter.NewMultiDiskImporter(params.WorkflowDir, computeClient, storageClient, logger),
+	permissionPreflight, err := param.CreatePermissionPreflight(ctx, params.Oauth, computeClient, storageClient)
+	if err != nil {
+		return nil, err
+	}
+	networkPreflight, err := param.CreateNetworkPreflight(ctx, params.Oauth, params.EndpointsOverride.Compute, computeClient)
+	if err != nil {
+		return nil, err
+	}
+	// Workflows share the compute client, which the quota preflight uses to read quotas.
+	return newOVFImporter(ctx, params, computeClient, storageClient,
+		daisyutils.WorkflowClients{ComputeClient: computeClient}, permissionPreflight, networkPreflight, logger), nil
+}
+
+// newOVFImporter creates an OVFImporter that uses the given clients and preflights. Its
+// workflows use workflowClients, or create their own clients when they're unset.
+func newOVFImporter(ctx context.Context, params *ovfdomain.OVFImportParams, computeClient daisyCompute.Client,
+	storageClient domain.StorageClientInterface, workflowClients daisyutils.WorkflowClients,
+	permissionPreflight param.PermissionPreflight, networkPreflight param.NetworkPreflight,
+	logger logging.ToolLogger) *OVFImporter {
+	return &OVFImporter{
+		ctx:             ctx,
+		storageClient:   storageClient,
+		computeClient:   computeClient,
+		workflowClients: workflowClients,
+		multiDiskImporter: multidiskimporter.NewMultiDiskImporter(params.WorkflowDir, computeClient, storageClient,
+			workflowClients, logger),
 		imageImporter:       nil,
 		resourceDeleter:     deleter.NewResourceDeleter(computeClient, logger),
-		tarGcsExtractor:     tarGcsExtractor,
-		workflowPath:        workingDirOVFImportWorkflow,
+		tarGcsExtractor:     storageutils.NewTarGcsExtractor(ctx, storageClient, logger),
+		workflowPath:        toWorkingDir(getImportWorkflowPath(params), params),
 		ovfDescriptorLoader: ovfutils.NewOvfDescriptorLoader(storageClient),
 		Logger:              logger,
 		params:              params,
@@ -132,8 +158,9 @@ func NewOVFImporter(params *ovfdomain.OVFImportParams, logger logging.ToolLogger
 			param.NewMachineSeriesDetector(computeClient),
 			logger,
 		},
+		permissionPreflight: permissionPreflight,
+		networkPreflight:    networkPreflight,
 	}
-	return ovfImporter, nil
 }
 
 func getImportWorkflowPath(params *ovfdomain.OVFImportParams) (workflow string) {
@@ -304,33 +331,19 @@ func (oi *OVFImporter) getMachineType(
 func (oi *OVFImporter) importDisksFiles() error {
 	oi.imageLocation = oi.params.Region
 
-	ovfGcsPath, shouldCleanup, err := oi.getOvfGcsPath(oi.params.ScratchBucketGcsPath)
-	if shouldCleanup {
-		oi.gcsPathToClean = ovfGcsPath
-	}
-	if err != nil {
-		return err
-	}
-
-	ovfDescriptor, diskInfos, err := ovfutils.GetOVFDescriptorAndDiskPaths(
-		oi.ovfDescriptorLoader, ovfGcsPath)
-	if err != nil {
-		return err
+	var diskInfos []ovfutils.DiskInfo
+	var err error
+	if oi.params.IsDiskFileImport() {
+		diskInfos, err = oi.readDiskFileParams()
+	} else {
+		diskInfos, err = oi.readOVFDescriptor()
 	}
-
-	osIDValue, err := oi.getOsIDValue(ovfDescriptor)
 	if err != nil {
 		return err
 	}
 
-	oi.params.OsID = osIDValue
 	oi.params.Deadline = oi.params.Deadline.Add(-1 * instanceConstructionTime)
 
-	oi.machineTypeString, err = oi.getMachineType(ovfDescriptor, *oi.params.Project, oi.params.Zone)
-	if err != nil {
-		return err
-	}
-
 	oi.Logger.User(fmt.Sprintf("Will create instance of `%v` machine type.", oi.machineTypeString))
 
 	var disksNamesPrefix string
@@ -361,6 +374,52 @@ func (oi *OVFImporter) importDisksFiles() error {
 	return err
 }
 
+// readOVFDescriptor loads the OVF descriptor, and uses it to populate the OS and machine type.
+// It returns the disk files referenced by the descriptor, starting with the boot disk.
+func (oi *OVFImporter) readOVFDescriptor() ([]ovfutils.DiskInfo, error) {
+	ovfGcsPath, shouldCleanup, err := oi.getOvfGcsPath(oi.params.ScratchBucketGcsPath)
+	if shouldCleanup {
+		oi.gcsPathToClean = ovfGcsPath
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	ovfDescriptor, diskInfos, err := ovfutils.GetOVFDescriptorAndDiskPaths(
+		oi.ovfDescriptorLoader, ovfGcsPath)
+	if err != nil {
+		return nil, err
+	}
+
+	if oi.params.OsID, err = oi.getOsIDValue(ovfDescriptor); err != nil {
+		return nil, err
+	}
+
+	oi.machineTypeString, err = oi.getMachineType(ovfDescriptor, *oi.params.Project, oi.params.Zone)
+	if err != nil {
+		return nil, err
+	}
+	return diskInfos, nil
+}
+
+// readDiskFileParams returns the disk files specified in the params, starting with the boot disk.
+// Without an OVF descriptor, the OS is detected from the boot disk unless it was specified, and the
+// machine type must be specified.
+func (oi *OVFImporter) readDiskFileParams() ([]ovfutils.DiskInfo, error) {
+	if oi.params.OsID != "" {
+		if err := daisyutils.ValidateOS(oi.params.OsID); err != nil {
+			return nil, err
+		}
+	}
+	oi.machineTypeString = oi.params.MachineType
+
+	diskInfos := []ovfutils.DiskInfo{{FilePath: oi.params.BootDiskFile}}
+	for _, dataDiskFile := range oi.params.DataDiskFiles {
+		diskInfos = append(diskInfos, ovfutils.DiskInfo{FilePath: dataDiskFile})
+	}
+	return diskInfos, nil
+}
+
 func (oi *OVFImporter) importBootDiskImage(bootDiskInfos ovfutils.DiskInfo, disksNamesPrefix string) error {
 
 	oi.Logger.User(fmt.Sprint("Importing boot Disk Image ..."))
@@ -370,7 +429,7 @@ func (oi *OVFImporter) importBootDiskImage(bootDiskInfos ovfutils.DiskInfo, disk
 	request, err := oi.buildBootDiskImageImportRequest(imageName, bootDiskInfos.FilePath)
 
 	if oi.imageImporter == nil {
-		oi.imageImporter, err = importer.NewImporter(request, oi.computeClient, oi.storageClient, oi.Logger)
+		oi.imageImporter, err = importer.NewImporter(request, oi.computeClient, oi.storageClient, oi.workflowClients, oi.Logger)
 		if err != nil {
 			return err
 		}
@@ -425,6 +484,20 @@ func (oi *OVFImporter) Import() error {
 	if err := oi.paramValidator.ValidateAndPopulate(oi.params); err != nil {
 		return err
 	}
+	if err := oi.permissionPreflight.Check(param.PermissionRequest{
+		Flow:                  param.OVFImportFlow,
+		Project:               *oi.params.Project,
+		ComputeServiceAccount: oi.params.ComputeServiceAccount,
+		NoExternalIP:          oi.params.NoExternalIP,
+		ScratchBucketGcsPath:  oi.params.ScratchBucketGcsPath,
+		SourceGcsPaths:        oi.sourceGcsPaths(),
+	}); err != nil {
+		return err
+	}
+	if err := oi.networkPreflight.Check(oi.params.Network, oi.params.Subnet, oi.params.Region,
+		*oi.params.Project, oi.params.NoExternalIP); err != nil {
+		return err
+	}
 	if err := oi.importDisksFiles(); err != nil {
 		oi.resourceDeleter.DeleteImagesIfExist(oi.images)
 		oi.resourceDeleter.DeleteDisksIfExist(oi.disks)
@@ -440,12 +513,21 @@ func (oi *OVFImporter) Import() error {
 	return nil
 }
 
+// sourceGcsPaths returns the Cloud Storage paths that are read by the import.
+func (oi *OVFImporter) sourceGcsPaths() []string {
+	if oi.params.IsDiskFileImport() {
+		return append([]string{oi.params.BootDiskFile}, oi.params.DataDiskFiles...)
+	}
+	return []string{oi.params.OvfOvaGcsPath}
+}
+
 func (oi *OVFImporter) createWorkerForFinalInstance() daisyutils.DaisyWorker {
 	// We enable nested virtualization to only boost the performance of worker VMs,
 	// so we don't propagate it to the output VM instance or a machine image.
 	// The same is true for the worker machine series argument - it mustn't affect
 	// the machine type of the final VM.
 	env := oi.params.EnvironmentSettings()
+	env.WorkflowClients = oi.workflowClients
 	env.NestedVirtualizationEnabled = false
 	env.WorkerMachineSeries = []string{}
 
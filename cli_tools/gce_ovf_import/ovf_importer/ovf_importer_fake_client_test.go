@@ -0,0 +1,164 @@
+//  Copyright 2026 Google Inc. All Rights Reserved.
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+package ovfimporter
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"cloud.google.com/go/storage"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/api/compute/v1"
+	"google.golang.org/api/option"
+	"google.golang.org/protobuf/proto"
+
+	computeutils "github.com/GoogleCloudPlatform/compute-image-import/cli_tools/common/utils/compute"
+	"github.com/GoogleCloudPlatform/compute-image-import/cli_tools/common/utils/daisyutils"
+	"github.com/GoogleCloudPlatform/compute-image-import/cli_tools/common/utils/logging"
+	"github.com/GoogleCloudPlatform/compute-image-import/cli_tools/common/utils/param"
+	storageutils "github.com/GoogleCloudPlatform/compute-image-import/cli_tools/common/utils/storage"
+	ovfdomain "github.com/GoogleCloudPlatform/compute-image-import/cli_tools/gce_ovf_import/domain"
+	"github.com/GoogleCloudPlatform/compute-image-import/proto/go/pb"
+)
+
+// TestImport_FakeClient imports an OVF package with a boot disk and a data disk, using
+// the in-memory compute client and a local storage backend. The workers that inflate
+// and translate the disks have scripted serial port output.
+func TestImport_FakeClient(t *testing.T) {
+	client := computeutils.NewFakeClient()
+	client.AddProject("project", "us-west1-a")
+	client.AddProject("compute-image-import")
+	client.AddLicense("compute-image-import", "virtual-disk-import")
+	for _, name := range []string{"debian-9-worker-v20230926", "debian-10-worker-v20230926", "debian-11-worker-v20241212"} {
+		client.AddImage("compute-image-import", &compute.Image{Name: name, DiskSizeGb: 10})
+	}
+	client.AddProject("ubuntu-os-cloud")
+	client.AddLicense("ubuntu-os-cloud", "ubuntu-1804-lts")
+	client.ScriptInstances("inst-importer", computeutils.InstanceScript{
+		SerialPortOutput: map[int64][]string{1: {
+			"Import: <serial-output key:'target-size-gb' value:'10'>\n" +
+				"Import: <serial-output key:'source-size-gb' value:'1'>\n" +
+				"Import: <serial-output key:'import-file-format' value:'vmdk'>\n" +
+				"ImportSuccess: Finished import.\n",
+		}},
+	})
+	client.ScriptInstances("run-inspection", computeutils.InstanceScript{
+		SerialPortOutput: map[int64][]string{1: {
+			"Status: inspection started.\n" +
+				fmt.Sprintf("Status: <serial-output key:'inspect_pb' value:'%s'>\n", encodedInspectionResults(t)) +
+				"Success: Done!\n",
+		}},
+	})
+	client.ScriptInstances("inst-translator", computeutils.InstanceScript{
+		SerialPortOutput: map[int64][]string{1: {"TranslateSuccess: Done!\n"}},
+	})
+
+	storageClient, err := storageutils.NewLocalStorageClient(context.Background(), logging.NewToolLogger("[test]"), t.TempDir())
+	assert.NoError(t, err)
+	assert.NoError(t, storageClient.CreateBucket("bucket", "project", nil))
+	descriptor, err := os.ReadFile("../../test_data/ovf_descriptor.ovf")
+	assert.NoError(t, err)
+	assert.NoError(t, storageClient.WriteToGCS("bucket", "ovf/descriptor.ovf", strings.NewReader(string(descriptor))))
+	for _, disk := range []string{"disk1.vmdk", "disk2.vmdk"} {
+		assert.NoError(t, storageClient.WriteToGCS("bucket", "ovf/"+disk, strings.NewReader("KDMV")))
+	}
+
+	project := "project"
+	params := &ovfdomain.OVFImportParams{
+		InstanceNames:        "imported-instance",
+		OvfOvaGcsPath:        "gs://bucket/ovf/",
+		OsID:                 "ubuntu-1804",
+		Zone:                 "us-west1-a",
+		Timeout:              "1h",
+		Project:              &project,
+		ScratchBucketGcsPath: "gs://bucket/scratch",
+		GcsLogsDisabled:      true,
+		CloudLogsDisabled:    true,
+		StdoutLogsDisabled:   true,
+		ReleaseTrack:         "ga",
+		BuildID:              "abcde",
+		WorkflowDir:          "../../../daisy_workflows",
+	}
+	logger := logging.NewToolLogger("[test]")
+	workflowClients := daisyutils.WorkflowClients{ComputeClient: client, StorageClient: newFakeStorageClient(t)}
+	oi := newOVFImporter(context.Background(), params, client, storageClient, workflowClients,
+		passingPermissionPreflight{}, passingNetworkPreflight{}, logger)
+	if !assert.NoError(t, oi.Import()) {
+		return
+	}
+
+	instance, err := client.GetInstance("project", "us-west1-a", "imported-instance")
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Len(t, instance.Disks, 2)
+	bootDisk, err := client.GetDisk("project", "us-west1-a", "imported-instance")
+	if assert.NoError(t, err) {
+		assert.Contains(t, bootDisk.Licenses, "projects/ubuntu-os-cloud/global/licenses/ubuntu-1804-lts")
+	}
+	images, _ := client.ListImages("project")
+	assert.Empty(t, images, "the boot disk's image is deleted")
+	instances, _ := client.ListInstances("project", "us-west1-a")
+	assert.Len(t, instances, 1, "workers are deleted")
+}
+
+// encodedInspectionResults returns the inspection worker's result for a BIOS-bootable
+// Ubuntu 18.04 disk.
+func encodedInspectionResults(t *testing.T) string {
+	bytes, err := proto.Marshal(&pb.InspectionResults{
+		OsCount:      1,
+		BiosBootable: true,
+		OsRelease: &pb.OsRelease{
+			MajorVersion: "18",
+			MinorVersion: "04",
+			Architecture: pb.Architecture_X64,
+			DistroId:     pb.Distro_UBUNTU,
+		},
+	})
+	assert.NoError(t, err)
+	return base64.StdEncoding.EncodeToString(bytes)
+}
+
+type passingPermissionPreflight struct{}
+
+func (passingPermissionPreflight) Check(param.PermissionRequest) error {
+	return nil
+}
+
+type passingNetworkPreflight struct{}
+
+func (passingNetworkPreflight) Check(network, subnet, region, project string, noExternalIP bool) error {
+	return nil
+}
+
+// newFakeStorageClient returns a storage client whose requests all succeed, for
+// workflows that validate their scratch bucket and upload their sources.
+func newFakeStorageClient(t *testing.T) *storage.Client {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		// "done" and "resource" complete the rewrite calls that copy GCS sources.
+		w.Write([]byte(`{"bucket": "scratch", "name": "object", "done": true, "resource": {"bucket": "scratch", "name": "object"}}`))
+	}))
+	t.Cleanup(ts.Close)
+	client, err := storage.NewClient(context.Background(), option.WithEndpoint(ts.URL), option.WithoutAuthentication())
+	assert.NoError(t, err)
+	return client
+}
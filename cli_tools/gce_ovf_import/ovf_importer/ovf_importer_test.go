@@ -117,6 +117,58 @@ func TestSetupWorkflow_WithMachineTypeInference(t *testing.T) {
 	}
 }
 
+func TestSetupWorkflow_DiskFilesWithoutDescriptor(t *testing.T) {
+	for _, mode := range []*importTarget{gmiMode, instanceMode} {
+		t.Run(mode.name, func(t *testing.T) {
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+			params := mode.paramGenerator()
+			params.MachineImageName = strings.ToLower(params.MachineImageName)
+			params.OvfOvaGcsPath = ""
+			params.BootDiskFile = "gs://bucket/boot.vmdk"
+			params.DataDiskFiles = []string{"gs://bucket/data-1.vmdk", "gs://bucket/data-2.vmdk"}
+			params.MachineType = "e2-standard-4"
+			dataDisk, err := disk.NewDisk("project-name", params.Zone, "data-disk")
+			assert.NoError(t, err)
+
+			mockStorageClient := mocks.NewMockStorageClientInterface(mockCtrl)
+			mockStorageObject := mocks.NewMockStorageObject(mockCtrl)
+			mockStorageObject.EXPECT().NewReader().Return(ioutil.NopCloser(strings.NewReader("file content")), nil)
+			mockStorageClient.EXPECT().GetObject("bucket", "boot.vmdk").Return(mockStorageObject)
+			mockImporter := imagemocks.NewMockImporter(mockCtrl)
+			mockImporter.EXPECT().Run(gomock.Any())
+			mockMultiDiskImporter := ovfdomainmocks.NewMockMultiDiskImporterInterface(mockCtrl)
+			mockMultiDiskImporter.EXPECT().Import(gomock.Any(), params, params.DataDiskFiles).Return(
+				[]domain.Disk{dataDisk, dataDisk}, nil)
+
+			// The descriptor loader and the tar extractor are nil, so a call to either panics.
+			oi := OVFImporter{ctx: context.Background(), workflowPath: mode.wfPath,
+				storageClient:     mockStorageClient,
+				imageImporter:     mockImporter,
+				multiDiskImporter: mockMultiDiskImporter,
+				Logger:            logging.NewToolLogger("test"), params: params}
+			assert.NoError(t, oi.importDisksFiles())
+			assert.Equal(t, []string{"gs://bucket/boot.vmdk", "gs://bucket/data-1.vmdk", "gs://bucket/data-2.vmdk"},
+				oi.sourceGcsPaths())
+			daisyutils.CheckWorkflow(oi.createWorkerForFinalInstance(), func(w *daisy.Workflow, err error) {
+				assert.NoError(t, err)
+				assertMachineType(t, w, "e2-standard-4")
+				assert.Len(t, w.Steps["create-instance"].CreateInstances.Instances[0].Disks, 3)
+			})
+		})
+	}
+}
+
+func TestDiskImport_DiskFilesWithoutDescriptor_ValidatesOS(t *testing.T) {
+	params := getAllInstanceImportParams()
+	params.OvfOvaGcsPath = ""
+	params.BootDiskFile = "gs://bucket/boot.vmdk"
+	params.OsID = "not-an-os"
+
+	oi := OVFImporter{workflowPath: instanceMode.wfPath, Logger: logging.NewToolLogger("test"), params: params}
+	assert.Error(t, oi.importDisksFiles())
+}
+
 func TestDiskImport_ErrorUnpackingOVA(t *testing.T) {
 	params := getAllInstanceImportParams()
 	project := defaultProject
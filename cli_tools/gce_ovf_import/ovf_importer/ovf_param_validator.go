@@ -60,6 +60,12 @@ const (
 
 	// EnableNestedVirtualizationFlagKey is key to enable nested virtualization on worker VMs
 	EnableNestedVirtualizationFlagKey = "enable-nested-virtualization"
+
+	// EmitWorkflowsDirFlagKey is key for the directory to which workflows are written
+	EmitWorkflowsDirFlagKey = "emit-workflows-dir"
+
+	// EmitWorkflowsOnlyFlagKey is key to write workflows without running them
+	EmitWorkflowsOnlyFlagKey = "emit-workflows-only"
 )
 
 // ParamValidatorAndPopulator validates parameters and infers missing values.
@@ -95,11 +101,10 @@ func (p *ParamValidatorAndPopulator) ValidateAndPopulate(params *ovfdomain.OVFIm
 		return err
 	}
 
-	if len(params.WorkerMachineSeries) == 0 {
-		params.WorkerMachineSeries, err = p.workerMachineSeriesDetector.Detect(*params.Project, params.Zone)
-		if err != nil {
-			return err
-		}
+	params.WorkerMachineSeries, err = p.workerMachineSeriesDetector.Detect(
+		*params.Project, params.Zone, params.WorkerMachineSeries)
+	if err != nil {
+		return err
 	}
 
 	if params.ReleaseTrack, err = p.resolveReleaseTrack(params.ReleaseTrack); err != nil {
@@ -134,6 +139,15 @@ func (p *ParamValidatorAndPopulator) ValidateAndPopulate(params *ovfdomain.OVFIm
 		params.InstanceAccessScopes = []string{}
 	}
 
+	if storageutils.IsLocalStorageBackend(params.StorageBackend) && (!params.EmitWorkflowsOnly || params.EmitWorkflowsDir == "") {
+		return daisy.Errf("storage backend `%v` requires -%v and -%v, since workflows use Cloud Storage",
+			params.StorageBackend, EmitWorkflowsOnlyFlagKey, EmitWorkflowsDirFlagKey)
+	}
+
+	if params.EmitWorkflowsOnly && params.EmitWorkflowsDir == "" {
+		return daisy.Errf("-%v requires -%v", EmitWorkflowsOnlyFlagKey, EmitWorkflowsDirFlagKey)
+	}
+
 	if params.InstanceNames == "" && params.MachineImageName == "" {
 		return daisy.Errf("Either the flag -%v or -%v must be provided", InstanceNameFlagKey, MachineImageNameFlagKey)
 	}
@@ -154,12 +168,26 @@ func (p *ParamValidatorAndPopulator) ValidateAndPopulate(params *ovfdomain.OVFIm
 		}
 	}
 
-	if err := validation.ValidateStringFlagNotEmpty(params.OvfOvaGcsPath, OvfGcsPathFlagKey); err != nil {
-		return err
-	}
+	if params.IsDiskFileImport() {
+		if params.OvfOvaGcsPath != "" {
+			return daisy.Errf("-%v can't be provided when importing disk files", OvfGcsPathFlagKey)
+		}
+		if params.MachineType == "" {
+			return daisy.Errf("A machine type must be provided when importing disk files")
+		}
+		for _, diskFile := range append([]string{params.BootDiskFile}, params.DataDiskFiles...) {
+			if _, err := storageutils.GetBucketNameFromGCSPath(diskFile); err != nil {
+				return daisy.Errf("Disk file `%v` should be a path to a file in Cloud Storage", diskFile)
+			}
+		}
+	} else {
+		if err := validation.ValidateStringFlagNotEmpty(params.OvfOvaGcsPath, OvfGcsPathFlagKey); err != nil {
+			return err
+		}
 
-	if _, err := storageutils.GetBucketNameFromGCSPath(params.OvfOvaGcsPath); err != nil {
-		return daisy.Errf("%v should be a path to OVF or OVA package in Cloud Storage", OvfGcsPathFlagKey)
+		if _, err := storageutils.GetBucketNameFromGCSPath(params.OvfOvaGcsPath); err != nil {
+			return daisy.Errf("%v should be a path to OVF or OVA package in Cloud Storage", OvfGcsPathFlagKey)
+		}
 	}
 
 	if params.Labels != "" {
@@ -46,6 +46,26 @@ func TestMachineImageStorageLocationProvidedForInstanceImport(t *testing.T) {
 	assertErrorOnValidate(t, params, "-machine-image-storage-location can't be provided when importing an instance")
 }
 
+func Test_ValidateAndParseParams_Fail_WhenWorkflowsAreOnlyEmittedWithoutDirectory(t *testing.T) {
+	params := getAllInstanceImportParams()
+	params.EmitWorkflowsOnly = true
+	assertErrorOnValidate(t, params, "-emit-workflows-only requires -emit-workflows-dir")
+}
+
+func Test_ValidateAndParseParams_Fail_WhenLocalStorageBackendRunsWorkflows(t *testing.T) {
+	params := getAllInstanceImportParams()
+	params.StorageBackend = "file:///tmp/gcs"
+	params.EmitWorkflowsDir = "/tmp/workflows"
+	assertErrorOnValidate(t, params, "storage backend `file:///tmp/gcs` requires -emit-workflows-only and -emit-workflows-dir")
+}
+
+func Test_ValidateAndParseParams_Fail_WhenLocalStorageBackendEmitsWorkflowsWithoutDirectory(t *testing.T) {
+	params := getAllInstanceImportParams()
+	params.StorageBackend = "file:///tmp/gcs"
+	params.EmitWorkflowsOnly = true
+	assertErrorOnValidate(t, params, "storage backend `file:///tmp/gcs` requires -emit-workflows-only and -emit-workflows-dir")
+}
+
 func Test_ValidateAndParseParams_Fail_WhenRegionCantBeFoundFromZone(t *testing.T) {
 	params := getAllInstanceImportParams()
 	params.Zone = "uscentral1"
@@ -144,7 +164,7 @@ func Test_ValidateAndParseParams_GenerateBucketName_WhenNotProvided(t *testing.T
 		params.Network, params.Subnet, defaultRegion, projectName).Return(params.Network, params.Subnet, nil)
 
 	mockMachineSeriesDetector := mocks.NewMockMachineSeriesDetector(mockCtrl)
-	mockMachineSeriesDetector.EXPECT().Detect(*params.Project, params.Zone).Return([]string{"n2", "n1"}, nil)
+	mockMachineSeriesDetector.EXPECT().Detect(*params.Project, params.Zone, gomock.Any()).Return([]string{"n2", "n1"}, nil)
 
 	mockStorage := mocks.NewMockStorageClientInterface(mockCtrl)
 	err := (&ParamValidatorAndPopulator{
@@ -192,7 +212,7 @@ func Test_ValidateAndParseParams_CreateScratchBucket_WhenGeneratedDoesntExist(t
 		params.Network, params.Subnet, defaultRegion, projectName).Return(params.Network, params.Subnet, nil)
 
 	mockMachineSeriesDetector := mocks.NewMockMachineSeriesDetector(mockCtrl)
-	mockMachineSeriesDetector.EXPECT().Detect(*params.Project, params.Zone).Return([]string{"n2", "n1"}, nil)
+	mockMachineSeriesDetector.EXPECT().Detect(*params.Project, params.Zone, gomock.Any()).Return([]string{"n2", "n1"}, nil)
 
 	mockStorage := mocks.NewMockStorageClientInterface(mockCtrl)
 	mockStorage.EXPECT().CreateBucket(expectedBucketName, projectName, &storage.BucketAttrs{
@@ -227,7 +247,7 @@ func Test_ValidateAndParseParams_UseNetworkResolverResults(t *testing.T) {
 		params.Network, params.Subnet, defaultRegion, *params.Project).Return("fixed-network", "fixed-subnet", nil)
 
 	mockMachineSeriesDetector := mocks.NewMockMachineSeriesDetector(mockCtrl)
-	mockMachineSeriesDetector.EXPECT().Detect(*params.Project, params.Zone).Return([]string{"n2", "n1"}, nil)
+	mockMachineSeriesDetector.EXPECT().Detect(*params.Project, params.Zone, gomock.Any()).Return([]string{"n2", "n1"}, nil)
 
 	err := (&ParamValidatorAndPopulator{
 		logger:                      logging.NewToolLogger("test"),
@@ -332,6 +352,28 @@ func Test_ValidateAndParseParams_ErrorMessages(t *testing.T) {
 				params.OvfOvaGcsPath = "%%%%%"
 			},
 			expectErrorToContain: "ovf-gcs-path should be a path to OVF or OVA package in Cloud Storage",
+		}, {
+			name: "don't allow OvfOvaGcsPath when importing disk files",
+			paramModifier: func(params *domain.OVFImportParams) {
+				params.BootDiskFile = "gs://bucket/boot.vmdk"
+			},
+			expectErrorToContain: "-ovf-gcs-path can't be provided when importing disk files",
+		}, {
+			name: "require MachineType when importing disk files",
+			paramModifier: func(params *domain.OVFImportParams) {
+				params.OvfOvaGcsPath = ""
+				params.BootDiskFile = "gs://bucket/boot.vmdk"
+				params.MachineType = ""
+			},
+			expectErrorToContain: "A machine type must be provided when importing disk files",
+		}, {
+			name: "validate DataDiskFiles",
+			paramModifier: func(params *domain.OVFImportParams) {
+				params.OvfOvaGcsPath = ""
+				params.BootDiskFile = "gs://bucket/boot.vmdk"
+				params.DataDiskFiles = []string{"%%%%%"}
+			},
+			expectErrorToContain: "Disk file `%%%%%` should be a path to a file in Cloud Storage",
 		}, {
 			name: "validate ReleaseTrack",
 			paramModifier: func(params *domain.OVFImportParams) {
@@ -387,6 +429,16 @@ func Test_ValidateAndParseParams_SuccessfulCases(t *testing.T) {
 			checkResult: func(t *testing.T, params *domain.OVFImportParams, importType string) {
 				assert.Equal(t, "", params.ClientID)
 			},
+		}, {
+			name: "allow disk files without OvfOvaGcsPath",
+			paramModifier: func(params *domain.OVFImportParams) {
+				params.OvfOvaGcsPath = ""
+				params.BootDiskFile = "gs://bucket/boot.vmdk"
+				params.DataDiskFiles = []string{"gs://bucket/data.vmdk"}
+			},
+			checkResult: func(t *testing.T, params *domain.OVFImportParams, importType string) {
+				assert.True(t, params.IsDiskFileImport())
+			},
 		}, {
 			name: "populate zone when missing",
 			paramModifier: func(params *domain.OVFImportParams) {
@@ -599,7 +651,7 @@ func runValidateAndParseParams(t *testing.T, params *domain.OVFImportParams) err
 		params.Network, params.Subnet, defaultRegion, defaultProject).Return(params.Network, params.Subnet, nil)
 
 	mockMachineSeriesDetector := mocks.NewMockMachineSeriesDetector(mockCtrl)
-	mockMachineSeriesDetector.EXPECT().Detect(defaultProject, defaultZone).Return([]string{"n2", "n1"}, nil)
+	mockMachineSeriesDetector.EXPECT().Detect(defaultProject, defaultZone, gomock.Any()).Return([]string{"n2", "n1"}, nil)
 
 	err := (&ParamValidatorAndPopulator{
 		metadataClient:              mockMetadataGce,
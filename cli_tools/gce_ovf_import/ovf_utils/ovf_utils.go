@@ -148,9 +148,9 @@ var ovfOSIDToImporterOSID = map[int16]OsInfo{
 	75:  {description: "Windows Embedded for Point of Service", importerOSIDs: []string{}},
 	76:  {description: "Microsoft Windows Server 2008", importerOSIDs: []string{}},
 	77:  {description: "Microsoft Windows Server 2008 64-Bit", importerOSIDs: []string{}},
-	78:  {description: "FreeBSD 64-Bit", importerOSIDs: []string{}},
+	78:  {description: "FreeBSD 64-Bit", importerOSIDs: []string{"freebsd-13", "freebsd-14"}},
 	79:  {description: "RedHat Enterprise Linux", importerOSIDs: []string{}},
-	80:  {description: "RedHat Enterprise Linux 64-Bit", importerOSIDs: []string{"rhel-6", "rhel-6-byol", "rhel-7", "rhel-7-byol", "rhel-8", "rhel-8-byol", "rhel-9", "rhel-9-byol"}},
+	80:  {description: "RedHat Enterprise Linux 64-Bit", importerOSIDs: []string{"rhel-6", "rhel-6-byol", "rhel-7", "rhel-7-byol", "rhel-8", "rhel-8-byol", "rhel-9", "rhel-9-byol", "rhel-10", "rhel-10-byol"}},
 	81:  {description: "Solaris 64-Bit", importerOSIDs: []string{}},
 	82:  {description: "SUSE", importerOSIDs: []string{}},
 	83:  {description: "SUSE 64-Bit", importerOSIDs: []string{"opensuse-15"}, nonDeterministic: true},
@@ -164,7 +164,7 @@ var ovfOSIDToImporterOSID = map[int16]OsInfo{
 	91:  {description: "TurboLinux", importerOSIDs: []string{}},
 	92:  {description: "TurboLinux 64-Bit", importerOSIDs: []string{}},
 	93:  {description: "Ubuntu", importerOSIDs: []string{}},
-	94:  {description: "Ubuntu 64-Bit", importerOSIDs: []string{"ubuntu-1404", "ubuntu-1604", "ubuntu-1804", "ubuntu-2004", "ubuntu-2204"}},
+	94:  {description: "Ubuntu 64-Bit", importerOSIDs: []string{"ubuntu-1404", "ubuntu-1604", "ubuntu-1804", "ubuntu-2004", "ubuntu-2204", "ubuntu-2404"}},
 	95:  {description: "Debian", importerOSIDs: []string{}},
 	96:  {description: "Debian 64-Bit", importerOSIDs: []string{"debian-8", "debian-9"}},
 	97:  {description: "Linux 2.4.x", importerOSIDs: []string{}},
@@ -178,7 +178,7 @@ var ovfOSIDToImporterOSID = map[int16]OsInfo{
 	106: {description: "CentOS 32-bit", importerOSIDs: []string{}},
 	107: {description: "CentOS 64-bit", importerOSIDs: []string{"centos-7"}},
 	108: {description: "Oracle Linux 32-bit", importerOSIDs: []string{}},
-	109: {description: "Oracle Linux 64-bit", importerOSIDs: []string{}},
+	109: {description: "Oracle Linux 64-bit", importerOSIDs: []string{"oracle-8", "oracle-9"}},
 	110: {description: "eComStation 32-bitx", importerOSIDs: []string{}},
 	111: {description: "Microsoft Windows Server 2011", importerOSIDs: []string{}},
 	113: {description: "Microsoft Windows Server 2012", importerOSIDs: []string{"windows-2012", "windows-2012-byol"}},
@@ -220,23 +220,35 @@ func GetOSInfoForInspectionResults(ir *pb.InspectionResults) (*OsInfo, int16) {
 // correctness. All Windows Client imports are in this category due to the fact we can't assume BYOL licensing.
 // Full list: https://vdc-download.vmware.com/vmwb-repository/dcr-public/da47f910-60ac-438b-8b9b-6122f4d14524/16b7274a-bf8b-4b4c-a05e-746f2aa93c8c/doc/vim.vm.GuestOsDescriptor.GuestOsIdentifier.html
 var ovfOSTypeToOSID = map[string]OsInfo{
-	"debian8_64Guest":       OsInfo{importerOSIDs: []string{"debian-8"}},
-	"debian9_64Guest":       OsInfo{importerOSIDs: []string{"debian-9"}},
-	"debian10_64Guest":      OsInfo{importerOSIDs: []string{"debian-10"}},
-	"debian11_64Guest":      OsInfo{importerOSIDs: []string{"debian-11"}},
-	"centos7_64Guest":       OsInfo{importerOSIDs: []string{"centos-7"}},
-	"rhel6_64Guest":         OsInfo{importerOSIDs: []string{"rhel-6"}},
-	"rhel7_64Guest":         OsInfo{importerOSIDs: []string{"rhel-7"}},
-	"windows7Server64Guest": OsInfo{importerOSIDs: []string{"windows-2008r2"}},
-	"ubuntu64Guest":         {importerOSIDs: []string{"ubuntu-1404", "ubuntu-1604", "ubuntu-1804"}, nonDeterministic: true},
-	"windows7Guest":         {importerOSIDs: []string{"windows-7-x86-byol"}, nonDeterministic: true},
-	"windows7_64Guest":      {importerOSIDs: []string{"windows-7-x64-byol"}, nonDeterministic: true},
-	"windows8Guest":         {importerOSIDs: []string{"windows-8-x86-byol"}, nonDeterministic: true},
-	"windows8_64Guest":      {importerOSIDs: []string{"windows-8-x64-byol"}, nonDeterministic: true},
-	"windows9Guest":         {importerOSIDs: []string{"windows-10-x86-byol"}, nonDeterministic: true},
-	"windows9_64Guest":      {importerOSIDs: []string{"windows-10-x64-byol"}, nonDeterministic: true},
-	"windows8Server64Guest": {importerOSIDs: []string{"windows-2012", "windows-2012r2", "windows-2012-byol", "windows-2012r2-byol"}},
-	"windows9Server64Guest": {importerOSIDs: []string{"windows-2016", "windows-2016-byol", "windows-2019", "windows-2019-byol"}},
+	"debian8_64Guest":            OsInfo{importerOSIDs: []string{"debian-8"}},
+	"debian9_64Guest":            OsInfo{importerOSIDs: []string{"debian-9"}},
+	"debian10_64Guest":           OsInfo{importerOSIDs: []string{"debian-10"}},
+	"debian11_64Guest":           OsInfo{importerOSIDs: []string{"debian-11"}},
+	"debian12_64Guest":           OsInfo{importerOSIDs: []string{"debian-12"}},
+	"centos7_64Guest":            OsInfo{importerOSIDs: []string{"centos-7"}},
+	"freebsd13_64Guest":          {importerOSIDs: []string{"freebsd-13"}},
+	"freebsd14_64Guest":          {importerOSIDs: []string{"freebsd-14"}},
+	"oracleLinux8_64Guest":       {importerOSIDs: []string{"oracle-8"}},
+	"oracleLinux9_64Guest":       {importerOSIDs: []string{"oracle-9"}},
+	"almalinux_64Guest":          {importerOSIDs: []string{"almalinux-8", "almalinux-9"}},
+	"amazonlinux2_64Guest":       {importerOSIDs: []string{"amazon-2"}},
+	"amazonlinux3_64Guest":       {importerOSIDs: []string{"amazon-2023"}},
+	"rhel6_64Guest":              OsInfo{importerOSIDs: []string{"rhel-6"}},
+	"rhel7_64Guest":              OsInfo{importerOSIDs: []string{"rhel-7"}},
+	"rhel10_64Guest":             {importerOSIDs: []string{"rhel-10", "rhel-10-byol"}},
+	"windows7Server64Guest":      OsInfo{importerOSIDs: []string{"windows-2008r2"}},
+	"ubuntu64Guest":              {importerOSIDs: []string{"ubuntu-1404", "ubuntu-1604", "ubuntu-1804"}, nonDeterministic: true},
+	"windows7Guest":              {importerOSIDs: []string{"windows-7-x86-byol"}, nonDeterministic: true},
+	"windows7_64Guest":           {importerOSIDs: []string{"windows-7-x64-byol"}, nonDeterministic: true},
+	"windows8Guest":              {importerOSIDs: []string{"windows-8-x86-byol"}, nonDeterministic: true},
+	"windows8_64Guest":           {importerOSIDs: []string{"windows-8-x64-byol"}, nonDeterministic: true},
+	"windows9Guest":              {importerOSIDs: []string{"windows-10-x86-byol"}, nonDeterministic: true},
+	"windows9_64Guest":           {importerOSIDs: []string{"windows-10-x64-byol"}, nonDeterministic: true},
+	"windows11_64Guest":          {importerOSIDs: []string{"windows-11-x64-byol"}, nonDeterministic: true},
+	"windows8Server64Guest":      {importerOSIDs: []string{"windows-2012", "windows-2012r2", "windows-2012-byol", "windows-2012r2-byol"}},
+	"windows9Server64Guest":      {importerOSIDs: []string{"windows-2016", "windows-2016-byol", "windows-2019", "windows-2019-byol"}},
+	"windows2019srvNext_64Guest": {importerOSIDs: []string{"windows-2022", "windows-2022-byol"}},
+	"windows2022srvNext_64Guest": {importerOSIDs: []string{"windows-2025", "windows-2025-byol"}},
 }
 
 // DiskInfo holds information about virtual disks in an OVF package
@@ -0,0 +1,191 @@
+//  Copyright 2026 Google Inc. All Rights Reserved.
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+package exporter
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"os"
+	"path"
+	"regexp"
+
+	daisy "github.com/GoogleCloudPlatform/compute-daisy"
+	"google.golang.org/api/cloudkms/v1"
+	"google.golang.org/api/option"
+
+	"github.com/GoogleCloudPlatform/compute-image-import/cli_tools/common/domain"
+	"github.com/GoogleCloudPlatform/compute-image-import/cli_tools/common/utils/storage"
+)
+
+const (
+	sha256Key = "sha256"
+
+	checksumFileSuffix  = ".sha256"
+	manifestFileSuffix  = ".mf"
+	signatureFileSuffix = ".sha256.sig"
+)
+
+var (
+	sha256Regex            = regexp.MustCompile("^[0-9a-f]{64}$")
+	kmsKeyVersionNameRegex = regexp.MustCompile("^projects/[^/]+/locations/[^/]+/keyRings/[^/]+/cryptoKeys/[^/]+/cryptoKeyVersions/[^/]+$")
+)
+
+// signer signs the checksum file of an exported image.
+type signer interface {
+	sign(message []byte) ([]byte, error)
+}
+
+// kmsSigner signs with a Cloud KMS asymmetric key version that uses SHA256 digests.
+type kmsSigner struct {
+	ctx            context.Context
+	service        *cloudkms.Service
+	keyVersionName string
+}
+
+func (s *kmsSigner) sign(message []byte) ([]byte, error) {
+	digest := sha256.Sum256(message)
+	resp, err := s.service.Projects.Locations.KeyRings.CryptoKeys.CryptoKeyVersions.AsymmetricSign(
+		s.keyVersionName, &cloudkms.AsymmetricSignRequest{
+			Digest: &cloudkms.Digest{Sha256: base64.StdEncoding.EncodeToString(digest[:])},
+		}).Context(s.ctx).Do()
+	if err != nil {
+		return nil, daisy.Errf("Failed to sign with KMS key %q: %v", s.keyVersionName, err)
+	}
+	return base64.StdEncoding.DecodeString(resp.Signature)
+}
+
+// localSigner signs with an RSA, ECDSA, or Ed25519 private key.
+type localSigner struct {
+	key crypto.Signer
+}
+
+func (s *localSigner) sign(message []byte) ([]byte, error) {
+	if _, isEd25519 := s.key.(ed25519.PrivateKey); isEd25519 {
+		return s.key.Sign(rand.Reader, message, crypto.Hash(0))
+	}
+	digest := sha256.Sum256(message)
+	return s.key.Sign(rand.Reader, digest[:], crypto.SHA256)
+}
+
+// newSigner returns a signer for either kmsKey or keyFile, or nil when neither is set.
+func newSigner(ctx context.Context, kmsKey, keyFile, oauth string) (signer, error) {
+	if kmsKey != "" && keyFile != "" {
+		return nil, daisy.Errf("-%v and -%v can't be provided together", SigningKMSKeyFlagKey, SigningKeyFileFlagKey)
+	}
+	if kmsKey != "" {
+		if !kmsKeyVersionNameRegex.MatchString(kmsKey) {
+			return nil, daisy.Errf("-%v must be a key version, such as "+
+				"projects/PROJECT/locations/LOCATION/keyRings/RING/cryptoKeys/KEY/cryptoKeyVersions/1", SigningKMSKeyFlagKey)
+		}
+		service, err := cloudkms.NewService(ctx, option.WithCredentialsFile(oauth))
+		if err != nil {
+			return nil, err
+		}
+		return &kmsSigner{ctx: ctx, service: service, keyVersionName: kmsKey}, nil
+	}
+	if keyFile != "" {
+		content, err := os.ReadFile(keyFile)
+		if err != nil {
+			return nil, daisy.Errf("Failed to read -%v: %v", SigningKeyFileFlagKey, err)
+		}
+		key, err := parsePrivateKey(content)
+		if err != nil {
+			return nil, daisy.Errf("Failed to parse -%v: %v", SigningKeyFileFlagKey, err)
+		}
+		return &localSigner{key: key}, nil
+	}
+	return nil, nil
+}
+
+// parsePrivateKey parses the first private key in a PEM file.
+func parsePrivateKey(content []byte) (crypto.Signer, error) {
+	block, _ := pem.Decode(content)
+	if block == nil {
+		return nil, fmt.Errorf("no PEM data found")
+	}
+	var key interface{}
+	var err error
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		key, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "EC PRIVATE KEY":
+		key, err = x509.ParseECPrivateKey(block.Bytes)
+	case "PRIVATE KEY":
+		key, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("unsupported PEM block %q", block.Type)
+	}
+	if err != nil {
+		return nil, err
+	}
+	signingKey, ok := key.(crypto.Signer)
+	if !ok {
+		return nil, fmt.Errorf("unsupported private key type %T", key)
+	}
+	return signingKey, nil
+}
+
+// writeToGCS returns a function that writes content to a GCS path.
+func writeToGCS(storageClient domain.StorageClientInterface) func(string, []byte) error {
+	return func(gcsPath string, content []byte) error {
+		bucket, object, err := storage.SplitGCSPath(gcsPath)
+		if err != nil {
+			return err
+		}
+		return storageClient.WriteToGCS(bucket, object, bytes.NewReader(content))
+	}
+}
+
+// writeChecksums uses write to write a sha256sum-style checksum file next to each exported
+// file, and optionally an OVF-style manifest and a signature of the checksum file.
+func writeChecksums(write func(destinationURI string, content []byte) error, destinationURIs, digests []string,
+	writeManifest bool, s signer) error {
+	for i, destinationURI := range destinationURIs {
+		digest := digests[i]
+		if !sha256Regex.MatchString(digest) {
+			return daisy.Errf("Failed to read the SHA256 of %v", destinationURI)
+		}
+		name := path.Base(destinationURI)
+
+		checksum := []byte(fmt.Sprintf("%v  %v\n", digest, name))
+		if err := write(destinationURI+checksumFileSuffix, checksum); err != nil {
+			return err
+		}
+		if writeManifest {
+			manifest := fmt.Sprintf("SHA256(%v)= %v\n", name, digest)
+			if err := write(destinationURI+manifestFileSuffix, []byte(manifest)); err != nil {
+				return err
+			}
+		}
+		if s != nil {
+			signature, err := s.sign(checksum)
+			if err != nil {
+				return err
+			}
+			if err := write(destinationURI+signatureFileSuffix, signature); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
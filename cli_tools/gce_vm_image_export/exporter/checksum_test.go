@@ -0,0 +1,163 @@
+//  Copyright 2026 Google Inc. All Rights Reserved.
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+package exporter
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/GoogleCloudPlatform/compute-image-import/cli_tools/mocks"
+)
+
+const digest = "9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08"
+
+type fakeSigner struct{}
+
+func (s *fakeSigner) sign(message []byte) ([]byte, error) {
+	return append([]byte("signed:"), message...), nil
+}
+
+func expectWrite(storageClient *mocks.MockStorageClientInterface, object, content string) {
+	storageClient.EXPECT().WriteToGCS("bucket", object, gomock.Any()).DoAndReturn(
+		func(_, _ string, reader io.Reader) error {
+			written, _ := io.ReadAll(reader)
+			if string(written) != content {
+				return errors.New("unexpected content for " + object + ": " + string(written))
+			}
+			return nil
+		})
+}
+
+func TestWriteChecksums(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	storageClient := mocks.NewMockStorageClientInterface(mockCtrl)
+	expectWrite(storageClient, "dir/image.vmdk.sha256", digest+"  image.vmdk\n")
+
+	assert.NoError(t, writeChecksums(writeToGCS(storageClient), []string{"gs://bucket/dir/image.vmdk"}, []string{digest}, false, nil))
+}
+
+func TestWriteChecksums_WithManifestAndSignature(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	storageClient := mocks.NewMockStorageClientInterface(mockCtrl)
+	for _, name := range []string{"image.vmdk", "image.qcow2"} {
+		expectWrite(storageClient, name+".sha256", digest+"  "+name+"\n")
+		expectWrite(storageClient, name+".mf", "SHA256("+name+")= "+digest+"\n")
+		expectWrite(storageClient, name+".sha256.sig", "signed:"+digest+"  "+name+"\n")
+	}
+
+	assert.NoError(t, writeChecksums(writeToGCS(storageClient), []string{"gs://bucket/image.vmdk", "gs://bucket/image.qcow2"},
+		[]string{digest, digest}, true, &fakeSigner{}))
+}
+
+func TestWriteChecksums_ReturnsError_WhenDigestMissing(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	storageClient := mocks.NewMockStorageClientInterface(mockCtrl)
+
+	assert.EqualError(t, writeChecksums(writeToGCS(storageClient), []string{"gs://bucket/image.vmdk"}, []string{""}, false, nil),
+		"Failed to read the SHA256 of gs://bucket/image.vmdk")
+}
+
+func TestWriteChecksums_ReturnsError_WhenWriteFails(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	storageClient := mocks.NewMockStorageClientInterface(mockCtrl)
+	storageClient.EXPECT().WriteToGCS("bucket", "image.vmdk.sha256", gomock.Any()).Return(errors.New("write failed"))
+
+	assert.EqualError(t, writeChecksums(writeToGCS(storageClient), []string{"gs://bucket/image.vmdk"}, []string{digest}, true, nil),
+		"write failed")
+}
+
+func TestNewSigner_LocalKeys(t *testing.T) {
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	ecKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	_, edKey, _ := ed25519.GenerateKey(rand.Reader)
+	ecDER, _ := x509.MarshalECPrivateKey(ecKey)
+	edDER, _ := x509.MarshalPKCS8PrivateKey(edKey)
+	message := []byte(digest + "  image.vmdk\n")
+	hashed := sha256.Sum256(message)
+
+	for _, tt := range []struct {
+		name   string
+		block  *pem.Block
+		verify func(signature []byte) bool
+	}{
+		{"rsa", &pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(rsaKey)}, func(signature []byte) bool {
+			return rsa.VerifyPKCS1v15(&rsaKey.PublicKey, crypto.SHA256, hashed[:], signature) == nil
+		}},
+		{"ecdsa", &pem.Block{Type: "EC PRIVATE KEY", Bytes: ecDER}, func(signature []byte) bool {
+			return ecdsa.VerifyASN1(&ecKey.PublicKey, hashed[:], signature)
+		}},
+		{"ed25519", &pem.Block{Type: "PRIVATE KEY", Bytes: edDER}, func(signature []byte) bool {
+			return ed25519.Verify(edKey.Public().(ed25519.PublicKey), message, signature)
+		}},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			keyFile := filepath.Join(t.TempDir(), "key.pem")
+			assert.NoError(t, os.WriteFile(keyFile, pem.EncodeToMemory(tt.block), 0600))
+
+			s, err := newSigner(context.Background(), "", keyFile, "")
+			assert.NoError(t, err)
+			signature, err := s.sign(message)
+			assert.NoError(t, err)
+			assert.True(t, tt.verify(signature))
+		})
+	}
+}
+
+func TestNewSigner_ReturnsNil_WhenSigningNotRequested(t *testing.T) {
+	s, err := newSigner(context.Background(), "", "", "")
+	assert.NoError(t, err)
+	assert.Nil(t, s)
+}
+
+func TestNewSigner_ReturnsError(t *testing.T) {
+	notAKey := filepath.Join(t.TempDir(), "key.pem")
+	assert.NoError(t, os.WriteFile(notAKey, []byte("not a key"), 0600))
+
+	for _, tt := range []struct {
+		kmsKey, keyFile, expectedError string
+	}{
+		{"projects/p/locations/l/keyRings/r/cryptoKeys/k/cryptoKeyVersions/1", notAKey,
+			"-signing_kms_key and -signing_key_file can't be provided together"},
+		{"projects/p/locations/l/keyRings/r/cryptoKeys/k", "",
+			"-signing_kms_key must be a key version, such as projects/PROJECT/locations/LOCATION/keyRings/RING/cryptoKeys/KEY/cryptoKeyVersions/1"},
+		{"", notAKey, "Failed to parse -signing_key_file: no PEM data found"},
+		{"", filepath.Join(t.TempDir(), "missing.pem"), "Failed to read -signing_key_file"},
+	} {
+		_, err := newSigner(context.Background(), tt.kmsKey, tt.keyFile, "")
+		assert.Error(t, err)
+		assert.True(t, strings.HasPrefix(err.Error(), tt.expectedError), err.Error())
+	}
+}
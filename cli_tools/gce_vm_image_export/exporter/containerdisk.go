@@ -0,0 +1,533 @@
+//  Copyright 2026 Google Inc. All Rights Reserved.
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+package exporter
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"hash"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	daisy "github.com/GoogleCloudPlatform/compute-daisy"
+	"golang.org/x/oauth2/google"
+
+	"github.com/GoogleCloudPlatform/compute-image-import/cli_tools/common/domain"
+	"github.com/GoogleCloudPlatform/compute-image-import/cli_tools/common/utils/storage"
+)
+
+const (
+	containerDiskFormat = "containerdisk"
+
+	// containerDiskFile is where KubeVirt looks for the disk in a containerDisk image.
+	containerDiskFile = "disk/disk.qcow2"
+	// containerDiskOwner is the qemu user that KubeVirt runs VMs as.
+	containerDiskOwner = 107
+
+	ociIndexMediaType    = "application/vnd.oci.image.index.v1+json"
+	ociManifestMediaType = "application/vnd.oci.image.manifest.v1+json"
+	ociConfigMediaType   = "application/vnd.oci.image.config.v1+json"
+	ociLayerMediaType    = "application/vnd.oci.image.layer.v1.tar+gzip"
+
+	cloudPlatformScope = "https://www.googleapis.com/auth/cloud-platform"
+
+	// registryResponseTimeout bounds how long the registry takes to respond to a request
+	// after it's sent.
+	registryResponseTimeout = 5 * time.Minute
+)
+
+var (
+	registryReferenceRegex = regexp.MustCompile(
+		`^docker://([^/]+)/([a-z0-9]+(?:[._/-][a-z0-9]+)*)(?::([A-Za-z0-9_][A-Za-z0-9_.-]{0,127}))?$`)
+	// googleRegistryHostRegex matches the hosts of Artifact Registry and Container Registry,
+	// which are the only registries that are sent the Google access token.
+	googleRegistryHostRegex = regexp.MustCompile(`^([a-z0-9-]+\.)*(pkg\.dev|gcr\.io)$`)
+)
+
+// packagedFormats are exported by the worker in another format, and then packaged by the tool.
+var packagedFormats = map[string]string{
+	containerDiskFormat: "qcow2",
+}
+
+// workerFormats returns the comma-separated formats that the worker exports for formats.
+func workerFormats(formats string) string {
+	var result []string
+	for _, format := range strings.Split(formats, ",") {
+		format = strings.TrimSpace(format)
+		if workerFormat, found := packagedFormats[format]; found {
+			format = workerFormat
+		}
+		result = append(result, format)
+	}
+	return strings.Join(result, ",")
+}
+
+// ociArchitecture returns the OCI architecture of an image whose GCE architecture is
+// gceArchitecture. Images with an unspecified architecture are x86.
+func ociArchitecture(gceArchitecture string) string {
+	if gceArchitecture == "ARM64" {
+		return "arm64"
+	}
+	return "amd64"
+}
+
+// validateContainerDiskDestination checks that a containerDisk is exported on its own, to either a
+// container registry or an OCI layout tarball in Cloud Storage.
+func validateContainerDiskDestination(destinationURI string, formats []string) error {
+	destinationURI = strings.TrimSpace(destinationURI)
+	isContainerDisk := false
+	for _, format := range formats {
+		isContainerDisk = isContainerDisk || format == containerDiskFormat
+	}
+	if !isContainerDisk {
+		if strings.HasPrefix(destinationURI, "docker://") {
+			return daisy.Errf("-%v can only be a container image when -format=%v", DestinationURIFlagKey, containerDiskFormat)
+		}
+		return nil
+	}
+	if len(formats) > 1 {
+		return daisy.Errf("-format=%v can't be combined with other formats", containerDiskFormat)
+	}
+	if !registryReferenceRegex.MatchString(destinationURI) && !strings.HasPrefix(destinationURI, "gs://") {
+		return daisy.Errf("When -format=%v, -%v must be a container image, such as "+
+			"docker://LOCATION-docker.pkg.dev/PROJECT/REPOSITORY/IMAGE:TAG, or an OCI layout tarball "+
+			"in Cloud Storage, such as gs://bucket/image.tar", containerDiskFormat, DestinationURIFlagKey)
+	}
+	return nil
+}
+
+// ociDescriptor references a blob of an OCI image.
+type ociDescriptor struct {
+	MediaType string `json:"mediaType"`
+	Digest    string `json:"digest"`
+	Size      int64  `json:"size"`
+}
+
+type ociManifest struct {
+	SchemaVersion int             `json:"schemaVersion"`
+	MediaType     string          `json:"mediaType"`
+	Config        ociDescriptor   `json:"config"`
+	Layers        []ociDescriptor `json:"layers"`
+}
+
+type ociIndex struct {
+	SchemaVersion int             `json:"schemaVersion"`
+	MediaType     string          `json:"mediaType"`
+	Manifests     []ociDescriptor `json:"manifests"`
+}
+
+type ociImageConfig struct {
+	Architecture string `json:"architecture"`
+	OS           string `json:"os"`
+	RootFS       struct {
+		Type    string   `json:"type"`
+		DiffIDs []string `json:"diff_ids"`
+	} `json:"rootfs"`
+}
+
+// containerDisk is a single-layer OCI image whose layer is staged in Cloud Storage.
+type containerDisk struct {
+	layerURI string
+	layer    ociDescriptor
+	config   []byte
+	manifest []byte
+}
+
+func newDescriptor(mediaType string, content []byte) ociDescriptor {
+	return ociDescriptor{MediaType: mediaType, Digest: sha256Digest(content), Size: int64(len(content))}
+}
+
+func sha256Digest(content []byte) string {
+	sum := sha256.Sum256(content)
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+func (d *containerDisk) configDescriptor() ociDescriptor {
+	return newDescriptor(ociConfigMediaType, d.config)
+}
+
+func (d *containerDisk) manifestDescriptor() ociDescriptor {
+	return newDescriptor(ociManifestMediaType, d.manifest)
+}
+
+// countingWriter counts the bytes written to it.
+type countingWriter struct {
+	n int64
+}
+
+func (w *countingWriter) Write(p []byte) (int, error) {
+	w.n += int64(len(p))
+	return len(p), nil
+}
+
+// buildContainerDisk packages the qcow2 file at qcow2URI into a layer that's written to layerURI,
+// and checks the file against the SHA256 reported by the worker. The image's architecture is
+// derived from gceArchitecture, the architecture of the exported image.
+func buildContainerDisk(storageClient domain.StorageClientInterface, qcow2URI, layerURI, qcow2Digest,
+	gceArchitecture string) (*containerDisk, error) {
+	bucket, object, err := storage.SplitGCSPath(qcow2URI)
+	if err != nil {
+		return nil, err
+	}
+	layerBucket, layerObject, err := storage.SplitGCSPath(layerURI)
+	if err != nil {
+		return nil, err
+	}
+	attrs, err := storageClient.GetObjectAttrs(bucket, object)
+	if err != nil {
+		return nil, daisy.Errf("Failed to read %v: %v", qcow2URI, err)
+	}
+	reader, err := storageClient.GetObject(bucket, object).NewReader()
+	if err != nil {
+		return nil, daisy.Errf("Failed to read %v: %v", qcow2URI, err)
+	}
+	defer reader.Close()
+
+	log.Printf("Packaging %v as a containerDisk layer.", qcow2URI)
+	diskHasher, diffIDHasher, layerHasher := sha256.New(), sha256.New(), sha256.New()
+	layerSize := &countingWriter{}
+	pipeReader, pipeWriter := io.Pipe()
+	go func() {
+		pipeWriter.CloseWithError(writeContainerDiskLayer(io.MultiWriter(pipeWriter, layerHasher, layerSize),
+			io.TeeReader(reader, diskHasher), attrs.Size, diffIDHasher))
+	}()
+	err = storageClient.WriteToGCS(layerBucket, layerObject, pipeReader)
+	pipeReader.CloseWithError(io.ErrClosedPipe)
+	if err != nil {
+		return nil, daisy.Errf("Failed to write the containerDisk layer: %v", err)
+	}
+	if digest := hex.EncodeToString(diskHasher.Sum(nil)); digest != qcow2Digest {
+		return nil, daisy.Errf("The SHA256 of %v is %v, which doesn't match the exported image's %v",
+			qcow2URI, digest, qcow2Digest)
+	}
+
+	config := ociImageConfig{Architecture: ociArchitecture(gceArchitecture), OS: "linux"}
+	config.RootFS.Type = "layers"
+	config.RootFS.DiffIDs = []string{"sha256:" + hex.EncodeToString(diffIDHasher.Sum(nil))}
+	d := &containerDisk{
+		layerURI: layerURI,
+		layer: ociDescriptor{
+			MediaType: ociLayerMediaType,
+			Digest:    "sha256:" + hex.EncodeToString(layerHasher.Sum(nil)),
+			Size:      layerSize.n,
+		},
+	}
+	if d.config, err = json.Marshal(config); err != nil {
+		return nil, err
+	}
+	if d.manifest, err = json.Marshal(ociManifest{
+		SchemaVersion: 2,
+		MediaType:     ociManifestMediaType,
+		Config:        d.configDescriptor(),
+		Layers:        []ociDescriptor{d.layer},
+	}); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// writeContainerDiskLayer writes a gzipped tar to w that contains the disk at containerDiskFile.
+// The uncompressed tar is also written to diffID.
+func writeContainerDiskLayer(w io.Writer, disk io.Reader, size int64, diffID hash.Hash) error {
+	gzipWriter := gzip.NewWriter(w)
+	tarWriter := tar.NewWriter(io.MultiWriter(gzipWriter, diffID))
+	for _, header := range []*tar.Header{
+		{Typeflag: tar.TypeDir, Name: "disk/", Mode: 0555},
+		{Typeflag: tar.TypeReg, Name: containerDiskFile, Mode: 0440, Size: size},
+	} {
+		header.Uid, header.Gid = containerDiskOwner, containerDiskOwner
+		if err := tarWriter.WriteHeader(header); err != nil {
+			return err
+		}
+	}
+	if _, err := io.CopyN(tarWriter, disk, size); err != nil {
+		return err
+	}
+	if err := tarWriter.Close(); err != nil {
+		return err
+	}
+	return gzipWriter.Close()
+}
+
+// packageContainerDisk packages the qcow2 file exported to qcow2URI as a containerDisk, and
+// pushes it to the registry or writes it to Cloud Storage, as chosen by args.DestinationURI.
+func packageContainerDisk(ctx context.Context, storageClient domain.StorageClientInterface, args *ImageExportRequest,
+	qcow2URI, layerURI, qcow2Digest, gceArchitecture string) error {
+	d, err := buildContainerDisk(storageClient, qcow2URI, layerURI, qcow2Digest, gceArchitecture)
+	if err != nil {
+		return err
+	}
+	destinationURI := strings.TrimSpace(args.DestinationURI)
+	if !strings.HasPrefix(destinationURI, "docker://") {
+		return writeOCILayout(storageClient, d, destinationURI)
+	}
+	registry, err := newRegistryClient(ctx, args.Oauth)
+	if err != nil {
+		return err
+	}
+	digest, err := registry.push(storageClient, d, destinationURI)
+	if err != nil {
+		return err
+	}
+	log.Printf("Pushed %v with digest %v.", destinationURI, digest)
+	return nil
+}
+
+// openLayer opens the staged layer of d.
+func (d *containerDisk) openLayer(storageClient domain.StorageClientInterface) (io.ReadCloser, error) {
+	bucket, object, err := storage.SplitGCSPath(d.layerURI)
+	if err != nil {
+		return nil, err
+	}
+	return storageClient.GetObject(bucket, object).NewReader()
+}
+
+// writeOCILayout writes d to destinationURI as a tarball of an OCI image layout.
+func writeOCILayout(storageClient domain.StorageClientInterface, d *containerDisk, destinationURI string) error {
+	bucket, object, err := storage.SplitGCSPath(destinationURI)
+	if err != nil {
+		return err
+	}
+	index, err := json.Marshal(ociIndex{
+		SchemaVersion: 2,
+		MediaType:     ociIndexMediaType,
+		Manifests:     []ociDescriptor{d.manifestDescriptor()},
+	})
+	if err != nil {
+		return err
+	}
+	pipeReader, pipeWriter := io.Pipe()
+	go func() {
+		pipeWriter.CloseWithError(func() error {
+			tarWriter := tar.NewWriter(pipeWriter)
+			for _, dir := range []string{"blobs/", "blobs/sha256/"} {
+				if err := tarWriter.WriteHeader(&tar.Header{Typeflag: tar.TypeDir, Name: dir, Mode: 0755}); err != nil {
+					return err
+				}
+			}
+			for _, file := range []struct {
+				name    string
+				content []byte
+			}{
+				{"oci-layout", []byte(`{"imageLayoutVersion":"1.0.0"}`)},
+				{"index.json", index},
+				{blobPath(d.manifestDescriptor()), d.manifest},
+				{blobPath(d.configDescriptor()), d.config},
+			} {
+				if err := writeTarFile(tarWriter, file.name, int64(len(file.content)), bytes.NewReader(file.content)); err != nil {
+					return err
+				}
+			}
+			layer, err := d.openLayer(storageClient)
+			if err != nil {
+				return err
+			}
+			defer layer.Close()
+			if err := writeTarFile(tarWriter, blobPath(d.layer), d.layer.Size, layer); err != nil {
+				return err
+			}
+			return tarWriter.Close()
+		}())
+	}()
+	log.Printf("Writing the OCI image layout to %v.", destinationURI)
+	err = storageClient.WriteToGCS(bucket, object, pipeReader)
+	pipeReader.CloseWithError(io.ErrClosedPipe)
+	return err
+}
+
+func blobPath(descriptor ociDescriptor) string {
+	return "blobs/" + strings.Replace(descriptor.Digest, ":", "/", 1)
+}
+
+func writeTarFile(tarWriter *tar.Writer, name string, size int64, content io.Reader) error {
+	if err := tarWriter.WriteHeader(&tar.Header{Typeflag: tar.TypeReg, Name: name, Mode: 0644, Size: size}); err != nil {
+		return err
+	}
+	_, err := io.CopyN(tarWriter, content, size)
+	return err
+}
+
+// registryClient pushes images with the Docker Registry HTTP API V2, which Artifact Registry implements.
+type registryClient struct {
+	client *http.Client
+	// authorization returns the Authorization header, or "" for anonymous access. It's only
+	// sent to Google registries; see sendsAuthorization.
+	authorization func() (string, error)
+}
+
+// newRegistryHTTPClient returns a client whose requests time out when the registry doesn't
+// respond. Client.Timeout isn't used, since it would also limit how long a layer of many
+// gigabytes takes to upload.
+func newRegistryHTTPClient() *http.Client {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.ResponseHeaderTimeout = registryResponseTimeout
+	return &http.Client{Transport: transport}
+}
+
+// sendsAuthorization returns whether requests to u are sent the Google access token, which
+// is only the case for Artifact Registry and Container Registry over HTTPS.
+func sendsAuthorization(u *url.URL) bool {
+	return u.Scheme == "https" && googleRegistryHostRegex.MatchString(u.Hostname())
+}
+
+// newRegistryClient returns a client that authenticates with the credentials in oauth, or with
+// the application default credentials when oauth is empty.
+func newRegistryClient(ctx context.Context, oauth string) (*registryClient, error) {
+	var credentials *google.Credentials
+	var err error
+	if oauth != "" {
+		content, readErr := os.ReadFile(oauth)
+		if readErr != nil {
+			return nil, daisy.Errf("Failed to read %v: %v", oauth, readErr)
+		}
+		credentials, err = google.CredentialsFromJSON(ctx, content, cloudPlatformScope)
+	} else {
+		credentials, err = google.FindDefaultCredentials(ctx, cloudPlatformScope)
+	}
+	if err != nil {
+		return nil, daisy.Errf("Failed to get credentials for the container registry: %v", err)
+	}
+	return &registryClient{
+		client: newRegistryHTTPClient(),
+		authorization: func() (string, error) {
+			token, err := credentials.TokenSource.Token()
+			if err != nil {
+				return "", err
+			}
+			return "Basic " + base64.StdEncoding.EncodeToString([]byte("oauth2accesstoken:"+token.AccessToken)), nil
+		},
+	}, nil
+}
+
+// push uploads d to reference, such as docker://HOST/REPOSITORY:TAG, and returns the manifest's digest.
+func (c *registryClient) push(storageClient domain.StorageClientInterface, d *containerDisk, reference string) (string, error) {
+	matches := registryReferenceRegex.FindStringSubmatch(strings.TrimSpace(reference))
+	if matches == nil {
+		return "", daisy.Errf("%v isn't a container image", reference)
+	}
+	host, repository, tag := matches[1], matches[2], matches[3]
+	if tag == "" {
+		tag = "latest"
+	}
+	scheme := "https"
+	if strings.HasPrefix(host, "localhost:") || strings.HasPrefix(host, "127.0.0.1:") {
+		scheme = "http"
+	}
+	repositoryURL := fmt.Sprintf("%v://%v/v2/%v", scheme, host, repository)
+
+	log.Printf("Pushing the containerDisk to %v.", reference)
+	if err := c.uploadBlob(repositoryURL, d.layer, func() (io.ReadCloser, error) {
+		return d.openLayer(storageClient)
+	}); err != nil {
+		return "", err
+	}
+	if err := c.uploadBlob(repositoryURL, d.configDescriptor(), func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(d.config)), nil
+	}); err != nil {
+		return "", err
+	}
+	req, err := http.NewRequest(http.MethodPut, repositoryURL+"/manifests/"+tag, bytes.NewReader(d.manifest))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", ociManifestMediaType)
+	if _, err := c.do(req, http.StatusCreated); err != nil {
+		return "", daisy.Errf("Failed to push the manifest to %v: %v", reference, err)
+	}
+	return d.manifestDescriptor().Digest, nil
+}
+
+// uploadBlob uploads a blob unless the repository already has it.
+func (c *registryClient) uploadBlob(repositoryURL string, descriptor ociDescriptor, open func() (io.ReadCloser, error)) error {
+	req, err := http.NewRequest(http.MethodHead, repositoryURL+"/blobs/"+descriptor.Digest, nil)
+	if err != nil {
+		return err
+	}
+	if _, err := c.do(req, http.StatusOK); err == nil {
+		return nil
+	}
+
+	if req, err = http.NewRequest(http.MethodPost, repositoryURL+"/blobs/uploads/", nil); err != nil {
+		return err
+	}
+	resp, err := c.do(req, http.StatusAccepted)
+	if err != nil {
+		return daisy.Errf("Failed to start the upload of %v: %v", descriptor.Digest, err)
+	}
+	location, err := resp.Request.URL.Parse(resp.Header.Get("Location"))
+	if err != nil {
+		return daisy.Errf("Failed to start the upload of %v: %v", descriptor.Digest, err)
+	}
+	query := location.Query()
+	query.Set("digest", descriptor.Digest)
+	location.RawQuery = query.Encode()
+
+	content, err := open()
+	if err != nil {
+		return err
+	}
+	defer content.Close()
+	if req, err = http.NewRequest(http.MethodPut, location.String(), content); err != nil {
+		return err
+	}
+	req.ContentLength = descriptor.Size
+	req.Header.Set("Content-Type", "application/octet-stream")
+	if _, err := c.do(req, http.StatusCreated); err != nil {
+		return daisy.Errf("Failed to upload %v: %v", descriptor.Digest, err)
+	}
+	return nil
+}
+
+// do sends req, and returns an error unless the response has expectedStatus.
+func (c *registryClient) do(req *http.Request, expectedStatus int) (*http.Response, error) {
+	if sendsAuthorization(req.URL) {
+		authorization, err := c.authorization()
+		if err != nil {
+			return nil, err
+		}
+		if authorization != "" {
+			req.Header.Set("Authorization", authorization)
+		}
+	}
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != expectedStatus {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return nil, fmt.Errorf("%v %v: %v %s", req.Method, redactQuery(req.URL), resp.Status, body)
+	}
+	return resp, nil
+}
+
+func redactQuery(u *url.URL) string {
+	redacted := *u
+	redacted.RawQuery = ""
+	return redacted.String()
+}
@@ -0,0 +1,333 @@
+//  Copyright 2026 Google Inc. All Rights Reserved.
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+package exporter
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/GoogleCloudPlatform/compute-image-import/cli_tools/common/domain"
+	"github.com/GoogleCloudPlatform/compute-image-import/cli_tools/common/utils/logging"
+	"github.com/GoogleCloudPlatform/compute-image-import/cli_tools/common/utils/storage"
+)
+
+const qcow2Content = "QFI\xfb the exported disk"
+
+func TestWorkerFormats(t *testing.T) {
+	assert.Equal(t, "qcow2", workerFormats("containerdisk"))
+	assert.Equal(t, "vmdk,qcow2", workerFormats("vmdk, qcow2"))
+}
+
+func TestBuildDaisyVarsWithContainerDisk(t *testing.T) {
+	got := buildDaisyVars("gs://bucket/staging/disk.qcow2", "image", "", 10, "containerdisk", "", "", "", "")
+	assert.Equal(t, "qcow2", got["format"])
+	assert.Equal(t, "11", got["export_instance_disk_size"])
+}
+
+func TestValidateContainerDiskDestination(t *testing.T) {
+	for _, tt := range []struct {
+		destinationURI string
+		formats        []string
+		expectedError  string
+	}{
+		{"docker://us-docker.pkg.dev/project/repo/image:tag", []string{"containerdisk"}, ""},
+		{"docker://localhost:5000/image", []string{"containerdisk"}, ""},
+		{"gs://bucket/image.tar", []string{"containerdisk"}, ""},
+		{"gs://bucket/image.vmdk", []string{"vmdk"}, ""},
+		{"docker://us-docker.pkg.dev/project/repo/image:tag", []string{"vmdk"},
+			"-destination_uri can only be a container image when -format=containerdisk"},
+		{"gs://bucket/image", []string{"containerdisk", "vmdk"}, "-format=containerdisk can't be combined with other formats"},
+		{"s3://bucket/image.tar", []string{"containerdisk"}, "When -format=containerdisk, -destination_uri must be a container image, " +
+			"such as docker://LOCATION-docker.pkg.dev/PROJECT/REPOSITORY/IMAGE:TAG, or an OCI layout tarball in Cloud Storage, " +
+			"such as gs://bucket/image.tar"},
+		{"docker://us-docker.pkg.dev/Project/Repo", []string{"containerdisk"}, "When -format=containerdisk, -destination_uri must be " +
+			"a container image, such as docker://LOCATION-docker.pkg.dev/PROJECT/REPOSITORY/IMAGE:TAG, or an OCI layout tarball in " +
+			"Cloud Storage, such as gs://bucket/image.tar"},
+	} {
+		t.Run(tt.destinationURI, func(t *testing.T) {
+			err := validateContainerDiskDestination(tt.destinationURI, tt.formats)
+			if tt.expectedError == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tt.expectedError)
+			}
+		})
+	}
+}
+
+// newStagingStorageClient returns a local storage client where the worker has staged
+// the exported qcow2 at gs://scratch/staging/disk.qcow2.
+func newStagingStorageClient(t *testing.T) domain.StorageClientInterface {
+	storageClient, err := storage.NewLocalStorageClient(context.Background(), logging.NewToolLogger("test"), t.TempDir())
+	assert.NoError(t, err)
+	for _, bucket := range []string{"scratch", "bucket"} {
+		assert.NoError(t, storageClient.CreateBucket(bucket, "project", nil))
+	}
+	assert.NoError(t, storageClient.WriteToGCS("scratch", "staging/disk.qcow2", strings.NewReader(qcow2Content)))
+	return storageClient
+}
+
+func setUpContainerDisk(t *testing.T) (domain.StorageClientInterface, *containerDisk) {
+	return setUpContainerDiskWithArchitecture(t, "")
+}
+
+func setUpContainerDiskWithArchitecture(t *testing.T, gceArchitecture string) (domain.StorageClientInterface, *containerDisk) {
+	storageClient := newStagingStorageClient(t)
+	sum := sha256.Sum256([]byte(qcow2Content))
+	d, err := buildContainerDisk(storageClient, "gs://scratch/staging/disk.qcow2", "gs://scratch/staging/layer.tar.gz",
+		hex.EncodeToString(sum[:]), gceArchitecture)
+	assert.NoError(t, err)
+	return storageClient, d
+}
+
+func readObject(t *testing.T, storageClient domain.StorageClientInterface, bucket, object string) []byte {
+	reader, err := storageClient.GetObject(bucket, object).NewReader()
+	assert.NoError(t, err)
+	defer reader.Close()
+	content, err := io.ReadAll(reader)
+	assert.NoError(t, err)
+	return content
+}
+
+// readTar returns the headers and content of the files in a tar.
+func readTar(t *testing.T, reader io.Reader) (map[string]*tar.Header, map[string][]byte) {
+	headers, files := map[string]*tar.Header{}, map[string][]byte{}
+	tarReader := tar.NewReader(reader)
+	for {
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			return headers, files
+		}
+		assert.NoError(t, err)
+		headers[header.Name] = header
+		files[header.Name], err = io.ReadAll(tarReader)
+		assert.NoError(t, err)
+	}
+}
+
+func TestBuildContainerDisk(t *testing.T) {
+	storageClient, d := setUpContainerDisk(t)
+
+	layer := readObject(t, storageClient, "scratch", "staging/layer.tar.gz")
+	assert.Equal(t, sha256Digest(layer), d.layer.Digest)
+	assert.Equal(t, int64(len(layer)), d.layer.Size)
+	gzipReader, err := gzip.NewReader(bytes.NewReader(layer))
+	assert.NoError(t, err)
+	uncompressed, err := io.ReadAll(gzipReader)
+	assert.NoError(t, err)
+	headers, files := readTar(t, bytes.NewReader(uncompressed))
+	assert.Equal(t, qcow2Content, string(files["disk/disk.qcow2"]))
+	assert.Equal(t, containerDiskOwner, headers["disk/disk.qcow2"].Uid)
+	assert.Equal(t, containerDiskOwner, headers["disk/"].Gid)
+
+	var config ociImageConfig
+	assert.NoError(t, json.Unmarshal(d.config, &config))
+	assert.Equal(t, "amd64", config.Architecture)
+	assert.Equal(t, []string{sha256Digest(uncompressed)}, config.RootFS.DiffIDs)
+	var manifest ociManifest
+	assert.NoError(t, json.Unmarshal(d.manifest, &manifest))
+	assert.Equal(t, d.configDescriptor(), manifest.Config)
+	assert.Equal(t, []ociDescriptor{d.layer}, manifest.Layers)
+}
+
+func TestBuildContainerDisk_UsesArchitectureOfImage(t *testing.T) {
+	_, d := setUpContainerDiskWithArchitecture(t, "ARM64")
+
+	var config ociImageConfig
+	assert.NoError(t, json.Unmarshal(d.config, &config))
+	assert.Equal(t, "arm64", config.Architecture)
+}
+
+func TestOCIArchitecture(t *testing.T) {
+	for gceArchitecture, expected := range map[string]string{
+		"":                         "amd64",
+		"ARCHITECTURE_UNSPECIFIED": "amd64",
+		"X86_64":                   "amd64",
+		"ARM64":                    "arm64",
+	} {
+		assert.Equal(t, expected, ociArchitecture(gceArchitecture), gceArchitecture)
+	}
+}
+
+func TestBuildContainerDisk_ReturnsError_WhenDigestDoesNotMatch(t *testing.T) {
+	storageClient := newStagingStorageClient(t)
+
+	_, err := buildContainerDisk(storageClient, "gs://scratch/staging/disk.qcow2", "gs://scratch/staging/layer.tar.gz",
+		strings.Repeat("0", 64), "")
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "doesn't match the exported image's")
+}
+
+func TestWriteOCILayout(t *testing.T) {
+	storageClient, d := setUpContainerDisk(t)
+
+	assert.NoError(t, writeOCILayout(storageClient, d, "gs://bucket/image.tar"))
+	_, files := readTar(t, bytes.NewReader(readObject(t, storageClient, "bucket", "image.tar")))
+	assert.JSONEq(t, `{"imageLayoutVersion":"1.0.0"}`, string(files["oci-layout"]))
+	var index ociIndex
+	assert.NoError(t, json.Unmarshal(files["index.json"], &index))
+	assert.Equal(t, []ociDescriptor{d.manifestDescriptor()}, index.Manifests)
+	for _, descriptor := range []ociDescriptor{d.manifestDescriptor(), d.configDescriptor(), d.layer} {
+		assert.Equal(t, descriptor.Digest, sha256Digest(files[blobPath(descriptor)]))
+	}
+}
+
+// fakeRegistry implements the push endpoints of the Docker Registry HTTP API V2.
+type fakeRegistry struct {
+	sync.Mutex
+	blobs          map[string][]byte
+	manifests      map[string][]byte
+	authorizations []string
+}
+
+func (r *fakeRegistry) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	r.Lock()
+	defer r.Unlock()
+	r.authorizations = append(r.authorizations, req.Header.Get("Authorization"))
+	const prefix = "/v2/project/repo/image/"
+	path := strings.TrimPrefix(req.URL.Path, prefix)
+	switch {
+	case !strings.HasPrefix(req.URL.Path, prefix):
+		w.WriteHeader(http.StatusNotFound)
+	case req.Method == http.MethodHead && strings.HasPrefix(path, "blobs/"):
+		if _, found := r.blobs[strings.TrimPrefix(path, "blobs/")]; found {
+			w.WriteHeader(http.StatusOK)
+		} else {
+			w.WriteHeader(http.StatusNotFound)
+		}
+	case req.Method == http.MethodPost && path == "blobs/uploads/":
+		w.Header().Set("Location", prefix+"blobs/uploads/upload-id?state=abc")
+		w.WriteHeader(http.StatusAccepted)
+	case req.Method == http.MethodPut && path == "blobs/uploads/upload-id":
+		content, _ := io.ReadAll(req.Body)
+		digest := req.URL.Query().Get("digest")
+		if sha256Digest(content) != digest || req.URL.Query().Get("state") != "abc" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		r.blobs[digest] = content
+		w.WriteHeader(http.StatusCreated)
+	case req.Method == http.MethodPut && strings.HasPrefix(path, "manifests/"):
+		content, _ := io.ReadAll(req.Body)
+		r.manifests[strings.TrimPrefix(path, "manifests/")] = content
+		w.WriteHeader(http.StatusCreated)
+	default:
+		w.WriteHeader(http.StatusBadRequest)
+	}
+}
+
+func TestRegistryClient_Push(t *testing.T) {
+	storageClient, d := setUpContainerDisk(t)
+	registry := &fakeRegistry{blobs: map[string][]byte{}, manifests: map[string][]byte{}}
+	server := httptest.NewServer(registry)
+	defer server.Close()
+	client := &registryClient{client: server.Client(), authorization: func() (string, error) {
+		return "Basic token", nil
+	}}
+
+	digest, err := client.push(storageClient, d,
+		"docker://"+strings.TrimPrefix(server.URL, "http://")+"/project/repo/image:v1")
+	assert.NoError(t, err)
+	assert.Equal(t, d.manifestDescriptor().Digest, digest)
+	assert.Equal(t, d.manifest, registry.manifests["v1"])
+	assert.Equal(t, d.config, registry.blobs[d.configDescriptor().Digest])
+	assert.Equal(t, readObject(t, storageClient, "scratch", "staging/layer.tar.gz"), registry.blobs[d.layer.Digest])
+	for _, authorization := range registry.authorizations {
+		assert.Empty(t, authorization, "the token is only sent to Google registries")
+	}
+}
+
+func TestRegistryClient_Push_SendsTokenToGoogleRegistry(t *testing.T) {
+	storageClient, d := setUpContainerDisk(t)
+	registry := &fakeRegistry{blobs: map[string][]byte{}, manifests: map[string][]byte{}}
+	server := httptest.NewTLSServer(registry)
+	defer server.Close()
+	// Connect to the server for every host, and accept its certificate for us-docker.pkg.dev.
+	transport := server.Client().Transport.(*http.Transport).Clone()
+	transport.DialContext = func(ctx context.Context, network, _ string) (net.Conn, error) {
+		return (&net.Dialer{}).DialContext(ctx, network, server.Listener.Addr().String())
+	}
+	transport.TLSClientConfig.InsecureSkipVerify = true
+	client := &registryClient{client: &http.Client{Transport: transport}, authorization: func() (string, error) {
+		return "Basic token", nil
+	}}
+
+	_, err := client.push(storageClient, d, "docker://us-docker.pkg.dev/project/repo/image:v1")
+	assert.NoError(t, err)
+	assert.Equal(t, d.manifest, registry.manifests["v1"])
+	assert.NotEmpty(t, registry.authorizations)
+	for _, authorization := range registry.authorizations {
+		assert.Equal(t, "Basic token", authorization)
+	}
+}
+
+func TestSendsAuthorization(t *testing.T) {
+	for rawURL, expected := range map[string]bool{
+		"https://us-docker.pkg.dev/v2/project/repo/image/blobs/uploads/": true,
+		"https://gcr.io/v2/project/image/blobs/uploads/":                 true,
+		"https://eu.gcr.io/v2/project/image/blobs/uploads/":              true,
+		"http://us-docker.pkg.dev/v2/project/repo/image/blobs/uploads/":  false,
+		"http://localhost:5000/v2/project/image/blobs/uploads/":          false,
+		"http://127.0.0.1:5000/v2/project/image/blobs/uploads/":          false,
+		"https://registry.example.com/v2/project/image/blobs/uploads/":   false,
+		"https://pkg.dev.example.com/v2/project/image/blobs/uploads/":    false,
+		"https://storage.example.com/upload?host=us-docker.pkg.dev":      false,
+	} {
+		u, err := url.Parse(rawURL)
+		assert.NoError(t, err)
+		assert.Equal(t, expected, sendsAuthorization(u), rawURL)
+	}
+}
+
+func TestRegistryClient_Push_SkipsExistingBlobsAndDefaultsToLatest(t *testing.T) {
+	storageClient, d := setUpContainerDisk(t)
+	registry := &fakeRegistry{blobs: map[string][]byte{d.layer.Digest: nil}, manifests: map[string][]byte{}}
+	server := httptest.NewServer(registry)
+	defer server.Close()
+	client := &registryClient{client: server.Client(), authorization: func() (string, error) { return "", nil }}
+
+	_, err := client.push(storageClient, d, "docker://"+strings.TrimPrefix(server.URL, "http://")+"/project/repo/image")
+	assert.NoError(t, err)
+	assert.Nil(t, registry.blobs[d.layer.Digest])
+	assert.Equal(t, d.manifest, registry.manifests["latest"])
+}
+
+func TestRegistryClient_Push_ReturnsError_WhenUploadFails(t *testing.T) {
+	storageClient, d := setUpContainerDisk(t)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer server.Close()
+	client := &registryClient{client: server.Client(), authorization: func() (string, error) { return "", nil }}
+
+	_, err := client.push(storageClient, d, "docker://"+strings.TrimPrefix(server.URL, "http://")+"/project/repo/image")
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "403 Forbidden")
+}
@@ -26,8 +26,11 @@ import (
 
 	daisy "github.com/GoogleCloudPlatform/compute-daisy"
 	daisyCompute "github.com/GoogleCloudPlatform/compute-daisy/compute"
+	v1 "google.golang.org/api/compute/v1"
 	"google.golang.org/api/option"
 
+	"github.com/GoogleCloudPlatform/compute-image-import/cli_tools/common/domain"
+	"github.com/GoogleCloudPlatform/compute-image-import/cli_tools/common/image"
 	"github.com/GoogleCloudPlatform/compute-image-import/cli_tools/common/utils/compute"
 	"github.com/GoogleCloudPlatform/compute-image-import/cli_tools/common/utils/daisyutils"
 	"github.com/GoogleCloudPlatform/compute-image-import/cli_tools/common/utils/logging"
@@ -43,6 +46,7 @@ import (
 var (
 	WorkflowDir    = "daisy_workflows/export/"
 	ExportWorkflow = "image_export_ext.wf.json"
+	RootfsWorkflow = "rootfs_export.wf.json"
 )
 
 // Parameter key shared with external packages
@@ -51,18 +55,41 @@ const (
 	DestinationURIFlagKey     = "destination_uri"
 	SourceImageFlagKey        = "source_image"
 	SourceDiskSnapshotFlagKey = "source_disk_snapshot"
+	SourceDiskFlagKey         = "source_disk"
+	PortableForFlagKey        = "portable_for"
+	SigningKMSKeyFlagKey      = "signing_kms_key"
+	SigningKeyFileFlagKey     = "signing_key_file"
+	EmitWorkflowsDirFlagKey   = "emit_workflows_dir"
+	EmitWorkflowsOnlyFlagKey  = "emit_workflows_only"
 
 	targetSizeGBKey = "target-size-gb"
 	sourceSizeGBKey = "source-size-gb"
+
+	// See daisy_workflows/export/disk_export_ext.wf.json.
+	exportDiskStepName = "export-disk"
+	copyImageStepName  = "copy-image-object"
+
+	// maxStreamingBufferDiskSizeGb is the largest buffer disk used when all formats
+	// are streamable.
+	maxStreamingBufferDiskSizeGb = 200
 )
 
+// streamableFormats are streamed by the worker to GCS without writing the output
+// to its buffer disk. See daisy_workflows/export/export_disk_ext.sh.
+var streamableFormats = map[string]bool{
+	"raw":    true,
+	"tar.gz": true,
+}
+
 // ImageExportRequest includes the parameters required to perform an image export.
 type ImageExportRequest struct {
 	ClientID                    string
 	DestinationURI              string
 	SourceImage                 string
 	SourceDiskSnapshot          string
+	SourceDisk                  string
 	Format                      string
+	PortableFor                 string
 	Project                     string
 	Network                     string
 	Subnet                      string
@@ -79,15 +106,27 @@ type ImageExportRequest struct {
 	CurrentExecutablePath       string
 	NestedVirtualizationEnabled bool
 	WorkerMachineSeries         []string
+	EmitWorkflowsDir            string
+	EmitWorkflowsOnly           bool
+	OVFManifest                 bool
+	SigningKMSKey               string
+	SigningKeyFile              string
+	AWSAccessKeyID              string
+	AWSSecretAccessKey          string
+	AWSSessionToken             string
+	AWSRegion                   string
+	AzureSASToken               string
 }
 
-func validateAndParseFlags(destinationURI string, sourceImage string, sourceDiskSnapshot string, labels string) (map[string]string, error) {
+func validateAndParseFlags(destinationURI string, sourceImage string, sourceDiskSnapshot string, sourceDisk string,
+	labels string) (map[string]string, error) {
 	if err := validation.ValidateStringFlagNotEmpty(destinationURI, DestinationURIFlagKey); err != nil {
 		return nil, err
 	}
 	if err := validation.ValidateExactlyOneOfStringFlagNotEmpty(map[string]string{
 		SourceImageFlagKey:        sourceImage,
 		SourceDiskSnapshotFlagKey: sourceDiskSnapshot,
+		SourceDiskFlagKey:         sourceDisk,
 	}); err != nil {
 		return nil, err
 	}
@@ -102,6 +141,96 @@ func validateAndParseFlags(destinationURI string, sourceImage string, sourceDisk
 	return nil, nil
 }
 
+// parseFormats splits a comma-separated list of formats. When there's more than one format,
+// destinationURI is a prefix, and each format is exported to `<destinationURI>.<format>`.
+func parseFormats(format string, destinationURI string) ([]string, error) {
+	var formats []string
+	for _, f := range strings.Split(format, ",") {
+		f = strings.TrimSpace(f)
+		if f == "" {
+			return nil, daisy.Errf("-format must be a comma-separated list of formats, such as vmdk,qcow2")
+		}
+		for _, existing := range formats {
+			if existing == f {
+				return nil, daisy.Errf("-format lists %q more than once", f)
+			}
+		}
+		formats = append(formats, f)
+	}
+	if len(formats) > 1 && strings.HasSuffix(strings.TrimSpace(destinationURI), "/") {
+		return nil, daisy.Errf("When exporting to multiple formats, -%v is a prefix for the exported files, "+
+			"and can't end with a slash", DestinationURIFlagKey)
+	}
+	return formats, nil
+}
+
+// destinationURIs returns the location of the file exported for each format.
+func destinationURIs(destinationURI string, formats []string) []string {
+	destinationURI = strings.TrimSpace(destinationURI)
+	if len(formats) == 1 {
+		return []string{destinationURI}
+	}
+	var uris []string
+	for _, format := range formats {
+		uris = append(uris, destinationURI+"."+format)
+	}
+	return uris
+}
+
+// outputKeys returns the serial output keys under which key is reported for each exported file.
+func outputKeys(key string, formats []string) []string {
+	if len(formats) == 1 {
+		return []string{key}
+	}
+	var keys []string
+	for _, format := range formats {
+		keys = append(keys, key+"-"+format)
+	}
+	return keys
+}
+
+// updateWorkflowForMultipleFormats copies the file exported for each format to its destination.
+func updateWorkflowForMultipleFormats(w *daisy.Workflow, formats []string) {
+	if len(formats) == 1 {
+		return
+	}
+	copyStep := w.Steps[exportDiskStepName].IncludeWorkflow.Workflow.Steps[copyImageStepName]
+	copyObjects := daisy.CopyGCSObjects{}
+	for _, format := range formats {
+		copyObjects = append(copyObjects, daisy.CopyGCSObject{
+			Source:      "${OUTSPATH}/${NAME}." + format,
+			Destination: "${destination}." + format,
+		})
+	}
+	copyStep.CopyGCSObjects = &copyObjects
+}
+
+// bufferDiskSizeGb returns the size of the worker's buffer disk when exporting an image
+// of imageDiskSizeGb to formats.
+//
+// Streamable formats are piped to GCS, and only a few parts of the output are buffered at
+// a time. Other formats are written to the buffer by qemu-img; when there's more than one,
+// the buffer also holds a raw copy of the disk. We allow for some overhead (5%) in case of
+// completely random data.
+func bufferDiskSizeGb(imageDiskSizeGb int64, formats []string) int64 {
+	buffered := 0
+	for _, format := range formats {
+		if !streamableFormats[strings.TrimSpace(format)] {
+			buffered++
+		}
+	}
+	size := int64(math.Ceil(float64(imageDiskSizeGb) * 1.05))
+	switch {
+	case buffered > 1:
+		size = int64(math.Ceil(float64(imageDiskSizeGb) * 2.05))
+	case buffered == 0 && size > maxStreamingBufferDiskSizeGb:
+		// PD throughput scales with size, so small images keep a buffer of
+		// the usual size; larger images don't need more than the cap.
+		size = maxStreamingBufferDiskSizeGb
+	}
+	return size
+}
+
 func getWorkflowPath(currentExecutablePath string) string {
 	if basePath := os.Getenv("WORKFLOW_BASE_PATH"); basePath != "" {
 		return filepath.Join(basePath, ExportWorkflow)
@@ -135,11 +264,10 @@ func buildDaisyVars(destinationURI string, sourceImage string, sourceDiskSnapsho
 		varMap["source_disk_snapshot"] = param.GetGlobalResourcePath("snapshots", sourceDiskSnapshot)
 	}
 
+	format = workerFormats(format)
 	if imageDiskSizeGb > 0 {
-		// We want the size to allow for a 2 step (dd and tar) packaging of the image.
-		// In addition, we want to allow for some overhead (5%) in case of completely random data.
-		bufferDiskSizeGb := int64(math.Ceil(float64(imageDiskSizeGb) * 2.05))
-		varMap["export_instance_disk_size"] = strconv.FormatInt(bufferDiskSizeGb, 10)
+		varMap["export_instance_disk_size"] = strconv.FormatInt(
+			bufferDiskSizeGb(imageDiskSizeGb, strings.Split(format, ",")), 10)
 	}
 
 	if format != "" {
@@ -166,26 +294,78 @@ func buildDaisyVars(destinationURI string, sourceImage string, sourceDiskSnapsho
 
 // Run runs export workflow.
 func Run(logger logging.Logger, args *ImageExportRequest) error {
-
-	userLabels, err := validateAndParseFlags(args.DestinationURI, args.SourceImage, args.SourceDiskSnapshot, args.Labels)
-	if err != nil {
-		return err
-	}
-
 	ctx := context.Background()
-	metadataGCE := &compute.MetadataGCE{}
 	storageClient, err := storage.NewStorageClient(
 		ctx, logger, option.WithCredentialsFile(args.Oauth))
 	if err != nil {
 		return err
 	}
 	defer storageClient.Close()
-
-	scratchBucketCreator := storage.NewScratchBucketCreator(ctx, storageClient)
 	computeClient, err := param.CreateComputeClient(&ctx, args.Oauth, args.ComputeEndpoint)
 	if err != nil {
 		return err
 	}
+	permissionPreflight, err := param.CreatePermissionPreflight(ctx, args.Oauth, computeClient, storageClient)
+	if err != nil {
+		return err
+	}
+	// Workflows share the compute client, which the quota preflight uses to read quotas.
+	return run(ctx, logger, args, computeClient, storageClient,
+		daisyutils.WorkflowClients{ComputeClient: computeClient}, permissionPreflight)
+}
+
+// run runs export workflow using the given clients and permission preflight. The workflows
+// use workflowClients, or create their own clients when they're unset.
+func run(ctx context.Context, logger logging.Logger, args *ImageExportRequest, computeClient daisyCompute.Client,
+	storageClient domain.StorageClientInterface, workflowClients daisyutils.WorkflowClients,
+	permissionPreflight param.PermissionPreflight) error {
+	userLabels, err := validateAndParseFlags(args.DestinationURI, args.SourceImage, args.SourceDiskSnapshot, args.SourceDisk,
+		args.Labels)
+	if err != nil {
+		return err
+	}
+	if args.EmitWorkflowsOnly && args.EmitWorkflowsDir == "" {
+		return daisy.Errf("-%v requires -%v", EmitWorkflowsOnlyFlagKey, EmitWorkflowsDirFlagKey)
+	}
+	formats, err := parseFormats(args.Format, args.DestinationURI)
+	if err != nil {
+		return err
+	}
+	if err := validateContainerDiskDestination(args.DestinationURI, formats); err != nil {
+		return err
+	}
+	isContainerDisk := formats[0] == containerDiskFormat
+	if err := image.ValidatePortableFor(args.PortableFor, PortableForFlagKey); err != nil {
+		return err
+	}
+	if err := validateRootfsTar(formats, args.PortableFor); err != nil {
+		return err
+	}
+	isRootfsTar := formats[0] == rootfsTarFormat
+
+	tool := daisyutils.Tool{
+		HumanReadableName: "gce image export",
+		ResourceLabelName: "gce-image-export",
+	}
+	executionID := os.Getenv(os.Getenv(daisyutils.BuildIDOSEnvVarName))
+	if executionID == "" {
+		executionID = path.RandString(5)
+	}
+	tempLabels := tempResourceLabels(tool, executionID, userLabels)
+	temp := newTempResources(logger)
+	defer temp.deleteAll()
+	defer temp.deleteOnInterrupt()()
+
+	checksumSigner, err := newSigner(ctx, args.SigningKMSKey, args.SigningKeyFile, args.Oauth)
+	if err != nil {
+		return err
+	}
+	external, err := newExternalDestination(args)
+	if err != nil {
+		return err
+	}
+	metadataGCE := &compute.MetadataGCE{}
+	scratchBucketCreator := storage.NewScratchBucketCreator(ctx, storageClient)
 	resourceLocationRetriever := storage.NewResourceLocationRetriever(metadataGCE, computeClient)
 
 	region := new(string)
@@ -196,17 +376,68 @@ func Run(logger logging.Logger, args *ImageExportRequest) error {
 		scratchBucketCreator,
 		param.NewMachineSeriesDetector(computeClient),
 	)
+	// External destinations aren't in GCS, so they can't be used to pick the scratch bucket's location.
+	destinationForScratchBucket := args.DestinationURI
+	if external != nil || !strings.HasPrefix(strings.TrimSpace(args.DestinationURI), "gs://") {
+		destinationForScratchBucket = ""
+	}
 	err = paramPopulator.PopulateMissingParameters(&args.Project, args.ClientID, &args.Zone, region, &args.ScratchBucketGcsPath,
-		args.DestinationURI, nil, &args.Network, &args.Subnet, &args.WorkerMachineSeries)
+		destinationForScratchBucket, nil, &args.Network, &args.Subnet, &args.WorkerMachineSeries)
 	if err != nil {
 		return err
 	}
 
+	// For external destinations and containerDisks, the image is exported to the scratch bucket,
+	// and then copied or packaged.
+	workflowDestination := strings.TrimSpace(args.DestinationURI)
+	var stagingDir string
+	if external != nil || isContainerDisk {
+		stagingDir = path.JoinURL(args.ScratchBucketGcsPath, "image-export-"+path.RandString(5))
+		temp.add(func() { storageClient.DeleteGcsPath(stagingDir) })
+		workflowDestination = stagingDir + "/" + workflowDestination[strings.LastIndex(workflowDestination, "/")+1:]
+		if isContainerDisk {
+			workflowDestination = stagingDir + "/disk.qcow2"
+		}
+	}
+
+	destinations := destinationURIs(workflowDestination, formats)
+	if err := permissionPreflight.Check(param.PermissionRequest{
+		Flow:                  param.ImageExportFlow,
+		Project:               args.Project,
+		ComputeServiceAccount: args.ComputeServiceAccount,
+		ScratchBucketGcsPath:  args.ScratchBucketGcsPath,
+		DestinationGcsPaths:   destinations,
+		SourceDisk:            args.SourceDisk,
+	}); err != nil {
+		return err
+	}
+
 	var imageDiskSizeGb int64
+	sourceDiskSnapshot := args.SourceDiskSnapshot
 	if args.SourceImage != "" {
 		if imageDiskSizeGb, err = validateImageExists(computeClient, args.Project, args.SourceImage); err != nil {
 			return err
 		}
+	} else if args.SourceDisk != "" {
+		// Disks are exported from a temporary snapshot, which is deleted afterwards.
+		disk, err := parseSourceDisk(args.SourceDisk, args.Project, args.Zone)
+		if err != nil {
+			return err
+		}
+		snapshotCtx, cancel := context.WithTimeout(ctx, snapshotTimeout(args.Timeout))
+		defer cancel()
+		snapshotter := newDiskSnapshotter(snapshotCtx, computeClient, args.Oauth, args.ComputeEndpoint)
+		if imageDiskSizeGb, err = snapshotter.validateDiskExists(disk); err != nil {
+			return err
+		}
+		sourceDiskSnapshot = "image-export-" + path.RandString(5)
+		if !args.EmitWorkflowsOnly {
+			// Registered before creating the snapshot, since it exists while createSnapshot waits for it.
+			temp.add(func() { snapshotter.deleteSnapshot(args.Project, sourceDiskSnapshot) })
+			if err := snapshotter.createSnapshot(disk, args.Project, sourceDiskSnapshot, tempLabels); err != nil {
+				return err
+			}
+		}
 	} else {
 		if imageDiskSizeGb, err = validateSnapshotExists(computeClient, args.Project, args.SourceDiskSnapshot); err != nil {
 			return err
@@ -214,11 +445,29 @@ func Run(logger logging.Logger, args *ImageExportRequest) error {
 	}
 
 	varMap := buildDaisyVars(
-		args.DestinationURI, args.SourceImage, args.SourceDiskSnapshot, imageDiskSizeGb,
-		args.Format, args.Network, args.Subnet, *region, args.ComputeServiceAccount)
+		workflowDestination, args.SourceImage, sourceDiskSnapshot, imageDiskSizeGb,
+		strings.Join(formats, ","), args.Network, args.Subnet, *region, args.ComputeServiceAccount)
+	// Daisy only looks up snapshots by a URI that includes their project.
+	if snapshot := varMap["source_disk_snapshot"]; strings.HasPrefix(snapshot, "global/") {
+		varMap["source_disk_snapshot"] = "projects/" + args.Project + "/" + snapshot
+	}
 
+	workflowPath := getWorkflowPath(args.CurrentExecutablePath)
+	if isRootfsTar {
+		workflowPath = filepath.Join(filepath.Dir(workflowPath), RootfsWorkflow)
+	}
 	workflowProvider := func() (*daisy.Workflow, error) {
-		return daisy.NewFromFile(getWorkflowPath(args.CurrentExecutablePath))
+		w, err := daisy.NewFromFile(workflowPath)
+		if err != nil {
+			return nil, err
+		}
+		updateWorkflowForMultipleFormats(w, formats)
+		if args.PortableFor != "" {
+			if err := addPortableStep(w, args.PortableFor); err != nil {
+				return nil, err
+			}
+		}
+		return w, nil
 	}
 
 	env := daisyutils.EnvironmentSettings{
@@ -235,25 +484,104 @@ func Run(logger logging.Logger, args *ImageExportRequest) error {
 		Subnet:                      args.Subnet,
 		ComputeServiceAccount:       args.ComputeServiceAccount,
 		Labels:                      userLabels,
-		ExecutionID:                 os.Getenv(os.Getenv(daisyutils.BuildIDOSEnvVarName)),
+		ExecutionID:                 executionID,
 		WorkerMachineSeries:         args.WorkerMachineSeries,
 		NestedVirtualizationEnabled: args.NestedVirtualizationEnabled,
-		Tool: daisyutils.Tool{
-			HumanReadableName: "gce image export",
-			ResourceLabelName: "gce-image-export",
-		},
+		EmitWorkflowsDir:            args.EmitWorkflowsDir,
+		EmitWorkflowsOnly:           args.EmitWorkflowsOnly,
+		WorkflowClients:             workflowClients,
+		Tool:                        tool,
 	}
 
-	if env.ExecutionID == "" {
-		env.ExecutionID = path.RandString(5)
+	// rootfs-tar archives the filesystems of a temporary disk, which is inspected first.
+	var inspection *pb.InspectionResults
+	if isRootfsTar {
+		diskName := "image-export-rootfs-" + path.RandString(5)
+		diskURI := daisyutils.GetDiskURI(args.Project, args.Zone, diskName)
+		if !args.EmitWorkflowsOnly {
+			diskCreator := newDiskSnapshotter(ctx, computeClient, args.Oauth, args.ComputeEndpoint)
+			temp.add(func() { diskCreator.deleteDisk(args.Project, args.Zone, diskName) })
+			if err := diskCreator.createDisk(args.Project, args.Zone, diskName,
+				varMap["source_image"], varMap["source_disk_snapshot"], tempLabels); err != nil {
+				return err
+			}
+			temp.runWorkflow(func() {
+				inspection = inspectRootfsDisk(env, logger, workflowPath, diskURI)
+			})
+		}
+		varMap = rootfsDaisyVars(varMap, diskURI)
+	}
+	sizeKeys := outputKeys(targetSizeGBKey, formats)
+	digestKeys := outputKeys(sha256Key, formats)
+	keys := append(append([]string{sourceSizeGBKey}, sizeKeys...), digestKeys...)
+	if args.PortableFor != "" {
+		keys = append(keys, image.PortableChangesKey)
+	}
+	if isRootfsTar {
+		keys = append(keys, rootfsMountsKey)
+	}
+	var values map[string]string
+	temp.runWorkflow(func() {
+		values, err = daisyutils.NewDaisyWorker(workflowProvider, env, logger).RunAndReadSerialValues(varMap, keys...)
+	})
+	var targetsSizeGb []int64
+	for _, key := range sizeKeys {
+		targetsSizeGb = append(targetsSizeGb, stringutils.SafeStringToInt(values[key]))
 	}
-	values, err := daisyutils.NewDaisyWorker(workflowProvider, env, logger).RunAndReadSerialValues(
-		varMap, targetSizeGBKey, sourceSizeGBKey)
 	logger.Metric(&pb.OutputInfo{
 		SourcesSizeGb: []int64{stringutils.SafeStringToInt(values[sourceSizeGBKey])},
-		TargetsSizeGb: []int64{stringutils.SafeStringToInt(values[targetSizeGBKey])},
+		TargetsSizeGb: targetsSizeGb,
 	})
-	return err
+	if err != nil || args.EmitWorkflowsOnly {
+		return err
+	}
+	var digests []string
+	for _, key := range digestKeys {
+		digests = append(digests, values[key])
+	}
+	var portableChanges image.PortableChanges
+	if args.PortableFor != "" {
+		if portableChanges, err = readPortableChanges(logger, values[image.PortableChangesKey]); err != nil {
+			return err
+		}
+	}
+	var rootfs rootfsMetadata
+	if isRootfsTar {
+		if rootfs, err = newRootfsMetadata(inspection, values[rootfsMountsKey]); err != nil {
+			return err
+		}
+	}
+	if isContainerDisk {
+		architecture := sourceArchitecture(computeClient, args.Project, args.SourceImage, sourceDiskSnapshot)
+		return packageContainerDisk(ctx, storageClient, args, destinations[0], stagingDir+"/layer.tar.gz", digests[0],
+			architecture)
+	}
+	write := writeToGCS(storageClient)
+	if external != nil {
+		externalDestinations := destinationURIs(args.DestinationURI, formats)
+		if err := transferToExternalDestinations(storageClient, external, destinations, externalDestinations, digests); err != nil {
+			return err
+		}
+		write = writeToExternalDestination(external)
+		destinations = externalDestinations
+	}
+	if err := writeChecksums(write, destinations, digests, args.OVFManifest, checksumSigner); err != nil {
+		return err
+	}
+	if args.PortableFor != "" {
+		if err := writePortableChanges(write, portableChanges, destinations); err != nil {
+			return err
+		}
+	}
+	if isRootfsTar {
+		if err := writeRootfsMetadata(write, rootfs, destinations); err != nil {
+			return err
+		}
+	}
+	if args.SourceImage == "" {
+		return nil
+	}
+	return writeImageMetadata(write, computeClient, args.Project, args.SourceImage, destinations)
 }
 
 // validateImageExists checks whether imageName exists in the specified project.
@@ -265,12 +593,22 @@ func Run(logger logging.Logger, args *ImageExportRequest) error {
 // and we don't want to copy that here, since this is a convenience method to create
 // user-friendly messages.
 func validateImageExists(computeClient daisyCompute.Client, project string, imageURI string) (diskSizeGb int64, err error) {
+	image, err := getSourceImage(computeClient, project, imageURI)
+	if image == nil || err != nil {
+		return diskSizeGb, err
+	}
+	return image.DiskSizeGb, nil
+}
+
+// getSourceImage fetches imageURI, using the same validation as validateImageExists.
+// The image is nil when imageURI isn't recognized as an image.
+func getSourceImage(computeClient daisyCompute.Client, project string, imageURI string) (*v1.Image, error) {
 	// try to get image even before validation in case it's a valid URL,
 	// in order to obtain its size
 	var imageName string
 	if err := validation.ValidateImageName(imageURI); err != nil {
 		if project, imageName, err = validation.ValidateImageURI(imageURI); err != nil {
-			return diskSizeGb, nil
+			return nil, nil
 		}
 	} else {
 		imageName = imageURI
@@ -279,9 +617,28 @@ func validateImageExists(computeClient daisyCompute.Client, project string, imag
 	image, err := computeClient.GetImage(project, imageName)
 	if err != nil {
 		log.Printf("Error when fetching image %q: %q.", imageURI, err)
-		return diskSizeGb, daisy.Errf("Image %q not found", imageURI)
+		return nil, daisy.Errf("Image %q not found", imageURI)
 	}
-	return image.DiskSizeGb, nil
+	return image, nil
+}
+
+// sourceArchitecture returns the architecture of sourceImage, or of sourceSnapshot when
+// sourceImage is empty. Disks are exported from a snapshot, which has the disk's architecture.
+// The architecture is "" when it's unspecified, or when the source isn't recognized.
+func sourceArchitecture(computeClient daisyCompute.Client, project, sourceImage, sourceSnapshot string) string {
+	if sourceImage != "" {
+		if image, err := getSourceImage(computeClient, project, sourceImage); err == nil && image != nil {
+			return image.Architecture
+		}
+		return ""
+	}
+	if err := validation.ValidateSnapshotName(sourceSnapshot); err != nil {
+		return ""
+	}
+	if snapshot, err := computeClient.GetSnapshot(project, sourceSnapshot); err == nil {
+		return snapshot.Architecture
+	}
+	return ""
 }
 
 // validateSnapshotExists checks whether snapshotName exists in the specified project.
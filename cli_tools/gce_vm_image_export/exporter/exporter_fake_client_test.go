@@ -0,0 +1,157 @@
+//  Copyright 2026 Google Inc. All Rights Reserved.
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+package exporter
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	gcs "cloud.google.com/go/storage"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/api/compute/v1"
+	"google.golang.org/api/option"
+
+	"github.com/GoogleCloudPlatform/compute-image-import/cli_tools/common/image"
+	computeutils "github.com/GoogleCloudPlatform/compute-image-import/cli_tools/common/utils/compute"
+	"github.com/GoogleCloudPlatform/compute-image-import/cli_tools/common/utils/daisyutils"
+	"github.com/GoogleCloudPlatform/compute-image-import/cli_tools/common/utils/logging"
+	"github.com/GoogleCloudPlatform/compute-image-import/cli_tools/common/utils/param"
+	"github.com/GoogleCloudPlatform/compute-image-import/cli_tools/common/utils/storage"
+)
+
+// TestRun_FakeClient exports an image to GCS using the in-memory compute client and a
+// local storage backend. The export worker has scripted serial port output.
+func TestRun_FakeClient(t *testing.T) {
+	t.Setenv("WORKFLOW_BASE_PATH", "../../../daisy_workflows/export")
+	digest := strings.Repeat("ab", 32)
+	client := computeutils.NewFakeClient()
+	client.AddProject("project", "us-west1-a")
+	client.AddImage("project", &compute.Image{Name: "source", DiskSizeGb: 10})
+	client.AddProject("cos-cloud")
+	client.AddImage("cos-cloud", &compute.Image{Name: "cos-stable-1", Family: "cos-stable", DiskSizeGb: 10})
+	client.ScriptInstances("inst-export-disk", computeutils.InstanceScript{
+		SerialPortOutput: map[int64][]string{1: {
+			"GCEExport: <serial-output key:'source-size-gb' value:'10'>\n" +
+				"GCEExport: <serial-output key:'target-size-gb' value:'2'>\n" +
+				"GCEExport: <serial-output key:'sha256' value:'" + digest + "'>\n" +
+				"export success\n",
+		}},
+	})
+
+	storageClient, err := storage.NewLocalStorageClient(context.Background(), logging.NewToolLogger("[test]"), t.TempDir())
+	assert.NoError(t, err)
+	assert.NoError(t, storageClient.CreateBucket("bucket", "project", nil))
+
+	args := &ImageExportRequest{
+		DestinationURI:       "gs://bucket/export/image.vmdk",
+		SourceImage:          "source",
+		Format:               "vmdk",
+		Project:              "project",
+		Zone:                 "us-west1-a",
+		Timeout:              "1h",
+		ScratchBucketGcsPath: "gs://bucket/scratch",
+		GcsLogsDisabled:      true,
+		CloudLogsDisabled:    true,
+		StdoutLogsDisabled:   true,
+	}
+	workflowClients := daisyutils.WorkflowClients{ComputeClient: client, StorageClient: newFakeStorageClient(t)}
+	err = run(context.Background(), logging.NewToolLogger("[test]"), args, client, storageClient, workflowClients,
+		passingPermissionPreflight{})
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.Equal(t, digest+"  image.vmdk\n",
+		string(readObject(t, storageClient, "bucket", "export/image.vmdk"+checksumFileSuffix)))
+	assert.Contains(t, string(readObject(t, storageClient, "bucket", "export/image.vmdk"+image.MetadataFileSuffix)), `"sourceImage": "projects/project/global/images/source"`)
+	disks, _ := client.ListDisks("project", "us-west1-a")
+	assert.Empty(t, disks, "the worker's disks are deleted")
+	instances, _ := client.ListInstances("project", "us-west1-a")
+	assert.Empty(t, instances)
+}
+
+// TestRun_FakeClient_SourceDisk exports a zonal disk from a temporary snapshot, which
+// is deleted after the export.
+func TestRun_FakeClient_SourceDisk(t *testing.T) {
+	t.Setenv("WORKFLOW_BASE_PATH", "../../../daisy_workflows/export")
+	digest := strings.Repeat("cd", 32)
+	client := computeutils.NewFakeClient()
+	client.AddProject("project", "us-west1-a")
+	client.AddDisk("project", "us-west1-a", &compute.Disk{Name: "source", SizeGb: 10})
+	client.AddProject("cos-cloud")
+	client.AddImage("cos-cloud", &compute.Image{Name: "cos-stable-1", Family: "cos-stable", DiskSizeGb: 10})
+	client.ScriptInstances("inst-export-disk", computeutils.InstanceScript{
+		SerialPortOutput: map[int64][]string{1: {
+			"GCEExport: <serial-output key:'source-size-gb' value:'10'>\n" +
+				"GCEExport: <serial-output key:'target-size-gb' value:'2'>\n" +
+				"GCEExport: <serial-output key:'sha256' value:'" + digest + "'>\n" +
+				"export success\n",
+		}},
+	})
+
+	storageClient, err := storage.NewLocalStorageClient(context.Background(), logging.NewToolLogger("[test]"), t.TempDir())
+	assert.NoError(t, err)
+	assert.NoError(t, storageClient.CreateBucket("bucket", "project", nil))
+
+	args := &ImageExportRequest{
+		DestinationURI:       "gs://bucket/export/disk.vmdk",
+		SourceDisk:           "source",
+		Format:               "vmdk",
+		Project:              "project",
+		Zone:                 "us-west1-a",
+		Timeout:              "1h",
+		ScratchBucketGcsPath: "gs://bucket/scratch",
+		GcsLogsDisabled:      true,
+		CloudLogsDisabled:    true,
+		StdoutLogsDisabled:   true,
+	}
+	workflowClients := daisyutils.WorkflowClients{ComputeClient: client, StorageClient: newFakeStorageClient(t)}
+	err = run(context.Background(), logging.NewToolLogger("[test]"), args, client, storageClient, workflowClients,
+		passingPermissionPreflight{})
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.Equal(t, digest+"  disk.vmdk\n",
+		string(readObject(t, storageClient, "bucket", "export/disk.vmdk"+checksumFileSuffix)))
+	assert.Equal(t, "createSnapshot", client.Operations()[0].OperationType)
+	snapshots, _ := client.ListSnapshots("project")
+	assert.Empty(t, snapshots, "the temporary snapshot is deleted")
+	disks, _ := client.ListDisks("project", "us-west1-a")
+	assert.Len(t, disks, 1, "only the source disk remains")
+}
+
+type passingPermissionPreflight struct{}
+
+func (passingPermissionPreflight) Check(param.PermissionRequest) error {
+	return nil
+}
+
+// newFakeStorageClient returns a storage client whose requests all succeed, for
+// workflows that validate their scratch bucket and copy their outputs.
+func newFakeStorageClient(t *testing.T) *gcs.Client {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		// "done" and "resource" complete the rewrite calls that copy GCS objects.
+		w.Write([]byte(`{"bucket": "scratch", "name": "object", "done": true, "resource": {"bucket": "scratch", "name": "object"}}`))
+	}))
+	t.Cleanup(ts.Close)
+	client, err := gcs.NewClient(context.Background(), option.WithEndpoint(ts.URL), option.WithoutAuthentication())
+	assert.NoError(t, err)
+	return client
+}
@@ -15,37 +15,36 @@
 package exporter
 
 import (
+	"context"
 	"errors"
 	"testing"
 
+	daisy "github.com/GoogleCloudPlatform/compute-daisy"
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
 	v1 "google.golang.org/api/compute/v1"
 
+	"github.com/GoogleCloudPlatform/compute-image-import/cli_tools/common/utils/daisyutils"
+	"github.com/GoogleCloudPlatform/compute-image-import/cli_tools/common/utils/logging"
 	"github.com/GoogleCloudPlatform/compute-image-import/cli_tools/common/utils/path"
 	"github.com/GoogleCloudPlatform/compute-image-import/cli_tools/mocks"
 )
 
 var (
-	destinationURI, sourceImage, sourceDiskSnapshot, format, network, subnet, labels string
+	destinationURI, sourceImage, sourceDiskSnapshot, sourceDiskURI, format, network, subnet, labels string
 )
 
-func TestGetWorkflowPathWithoutFormatConversion(t *testing.T) {
-	resetArgs()
-	workflow := getWorkflowPath(format, "")
+func TestGetWorkflowPath(t *testing.T) {
+	workflow := getWorkflowPath("")
 	expectedWorkflow := path.ToWorkingDir(WorkflowDir+ExportWorkflow, "")
 	if workflow != expectedWorkflow {
 		t.Errorf("%v != %v", workflow, expectedWorkflow)
 	}
 }
 
-func TestGetWorkflowPathWithFormatConversion(t *testing.T) {
-	resetArgs()
-	workflow := getWorkflowPath("vmdk", "")
-	expectedWorkflow := path.ToWorkingDir(WorkflowDir+ExportAndConvertWorkflow, "")
-	if workflow != expectedWorkflow {
-		t.Errorf("%v != %v", workflow, expectedWorkflow)
-	}
+func TestGetWorkflowPathWithWorkflowBasePath(t *testing.T) {
+	t.Setenv("WORKFLOW_BASE_PATH", "/workflows")
+	assert.Equal(t, "/workflows/"+ExportWorkflow, getWorkflowPath(""))
 }
 
 func TestFlagsBothSourceImageAndSourceSnapshotNotProvided(t *testing.T) {
@@ -62,14 +61,40 @@ func TestFlagsBothSourceImageAndSourceSnapshotProvided(t *testing.T) {
 	assertErrorOnValidate("Expected error for both source_image and source_disk_snapshot flags provided", t)
 }
 
+func TestFlagsBothSourceSnapshotAndSourceDiskProvided(t *testing.T) {
+	resetArgs()
+	sourceImage = ""
+	sourceDiskSnapshot = "aSnapshot"
+	sourceDiskURI = "aDisk"
+	assertErrorOnValidate("Expected error for both source_disk_snapshot and source_disk flags provided", t)
+}
+
+func TestFlagsSourceDiskProvided(t *testing.T) {
+	resetArgs()
+	sourceImage = ""
+	sourceDiskSnapshot = ""
+	sourceDiskURI = "zones/us-central1-b/disks/aDisk"
+	_, err := validateAndParseFlags(destinationURI, sourceImage, sourceDiskSnapshot, sourceDiskURI, labels)
+	assert.NoError(t, err)
+}
+
 func TestFlagsDestinationUriNotProvided(t *testing.T) {
 	resetArgs()
 	destinationURI = ""
 	assertErrorOnValidate("Expected error for missing destination_uri flag", t)
 }
 
+func TestRun_ReturnsError_WhenWorkflowsAreOnlyEmittedWithoutDirectory(t *testing.T) {
+	err := run(context.Background(), logging.NewToolLogger("[test]"), &ImageExportRequest{
+		DestinationURI:    "gs://bucket/image.vmdk",
+		SourceImage:       "image",
+		EmitWorkflowsOnly: true,
+	}, nil, nil, daisyutils.WorkflowClients{}, nil)
+	assert.EqualError(t, err, "-emit_workflows_only requires -emit_workflows_dir")
+}
+
 func assertErrorOnValidate(errorMsg string, t *testing.T) {
-	if _, err := validateAndParseFlags(destinationURI, sourceImage, sourceDiskSnapshot, labels); err == nil {
+	if _, err := validateAndParseFlags(destinationURI, sourceImage, sourceDiskSnapshot, sourceDiskURI, labels); err == nil {
 		t.Error(errorMsg)
 	}
 }
@@ -119,6 +144,33 @@ func TestBuildDaisyVarsWithFormatConversion(t *testing.T) {
 	assert.Equal(t, 6, len(got))
 }
 
+func TestBuildDaisyVarsWithStreamedFormat(t *testing.T) {
+	resetArgs()
+	got := buildDaisyVars(destinationURI, sourceImage, sourceDiskSnapshot, 5000, "tar.gz",
+		network, subnet, "aRegion", "")
+
+	assert.Equal(t, "tar.gz", got["format"])
+	assert.Equal(t, "200", got["export_instance_disk_size"])
+}
+
+func TestBufferDiskSizeGb(t *testing.T) {
+	for _, tt := range []struct {
+		formats  []string
+		sizeGb   int64
+		expected int64
+	}{
+		{[]string{"tar.gz"}, 10, 11},
+		{[]string{"raw"}, 100, 105},
+		{[]string{"tar.gz", "raw"}, 4000, 200},
+		{[]string{"vmdk"}, 4000, 4200},
+		{[]string{"tar.gz", "vmdk"}, 100, 105},
+		{[]string{"vmdk", "qcow2"}, 100, 205},
+		{[]string{"tar.gz", "vmdk", "qcow2"}, 100, 205},
+	} {
+		assert.Equal(t, tt.expected, bufferDiskSizeGb(tt.sizeGb, tt.formats), tt.formats)
+	}
+}
+
 func TestBuildDaisyVarsWithSimpleImageName(t *testing.T) {
 	resetArgs()
 	ws := "\t \r\n\f\u0085\u00a0\u2000\u3000"
@@ -174,6 +226,56 @@ func TestBuildDaisyVarsWithoutComputeServiceAccount(t *testing.T) {
 	assert.False(t, hasVar)
 }
 
+func TestParseFormats(t *testing.T) {
+	formats, err := parseFormats(" vmdk, qcow2 ,vhdx ", "gs://bucket/image")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"vmdk", "qcow2", "vhdx"}, formats)
+
+	formats, err = parseFormats("vmdk", "gs://bucket/dir/")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"vmdk"}, formats)
+}
+
+func TestParseFormats_ReturnsError(t *testing.T) {
+	for _, tt := range []struct {
+		format, destinationURI, expectedError string
+	}{
+		{"vmdk,,qcow2", "gs://bucket/image", "-format must be a comma-separated list of formats, such as vmdk,qcow2"},
+		{"vmdk,vmdk", "gs://bucket/image", "-format lists \"vmdk\" more than once"},
+		{"vmdk,qcow2", "gs://bucket/dir/", "When exporting to multiple formats, -destination_uri is a prefix " +
+			"for the exported files, and can't end with a slash"},
+	} {
+		_, err := parseFormats(tt.format, tt.destinationURI)
+		assert.EqualError(t, err, tt.expectedError)
+	}
+}
+
+func TestDestinationURIsAndOutputKeys(t *testing.T) {
+	assert.Equal(t, []string{"gs://bucket/image.vmdk"}, destinationURIs("gs://bucket/image.vmdk", []string{"vmdk"}))
+	assert.Equal(t, []string{"target-size-gb"}, outputKeys(targetSizeGBKey, []string{"vmdk"}))
+
+	formats := []string{"vmdk", "qcow2"}
+	assert.Equal(t, []string{"gs://bucket/image.vmdk", "gs://bucket/image.qcow2"},
+		destinationURIs("gs://bucket/image", formats))
+	assert.Equal(t, []string{"target-size-gb-vmdk", "target-size-gb-qcow2"}, outputKeys(targetSizeGBKey, formats))
+}
+
+func TestUpdateWorkflowForMultipleFormats(t *testing.T) {
+	w, err := daisy.NewFromFile("../../../daisy_workflows/export/" + ExportWorkflow)
+	assert.NoError(t, err)
+	copyStep := w.Steps[exportDiskStepName].IncludeWorkflow.Workflow.Steps[copyImageStepName]
+	original := *copyStep.CopyGCSObjects
+
+	updateWorkflowForMultipleFormats(w, []string{"vmdk"})
+	assert.Equal(t, original, *copyStep.CopyGCSObjects)
+
+	updateWorkflowForMultipleFormats(w, []string{"vmdk", "qcow2"})
+	assert.Equal(t, daisy.CopyGCSObjects{
+		{Source: "${OUTSPATH}/${NAME}.vmdk", Destination: "${destination}.vmdk"},
+		{Source: "${OUTSPATH}/${NAME}.qcow2", Destination: "${destination}.qcow2"},
+	}, *copyStep.CopyGCSObjects)
+}
+
 func TestValidateImageExists_ReturnsNoError_WhenImageByNameFound(t *testing.T) {
 	mockCtrl := gomock.NewController(t)
 	defer mockCtrl.Finish()
@@ -247,10 +349,35 @@ func TestValidateSnapshotExists_ReturnsError_WhenSnapshotNotFound(t *testing.T)
 	assert.Equal(t, int64(0), diskSize)
 }
 
+func TestSourceArchitecture_ReadsImage(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	mockComputeClient := mocks.NewMockClient(mockCtrl)
+	mockComputeClient.EXPECT().GetImage("project", "image").Return(&v1.Image{Architecture: "ARM64"}, nil)
+	assert.Equal(t, "ARM64", sourceArchitecture(mockComputeClient, "project", "image", ""))
+}
+
+func TestSourceArchitecture_ReadsSnapshot(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	mockComputeClient := mocks.NewMockClient(mockCtrl)
+	mockComputeClient.EXPECT().GetSnapshot("project", "snapshot").Return(&v1.Snapshot{Architecture: "ARM64"}, nil)
+	assert.Equal(t, "ARM64", sourceArchitecture(mockComputeClient, "project", "", "snapshot"))
+}
+
+func TestSourceArchitecture_ReturnsEmpty_WhenImageNotFound(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	mockComputeClient := mocks.NewMockClient(mockCtrl)
+	mockComputeClient.EXPECT().GetImage("project", "image").Return(nil, errors.New("image not found"))
+	assert.Equal(t, "", sourceArchitecture(mockComputeClient, "project", "image", ""))
+}
+
 func resetArgs() {
 	destinationURI = "gs://bucket/exported_image"
 	sourceImage = "global/images/anImage"
 	sourceDiskSnapshot = ""
+	sourceDiskURI = ""
 	format = ""
 	network = "aNetwork"
 	subnet = "aSubnet"
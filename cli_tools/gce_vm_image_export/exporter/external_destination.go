@@ -0,0 +1,367 @@
+//  Copyright 2026 Google Inc. All Rights Reserved.
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+package exporter
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	daisy "github.com/GoogleCloudPlatform/compute-daisy"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3iface"
+	"github.com/aws/aws-sdk-go/service/s3/s3manager"
+	"github.com/aws/aws-sdk-go/service/s3/s3manager/s3manageriface"
+	"github.com/dustin/go-humanize"
+
+	"github.com/GoogleCloudPlatform/compute-image-import/cli_tools/common/domain"
+	"github.com/GoogleCloudPlatform/compute-image-import/cli_tools/common/utils/storage"
+	"github.com/GoogleCloudPlatform/compute-image-import/cli_tools/common/utils/validation"
+)
+
+// Flags for the credentials of external destinations. The AWS flags match gce_onestep_image_import.
+const (
+	AWSAccessKeyIDFlagKey     = "aws_access_key_id"
+	AWSSecretAccessKeyFlagKey = "aws_secret_access_key"
+	AWSSessionTokenFlagKey    = "aws_session_token"
+	AWSRegionFlagKey          = "aws_region"
+	AzureSASTokenFlagKey      = "azure_sas_token"
+)
+
+const (
+	uploadPartSize   = 64 * humanize.MiByte
+	uploadWorkers    = 4
+	maxAzureBlocks   = 50000
+	azureAPIVersion  = "2020-10-02"
+	maxUploadRetries = 5
+
+	// azureRequestTimeout bounds each request to Azure, including the upload of a block.
+	azureRequestTimeout = 10 * time.Minute
+)
+
+var (
+	s3URIRegex        = regexp.MustCompile(`^s3://([a-z0-9][-.a-z0-9]*)/(.+)$`)
+	azureBlobURLRegex = regexp.MustCompile(`^https://[a-z0-9]+\.blob\.core\.windows\.net/[^/]+/.+$`)
+)
+
+// externalDestination uploads exported files to a destination outside of Cloud Storage.
+type externalDestination interface {
+	// upload copies size bytes from reader to destinationURI, and verifies the size of the upload.
+	upload(destinationURI string, reader io.Reader, size int64) error
+}
+
+// newExternalDestination returns the destination for args.DestinationURI, or nil when it's in Cloud Storage.
+func newExternalDestination(args *ImageExportRequest) (externalDestination, error) {
+	destinationURI := strings.TrimSpace(args.DestinationURI)
+	switch {
+	case strings.HasPrefix(destinationURI, "s3://"):
+		if !s3URIRegex.MatchString(destinationURI) {
+			return nil, daisy.Errf("-%v must be an S3 object, such as s3://bucket/image.vmdk", DestinationURIFlagKey)
+		}
+		for _, flag := range []struct{ key, value string }{
+			{AWSAccessKeyIDFlagKey, args.AWSAccessKeyID},
+			{AWSSecretAccessKeyFlagKey, args.AWSSecretAccessKey},
+			{AWSRegionFlagKey, args.AWSRegion},
+		} {
+			if err := validation.ValidateStringFlagNotEmpty(flag.value, flag.key); err != nil {
+				return nil, err
+			}
+		}
+		awsSession, err := session.NewSession(&aws.Config{
+			Region: aws.String(args.AWSRegion),
+			Credentials: credentials.NewStaticCredentials(
+				args.AWSAccessKeyID,
+				args.AWSSecretAccessKey,
+				args.AWSSessionToken),
+		})
+		if err != nil {
+			return nil, daisy.Errf("failed to create AWS session: %v", err)
+		}
+		client := s3.New(awsSession)
+		return &s3Destination{
+			client: client,
+			uploader: s3manager.NewUploaderWithClient(client, func(u *s3manager.Uploader) {
+				u.PartSize = uploadPartSize
+				u.Concurrency = uploadWorkers
+			}),
+		}, nil
+	case strings.HasPrefix(destinationURI, "https://"):
+		if !azureBlobURLRegex.MatchString(destinationURI) {
+			return nil, daisy.Errf("-%v must be an Azure blob, such as "+
+				"https://ACCOUNT.blob.core.windows.net/CONTAINER/image.vhd", DestinationURIFlagKey)
+		}
+		if err := validation.ValidateStringFlagNotEmpty(args.AzureSASToken, AzureSASTokenFlagKey); err != nil {
+			return nil, err
+		}
+		return &azureDestination{
+			client:   &http.Client{Timeout: azureRequestTimeout},
+			sasToken: strings.TrimPrefix(strings.TrimSpace(args.AzureSASToken), "?"),
+			minBlock: uploadPartSize,
+			workers:  uploadWorkers,
+		}, nil
+	}
+	return nil, nil
+}
+
+// transferToExternalDestinations copies each file exported to stagedURIs in Cloud Storage to
+// the matching destination. While copying, it verifies that the SHA256 of the staged copy
+// matches digests, which the export worker computed; the destination's own copy isn't read back.
+func transferToExternalDestinations(storageClient domain.StorageClientInterface, destination externalDestination,
+	stagedURIs, destinationURIs, digests []string) error {
+	for i, stagedURI := range stagedURIs {
+		bucket, object, err := storage.SplitGCSPath(stagedURI)
+		if err != nil {
+			return err
+		}
+		attrs, err := storageClient.GetObjectAttrs(bucket, object)
+		if err != nil {
+			return daisy.Errf("Failed to read %v: %v", stagedURI, err)
+		}
+		reader, err := storageClient.GetObject(bucket, object).NewReader()
+		if err != nil {
+			return daisy.Errf("Failed to read %v: %v", stagedURI, err)
+		}
+		start := time.Now()
+		log.Printf("Copying %v to %v.", humanize.IBytes(uint64(attrs.Size)), destinationURIs[i])
+		hasher := sha256.New()
+		err = destination.upload(destinationURIs[i], io.TeeReader(reader, hasher), attrs.Size)
+		reader.Close()
+		if err != nil {
+			return err
+		}
+		if digest := hex.EncodeToString(hasher.Sum(nil)); digest != digests[i] {
+			return daisy.Errf("The SHA256 of the staged copy %v is %v, which doesn't match the export "+
+				"worker's digest %v. The file copied to %v may be corrupt.", stagedURI, digest, digests[i], destinationURIs[i])
+		}
+		log.Printf("Copied to %v in %v.", destinationURIs[i], time.Since(start))
+	}
+	return nil
+}
+
+// writeToExternalDestination returns a function that writes content to destination.
+func writeToExternalDestination(destination externalDestination) func(string, []byte) error {
+	return func(destinationURI string, content []byte) error {
+		return destination.upload(destinationURI, bytes.NewReader(content), int64(len(content)))
+	}
+}
+
+// s3Destination uploads to S3 with a parallel multipart upload.
+type s3Destination struct {
+	client   s3iface.S3API
+	uploader s3manageriface.UploaderAPI
+}
+
+func (d *s3Destination) upload(destinationURI string, reader io.Reader, size int64) error {
+	matches := s3URIRegex.FindStringSubmatch(destinationURI)
+	if matches == nil {
+		return daisy.Errf("%v isn't an S3 object", destinationURI)
+	}
+	bucket, key := matches[1], matches[2]
+	if _, err := d.uploader.Upload(&s3manager.UploadInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+		Body:   reader,
+	}); err != nil {
+		return daisy.Errf("Failed to upload to %v: %v", destinationURI, err)
+	}
+	resp, err := d.client.HeadObject(&s3.HeadObjectInput{Bucket: aws.String(bucket), Key: aws.String(key)})
+	if err != nil {
+		return daisy.Errf("Failed to verify %v: %v", destinationURI, err)
+	}
+	if uploaded := aws.Int64Value(resp.ContentLength); uploaded != size {
+		return daisy.Errf("%v has %v bytes, expected %v", destinationURI, uploaded, size)
+	}
+	return nil
+}
+
+// azureDestination uploads to Azure Blob Storage by uploading blocks in parallel,
+// and then committing the block list.
+type azureDestination struct {
+	client   *http.Client
+	sasToken string
+	minBlock int64
+	workers  int
+}
+
+type azureBlock struct {
+	id   string
+	data []byte
+}
+
+func (d *azureDestination) upload(destinationURI string, reader io.Reader, size int64) error {
+	blockSize := d.minBlock
+	if minForSize := (size-1)/maxAzureBlocks + 1; minForSize > blockSize {
+		blockSize = minForSize
+	}
+
+	blocks := make(chan azureBlock)
+	// failed is closed when the first block fails to upload, which stops the reader
+	// and the other workers.
+	failed := make(chan struct{})
+	var failOnce sync.Once
+	var uploadErr error
+	var wg sync.WaitGroup
+	for i := 0; i < d.workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for block := range blocks {
+				select {
+				case <-failed:
+					return
+				default:
+				}
+				if err := d.do(http.MethodPut, destinationURI, "comp=block&blockid="+url.QueryEscape(block.id), block.data); err != nil {
+					failOnce.Do(func() {
+						uploadErr = err
+						close(failed)
+					})
+					return
+				}
+			}
+		}()
+	}
+
+	var ids []string
+	var total int64
+	var readErr error
+read:
+	for {
+		select {
+		case <-failed:
+			break read
+		default:
+		}
+		data := make([]byte, blockSize)
+		n, err := io.ReadFull(reader, data)
+		if n > 0 {
+			id := base64.StdEncoding.EncodeToString([]byte(fmt.Sprintf("%010d", len(ids))))
+			ids = append(ids, id)
+			total += int64(n)
+			select {
+			case blocks <- azureBlock{id: id, data: data[:n]}:
+			case <-failed:
+				break read
+			}
+		}
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			break
+		}
+		if err != nil {
+			readErr = err
+			break
+		}
+	}
+	close(blocks)
+	wg.Wait()
+	if uploadErr != nil {
+		return daisy.Errf("Failed to upload to %v: %v", destinationURI, uploadErr)
+	}
+	if readErr != nil {
+		return daisy.Errf("Failed to upload to %v: %v", destinationURI, readErr)
+	}
+	if total != size {
+		return daisy.Errf("Read %v bytes for %v, expected %v", total, destinationURI, size)
+	}
+
+	var blockList strings.Builder
+	blockList.WriteString("<?xml version=\"1.0\" encoding=\"utf-8\"?><BlockList>")
+	for _, id := range ids {
+		blockList.WriteString("<Latest>" + id + "</Latest>")
+	}
+	blockList.WriteString("</BlockList>")
+	if err := d.do(http.MethodPut, destinationURI, "comp=blocklist", []byte(blockList.String())); err != nil {
+		return daisy.Errf("Failed to upload to %v: %v", destinationURI, err)
+	}
+	return d.verify(destinationURI, size)
+}
+
+// verify checks that the blob at destinationURI has size bytes.
+func (d *azureDestination) verify(destinationURI string, size int64) error {
+	req, err := http.NewRequest(http.MethodHead, destinationURI+"?"+d.sasToken, nil)
+	if err != nil {
+		return redactURLError(err)
+	}
+	req.Header.Set("x-ms-version", azureAPIVersion)
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return daisy.Errf("Failed to verify %v: %v", destinationURI, redactURLError(err))
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return daisy.Errf("Failed to verify %v: %v", destinationURI, resp.Status)
+	}
+	if resp.ContentLength != size {
+		return daisy.Errf("%v has %v bytes, expected %v", destinationURI, resp.ContentLength, size)
+	}
+	return nil
+}
+
+// do sends a request for destinationURI with query and body, retrying on errors.
+func (d *azureDestination) do(method, destinationURI, query string, body []byte) error {
+	var err error
+	for attempt := 0; attempt < maxUploadRetries; attempt++ {
+		if attempt > 0 {
+			time.Sleep(time.Duration(1<<uint(attempt-1)) * time.Second)
+		}
+		var req *http.Request
+		req, err = http.NewRequest(method, destinationURI+"?"+d.sasToken+"&"+query, bytes.NewReader(body))
+		if err != nil {
+			return redactURLError(err)
+		}
+		req.Header.Set("x-ms-version", azureAPIVersion)
+		var resp *http.Response
+		resp, err = d.client.Do(req)
+		if err != nil {
+			err = redactURLError(err)
+			continue
+		}
+		respBody, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if resp.StatusCode == http.StatusCreated {
+			return nil
+		}
+		err = fmt.Errorf("%v: %s", resp.Status, respBody)
+		if resp.StatusCode < 500 && resp.StatusCode != http.StatusTooManyRequests {
+			return err
+		}
+	}
+	return err
+}
+
+// redactURLError removes the query, which has the SAS token, from the URL of a *url.Error.
+func redactURLError(err error) error {
+	var urlErr *url.Error
+	if !errors.As(err, &urlErr) {
+		return err
+	}
+	redacted := *urlErr
+	redacted.URL = strings.SplitN(urlErr.URL, "?", 2)[0]
+	return &redacted
+}
@@ -0,0 +1,273 @@
+//  Copyright 2026 Google Inc. All Rights Reserved.
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+package exporter
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	gcsstorage "cloud.google.com/go/storage"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3iface"
+	"github.com/aws/aws-sdk-go/service/s3/s3manager"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/GoogleCloudPlatform/compute-image-import/cli_tools/mocks"
+)
+
+func TestNewExternalDestination(t *testing.T) {
+	destination, err := newExternalDestination(&ImageExportRequest{DestinationURI: "gs://bucket/image.vmdk"})
+	assert.NoError(t, err)
+	assert.Nil(t, destination)
+
+	destination, err = newExternalDestination(&ImageExportRequest{DestinationURI: "s3://bucket/image.vmdk",
+		AWSAccessKeyID: "id", AWSSecretAccessKey: "secret", AWSRegion: "us-east-1"})
+	assert.NoError(t, err)
+	assert.IsType(t, &s3Destination{}, destination)
+
+	destination, err = newExternalDestination(&ImageExportRequest{
+		DestinationURI: "https://account.blob.core.windows.net/container/image.vhd", AzureSASToken: "?sv=1&sig=abc"})
+	assert.NoError(t, err)
+	assert.Equal(t, "sv=1&sig=abc", destination.(*azureDestination).sasToken)
+}
+
+func TestNewExternalDestination_ReturnsError(t *testing.T) {
+	for _, tt := range []struct {
+		args          ImageExportRequest
+		expectedError string
+	}{
+		{ImageExportRequest{DestinationURI: "s3://bucket"},
+			"-destination_uri must be an S3 object, such as s3://bucket/image.vmdk"},
+		{ImageExportRequest{DestinationURI: "s3://bucket/image.vmdk", AWSSecretAccessKey: "secret", AWSRegion: "us-east-1"},
+			"The flag -aws_access_key_id must be provided"},
+		{ImageExportRequest{DestinationURI: "s3://bucket/image.vmdk", AWSAccessKeyID: "id", AWSRegion: "us-east-1"},
+			"The flag -aws_secret_access_key must be provided"},
+		{ImageExportRequest{DestinationURI: "s3://bucket/image.vmdk", AWSAccessKeyID: "id", AWSSecretAccessKey: "secret"},
+			"The flag -aws_region must be provided"},
+		{ImageExportRequest{DestinationURI: "https://example.com/image.vhd", AzureSASToken: "sig=abc"},
+			"-destination_uri must be an Azure blob, such as https://ACCOUNT.blob.core.windows.net/CONTAINER/image.vhd"},
+		{ImageExportRequest{DestinationURI: "https://account.blob.core.windows.net/container/image.vhd"},
+			"The flag -azure_sas_token must be provided"},
+	} {
+		_, err := newExternalDestination(&tt.args)
+		assert.EqualError(t, err, tt.expectedError)
+	}
+}
+
+type fakeUploader struct {
+	input   *s3manager.UploadInput
+	content string
+}
+
+func (u *fakeUploader) Upload(input *s3manager.UploadInput, _ ...func(*s3manager.Uploader)) (*s3manager.UploadOutput, error) {
+	u.input = input
+	content, err := io.ReadAll(input.Body)
+	u.content = string(content)
+	return &s3manager.UploadOutput{}, err
+}
+
+func (u *fakeUploader) UploadWithContext(aws.Context, *s3manager.UploadInput, ...func(*s3manager.Uploader)) (*s3manager.UploadOutput, error) {
+	panic("not used")
+}
+
+type fakeS3Client struct {
+	s3iface.S3API
+	contentLength int64
+}
+
+func (c *fakeS3Client) HeadObject(*s3.HeadObjectInput) (*s3.HeadObjectOutput, error) {
+	return &s3.HeadObjectOutput{ContentLength: aws.Int64(c.contentLength)}, nil
+}
+
+func (c *fakeS3Client) HeadObjectWithContext(aws.Context, *s3.HeadObjectInput, ...request.Option) (*s3.HeadObjectOutput, error) {
+	panic("not used")
+}
+
+func TestS3Destination_Upload(t *testing.T) {
+	uploader := &fakeUploader{}
+	destination := &s3Destination{client: &fakeS3Client{contentLength: 4}, uploader: uploader}
+
+	assert.NoError(t, destination.upload("s3://bucket/dir/image.vmdk", strings.NewReader("data"), 4))
+	assert.Equal(t, "bucket", *uploader.input.Bucket)
+	assert.Equal(t, "dir/image.vmdk", *uploader.input.Key)
+	assert.Equal(t, "data", uploader.content)
+}
+
+func TestS3Destination_Upload_ReturnsError_WhenSizeDoesNotMatch(t *testing.T) {
+	destination := &s3Destination{client: &fakeS3Client{contentLength: 3}, uploader: &fakeUploader{}}
+
+	assert.EqualError(t, destination.upload("s3://bucket/image.vmdk", strings.NewReader("data"), 4),
+		"s3://bucket/image.vmdk has 3 bytes, expected 4")
+}
+
+// fakeAzureServer stores blocks, and commits them to a blob when the block list is uploaded.
+type fakeAzureServer struct {
+	sync.Mutex
+	blocks      map[string]string
+	blob        string
+	failBlocks  bool
+	queryTokens []string
+}
+
+func (s *fakeAzureServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.Lock()
+	defer s.Unlock()
+	s.queryTokens = append(s.queryTokens, r.URL.Query().Get("sig"))
+	body, _ := io.ReadAll(r.Body)
+	switch {
+	case r.Method == http.MethodHead:
+		w.Header().Set("Content-Length", strconv.Itoa(len(s.blob)))
+		w.WriteHeader(http.StatusOK)
+	case r.URL.Query().Get("comp") == "block":
+		if s.failBlocks {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		s.blocks[r.URL.Query().Get("blockid")] = string(body)
+		w.WriteHeader(http.StatusCreated)
+	case r.URL.Query().Get("comp") == "blocklist":
+		var blockList struct {
+			Latest []string
+		}
+		if err := xml.Unmarshal(body, &blockList); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		s.blob = ""
+		for _, id := range blockList.Latest {
+			s.blob += s.blocks[id]
+		}
+		w.WriteHeader(http.StatusCreated)
+	default:
+		w.WriteHeader(http.StatusBadRequest)
+	}
+}
+
+func TestAzureDestination_Upload(t *testing.T) {
+	server := &fakeAzureServer{blocks: map[string]string{}}
+	httpServer := httptest.NewServer(server)
+	defer httpServer.Close()
+	destination := &azureDestination{client: httpServer.Client(), sasToken: "sig=abc", minBlock: 3, workers: 2}
+
+	content := "the exported image"
+	assert.NoError(t, destination.upload(httpServer.URL+"/container/image.vhd", strings.NewReader(content), int64(len(content))))
+	assert.Equal(t, content, server.blob)
+	assert.Len(t, server.blocks, 6)
+	for _, token := range server.queryTokens {
+		assert.Equal(t, "abc", token)
+	}
+}
+
+func TestAzureDestination_Upload_ReturnsError_WhenBlockFails(t *testing.T) {
+	server := &fakeAzureServer{blocks: map[string]string{}, failBlocks: true}
+	httpServer := httptest.NewServer(server)
+	defer httpServer.Close()
+	destination := &azureDestination{client: httpServer.Client(), sasToken: "sig=abc", minBlock: 3, workers: 2}
+
+	err := destination.upload(httpServer.URL+"/container/image.vhd", strings.NewReader("the exported image"), 18)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "403 Forbidden")
+	assert.Empty(t, server.blob)
+}
+
+func TestAzureDestination_Upload_StopsReading_WhenBlockFails(t *testing.T) {
+	server := &fakeAzureServer{blocks: map[string]string{}, failBlocks: true}
+	httpServer := httptest.NewServer(server)
+	defer httpServer.Close()
+	destination := &azureDestination{client: httpServer.Client(), sasToken: "sig=abc", minBlock: 3, workers: 2}
+	content := strings.Repeat("a", 300)
+	reader := &countingReader{reader: strings.NewReader(content)}
+
+	assert.Error(t, destination.upload(httpServer.URL+"/container/image.vhd", reader, int64(len(content))))
+	assert.Less(t, reader.n, len(content))
+}
+
+func TestAzureDestination_Verify_RedactsSASToken(t *testing.T) {
+	httpServer := httptest.NewServer(http.NotFoundHandler())
+	httpServer.Close()
+	destination := &azureDestination{client: http.DefaultClient, sasToken: "sig=secret"}
+
+	err := destination.verify(httpServer.URL+"/container/image.vhd", 1)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), httpServer.URL+"/container/image.vhd")
+	assert.NotContains(t, err.Error(), "secret")
+}
+
+type countingReader struct {
+	reader io.Reader
+	n      int
+}
+
+func (r *countingReader) Read(p []byte) (int, error) {
+	n, err := r.reader.Read(p)
+	r.n += n
+	return n, err
+}
+
+type fakeDestination struct {
+	uploaded map[string]string
+}
+
+func (d *fakeDestination) upload(destinationURI string, reader io.Reader, size int64) error {
+	content, err := io.ReadAll(reader)
+	d.uploaded[destinationURI] = string(content)
+	return err
+}
+
+func setUpStagedObject(mockCtrl *gomock.Controller, content string) *mocks.MockStorageClientInterface {
+	storageObject := mocks.NewMockStorageObject(mockCtrl)
+	storageObject.EXPECT().NewReader().Return(io.NopCloser(strings.NewReader(content)), nil)
+	storageClient := mocks.NewMockStorageClientInterface(mockCtrl)
+	storageClient.EXPECT().GetObjectAttrs("scratch", "staged/image.vmdk").Return(
+		&gcsstorage.ObjectAttrs{Size: int64(len(content))}, nil)
+	storageClient.EXPECT().GetObject("scratch", "staged/image.vmdk").Return(storageObject)
+	return storageClient
+}
+
+func TestTransferToExternalDestinations(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	storageClient := setUpStagedObject(mockCtrl, "data")
+	destination := &fakeDestination{uploaded: map[string]string{}}
+	sum := sha256.Sum256([]byte("data"))
+
+	assert.NoError(t, transferToExternalDestinations(storageClient, destination,
+		[]string{"gs://scratch/staged/image.vmdk"}, []string{"s3://bucket/image.vmdk"}, []string{hex.EncodeToString(sum[:])}))
+	assert.Equal(t, map[string]string{"s3://bucket/image.vmdk": "data"}, destination.uploaded)
+}
+
+func TestTransferToExternalDestinations_ReturnsError_WhenDigestDoesNotMatch(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	storageClient := setUpStagedObject(mockCtrl, "corrupted")
+	destination := &fakeDestination{uploaded: map[string]string{}}
+	sum := sha256.Sum256([]byte("data"))
+
+	err := transferToExternalDestinations(storageClient, destination,
+		[]string{"gs://scratch/staged/image.vmdk"}, []string{"s3://bucket/image.vmdk"}, []string{hex.EncodeToString(sum[:])})
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "doesn't match the export worker's digest "+hex.EncodeToString(sum[:]))
+}
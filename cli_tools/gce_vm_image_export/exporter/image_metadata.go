@@ -0,0 +1,47 @@
+//  Copyright 2026 Google Inc. All Rights Reserved.
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+package exporter
+
+import (
+	"log"
+
+	daisyCompute "github.com/GoogleCloudPlatform/compute-daisy/compute"
+
+	"github.com/GoogleCloudPlatform/compute-image-import/cli_tools/common/image"
+)
+
+// writeImageMetadata uses write to write the metadata of sourceImage next to each exported
+// file, so that it can be restored with gce_vm_image_import -metadata_file.
+func writeImageMetadata(write func(destinationURI string, content []byte) error, computeClient daisyCompute.Client,
+	project, sourceImage string, destinationURIs []string) error {
+	img, err := getSourceImage(computeClient, project, sourceImage)
+	if err != nil {
+		return err
+	}
+	if img == nil {
+		log.Printf("Skipping the metadata of %q, since it isn't recognized as an image.", sourceImage)
+		return nil
+	}
+	content, err := image.NewMetadata(img).Marshal()
+	if err != nil {
+		return err
+	}
+	for _, destinationURI := range destinationURIs {
+		if err := write(destinationURI+image.MetadataFileSuffix, content); err != nil {
+			return err
+		}
+	}
+	return nil
+}
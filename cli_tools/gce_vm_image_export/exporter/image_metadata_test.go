@@ -0,0 +1,76 @@
+//  Copyright 2026 Google Inc. All Rights Reserved.
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+package exporter
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	v1 "google.golang.org/api/compute/v1"
+
+	"github.com/GoogleCloudPlatform/compute-image-import/cli_tools/common/image"
+	"github.com/GoogleCloudPlatform/compute-image-import/cli_tools/mocks"
+)
+
+func TestWriteImageMetadata(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	mockComputeClient := mocks.NewMockClient(mockCtrl)
+	mockComputeClient.EXPECT().GetImage("project", "image").Return(&v1.Image{
+		Family:          "family",
+		Licenses:        []string{"https://www.googleapis.com/compute/v1/projects/debian-cloud/global/licenses/debian-12-bookworm"},
+		GuestOsFeatures: []*v1.GuestOsFeature{{Type: "UEFI_COMPATIBLE"}},
+	}, nil)
+	written := map[string]string{}
+	write := func(destinationURI string, content []byte) error {
+		written[destinationURI] = string(content)
+		return nil
+	}
+
+	assert.NoError(t, writeImageMetadata(write, mockComputeClient, "project", "image",
+		[]string{"gs://bucket/image.vmdk", "gs://bucket/image.qcow2"}))
+	assert.Len(t, written, 2)
+	m, err := image.ParseMetadata([]byte(written["gs://bucket/image.vmdk.metadata.json"]))
+	assert.NoError(t, err)
+	assert.Equal(t, image.Metadata{
+		Family:          "family",
+		Licenses:        []string{"projects/debian-cloud/global/licenses/debian-12-bookworm"},
+		GuestOSFeatures: []string{"UEFI_COMPATIBLE"},
+	}, m)
+	assert.Equal(t, written["gs://bucket/image.vmdk.metadata.json"], written["gs://bucket/image.qcow2.metadata.json"])
+}
+
+func TestWriteImageMetadata_SkipsUnrecognizedImage(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	write := func(string, []byte) error {
+		return errors.New("unexpected write")
+	}
+
+	assert.NoError(t, writeImageMetadata(write, mocks.NewMockClient(mockCtrl), "project", "not/an/image",
+		[]string{"gs://bucket/image.vmdk"}))
+}
+
+func TestWriteImageMetadata_ReturnsError_WhenImageNotFound(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	mockComputeClient := mocks.NewMockClient(mockCtrl)
+	mockComputeClient.EXPECT().GetImage("project", "image").Return(nil, errors.New("image not found"))
+
+	assert.EqualError(t, writeImageMetadata(nil, mockComputeClient, "project", "image", []string{"gs://bucket/image.vmdk"}),
+		"Image \"image\" not found")
+}
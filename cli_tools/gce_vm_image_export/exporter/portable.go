@@ -0,0 +1,91 @@
+//  Copyright 2026 Google Inc. All Rights Reserved.
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+package exporter
+
+import (
+	"fmt"
+
+	daisy "github.com/GoogleCloudPlatform/compute-daisy"
+
+	"github.com/GoogleCloudPlatform/compute-image-import/cli_tools/common/image"
+	"github.com/GoogleCloudPlatform/compute-image-import/cli_tools/common/utils/logging"
+)
+
+const (
+	// See daisy_workflows/export/image_export_ext.wf.json.
+	setupDisksStepName = "setup-disks"
+	exportedDiskName   = "disk-${NAME}"
+
+	portableStepName = "make-portable"
+)
+
+// addPortableStep adds a step to w that prepares the disk created from the source
+// image or snapshot to boot on portableFor, before the disk is exported.
+func addPortableStep(w *daisy.Workflow, portableFor string) error {
+	// Included workflows are relative to daisy_workflows/export.
+	portableWorkflowPath := "../" + image.PortableWorkflow
+	include, err := w.NewIncludedWorkflowFromFile(portableWorkflowPath)
+	if err != nil {
+		return err
+	}
+	step := daisy.NewStepDefaultTimeout(portableStepName, w)
+	step.IncludeWorkflow = &daisy.IncludeWorkflow{
+		Path: portableWorkflowPath,
+		Vars: map[string]string{
+			"source_disk":             exportedDiskName,
+			"portable_for":            portableFor,
+			"export_network":          "${export_network}",
+			"export_subnet":           "${export_subnet}",
+			"compute_service_account": "${compute_service_account}",
+		},
+		Workflow: include,
+	}
+	w.Steps[portableStepName] = step
+	w.Dependencies[portableStepName] = []string{setupDisksStepName}
+	w.Dependencies[exportDiskStepName] = append(w.Dependencies[exportDiskStepName], portableStepName)
+	return nil
+}
+
+// readPortableChanges parses the changes reported by the portable step, and shows
+// them to the user.
+func readPortableChanges(logger logging.Logger, value string) (image.PortableChanges, error) {
+	changes, err := image.ParsePortableChanges(value)
+	if err != nil {
+		return changes, err
+	}
+	logger.User(fmt.Sprintf("Prepared the %v image for %v:", changes.Distro, changes.Target))
+	for _, change := range changes.Changes {
+		logger.User("  " + change)
+	}
+	for _, note := range changes.Notes {
+		logger.User("  Note: " + note)
+	}
+	return changes, nil
+}
+
+// writePortableChanges uses write to write the record of changes next to each exported file.
+func writePortableChanges(write func(destinationURI string, content []byte) error,
+	changes image.PortableChanges, destinationURIs []string) error {
+	content, err := changes.Marshal()
+	if err != nil {
+		return err
+	}
+	for _, destinationURI := range destinationURIs {
+		if err := write(destinationURI+image.PortableChangesFileSuffix, content); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,72 @@
+//  Copyright 2026 Google Inc. All Rights Reserved.
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+package exporter
+
+import (
+	"encoding/base64"
+	"testing"
+
+	daisy "github.com/GoogleCloudPlatform/compute-daisy"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/GoogleCloudPlatform/compute-image-import/cli_tools/common/image"
+	"github.com/GoogleCloudPlatform/compute-image-import/cli_tools/common/utils/logging"
+)
+
+func TestAddPortableStep(t *testing.T) {
+	w, err := daisy.NewFromFile("../../../daisy_workflows/export/" + ExportWorkflow)
+	assert.NoError(t, err)
+
+	assert.NoError(t, addPortableStep(w, "vmware"))
+
+	include := w.Steps[portableStepName].IncludeWorkflow
+	assert.Equal(t, map[string]string{
+		"source_disk":             "disk-${NAME}",
+		"portable_for":            "vmware",
+		"export_network":          "${export_network}",
+		"export_subnet":           "${export_subnet}",
+		"compute_service_account": "${compute_service_account}",
+	}, include.Vars)
+	assert.Equal(t, "make-portable", include.Workflow.Name)
+	assert.Contains(t, include.Workflow.Steps, "attach-source-disk")
+	assert.Equal(t, []string{setupDisksStepName}, w.Dependencies[portableStepName])
+	assert.Equal(t, []string{setupDisksStepName, portableStepName}, w.Dependencies[exportDiskStepName])
+}
+
+func TestReadPortableChanges(t *testing.T) {
+	changes, err := readPortableChanges(logging.NewToolLogger("test"), base64.StdEncoding.EncodeToString(
+		[]byte(`{"target":"kvm","distro":"ubuntu","changes":["Installed qemu-guest-agent."]}`)))
+	assert.NoError(t, err)
+	assert.Equal(t, image.PortableChanges{Target: "kvm", Distro: "ubuntu", Changes: []string{"Installed qemu-guest-agent."}}, changes)
+
+	_, err = readPortableChanges(logging.NewToolLogger("test"), "")
+	assert.Error(t, err)
+}
+
+func TestWritePortableChanges(t *testing.T) {
+	written := map[string]string{}
+	write := func(destinationURI string, content []byte) error {
+		written[destinationURI] = string(content)
+		return nil
+	}
+
+	err := writePortableChanges(write, image.PortableChanges{Target: "hyperv", Changes: []string{"Installed hyperv-daemons."}},
+		[]string{"gs://bucket/image.vhdx", "gs://bucket/image.vmdk"})
+
+	assert.NoError(t, err)
+	assert.Len(t, written, 2)
+	assert.JSONEq(t, `{"target":"hyperv","changes":["Installed hyperv-daemons."]}`, written["gs://bucket/image.vhdx.portable.json"])
+	assert.Equal(t, written["gs://bucket/image.vhdx.portable.json"], written["gs://bucket/image.vmdk.portable.json"])
+}
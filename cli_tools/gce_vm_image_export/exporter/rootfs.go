@@ -0,0 +1,158 @@
+//  Copyright 2026 Google Inc. All Rights Reserved.
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+package exporter
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	daisy "github.com/GoogleCloudPlatform/compute-daisy"
+
+	"github.com/GoogleCloudPlatform/compute-image-import/cli_tools/common/disk"
+	"github.com/GoogleCloudPlatform/compute-image-import/cli_tools/common/utils/daisyutils"
+	"github.com/GoogleCloudPlatform/compute-image-import/cli_tools/common/utils/logging"
+	"github.com/GoogleCloudPlatform/compute-image-import/proto/go/pb"
+)
+
+const (
+	// rootfsTarFormat exports a tarball of the image's root filesystem, rather than a disk.
+	rootfsTarFormat = "rootfs-tar"
+
+	// rootfsMountsKey is the serial output key with which RootfsWorkflow reports the
+	// filesystems that it archived.
+	rootfsMountsKey = "rootfs-mounts"
+
+	// rootfsMetadataFileSuffix is appended to the URI of a rootfs tarball to name its
+	// metadata sidecar.
+	rootfsMetadataFileSuffix = ".rootfs.json"
+)
+
+// validateRootfsTar checks that rootfs-tar is exported on its own.
+func validateRootfsTar(formats []string, portableFor string) error {
+	isRootfsTar := false
+	for _, format := range formats {
+		isRootfsTar = isRootfsTar || format == rootfsTarFormat
+	}
+	if !isRootfsTar {
+		return nil
+	}
+	if len(formats) > 1 {
+		return daisy.Errf("-format=%v can't be combined with other formats", rootfsTarFormat)
+	}
+	if portableFor != "" {
+		return daisy.Errf("-format=%v can't be combined with -%v", rootfsTarFormat, PortableForFlagKey)
+	}
+	return nil
+}
+
+// rootfsDaisyVars returns the variables of RootfsWorkflow, which archives the filesystems
+// of sourceDiskURI. varMap are the variables built for ExportWorkflow.
+func rootfsDaisyVars(varMap map[string]string, sourceDiskURI string) map[string]string {
+	rootfsVars := map[string]string{"source_disk": sourceDiskURI}
+	for _, key := range []string{"destination", "export_network", "export_subnet", "compute_service_account"} {
+		if value, found := varMap[key]; found {
+			rootfsVars[key] = value
+		}
+	}
+	return rootfsVars
+}
+
+// inspectRootfsDisk finds the partition and OS details of diskURI. Inspection errors
+// are logged, since the worker reports whether the root filesystem can be mounted.
+func inspectRootfsDisk(env daisyutils.EnvironmentSettings, logger logging.Logger, workflowPath string,
+	diskURI string) *pb.InspectionResults {
+	// workflowPath is in daisy_workflows/export.
+	env.WorkflowDirectory = filepath.Dir(filepath.Dir(workflowPath))
+	inspector, err := disk.NewInspector(env, logger)
+	if err != nil {
+		logger.User(fmt.Sprintf("WARNING: Could not create the disk inspector: %v", err))
+		return nil
+	}
+	logger.User("Inspecting the disk.")
+	results, err := inspector.Inspect(diskURI)
+	if err != nil {
+		logger.User(fmt.Sprintf("WARNING: Could not detect operating system on the disk: %v", err))
+		return nil
+	}
+	return results
+}
+
+// rootfsMetadata describes the filesystems in a rootfs tarball, and the disk they were
+// archived from. The partition and OS details are found by disk inspection.
+type rootfsMetadata struct {
+	Format        string            `json:"format"`
+	RootPartition string            `json:"rootPartition,omitempty"`
+	Mounts        map[string]string `json:"mounts"`
+	Distro        string            `json:"distro,omitempty"`
+	MajorVersion  string            `json:"majorVersion,omitempty"`
+	MinorVersion  string            `json:"minorVersion,omitempty"`
+	Architecture  string            `json:"architecture,omitempty"`
+	BIOSBootable  bool              `json:"biosBootable"`
+	UEFIBootable  bool              `json:"uefiBootable"`
+}
+
+// newRootfsMetadata combines the inspection results, which may be nil, with the mounts
+// reported by RootfsWorkflow.
+func newRootfsMetadata(inspection *pb.InspectionResults, mountsValue string) (rootfsMetadata, error) {
+	m := rootfsMetadata{Format: rootfsTarFormat}
+	if mountsValue == "" {
+		return m, daisy.Errf("The worker didn't report the filesystems that it archived")
+	}
+	content, err := base64.StdEncoding.DecodeString(mountsValue)
+	if err == nil {
+		err = json.Unmarshal(content, &m.Mounts)
+	}
+	if err != nil {
+		return m, fmt.Errorf("invalid record of the filesystems archived by the worker: %v", err)
+	}
+	if inspection == nil {
+		return m, nil
+	}
+	m.BIOSBootable, m.UEFIBootable = inspection.BiosBootable, inspection.UefiBootable
+	if len(inspection.OsInstallations) == 1 {
+		installation := inspection.OsInstallations[0]
+		m.RootPartition = installation.RootPartition
+		m.BIOSBootable = m.BIOSBootable || installation.BiosBootable
+		m.UEFIBootable = m.UEFIBootable || installation.UefiBootable
+	}
+	if release := inspection.OsRelease; release != nil {
+		m.Distro = release.Distro
+		m.MajorVersion = release.MajorVersion
+		m.MinorVersion = release.MinorVersion
+		if release.Architecture != pb.Architecture_ARCHITECTURE_UNKNOWN {
+			m.Architecture = strings.ToLower(release.Architecture.String())
+		}
+	}
+	return m, nil
+}
+
+// writeRootfsMetadata uses write to write the metadata next to each exported tarball.
+func writeRootfsMetadata(write func(destinationURI string, content []byte) error,
+	m rootfsMetadata, destinationURIs []string) error {
+	content, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	content = append(content, '\n')
+	for _, destinationURI := range destinationURIs {
+		if err := write(destinationURI+rootfsMetadataFileSuffix, content); err != nil {
+			return err
+		}
+	}
+	return nil
+}
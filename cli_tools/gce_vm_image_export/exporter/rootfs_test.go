@@ -0,0 +1,120 @@
+//  Copyright 2026 Google Inc. All Rights Reserved.
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+package exporter
+
+import (
+	"encoding/base64"
+	"testing"
+
+	daisy "github.com/GoogleCloudPlatform/compute-daisy"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/GoogleCloudPlatform/compute-image-import/proto/go/pb"
+)
+
+func TestValidateRootfsTar(t *testing.T) {
+	assert.NoError(t, validateRootfsTar([]string{"vmdk", "qcow2"}, "vmware"))
+	assert.NoError(t, validateRootfsTar([]string{"rootfs-tar"}, ""))
+
+	assert.EqualError(t, validateRootfsTar([]string{"rootfs-tar", "vmdk"}, ""),
+		"-format=rootfs-tar can't be combined with other formats")
+	assert.EqualError(t, validateRootfsTar([]string{"rootfs-tar"}, "kvm"),
+		"-format=rootfs-tar can't be combined with -portable_for")
+}
+
+func TestRootfsWorkflow(t *testing.T) {
+	w, err := daisy.NewFromFile("../../../daisy_workflows/export/" + RootfsWorkflow)
+	assert.NoError(t, err)
+
+	for key := range rootfsDaisyVars(map[string]string{}, "disk") {
+		assert.Contains(t, w.Vars, key)
+	}
+	assert.Equal(t, "READ_ONLY", (*w.Steps["attach-source-disk"].AttachDisks)[0].Mode)
+}
+
+func TestRootfsDaisyVars(t *testing.T) {
+	varMap := buildDaisyVars("gs://bucket/rootfs.tar", "image", "", 10, "rootfs-tar",
+		"network", "subnet", "us-central1", "sa@project.iam.gserviceaccount.com")
+
+	assert.Equal(t, map[string]string{
+		"source_disk":             "projects/project/zones/us-central1-b/disks/disk",
+		"destination":             "gs://bucket/rootfs.tar",
+		"export_network":          "global/networks/network",
+		"export_subnet":           "regions/us-central1/subnetworks/subnet",
+		"compute_service_account": "sa@project.iam.gserviceaccount.com",
+	}, rootfsDaisyVars(varMap, "projects/project/zones/us-central1-b/disks/disk"))
+}
+
+func TestNewRootfsMetadata(t *testing.T) {
+	mounts := base64.StdEncoding.EncodeToString([]byte(`{"/":"/dev/sda1","/boot/efi":"/dev/sda15"}`))
+	inspection := &pb.InspectionResults{
+		OsCount: 1,
+		OsRelease: &pb.OsRelease{
+			Distro:       "debian",
+			MajorVersion: "12",
+			MinorVersion: "5",
+			Architecture: pb.Architecture_X64,
+		},
+		OsInstallations: []*pb.OsInstallation{{
+			RootPartition: "/dev/sda1",
+			BiosBootable:  true,
+			UefiBootable:  true,
+		}},
+	}
+
+	m, err := newRootfsMetadata(inspection, mounts)
+	assert.NoError(t, err)
+	assert.Equal(t, rootfsMetadata{
+		Format:        "rootfs-tar",
+		RootPartition: "/dev/sda1",
+		Mounts:        map[string]string{"/": "/dev/sda1", "/boot/efi": "/dev/sda15"},
+		Distro:        "debian",
+		MajorVersion:  "12",
+		MinorVersion:  "5",
+		Architecture:  "x64",
+		BIOSBootable:  true,
+		UEFIBootable:  true,
+	}, m)
+}
+
+func TestNewRootfsMetadata_WithoutInspection(t *testing.T) {
+	m, err := newRootfsMetadata(nil, base64.StdEncoding.EncodeToString([]byte(`{"/":"/dev/sda"}`)))
+	assert.NoError(t, err)
+	assert.Equal(t, rootfsMetadata{Format: "rootfs-tar", Mounts: map[string]string{"/": "/dev/sda"}}, m)
+}
+
+func TestNewRootfsMetadata_ReturnsError(t *testing.T) {
+	_, err := newRootfsMetadata(nil, "")
+	assert.EqualError(t, err, "The worker didn't report the filesystems that it archived")
+
+	_, err = newRootfsMetadata(nil, "not base64!")
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid record of the filesystems")
+}
+
+func TestWriteRootfsMetadata(t *testing.T) {
+	written := map[string]string{}
+	write := func(destinationURI string, content []byte) error {
+		written[destinationURI] = string(content)
+		return nil
+	}
+
+	err := writeRootfsMetadata(write, rootfsMetadata{Format: "rootfs-tar", RootPartition: "/dev/sda1",
+		Mounts: map[string]string{"/": "/dev/sda1"}, Distro: "ubuntu"}, []string{"gs://bucket/rootfs.tar"})
+
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"format":"rootfs-tar","rootPartition":"/dev/sda1","mounts":{"/":"/dev/sda1"},
+		"distro":"ubuntu","biosBootable":false,"uefiBootable":false}`, written["gs://bucket/rootfs.tar.rootfs.json"])
+}
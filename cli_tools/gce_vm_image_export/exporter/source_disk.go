@@ -0,0 +1,232 @@
+//  Copyright 2026 Google Inc. All Rights Reserved.
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+package exporter
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"regexp"
+	"strings"
+	"time"
+
+	daisy "github.com/GoogleCloudPlatform/compute-daisy"
+	daisyCompute "github.com/GoogleCloudPlatform/compute-daisy/compute"
+	"google.golang.org/api/compute/v1"
+	"google.golang.org/api/option"
+
+	"github.com/GoogleCloudPlatform/compute-image-import/cli_tools/common/utils/param"
+)
+
+var sourceDiskURIRegex = regexp.MustCompile(
+	`^(projects/(?P<project>[^/]+)/)?(?P<location>(zones|regions)/[^/]+)/disks/(?P<disk>[^/]+)$`)
+
+// sourceDisk is a zonal or regional disk.
+type sourceDisk struct {
+	project, location, name string
+}
+
+func (d sourceDisk) uri() string {
+	return fmt.Sprintf("projects/%v/%v/disks/%v", d.project, d.location, d.name)
+}
+
+// zone returns the disk's zone, and whether the disk is zonal.
+func (d sourceDisk) zone() (string, bool) {
+	zone := strings.TrimPrefix(d.location, "zones/")
+	return zone, zone != d.location
+}
+
+// parseSourceDisk parses a disk URI, such as zones/ZONE/disks/DISK or regions/REGION/disks/DISK.
+// A disk name is looked up in zone, and the disk is in project unless the URI includes a project.
+func parseSourceDisk(sourceDiskURI, project, zone string) (sourceDisk, error) {
+	match := sourceDiskURIRegex.FindStringSubmatch(
+		param.GetZonalResourcePath(zone, "disks", strings.TrimSpace(sourceDiskURI)))
+	if match == nil {
+		return sourceDisk{}, daisy.Errf("-%v must be a disk name, or a URI such as zones/ZONE/disks/DISK "+
+			"or regions/REGION/disks/DISK", SourceDiskFlagKey)
+	}
+	disk := sourceDisk{project: project}
+	for i, name := range sourceDiskURIRegex.SubexpNames() {
+		switch name {
+		case "project":
+			if match[i] != "" {
+				disk.project = match[i]
+			}
+		case "location":
+			disk.location = match[i]
+		case "disk":
+			disk.name = match[i]
+		}
+	}
+	return disk, nil
+}
+
+// diskSnapshotter takes temporary snapshots of disks, so that they can be exported like snapshots.
+// It also creates the temporary disks whose filesystems are exported by rootfs-tar.
+//
+// Zonal disks use the daisy compute client. The daisy client doesn't support regional disks,
+// or snapshotting a disk into another project, so these use the compute API directly.
+type diskSnapshotter struct {
+	ctx    context.Context
+	client daisyCompute.Client
+
+	// newService creates the compute service that's used when the daisy client can't be.
+	newService func() (*compute.Service, error)
+	service    *compute.Service
+}
+
+func newDiskSnapshotter(ctx context.Context, client daisyCompute.Client, oauth, computeEndpoint string) *diskSnapshotter {
+	return &diskSnapshotter{ctx: ctx, client: client, newService: func() (*compute.Service, error) {
+		options := []option.ClientOption{option.WithCredentialsFile(oauth)}
+		if computeEndpoint != "" {
+			options = append(options, option.WithEndpoint(computeEndpoint))
+		}
+		return compute.NewService(ctx, options...)
+	}}
+}
+
+// computeService returns the compute service, creating it on first use.
+func (s *diskSnapshotter) computeService() (*compute.Service, error) {
+	if s.service == nil {
+		service, err := s.newService()
+		if err != nil {
+			return nil, daisy.Errf("failed to create compute client: %v", err)
+		}
+		s.service = service
+	}
+	return s.service, nil
+}
+
+// validateDiskExists checks whether disk exists, and returns its size.
+func (s *diskSnapshotter) validateDiskExists(disk sourceDisk) (diskSizeGb int64, err error) {
+	log.Printf("Fetching disk %q from project %q.", disk.location+"/disks/"+disk.name, disk.project)
+	var d *compute.Disk
+	if zone, isZonal := disk.zone(); isZonal {
+		d, err = s.client.GetDisk(disk.project, zone, disk.name)
+	} else {
+		var service *compute.Service
+		if service, err = s.computeService(); err != nil {
+			return diskSizeGb, err
+		}
+		d, err = service.RegionDisks.Get(disk.project, strings.TrimPrefix(disk.location, "regions/"), disk.name).Context(s.ctx).Do()
+	}
+	if err != nil {
+		log.Printf("Error when fetching disk %q: %q.", disk.uri(), err)
+		return diskSizeGb, daisy.Errf("Disk %q not found", disk.uri())
+	}
+	return d.SizeGb, nil
+}
+
+// createSnapshot snapshots disk to a new snapshot in project.
+func (s *diskSnapshotter) createSnapshot(disk sourceDisk, project, snapshotName string, labels map[string]string) error {
+	log.Printf("Creating temporary snapshot %q of disk %q.", snapshotName, disk.uri())
+	snapshot := &compute.Snapshot{
+		Name:        snapshotName,
+		Labels:      labels,
+		Description: "Temporary snapshot created by gce_vm_image_export.",
+	}
+	var err error
+	if zone, isZonal := disk.zone(); isZonal && disk.project == project {
+		err = s.client.CreateSnapshot(project, zone, disk.name, snapshot)
+	} else {
+		snapshot.SourceDisk = disk.uri()
+		err = s.insertSnapshot(project, snapshot)
+	}
+	if err != nil {
+		return daisy.Errf("Failed to snapshot disk %q: %v", disk.uri(), err)
+	}
+	return nil
+}
+
+// insertSnapshot creates snapshot in project from its SourceDisk, which may be in another
+// project or region. It waits for the snapshot until the snapshotter's context is done.
+func (s *diskSnapshotter) insertSnapshot(project string, snapshot *compute.Snapshot) error {
+	service, err := s.computeService()
+	if err != nil {
+		return err
+	}
+	op, err := service.Snapshots.Insert(project, snapshot).Context(s.ctx).Do()
+	if err != nil {
+		return err
+	}
+	for op.Status != "DONE" {
+		select {
+		case <-s.ctx.Done():
+		case <-time.After(operationPollInterval):
+			op, err = service.GlobalOperations.Get(project, op.Name).Context(s.ctx).Do()
+		}
+		if s.ctx.Err() != nil {
+			return fmt.Errorf("snapshot %q wasn't created before the timeout: %v", snapshot.Name, s.ctx.Err())
+		}
+		if err != nil {
+			return err
+		}
+	}
+	if op.Error != nil && len(op.Error.Errors) > 0 {
+		return fmt.Errorf("%v: %v", op.Error.Errors[0].Code, op.Error.Errors[0].Message)
+	}
+	return nil
+}
+
+// deleteSnapshot deletes a snapshot created by createSnapshot.
+func (s *diskSnapshotter) deleteSnapshot(project, snapshotName string) error {
+	if err := s.client.DeleteSnapshot(project, snapshotName); err != nil {
+		log.Printf("Failed to delete temporary snapshot %q: %v", snapshotName, err)
+		return err
+	}
+	return nil
+}
+
+// createDisk creates a disk in zone from sourceImage or sourceSnapshot, which are URIs such as
+// global/images/IMAGE.
+func (s *diskSnapshotter) createDisk(project, zone, diskName, sourceImage, sourceSnapshot string,
+	labels map[string]string) error {
+	log.Printf("Creating temporary disk %q.", diskName)
+	if err := s.client.CreateDisk(project, zone, &compute.Disk{
+		Name:           diskName,
+		SourceImage:    sourceImage,
+		SourceSnapshot: sourceSnapshot,
+		Labels:         labels,
+		Description:    "Temporary disk created by gce_vm_image_export.",
+	}); err != nil {
+		return daisy.Errf("Failed to create disk %q: %v", diskName, err)
+	}
+	return nil
+}
+
+// deleteDisk deletes a disk created by createDisk.
+func (s *diskSnapshotter) deleteDisk(project, zone, diskName string) error {
+	if err := s.client.DeleteDisk(project, zone, diskName); err != nil {
+		log.Printf("Failed to delete temporary disk %q: %v", diskName, err)
+		return err
+	}
+	return nil
+}
+
+// operationPollInterval is how often operations are polled. It's a variable for testing.
+var operationPollInterval = 5 * time.Second
+
+// defaultSnapshotTimeout bounds how long a snapshot is waited for, when the export's
+// timeout isn't set, or isn't a Go duration.
+const defaultSnapshotTimeout = 2 * time.Hour
+
+// snapshotTimeout returns how long to wait for the source disk's snapshot, given the
+// export's -timeout flag.
+func snapshotTimeout(timeout string) time.Duration {
+	if d, err := time.ParseDuration(timeout); err == nil && d > 0 {
+		return d
+	}
+	return defaultSnapshotTimeout
+}
@@ -0,0 +1,209 @@
+//  Copyright 2026 Google Inc. All Rights Reserved.
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+package exporter
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/api/compute/v1"
+	"google.golang.org/api/option"
+
+	computeutils "github.com/GoogleCloudPlatform/compute-image-import/cli_tools/common/utils/compute"
+)
+
+func TestParseSourceDisk(t *testing.T) {
+	for _, tt := range []struct {
+		input    string
+		expected sourceDisk
+	}{
+		{"disk", sourceDisk{"project", "zones/us-central1-b", "disk"}},
+		{"zones/us-east1-c/disks/disk", sourceDisk{"project", "zones/us-east1-c", "disk"}},
+		{"regions/us-east1/disks/disk", sourceDisk{"project", "regions/us-east1", "disk"}},
+		{"projects/other/regions/us-east1/disks/disk", sourceDisk{"other", "regions/us-east1", "disk"}},
+		{"https://www.googleapis.com/compute/v1/projects/other/zones/us-east1-c/disks/disk",
+			sourceDisk{"other", "zones/us-east1-c", "disk"}},
+	} {
+		t.Run(tt.input, func(t *testing.T) {
+			disk, err := parseSourceDisk(tt.input, "project", "us-central1-b")
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, disk)
+		})
+	}
+}
+
+func TestParseSourceDisk_ReturnsError_WhenURIIsInvalid(t *testing.T) {
+	for _, input := range []string{"global/disks/disk", "zones/us-east1-c/snapshots/snap", "projects/p/disks/disk"} {
+		t.Run(input, func(t *testing.T) {
+			_, err := parseSourceDisk(input, "project", "us-central1-b")
+			assert.EqualError(t, err, "-source_disk must be a disk name, or a URI such as zones/ZONE/disks/DISK "+
+				"or regions/REGION/disks/DISK")
+		})
+	}
+}
+
+// fakeComputeServer implements the regional disk and snapshot APIs, which aren't supported
+// by the daisy client. It records requests, and completes operations on the first poll,
+// unless operationsPending is set.
+type fakeComputeServer struct {
+	sync.Mutex
+	requests          []string
+	snapshots         []*compute.Snapshot
+	operationsPending bool
+}
+
+func (s *fakeComputeServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.Lock()
+	defer s.Unlock()
+	s.requests = append(s.requests, r.Method+" "+r.URL.Path)
+	var response interface{}
+	switch {
+	case r.Method == http.MethodGet && r.URL.Path == "/projects/project/regions/us-central1/disks/disk":
+		response = &compute.Disk{SizeGb: 30}
+	case r.Method == http.MethodPost && r.URL.Path == "/projects/project/global/snapshots":
+		snapshot := &compute.Snapshot{}
+		if err := json.NewDecoder(r.Body).Decode(snapshot); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		s.snapshots = append(s.snapshots, snapshot)
+		response = &compute.Operation{Name: "op", Status: "RUNNING"}
+	case r.Method == http.MethodGet && r.URL.Path == "/projects/project/global/operations/op":
+		response = &compute.Operation{Name: "op", Status: "DONE"}
+		if s.operationsPending {
+			response = &compute.Operation{Name: "op", Status: "RUNNING"}
+		}
+	default:
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	json.NewEncoder(w).Encode(response)
+}
+
+func setUpDiskSnapshotter(t *testing.T) (*diskSnapshotter, *computeutils.FakeClient, *fakeComputeServer) {
+	operationPollInterval = time.Millisecond
+	client := computeutils.NewFakeClient()
+	client.AddProject("project", "us-central1-b")
+	client.AddDisk("project", "us-central1-b", &compute.Disk{Name: "disk", SizeGb: 20})
+	server := &fakeComputeServer{}
+	httpServer := httptest.NewServer(server)
+	t.Cleanup(httpServer.Close)
+	snapshotter := newDiskSnapshotter(context.Background(), client, "", "")
+	snapshotter.newService = func() (*compute.Service, error) {
+		return compute.NewService(context.Background(),
+			option.WithEndpoint(httpServer.URL+"/"), option.WithoutAuthentication())
+	}
+	return snapshotter, client, server
+}
+
+func TestDiskSnapshotter_ValidateDiskExists(t *testing.T) {
+	snapshotter, _, server := setUpDiskSnapshotter(t)
+
+	size, err := snapshotter.validateDiskExists(sourceDisk{"project", "zones/us-central1-b", "disk"})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(20), size)
+	assert.Empty(t, server.requests, "zonal disks use the daisy client")
+
+	size, err = snapshotter.validateDiskExists(sourceDisk{"project", "regions/us-central1", "disk"})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(30), size)
+}
+
+func TestDiskSnapshotter_ValidateDiskExists_ReturnsError_WhenDiskNotFound(t *testing.T) {
+	snapshotter, _, _ := setUpDiskSnapshotter(t)
+
+	_, err := snapshotter.validateDiskExists(sourceDisk{"project", "zones/us-central1-b", "missing"})
+	assert.EqualError(t, err, "Disk \"projects/project/zones/us-central1-b/disks/missing\" not found")
+}
+
+func TestDiskSnapshotter_CreateAndDeleteSnapshot_ZonalDisk(t *testing.T) {
+	snapshotter, client, server := setUpDiskSnapshotter(t)
+
+	assert.NoError(t, snapshotter.createSnapshot(sourceDisk{"project", "zones/us-central1-b", "disk"}, "project",
+		"snapshot", map[string]string{"key": "value"}))
+	snapshot, err := client.GetSnapshot("project", "snapshot")
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"key": "value"}, snapshot.Labels)
+	assert.Equal(t, int64(20), snapshot.DiskSizeGb)
+
+	assert.NoError(t, snapshotter.deleteSnapshot("project", "snapshot"))
+	_, err = client.GetSnapshot("project", "snapshot")
+	assert.Error(t, err)
+	assert.Empty(t, server.requests)
+}
+
+func TestDiskSnapshotter_CreateSnapshot_RegionalDisk(t *testing.T) {
+	snapshotter, _, server := setUpDiskSnapshotter(t)
+
+	assert.NoError(t, snapshotter.createSnapshot(sourceDisk{"project", "regions/us-central1", "disk"}, "project",
+		"snapshot", map[string]string{"key": "value"}))
+
+	assert.Len(t, server.snapshots, 1)
+	assert.Equal(t, "snapshot", server.snapshots[0].Name)
+	assert.Equal(t, "projects/project/regions/us-central1/disks/disk", server.snapshots[0].SourceDisk)
+	assert.Equal(t, map[string]string{"key": "value"}, server.snapshots[0].Labels)
+	assert.Equal(t, []string{
+		"POST /projects/project/global/snapshots",
+		"GET /projects/project/global/operations/op",
+	}, server.requests)
+}
+
+func TestDiskSnapshotter_CreateSnapshot_ReturnsError_WhenSnapshotIsntCreatedBeforeTimeout(t *testing.T) {
+	snapshotter, _, server := setUpDiskSnapshotter(t)
+	server.operationsPending = true
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	snapshotter.ctx = ctx
+
+	err := snapshotter.createSnapshot(sourceDisk{"project", "regions/us-central1", "disk"}, "project",
+		"snapshot", nil)
+	assert.EqualError(t, err, "Failed to snapshot disk \"projects/project/regions/us-central1/disks/disk\": "+
+		"snapshot \"snapshot\" wasn't created before the timeout: context deadline exceeded")
+}
+
+func TestSnapshotTimeout(t *testing.T) {
+	assert.Equal(t, 3*time.Hour, snapshotTimeout("3h"))
+	assert.Equal(t, defaultSnapshotTimeout, snapshotTimeout(""))
+	assert.Equal(t, defaultSnapshotTimeout, snapshotTimeout("1d"))
+}
+
+func TestDiskSnapshotter_CreateAndDeleteDisk(t *testing.T) {
+	snapshotter, client, _ := setUpDiskSnapshotter(t)
+	client.AddImage("project", &compute.Image{Name: "image", DiskSizeGb: 10})
+
+	assert.NoError(t, snapshotter.createDisk("project", "us-central1-b", "rootfs", "global/images/image", "",
+		map[string]string{"key": "value"}))
+	disk, err := client.GetDisk("project", "us-central1-b", "rootfs")
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"key": "value"}, disk.Labels)
+
+	assert.NoError(t, snapshotter.deleteDisk("project", "us-central1-b", "rootfs"))
+	_, err = client.GetDisk("project", "us-central1-b", "rootfs")
+	assert.Error(t, err)
+}
+
+func TestDiskSnapshotter_CreateDisk_ReturnsError_WhenInsertFails(t *testing.T) {
+	snapshotter, _, _ := setUpDiskSnapshotter(t)
+
+	err := snapshotter.createDisk("project", "us-east1-c", "disk", "", "global/snapshots/snapshot", nil)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "Failed to create disk \"disk\"")
+}
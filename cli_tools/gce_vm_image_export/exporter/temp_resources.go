@@ -0,0 +1,117 @@
+//  Copyright 2026 Google Inc. All Rights Reserved.
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+package exporter
+
+import (
+	"os"
+	"os/signal"
+	"sync"
+
+	"github.com/GoogleCloudPlatform/compute-image-import/cli_tools/common/utils/daisyutils"
+	"github.com/GoogleCloudPlatform/compute-image-import/cli_tools/common/utils/logging"
+)
+
+// tempResources deletes the temporary resources that are created outside of Daisy workflows,
+// such as the snapshot of a source disk.
+//
+// Deferred deletes don't run when the user types Ctrl-C, so the resources are also deleted
+// when the process is interrupted. While a workflow runs, Daisy handles Ctrl-C by cancelling
+// the workflow, and the resources are deleted after the workflow returns.
+type tempResources struct {
+	logger     logging.Logger
+	interrupt  chan os.Signal
+	exit       func(code int)
+	mu         sync.Mutex
+	deletes    []func()
+	inWorkflow bool
+}
+
+func newTempResources(logger logging.Logger) *tempResources {
+	return &tempResources{logger: logger, interrupt: make(chan os.Signal, 1), exit: os.Exit}
+}
+
+// add registers a function that deletes a resource.
+func (r *tempResources) add(delete func()) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.deletes = append(r.deletes, delete)
+}
+
+// deleteAll deletes the resources in the reverse order in which they were added. Each
+// resource is deleted once.
+func (r *tempResources) deleteAll() {
+	r.mu.Lock()
+	deletes := r.deletes
+	r.deletes = nil
+	r.mu.Unlock()
+	for i := len(deletes) - 1; i >= 0; i-- {
+		deletes[i]()
+	}
+}
+
+// runWorkflow runs a function that runs Daisy workflows, which handle Ctrl-C themselves.
+func (r *tempResources) runWorkflow(run func()) {
+	r.setInWorkflow(true)
+	defer r.setInWorkflow(false)
+	run()
+}
+
+func (r *tempResources) setInWorkflow(inWorkflow bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.inWorkflow = inWorkflow
+}
+
+// deleteOnInterrupt deletes the resources and exits when the user types Ctrl-C outside
+// of a workflow. The returned function stops handling Ctrl-C.
+func (r *tempResources) deleteOnInterrupt() (stop func()) {
+	signal.Notify(r.interrupt, os.Interrupt)
+	done := make(chan struct{})
+	go func() {
+		for {
+			select {
+			case <-done:
+				return
+			case <-r.interrupt:
+				r.mu.Lock()
+				inWorkflow := r.inWorkflow
+				r.mu.Unlock()
+				if inWorkflow {
+					continue
+				}
+				r.logger.User("Ctrl-C caught, deleting temporary resources.")
+				r.deleteAll()
+				r.exit(1)
+			}
+		}
+	}()
+	return func() {
+		signal.Stop(r.interrupt)
+		close(done)
+	}
+}
+
+// tempResourceLabels returns the labels of temporary resources: userLabels, along with the
+// labels that Daisy workflows add to the tool's temporary resources.
+func tempResourceLabels(tool daisyutils.Tool, executionID string, userLabels map[string]string) map[string]string {
+	labels := map[string]string{
+		tool.ResourceLabelName + "-tmp":      "true",
+		tool.ResourceLabelName + "-build-id": executionID,
+	}
+	for k, v := range userLabels {
+		labels[k] = v
+	}
+	return labels
+}
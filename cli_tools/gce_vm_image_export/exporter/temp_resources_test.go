@@ -0,0 +1,81 @@
+//  Copyright 2026 Google Inc. All Rights Reserved.
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+package exporter
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/GoogleCloudPlatform/compute-image-import/cli_tools/common/utils/daisyutils"
+	"github.com/GoogleCloudPlatform/compute-image-import/cli_tools/common/utils/logging"
+)
+
+func TestTempResources_DeleteAll_DeletesOnceInReverseOrder(t *testing.T) {
+	temp := newTempResources(logging.NewToolLogger("[test]"))
+	var deleted []string
+	temp.add(func() { deleted = append(deleted, "snapshot") })
+	temp.add(func() { deleted = append(deleted, "disk") })
+
+	temp.deleteAll()
+	temp.deleteAll()
+	assert.Equal(t, []string{"disk", "snapshot"}, deleted)
+}
+
+func TestTempResources_DeletesAndExits_WhenInterrupted(t *testing.T) {
+	temp := newTempResources(logging.NewToolLogger("[test]"))
+	exited := make(chan int, 1)
+	temp.exit = func(code int) { exited <- code }
+	deleted := false
+	temp.add(func() { deleted = true })
+	defer temp.deleteOnInterrupt()()
+
+	temp.interrupt <- os.Interrupt
+	select {
+	case code := <-exited:
+		assert.Equal(t, 1, code)
+		assert.True(t, deleted)
+	case <-time.After(10 * time.Second):
+		t.Fatal("didn't exit after Ctrl-C")
+	}
+}
+
+func TestTempResources_LeavesInterruptToDaisy_WhileWorkflowRuns(t *testing.T) {
+	temp := newTempResources(logging.NewToolLogger("[test]"))
+	exited := make(chan int, 1)
+	temp.exit = func(code int) { exited <- code }
+	temp.add(func() {})
+	defer temp.deleteOnInterrupt()()
+
+	temp.runWorkflow(func() {
+		temp.interrupt <- os.Interrupt
+		select {
+		case <-exited:
+			t.Error("exited while a workflow was running")
+		case <-time.After(100 * time.Millisecond):
+		}
+	})
+}
+
+func TestTempResourceLabels(t *testing.T) {
+	tool := daisyutils.Tool{HumanReadableName: "gce image export", ResourceLabelName: "gce-image-export"}
+	assert.Equal(t, map[string]string{
+		"gce-image-export-tmp":      "true",
+		"gce-image-export-build-id": "abcde",
+		"team":                      "infra",
+	}, tempResourceLabels(tool, "abcde", map[string]string{"team": "infra"}))
+}
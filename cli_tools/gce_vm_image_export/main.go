@@ -19,6 +19,7 @@ import (
 	"flag"
 	"os"
 
+	"github.com/GoogleCloudPlatform/compute-image-import/cli_tools/common/utils/daisyutils"
 	"github.com/GoogleCloudPlatform/compute-image-import/cli_tools/common/utils/flags"
 	"github.com/GoogleCloudPlatform/compute-image-import/cli_tools/common/utils/logging"
 	"github.com/GoogleCloudPlatform/compute-image-import/cli_tools/common/utils/logging/service"
@@ -30,10 +31,12 @@ const logPrefix = "[image-export]"
 var (
 	clientID                    = flag.String(exporter.ClientIDFlagKey, "", "Identifies the client of the exporter, e.g. `gcloud` or `pantheon`.")
 	clientVersion               = flag.String("client_version", "", "Identifies the version of the client of the exporter")
-	destinationURI              = flag.String(exporter.DestinationURIFlagKey, "", "The Google Cloud Storage URI destination for the exported virtual disk file. For example: gs://my-bucket/my-exported-image.vmdk.")
+	destinationURI              = flag.String(exporter.DestinationURIFlagKey, "", "The destination for the exported virtual disk file: a Google Cloud Storage URI such as gs://my-bucket/my-exported-image.vmdk, an S3 URI such as s3://my-bucket/my-exported-image.vmdk, or an Azure blob URL such as https://myaccount.blob.core.windows.net/my-container/my-exported-image.vhd.")
 	sourceImage                 = flag.String(exporter.SourceImageFlagKey, "", "Compute Engine image from which to export")
 	sourceDiskSnapshot          = flag.String(exporter.SourceDiskSnapshotFlagKey, "", "Compute Engine disk snapshot from which to export")
-	format                      = flag.String("format", "tar.gz", "Specify the format to export to, such as vmdk, vhdx, vpc, or qcow2.")
+	sourceDisk                  = flag.String(exporter.SourceDiskFlagKey, "", "Compute Engine zonal or regional disk from which to export, such as zones/ZONE/disks/DISK or regions/REGION/disks/DISK. The disk is exported from a temporary snapshot, which is deleted afterwards.")
+	format                      = flag.String("format", "tar.gz", "Specify the format to export to, such as vmdk, vhdx, vpc, or qcow2. To export to several formats in one run, specify a comma-separated list, such as vmdk,qcow2; -destination_uri is then a prefix, and each format is exported to <destination_uri>.<format>. Specify containerdisk to package the image as a qcow2 KubeVirt containerDisk, which is pushed to a docker:// -destination_uri, or written as an OCI layout tarball to a gs:// -destination_uri. Specify rootfs-tar to export a tarball of the image's root filesystem, with its partition and OS details in <destination_uri>.rootfs.json.")
+	portableFor                 = flag.String(exporter.PortableForFlagKey, "", "Prepare a Linux image to boot on another hypervisor: vmware, kvm, or hyperv. Before export, the GCE guest environment is removed, GCE-specific cloud-init and network configuration is replaced, and the hypervisor's guest tools and drivers are installed. The changes are written to <destination_uri>.portable.json.")
 	project                     = flag.String("project", "", "Project to run in, overrides what is set in workflow.")
 	network                     = flag.String("network", "", "Name of the network in your project to use for the image export. The network must have access to Google Cloud Storage. If not specified, the network named default is used.")
 	subnet                      = flag.String("subnet", "", "Name of the subnetwork in your project to use for the image export. If	the network resource is in legacy mode, do not provide this property. If the network is in auto subnet mode, providing the subnetwork is optional. If the network is in custom subnet mode, then this field should be specified. Zone should be specified if this field is specified.")
@@ -48,11 +51,21 @@ var (
 	stdoutLogsDisabled          = flag.Bool("disable_stdout_logging", false, "do not display individual workflow logs on stdout.")
 	labels                      = flag.String("labels", "", "List of label KEY=VALUE pairs to add. Keys must start with a lowercase character and contain only hyphens (-), underscores (_), lowercase characters, and numbers. Values must contain only hyphens (-), underscores (_), lowercase characters, and numbers.")
 	nestedVirtualizationEnabled = flag.Bool("enable_nested_virtualization", true, "When enabled, temporary worker VMs will be created with enabled nested virtualization. See https://cloud.google.com/compute/docs/instances/nested-virtualization/enabling for details.")
-	workerMachineSeries         flags.StringArrayFlag
+	workerMachineSeries         flags.StringListFlag
+	emitWorkflowsDir            = flag.String(exporter.EmitWorkflowsDirFlagKey, "", "A local directory to which each daisy workflow is written as JSON, after all variables and overrides are applied. Useful for debugging and auditing which resources are created.")
+	emitWorkflowsOnly           = flag.Bool(exporter.EmitWorkflowsOnlyFlagKey, false, "Writes workflows to -emit_workflows_dir without running them. Requires -emit_workflows_dir. Later workflows depend on the results of earlier ones, so the export stops after the first workflow is written.")
+	ovfManifest                 = flag.Bool("ovf_manifest", false, "When enabled, an OVF-style manifest with the SHA256 of each exported file is written to <destination_uri>.mf, in addition to the <destination_uri>.sha256 checksum file.")
+	signingKMSKey               = flag.String(exporter.SigningKMSKeyFlagKey, "", "A Cloud KMS asymmetric signing key version that uses SHA256 digests, such as projects/PROJECT/locations/LOCATION/keyRings/RING/cryptoKeys/KEY/cryptoKeyVersions/1. The checksum file is signed, and the signature is written to <destination_uri>.sha256.sig.")
+	signingKeyFile              = flag.String(exporter.SigningKeyFileFlagKey, "", "A local PEM file with an RSA, ECDSA, or Ed25519 private key. The checksum file is signed, and the signature is written to <destination_uri>.sha256.sig.")
+	awsAccessKeyID              = flag.String(exporter.AWSAccessKeyIDFlagKey, "", "The AWS access key ID, required when -destination_uri is in S3.")
+	awsSecretAccessKey          = flag.String(exporter.AWSSecretAccessKeyFlagKey, "", "The AWS secret access key, required when -destination_uri is in S3.")
+	awsSessionToken             = flag.String(exporter.AWSSessionTokenFlagKey, "", "The AWS session token, when -destination_uri is in S3 and temporary credentials are used.")
+	awsRegion                   = flag.String(exporter.AWSRegionFlagKey, "", "The AWS region of the S3 bucket, required when -destination_uri is in S3.")
+	azureSASToken               = flag.String(exporter.AzureSASTokenFlagKey, "", "A shared access signature that allows writing blobs to the container, required when -destination_uri is an Azure blob.")
 )
 
 func init() {
-	flag.Var(&workerMachineSeries, "worker_machine_series", "The export tool automatically selects the machine series for temporary worker VMs based on the execution context. The argument overrides this behavior and specifies the machine series to use for worker VMs. Additionally it is possible to specify fallback machine series by setting this argument twice. For example, -worker_machine_series n1 -worker_machine_series n2")
+	flag.Var(&workerMachineSeries, "worker_machine_series", "The export tool automatically selects the machine series for temporary worker VMs based on the execution context. The argument overrides this behavior and specifies the machine series to use for worker VMs. Additionally it is possible to specify an ordered list of fallback machine series, either as a comma-separated list or by repeating the argument. A fallback is used when a series is out of quota or capacity, or isn't compatible with the worker's disks. For example, -worker_machine_series c3,n2,n1")
 }
 
 func exportEntry() (service.Loggable, error) {
@@ -65,7 +78,9 @@ func exportEntry() (service.Loggable, error) {
 		DestinationURI:              *destinationURI,
 		SourceImage:                 *sourceImage,
 		SourceDiskSnapshot:          *sourceDiskSnapshot,
+		SourceDisk:                  *sourceDisk,
 		Format:                      *format,
+		PortableFor:                 *portableFor,
 		Project:                     *project,
 		Network:                     *network,
 		Subnet:                      *subnet,
@@ -82,9 +97,23 @@ func exportEntry() (service.Loggable, error) {
 		CurrentExecutablePath:       currentExecutablePath,
 		WorkerMachineSeries:         *&workerMachineSeries,
 		NestedVirtualizationEnabled: *nestedVirtualizationEnabled,
+		EmitWorkflowsDir:            *emitWorkflowsDir,
+		EmitWorkflowsOnly:           *emitWorkflowsOnly,
+		OVFManifest:                 *ovfManifest,
+		SigningKMSKey:               *signingKMSKey,
+		SigningKeyFile:              *signingKeyFile,
+		AWSAccessKeyID:              *awsAccessKeyID,
+		AWSSecretAccessKey:          *awsSecretAccessKey,
+		AWSSessionToken:             *awsSessionToken,
+		AWSRegion:                   *awsRegion,
+		AzureSASToken:               *azureSASToken,
 	}
 
 	err := exporter.Run(logger, args)
+	if daisyutils.IsWorkflowNotRun(err) {
+		// The workflows were written, and not run, as requested.
+		err = nil
+	}
 	return service.NewOutputInfoLoggable(logger.ReadOutputInfo()), err
 }
 
@@ -115,6 +144,8 @@ func main() {
 			Format:                *format,
 			ComputeServiceAccount: *computeServiceAccount,
 			SourceDiskSnapshot:    *sourceDiskSnapshot,
+			SourceDisk:            *sourceDisk,
+			PortableFor:           *portableFor,
 		},
 	}
 
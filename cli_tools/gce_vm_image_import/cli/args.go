@@ -18,14 +18,34 @@ import (
 	"flag"
 	"fmt"
 	"io"
+	"os"
 	"strings"
 	"time"
 
+	"github.com/GoogleCloudPlatform/compute-image-import/cli_tools/common/domain"
+	"github.com/GoogleCloudPlatform/compute-image-import/cli_tools/common/image"
 	"github.com/GoogleCloudPlatform/compute-image-import/cli_tools/common/image/importer"
 	"github.com/GoogleCloudPlatform/compute-image-import/cli_tools/common/utils/daisyutils"
 	"github.com/GoogleCloudPlatform/compute-image-import/cli_tools/common/utils/flags"
 	"github.com/GoogleCloudPlatform/compute-image-import/cli_tools/common/utils/param"
 	"github.com/GoogleCloudPlatform/compute-image-import/cli_tools/common/utils/path"
+	"github.com/GoogleCloudPlatform/compute-image-import/cli_tools/common/utils/storage"
+	ovfdomain "github.com/GoogleCloudPlatform/compute-image-import/cli_tools/gce_ovf_import/domain"
+	ovfimporter "github.com/GoogleCloudPlatform/compute-image-import/cli_tools/gce_ovf_import/ovf_importer"
+)
+
+const (
+	targetFlag         = "target"
+	machineTypeFlag    = "machine_type"
+	dataDiskFileFlag   = "data_disk_file"
+	storageBackendFlag = "storage_backend"
+
+	emitWorkflowsDirFlag  = "emit_workflows_dir"
+	emitWorkflowsOnlyFlag = "emit_workflows_only"
+
+	imageTarget        = "image"
+	machineImageTarget = "machine_image"
+	instanceTarget     = "instance"
 )
 
 // imageImportArgs receives arguments passed by the user and facilitates creating
@@ -37,6 +57,24 @@ type imageImportArgs struct {
 	SourceFile    string
 	SourceImage   string
 	Started       time.Time
+
+	// AdditionalSourceFiles are converted to ImageImportRequest.AdditionalSources.
+	AdditionalSourceFiles []string
+
+	// Target is the type of resource to create: an image (default), a machine
+	// image, or an instance. When it's not an image, the source file and the
+	// data disk files are imported using the OVF importer, without an OVF descriptor.
+	Target        string
+	MachineType   string
+	DataDiskFiles []string
+
+	// StorageBackend selects where Cloud Storage objects are read and written.
+	// See storage.NewStorageClientForBackend.
+	StorageBackend string
+
+	// MetadataFile is the metadata sidecar written by the image exporter, as a
+	// local path or a Cloud Storage URI. See applyMetadataFile.
+	MetadataFile string
 	importer.ImageImportRequest
 }
 
@@ -71,10 +109,27 @@ func (args *imageImportArgs) populateAndValidate(populator param.Populator,
 		HumanReadableName: "image import",
 		ResourceLabelName: "image-import",
 	}
+	if err := args.validateTarget(); err != nil {
+		return err
+	}
+	if storage.IsLocalStorageBackend(args.StorageBackend) && (!args.EmitWorkflowsOnly || args.EmitWorkflowsDir == "") {
+		return fmt.Errorf("-%s=%s requires -%s and -%s, since workflows use Cloud Storage",
+			storageBackendFlag, storage.LocalStorageBackendPrefix, emitWorkflowsOnlyFlag, emitWorkflowsDirFlag)
+	}
+	if args.EmitWorkflowsOnly && args.EmitWorkflowsDir == "" {
+		return fmt.Errorf("-%s requires -%s", emitWorkflowsOnlyFlag, emitWorkflowsDirFlag)
+	}
 	args.Source, err = sourceFactory.Init(args.SourceFile, args.SourceImage)
 	if err != nil {
 		return err
 	}
+	for _, sourceFile := range args.AdditionalSourceFiles {
+		source, err := sourceFactory.Init(sourceFile, "")
+		if err != nil {
+			return err
+		}
+		args.AdditionalSources = append(args.AdditionalSources, source)
+	}
 
 	if err := populator.PopulateMissingParameters(&args.Project, args.ClientID, &args.Zone, &args.Region,
 		&args.ScratchBucketGcsPath, args.SourceFile, &args.StorageLocation, &args.Network, &args.Subnet,
@@ -93,6 +148,156 @@ func (args *imageImportArgs) populateAndValidate(populator param.Populator,
 	return nil
 }
 
+// validateTarget validates the flags that select, and are specific to,
+// creating a machine image or an instance.
+func (args *imageImportArgs) validateTarget() error {
+	if args.Target == "" {
+		args.Target = imageTarget
+	}
+	switch args.Target {
+	case imageTarget:
+		if len(args.DataDiskFiles) > 0 {
+			return fmt.Errorf("-%s requires -%s=%s or -%s=%s",
+				dataDiskFileFlag, targetFlag, machineImageTarget, targetFlag, instanceTarget)
+		}
+		if args.MachineType != "" {
+			return fmt.Errorf("-%s requires -%s=%s or -%s=%s",
+				machineTypeFlag, targetFlag, machineImageTarget, targetFlag, instanceTarget)
+		}
+		return nil
+	case machineImageTarget, instanceTarget:
+	default:
+		return fmt.Errorf("-%s must be one of %s, %s, or %s",
+			targetFlag, imageTarget, machineImageTarget, instanceTarget)
+	}
+
+	if args.SourceFile == "" {
+		return fmt.Errorf("-source_file is required when -%s=%s", targetFlag, args.Target)
+	}
+	if args.MachineType == "" {
+		return fmt.Errorf("-%s is required when -%s=%s", machineTypeFlag, targetFlag, args.Target)
+	}
+	if args.ImageName == "" {
+		return fmt.Errorf("-%s is required when -%s=%s", importer.ImageFlag, targetFlag, args.Target)
+	}
+	for _, unsupported := range []struct {
+		flagName string
+		isSet    bool
+	}{
+		{"source_image", args.SourceImage != ""},
+		{importer.DataDiskFlag, args.DataDisk},
+		{importer.AdditionalSourcesFlag, len(args.AdditionalSourceFiles) > 0},
+		{importer.ConvertToUEFIFlag, args.ConvertToUEFI},
+		{importer.CustomWorkflowFlag, args.CustomWorkflow != ""},
+		{"family", args.Family != ""},
+		{"storage_location", args.StorageLocation != ""},
+		{"sysprep_windows", args.SysprepWindows},
+		{importer.MetadataFileFlag, args.MetadataFile != ""},
+	} {
+		if unsupported.isSet {
+			return fmt.Errorf("-%s isn't supported when -%s=%s", unsupported.flagName, targetFlag, args.Target)
+		}
+	}
+	return nil
+}
+
+// applyMetadataFile restores the metadata of an exported image from MetadataFile.
+// The family, description, and labels are only used when they weren't specified
+// with flags.
+func (args *imageImportArgs) applyMetadataFile(storageClient domain.StorageClientInterface) error {
+	if args.MetadataFile == "" {
+		return nil
+	}
+	content, err := readMetadataFile(storageClient, args.MetadataFile)
+	if err != nil {
+		return fmt.Errorf("failed to read -%s: %v", importer.MetadataFileFlag, err)
+	}
+	metadata, err := image.ParseMetadata(content)
+	if err != nil {
+		return fmt.Errorf("failed to read -%s: %v", importer.MetadataFileFlag, err)
+	}
+	if args.Family == "" {
+		args.Family = metadata.Family
+	}
+	if args.Description == "" {
+		args.Description = metadata.Description
+	}
+	for key, value := range metadata.Labels {
+		if _, found := args.Labels[key]; !found {
+			if args.Labels == nil {
+				args.Labels = map[string]string{}
+			}
+			args.Labels[key] = value
+		}
+	}
+	args.Licenses = metadata.Licenses
+	args.GuestOSFeatures = metadata.GuestOSFeatures
+	args.Architecture = metadata.Architecture
+	return nil
+}
+
+func readMetadataFile(storageClient domain.StorageClientInterface, metadataFile string) ([]byte, error) {
+	if !strings.HasPrefix(metadataFile, "gs://") {
+		return os.ReadFile(metadataFile)
+	}
+	bucket, object, err := storage.SplitGCSPath(metadataFile)
+	if err != nil {
+		return nil, err
+	}
+	reader, err := storageClient.GetObject(bucket, object).NewReader()
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+	return io.ReadAll(reader)
+}
+
+// ovfImportParams creates the parameters for the OVF importer to create a
+// machine image or an instance from the source file and the data disk files.
+func (args *imageImportArgs) ovfImportParams() *ovfdomain.OVFImportParams {
+	project := args.Project
+	params := &ovfdomain.OVFImportParams{
+		ClientID:                    args.ClientID,
+		BootDiskFile:                args.SourceFile,
+		DataDiskFiles:               args.DataDiskFiles,
+		NoGuestEnvironment:          args.NoGuestEnvironment,
+		Description:                 args.Description,
+		UserLabels:                  args.Labels,
+		MachineType:                 args.MachineType,
+		Network:                     args.Network,
+		Subnet:                      args.Subnet,
+		NoExternalIP:                args.NoExternalIP,
+		OsID:                        args.OS,
+		BYOL:                        args.BYOL,
+		Zone:                        args.Zone,
+		Timeout:                     args.Timeout.String(),
+		Project:                     &project,
+		ScratchBucketGcsPath:        args.ScratchBucketGcsPath,
+		Oauth:                       args.Oauth,
+		ComputeServiceAccount:       args.ComputeServiceAccount,
+		InstanceServiceAccount:      "default",
+		InstanceAccessScopesFlag:    strings.Join(ovfimporter.DefaultInstanceAccessScopes, ","),
+		GcsLogsDisabled:             args.GcsLogsDisabled,
+		CloudLogsDisabled:           args.CloudLogsDisabled,
+		StdoutLogsDisabled:          args.StdoutLogsDisabled,
+		UefiCompatible:              args.UefiCompatible,
+		BuildID:                     args.ExecutionID,
+		NestedVirtualizationEnabled: args.NestedVirtualizationEnabled,
+		WorkerMachineSeries:         args.WorkerMachineSeries,
+		EndpointsOverride:           args.EndpointsOverride,
+		EmitWorkflowsDir:            args.EmitWorkflowsDir,
+		EmitWorkflowsOnly:           args.EmitWorkflowsOnly,
+		StorageBackend:              args.StorageBackend,
+		WorkflowDir:                 args.WorkflowDir,
+	}
+	if args.Target == instanceTarget {
+		params.InstanceNames = args.ImageName
+	} else {
+		params.MachineImageName = args.ImageName
+	}
+	return params
+}
+
 func (args *imageImportArgs) registerFlags(flagSet *flag.FlagSet) {
 	flagSet.Var((*flags.LowerTrimmedString)(&args.ClientID), importer.ClientFlag,
 		"Identifies the client of the importer, e.g. 'gcloud', 'pantheon', or 'api'.")
@@ -131,6 +336,11 @@ func (args *imageImportArgs) registerFlags(flagSet *flag.FlagSet) {
 	flagSet.Var((*flags.TrimmedString)(&args.EndpointsOverride.Storage), "storage_endpoint_override",
 		"API endpoint to override default for Storage.")
 
+	flagSet.Var((*flags.TrimmedString)(&args.StorageBackend), storageBackendFlag,
+		"Where Cloud Storage buckets and objects are read and written. Either gs:// (default), "+
+			"or file://<directory> to map buckets and objects onto a local directory. file:// requires "+
+			"-emit_workflows_only and -emit_workflows_dir, since workflows always use Cloud Storage.")
+
 	flagSet.Var((*flags.TrimmedString)(&args.EndpointsOverride.CloudLogging), "cloud_logging_endpoint_override",
 		"API endpoint to override default for Cloud Logging.")
 
@@ -160,7 +370,21 @@ func (args *imageImportArgs) registerFlags(flagSet *flag.FlagSet) {
 	flagSet.Bool("kms_project", false, "Reserved for future use.")
 
 	flagSet.Var((*flags.LowerTrimmedString)(&args.ImageName), importer.ImageFlag,
-		"Name of the disk image to create.")
+		"Name of the disk image to create. With -"+targetFlag+"="+machineImageTarget+" or -"+targetFlag+"="+
+			instanceTarget+", the name of the machine image or instance to create.")
+
+	flagSet.Var((*flags.LowerTrimmedString)(&args.Target), targetFlag,
+		"The type of resource to create: "+imageTarget+" (default), "+machineImageTarget+", or "+instanceTarget+". "+
+			"When creating a machine image or an instance, -source_file is imported as its boot disk, "+
+			"and -"+dataDiskFileFlag+" as its data disks.")
+
+	flagSet.Var((*flags.TrimmedString)(&args.MachineType), machineTypeFlag,
+		"The machine type of the machine image or instance to create. Required when -"+targetFlag+"="+
+			machineImageTarget+" or -"+targetFlag+"="+instanceTarget+".")
+
+	flagSet.Var((*flags.StringListFlag)(&args.DataDiskFiles), dataDiskFileFlag,
+		"The Cloud Storage URIs of virtual disk files to import as data disks of the machine image or "+
+			"instance. Specify as a comma-separated list, or by repeating the argument.")
 
 	flagSet.Var((*flags.TrimmedString)(&args.Family), "family",
 		"Family to set for the imported image.")
@@ -168,6 +392,13 @@ func (args *imageImportArgs) registerFlags(flagSet *flag.FlagSet) {
 	flagSet.Var((*flags.TrimmedString)(&args.Description), "description",
 		"Description to set for the imported image.")
 
+	flagSet.Var((*flags.TrimmedString)(&args.MetadataFile), importer.MetadataFileFlag,
+		"The metadata file written by gce_vm_image_export next to the exported file, such as "+
+			"gs://bucket/image.vmdk.metadata.json. Restores the licenses, guest OS features, architecture, "+
+			"family, description, and labels of the exported image. When the licenses show that the image "+
+			"was already configured for Compute Engine, the disk isn't translated unless -os, -convert_to_uefi, "+
+			"-root_partition, or -select_os is specified.")
+
 	flagSet.Var((*flags.KeyValueString)(&args.Labels), "labels",
 		"List of label KEY=VALUE pairs to add. "+
 			"For more information, see: https://cloud.google.com/compute/docs/labeling-resources")
@@ -183,6 +414,16 @@ func (args *imageImportArgs) registerFlags(flagSet *flag.FlagSet) {
 	flagSet.Var((*flags.TrimmedString)(&args.SourceImage), "source_image",
 		"An existing Compute Engine image from which to import.")
 
+	flagSet.Var((*flags.StringListFlag)(&args.AdditionalSourceFiles), importer.AdditionalSourcesFlag,
+		"The Cloud Storage URIs of the other virtual disk files of the boot disk, when its root filesystem "+
+			"is on an LVM volume group that spans multiple disks. Specify as a comma-separated list, or by "+
+			"repeating the argument.")
+
+	flagSet.BoolVar(&args.ConsolidateLVM, importer.ConsolidateLVMFlag, false,
+		"When -"+importer.AdditionalSourcesFlag+" is specified, moves the volume group onto the boot disk, "+
+			"and imports a single image. Otherwise, each additional disk is imported as a separate image, "+
+			"and -os is required.")
+
 	flagSet.BoolVar(&args.BYOL, importer.BYOLFlag, false,
 		"Import using an existing license. These are equivalent: "+
 			"`-os=rhel-8 -byol`, `-os=rhel-8-byol -byol`, and `-os=rhel-8-byol`")
@@ -195,6 +436,19 @@ func (args *imageImportArgs) registerFlags(flagSet *flag.FlagSet) {
 		"Specifies the OS of the image being imported. OS must be one of: "+
 			strings.Join(daisyutils.GetSortedOSIDs(), ", ")+".")
 
+	flagSet.Var((*flags.TrimmedString)(&args.RootPartition), importer.RootPartitionFlag,
+		"When the disk has multiple operating systems, the block device holding the root "+
+			"filesystem of the one to import, such as /dev/sda2. Inspection lists the candidates. "+
+			"Not supported for Windows.")
+
+	flagSet.Var((*flags.LowerTrimmedString)(&args.SelectOS), importer.SelectOSFlag,
+		"When the disk has multiple operating systems, the one to import, using the format of -os. "+
+			"For example, -select_os=ubuntu-2204. Not supported for Windows.")
+
+	flagSet.BoolVar(&args.ConvertToUEFI, importer.ConvertToUEFIFlag, false,
+		"Converts a disk that boots with BIOS to boot with UEFI. The partition table is converted "+
+			"to GPT, an EFI system partition is created, and a UEFI bootloader is installed.")
+
 	flagSet.BoolVar(&args.NoGuestEnvironment, "no_guest_environment", false,
 		"When enabled, the Google Guest Environment will not be installed.")
 
@@ -202,11 +456,13 @@ func (args *imageImportArgs) registerFlags(flagSet *flag.FlagSet) {
 		"When enabled, temporary worker VMs will be created with enabled nested virtualization. "+
 			"See https://cloud.google.com/compute/docs/instances/nested-virtualization/enabling for details.")
 
-	flagSet.Var((*flags.StringArrayFlag)(&args.WorkerMachineSeries), "worker_machine_series",
+	flagSet.Var((*flags.StringListFlag)(&args.WorkerMachineSeries), "worker_machine_series",
 		"The import tool automatically selects the machine series for temporary worker VMs based on the execution context. "+
 			"The argument overrides this behavior and specifies the machine series to use for worker VMs. "+
-			"Additionally it is possible to specify fallback machine series by setting this argument twice. "+
-			"For example, -worker_machine_series n1 -worker_machine_series n2")
+			"Additionally it is possible to specify an ordered list of fallback machine series, either as a comma-separated "+
+			"list or by repeating the argument. A fallback is used when a series is out of quota or capacity, "+
+			"or isn't compatible with the worker's disks. "+
+			"For example, -worker_machine_series c3,n2,n1")
 
 	flagSet.DurationVar(&args.Timeout, "timeout", time.Hour*2,
 		"Maximum time a build can last before it is failed as TIMEOUT. For example, "+
@@ -222,4 +478,14 @@ func (args *imageImportArgs) registerFlags(flagSet *flag.FlagSet) {
 
 	flagSet.BoolVar(&args.SysprepWindows, "sysprep_windows", false,
 		"Generalize image using Windows Sysprep. Only applicable to Windows.")
+
+	flagSet.Var((*flags.TrimmedString)(&args.EmitWorkflowsDir), emitWorkflowsDirFlag,
+		"A local directory to which each daisy workflow is written as JSON, after all variables "+
+			"and overrides are applied. Useful for debugging and auditing which resources are created.")
+
+	flagSet.BoolVar(&args.EmitWorkflowsOnly, emitWorkflowsOnlyFlag, false,
+		"Writes workflows to -emit_workflows_dir without running them. Requires -emit_workflows_dir. "+
+			"Later workflows depend on the results of earlier ones, so the import stops after the first "+
+			"workflow is written, or, with -data_disk_file, after the workflows that import the data disks "+
+			"in parallel are written.")
 }
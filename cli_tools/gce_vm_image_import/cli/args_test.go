@@ -17,14 +17,19 @@ package cli
 import (
 	"errors"
 	"fmt"
+	"io"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 	"time"
 
+	"github.com/golang/mock/gomock"
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 
 	"github.com/GoogleCloudPlatform/compute-image-import/cli_tools/common/image/importer"
+	"github.com/GoogleCloudPlatform/compute-image-import/cli_tools/mocks"
 )
 
 func Test_populateAndValidate_InitializesStarted(t *testing.T) {
@@ -167,6 +172,19 @@ func Test_populateAndValidate_TrimsAndLowerOS(t *testing.T) {
 	assert.Equal(t, "ubuntu-1804", parseAndPopulate(t, "-os", "  UBUNTU-1804 ").OS)
 }
 
+func Test_populateAndValidate_TrimsRootPartition(t *testing.T) {
+	assert.Equal(t, "/dev/sda2", parseAndPopulate(t, "-root_partition", "  /dev/sda2 ").RootPartition)
+}
+
+func Test_populateAndValidate_TrimsAndLowerSelectOS(t *testing.T) {
+	assert.Equal(t, "ubuntu-2204", parseAndPopulate(t, "-select_os", "  UBUNTU-2204 ").SelectOS)
+}
+
+func Test_populateAndValidate_SupportsConvertToUEFI(t *testing.T) {
+	assert.False(t, parseAndPopulate(t, "-os=ubuntu-1804").ConvertToUEFI)
+	assert.True(t, parseAndPopulate(t, "-convert_to_uefi").ConvertToUEFI)
+}
+
 func Test_populateAndValidate_SupportsDataDisk(t *testing.T) {
 	assert.False(t, parseAndPopulate(t, "-data_disk=false", "-os=ubuntu-1804").DataDisk)
 	assert.False(t, parseAndPopulate(t, "-os=ubuntu-1804").DataDisk)
@@ -344,6 +362,173 @@ func Test_populateAndValidate_CreatesSourceObjectFromSourceFile(t *testing.T) {
 	assert.Equal(t, "gs://path/file", actual.Source.Path())
 }
 
+func Test_populateAndValidate_CreatesSourceObjectsFromAdditionalSourceFiles(t *testing.T) {
+	actual := parseAndPopulate(t, "-additional_source_files", "gs://path/disk2.vmdk, gs://path/disk3.vmdk",
+		"-additional_source_files", "gs://path/disk4.vmdk", "-consolidate_lvm")
+	assert.Equal(t, []string{"gs://path/disk2.vmdk", "gs://path/disk3.vmdk", "gs://path/disk4.vmdk"},
+		actual.AdditionalSourceFiles)
+	assert.Len(t, actual.AdditionalSources, 3)
+	assert.True(t, actual.ConsolidateLVM)
+}
+
+func Test_populateAndValidate_TargetDefaultsToImage(t *testing.T) {
+	assert.Equal(t, "image", parseAndPopulate(t).Target)
+}
+
+func Test_populateAndValidate_SupportsMachineImageAndInstanceTargets(t *testing.T) {
+	for _, target := range []string{"machine_image", "instance"} {
+		t.Run(target, func(t *testing.T) {
+			actual := parseAndPopulate(t, "-target", " "+strings.ToUpper(target)+" ", "-source_file=gs://path/boot.vmdk",
+				"-machine_type", " e2-standard-4 ", "-data_disk_file", "gs://path/data1.vmdk,gs://path/data2.vmdk")
+			assert.Equal(t, target, actual.Target)
+			assert.Equal(t, "e2-standard-4", actual.MachineType)
+			assert.Equal(t, []string{"gs://path/data1.vmdk", "gs://path/data2.vmdk"}, actual.DataDiskFiles)
+		})
+	}
+}
+
+func Test_populateAndValidate_SupportsLocalStorageBackendWhenWorkflowsAreOnlyEmitted(t *testing.T) {
+	actual := parseAndPopulate(t, "-storage_backend", " file:///tmp/gcs ", "-emit_workflows_only",
+		"-emit_workflows_dir", "/tmp/workflows")
+	assert.Equal(t, "file:///tmp/gcs", actual.StorageBackend)
+}
+
+func Test_populateAndValidate_ValidatesTarget(t *testing.T) {
+	for _, tt := range []struct {
+		name          string
+		args          []string
+		expectedError string
+	}{
+		{
+			name:          "unknown target",
+			args:          []string{"-target=disk"},
+			expectedError: "-target must be one of image, machine_image, or instance",
+		}, {
+			name:          "data disk files require target",
+			args:          []string{"-data_disk_file=gs://path/data.vmdk"},
+			expectedError: "-data_disk_file requires -target=machine_image or -target=instance",
+		}, {
+			name:          "machine type requires target",
+			args:          []string{"-machine_type=e2-standard-4"},
+			expectedError: "-machine_type requires -target=machine_image or -target=instance",
+		}, {
+			name:          "target requires source file",
+			args:          []string{"-target=instance", "-source_image=image", "-machine_type=e2-standard-4"},
+			expectedError: "-source_file is required when -target=instance",
+		}, {
+			name:          "target requires machine type",
+			args:          []string{"-target=machine_image", "-source_file=gs://path/boot.vmdk"},
+			expectedError: "-machine_type is required when -target=machine_image",
+		}, {
+			name: "target doesn't support data disk",
+			args: []string{"-target=instance", "-source_file=gs://path/boot.vmdk", "-machine_type=e2-standard-4",
+				"-data_disk"},
+			expectedError: "-data_disk isn't supported when -target=instance",
+		}, {
+			name: "target doesn't support family",
+			args: []string{"-target=machine_image", "-source_file=gs://path/boot.vmdk", "-machine_type=e2-standard-4",
+				"-family=f"},
+			expectedError: "-family isn't supported when -target=machine_image",
+		}, {
+			name: "target doesn't support metadata file",
+			args: []string{"-target=instance", "-source_file=gs://path/boot.vmdk", "-machine_type=e2-standard-4",
+				"-metadata_file=gs://path/boot.vmdk.metadata.json"},
+			expectedError: "-metadata_file isn't supported when -target=instance",
+		}, {
+			name:          "emit_workflows_only requires emit_workflows_dir",
+			args:          []string{"-emit_workflows_only"},
+			expectedError: "-emit_workflows_only requires -emit_workflows_dir",
+		}, {
+			name:          "local storage backend requires emit_workflows_only",
+			args:          []string{"-storage_backend=file:///tmp/gcs", "-emit_workflows_dir=/tmp/workflows"},
+			expectedError: "-storage_backend=file:// requires -emit_workflows_only and -emit_workflows_dir, since workflows use Cloud Storage",
+		}, {
+			name:          "local storage backend requires emit_workflows_dir",
+			args:          []string{"-storage_backend=file:///tmp/gcs", "-emit_workflows_only"},
+			expectedError: "-storage_backend=file:// requires -emit_workflows_only and -emit_workflows_dir, since workflows use Cloud Storage",
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			actual := addRequiredArgsAndParse(t, tt.args...)
+			err := actual.populateAndValidate(mockPopulator{
+				zone:   "us-west2-a",
+				region: "us-west2",
+			}, mockSourceFactory{})
+			assert.EqualError(t, err, tt.expectedError)
+		})
+	}
+}
+
+const exportedMetadata = `{
+  "description": "exported description",
+  "family": "exported-family",
+  "labels": {"env": "prod", "team": "exported"},
+  "licenses": ["projects/debian-cloud/global/licenses/debian-12-bookworm"],
+  "guestOsFeatures": ["UEFI_COMPATIBLE", "GVNIC"],
+  "architecture": "X86_64"
+}`
+
+func Test_applyMetadataFile_RestoresMetadataFromLocalFile(t *testing.T) {
+	metadataFile := filepath.Join(t.TempDir(), "image.vmdk.metadata.json")
+	assert.NoError(t, os.WriteFile(metadataFile, []byte(exportedMetadata), 0644))
+	actual := addRequiredArgsAndParse(t, "-metadata_file="+metadataFile, "-family=user-family", "-labels=team=user")
+
+	assert.NoError(t, actual.applyMetadataFile(nil))
+	assert.Equal(t, "user-family", actual.Family)
+	assert.Equal(t, "exported description", actual.Description)
+	assert.Equal(t, map[string]string{"env": "prod", "team": "user"}, actual.Labels)
+	assert.Equal(t, []string{"projects/debian-cloud/global/licenses/debian-12-bookworm"}, actual.Licenses)
+	assert.Equal(t, []string{"UEFI_COMPATIBLE", "GVNIC"}, actual.GuestOSFeatures)
+	assert.Equal(t, "X86_64", actual.Architecture)
+}
+
+func Test_applyMetadataFile_RestoresMetadataFromGCS(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	storageObject := mocks.NewMockStorageObject(mockCtrl)
+	storageObject.EXPECT().NewReader().Return(io.NopCloser(strings.NewReader(exportedMetadata)), nil)
+	storageClient := mocks.NewMockStorageClientInterface(mockCtrl)
+	storageClient.EXPECT().GetObject("bucket", "image.vmdk.metadata.json").Return(storageObject)
+	actual := addRequiredArgsAndParse(t, "-metadata_file=gs://bucket/image.vmdk.metadata.json")
+
+	assert.NoError(t, actual.applyMetadataFile(storageClient))
+	assert.Equal(t, "exported-family", actual.Family)
+	assert.Equal(t, map[string]string{"env": "prod", "team": "exported"}, actual.Labels)
+}
+
+func Test_applyMetadataFile_FailsWhenFileIsInvalid(t *testing.T) {
+	metadataFile := filepath.Join(t.TempDir(), "image.vmdk.metadata.json")
+	assert.NoError(t, os.WriteFile(metadataFile, []byte("not json"), 0644))
+	actual := addRequiredArgsAndParse(t, "-metadata_file="+metadataFile)
+
+	err := actual.applyMetadataFile(nil)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to read -metadata_file: invalid image metadata")
+}
+
+func Test_ovfImportParams(t *testing.T) {
+	actual := parseAndPopulate(t, "-target=instance", "-source_file=gs://path/boot.vmdk",
+		"-machine_type=e2-standard-4", "-data_disk_file=gs://path/data.vmdk", "-image_name=vm",
+		"-os=ubuntu-2204", "-labels=k=v", "-execution_id=abc")
+	params := actual.ovfImportParams()
+	assert.True(t, params.IsInstanceImport())
+	assert.True(t, params.IsDiskFileImport())
+	assert.Equal(t, "vm", params.InstanceNames)
+	assert.Equal(t, "gs://path/boot.vmdk", params.BootDiskFile)
+	assert.Equal(t, []string{"gs://path/data.vmdk"}, params.DataDiskFiles)
+	assert.Equal(t, "e2-standard-4", params.MachineType)
+	assert.Equal(t, "ubuntu-2204", params.OsID)
+	assert.Equal(t, map[string]string{"k": "v"}, params.UserLabels)
+	assert.Equal(t, "abc", params.BuildID)
+	assert.Equal(t, "2h0m0s", params.Timeout)
+	assert.Equal(t, actual.ScratchBucketGcsPath, params.ScratchBucketGcsPath)
+
+	actual.Target = "machine_image"
+	params = actual.ovfImportParams()
+	assert.True(t, params.IsMachineImageImport())
+	assert.Equal(t, "vm", params.MachineImageName)
+}
+
 func Test_populateAndValidate_FailsWhenSourceValidateFails(t *testing.T) {
 	args := []string{"-image_name=i", "-client_id=c", "-data_disk"}
 	actual, err := parseArgsFromUser(args)
@@ -401,6 +586,7 @@ func Test_populateAndValidate_NestedVirtualization(t *testing.T) {
 
 func Test_populateAndValidate_WorkerMachineSeriesAreSpecified(t *testing.T) {
 	assert.Equal(t, []string{"n2", "n1"}, parseAndPopulate(t, "-worker_machine_series=n2", "-worker_machine_series=n1").WorkerMachineSeries)
+	assert.Equal(t, []string{"c3", "n2", "n1", "e2"}, parseAndPopulate(t, "-worker_machine_series=c3,n2", "-worker_machine_series=n1,e2").WorkerMachineSeries)
 }
 
 func Test_populateAndValidate_WorkerMachineSeriesAreDetected(t *testing.T) {
@@ -22,12 +22,14 @@ import (
 
 	"github.com/GoogleCloudPlatform/compute-image-import/cli_tools/common/utils/daisyutils"
 
+	"github.com/GoogleCloudPlatform/compute-image-import/cli_tools/common/domain"
 	"github.com/GoogleCloudPlatform/compute-image-import/cli_tools/common/image/importer"
 	"github.com/GoogleCloudPlatform/compute-image-import/cli_tools/common/utils/compute"
 	"github.com/GoogleCloudPlatform/compute-image-import/cli_tools/common/utils/logging"
 	"github.com/GoogleCloudPlatform/compute-image-import/cli_tools/common/utils/logging/service"
 	"github.com/GoogleCloudPlatform/compute-image-import/cli_tools/common/utils/param"
 	"github.com/GoogleCloudPlatform/compute-image-import/cli_tools/common/utils/storage"
+	ovfimporter "github.com/GoogleCloudPlatform/compute-image-import/cli_tools/gce_ovf_import/ovf_importer"
 	"google.golang.org/api/option"
 )
 
@@ -36,11 +38,13 @@ func Main(args []string, toolLogger logging.ToolLogger, workflowDir string) erro
 	logging.RedirectGlobalLogsToUser(toolLogger)
 	ctx := context.Background()
 
-	// Interpreting the user's request occurs in three steps:
+	// Interpreting the user's request occurs in four steps:
 	//  1. Parse the CLI arguments, without performing validation or population.
 	//  2. Instantiate API clients using authentication overrides from arguments,
 	//     if they were provided.
 	//  3. Populate missing arguments using the API clients.
+	//  4. Check that workers will be able to reach Google APIs, and that the
+	//     caller has the required IAM permissions.
 
 	// 1. Parse the CLI arguments
 	importArgs, err := parseArgsFromUser(args)
@@ -52,6 +56,10 @@ func Main(args []string, toolLogger logging.ToolLogger, workflowDir string) erro
 	printOverriddenAPIsInfo(importArgs.EndpointsOverride)
 
 	importArgs.WorkflowDir = workflowDir
+	if err := daisyutils.ValidateTranslationSettings(workflowDir); err != nil {
+		logFailure(importArgs, err)
+		return err
+	}
 
 	// 2. Setup dependencies.
 	storageClient, err := createStorageClient(ctx, importArgs, toolLogger)
@@ -77,6 +85,10 @@ func Main(args []string, toolLogger logging.ToolLogger, workflowDir string) erro
 	)
 
 	// 3. Populate missing arguments.
+	if err := importArgs.applyMetadataFile(storageClient); err != nil {
+		logFailure(importArgs, err)
+		return err
+	}
 	err = importArgs.populateAndValidate(paramPopulator,
 		importer.NewSourceFactory(storageClient))
 	if err != nil {
@@ -84,8 +96,46 @@ func Main(args []string, toolLogger logging.ToolLogger, workflowDir string) erro
 		return err
 	}
 
-	// Run the import.
-	importRunner, err := importer.NewImporter(importArgs.ImageImportRequest, computeClient, storageClient, toolLogger)
+	// 4. Run preflight checks before any resource is created. The OVF importer
+	// runs its own network and permission preflights.
+	if importArgs.Target != imageTarget {
+		return importToMachineImageOrInstance(importArgs, toolLogger)
+	}
+	networkPreflight, err := param.CreateNetworkPreflight(
+		ctx, importArgs.Oauth, importArgs.EndpointsOverride.Compute, computeClient)
+	if err != nil {
+		logFailure(importArgs, err)
+		return err
+	}
+	err = networkPreflight.Check(importArgs.Network, importArgs.Subnet, importArgs.Region,
+		importArgs.Project, importArgs.NoExternalIP)
+	if err != nil {
+		logFailure(importArgs, err)
+		return err
+	}
+	permissionPreflight, err := param.CreatePermissionPreflight(
+		ctx, importArgs.Oauth, computeClient, storageClient)
+	if err != nil {
+		logFailure(importArgs, err)
+		return err
+	}
+	err = permissionPreflight.Check(param.PermissionRequest{
+		Flow:                  param.ImageImportFlow,
+		Project:               importArgs.Project,
+		ComputeServiceAccount: importArgs.ComputeServiceAccount,
+		NoExternalIP:          importArgs.NoExternalIP,
+		ScratchBucketGcsPath:  importArgs.ScratchBucketGcsPath,
+		SourceGcsPaths:        append([]string{importArgs.SourceFile}, importArgs.AdditionalSourceFiles...),
+	})
+	if err != nil {
+		logFailure(importArgs, err)
+		return err
+	}
+
+	// Run the import. Workflows share the compute client, which the quota preflight
+	// uses to read quotas.
+	importRunner, err := importer.NewImporter(importArgs.ImageImportRequest, computeClient, storageClient,
+		daisyutils.WorkflowClients{ComputeClient: computeClient}, toolLogger)
 	if err != nil {
 		logFailure(importArgs, err)
 		return err
@@ -93,6 +143,10 @@ func Main(args []string, toolLogger logging.ToolLogger, workflowDir string) erro
 
 	importClosure := func() (service.Loggable, error) {
 		err := importRunner.Run(ctx)
+		if daisyutils.IsWorkflowNotRun(err) {
+			// The workflows were written, and not run, as requested.
+			err = nil
+		}
 		return service.NewOutputInfoLoggable(toolLogger.ReadOutputInfo()), userFriendlyError(err, importArgs)
 	}
 
@@ -104,8 +158,32 @@ func Main(args []string, toolLogger logging.ToolLogger, workflowDir string) erro
 	return nil
 }
 
+// importToMachineImageOrInstance uses the OVF importer to create a machine image or an
+// instance from the source file and the data disk files, without an OVF descriptor.
+func importToMachineImageOrInstance(importArgs imageImportArgs, toolLogger logging.ToolLogger) error {
+	ovfImporter, err := ovfimporter.NewOVFImporter(importArgs.ovfImportParams(), toolLogger)
+	if err != nil {
+		logFailure(importArgs, err)
+		return err
+	}
+	defer ovfImporter.CleanUp()
+
+	importClosure := func() (service.Loggable, error) {
+		err := ovfImporter.Import()
+		if daisyutils.IsWorkflowNotRun(err) {
+			// The workflows were written, and not run, as requested.
+			err = nil
+		}
+		return service.NewOutputInfoLoggable(toolLogger.ReadOutputInfo()), err
+	}
+
+	project := importArgs.Project
+	return service.RunWithServerLogging(
+		service.ImageImportAction, initLoggingParams(importArgs), &project, importClosure)
+}
+
 // Create a new storageClient client object with option to override storage endpoint.
-func createStorageClient(ctx context.Context, importArgs imageImportArgs, toolLogger logging.ToolLogger) (*storage.Client, error) {
+func createStorageClient(ctx context.Context, importArgs imageImportArgs, toolLogger logging.ToolLogger) (domain.StorageClientInterface, error) {
 	storageOptions := []option.ClientOption{}
 	if importArgs.Oauth != "" {
 		storageOptions = append(storageOptions, option.WithCredentialsFile(importArgs.Oauth))
@@ -115,7 +193,7 @@ func createStorageClient(ctx context.Context, importArgs imageImportArgs, toolLo
 		storageOptions = append(storageOptions, option.WithEndpoint(importArgs.EndpointsOverride.Storage))
 	}
 
-	storageClient, err := storage.NewStorageClient(ctx, toolLogger, storageOptions...)
+	storageClient, err := storage.NewStorageClientForBackend(ctx, toolLogger, importArgs.StorageBackend, storageOptions...)
 	if err != nil {
 		logFailure(importArgs, err)
 		return nil, err
@@ -124,6 +202,10 @@ func createStorageClient(ctx context.Context, importArgs imageImportArgs, toolLo
 	return storageClient, nil
 }
 
+// userFriendlyError rewrites errors from the vmExternalIpAccess constraint to point to
+// the documentation for imports without external IPs. The network preflight reports the
+// constraint before the import starts, but it skips the check when the organization
+// policy can't be read; in that case the constraint surfaces when workers are created.
 func userFriendlyError(err error, importArgs imageImportArgs) error {
 	if err == nil {
 		return err
@@ -31,7 +31,7 @@ import (
 //   - finding the root filesystem partition
 //   - checking if the device is MBR
 //   - checking whether the root mount is physically located on a single disk.
-//     The check fails, for example, when the root mount is on an LVM
+//     The check warns, for example, when the root mount is on an LVM
 //     logical volume that spans multiple disks.
 //   - check for GRUB
 //   - warning for any mount points from partitions from other devices
@@ -107,9 +107,10 @@ func getBootDiskMountInfo(r *Report, inspector mount.Inspector) (mount.Inspectio
 
 	if len(mountInfo.UnderlyingBlockDevices) > 1 {
 		format := "root filesystem spans multiple block devices (%s). Typically this occurs when an LVM logical " +
-			"volume spans multiple block devices. Image import only supports single block device."
-		r.Fatal(fmt.Sprintf(format, strings.Join(mountInfo.UnderlyingBlockDevices, ", ")))
-		return mountInfo, fmt.Errorf("Root filesystem spans multiple block devices")
+			"volume spans multiple block devices. To import, export each block device, and pass the disks " +
+			"other than the boot disk using -additional_source_files. Specify -consolidate_lvm to move the " +
+			"volume group onto the boot disk."
+		r.Warn(fmt.Sprintf(format, strings.Join(mountInfo.UnderlyingBlockDevices, ", ")))
 	}
 
 	r.Info(fmt.Sprintf("boot disk detected as %s", mountInfo.UnderlyingBlockDevices[0]))
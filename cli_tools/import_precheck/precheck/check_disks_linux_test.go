@@ -65,17 +65,20 @@ func TestDisksCheck_Inspector(t *testing.T) {
 			},
 			expectedStatus: Passed,
 		}, {
-			name: "fail if boot device is virtual with multiple underlying devices",
+			name: "warn if boot device is virtual with multiple underlying devices",
 			mountInfo: mount.InspectionResults{
 				BlockDevicePath:        "/dev/mapper/vg-lv",
 				BlockDeviceIsVirtual:   true,
 				UnderlyingBlockDevices: []string{"/dev/sda", "/dev/sdb"},
 			},
 			expectAllLogs: []string{
-				"FATAL: root filesystem spans multiple block devices (/dev/sda, /dev/sdb). Typically this occurs when an LVM " +
-					"logical volume spans multiple block devices. Image import only supports single block device.",
+				"WARN: root filesystem spans multiple block devices (/dev/sda, /dev/sdb). Typically this occurs when an LVM " +
+					"logical volume spans multiple block devices. To import, export each block device, and pass the disks " +
+					"other than the boot disk using -additional_source_files. Specify -consolidate_lvm to move the " +
+					"volume group onto the boot disk.",
+				"INFO: boot disk detected as /dev/sda",
 			},
-			expectedStatus: Failed,
+			expectedStatus: Passed,
 		}, {
 			name:         "fail if inspect fails",
 			inspectError: errors.New("failed to find root device"),
@@ -34,16 +34,16 @@ func (m *MockMachineSeriesDetector) EXPECT() *MockMachineSeriesDetectorMockRecor
 }
 
 // Detect mocks base method.
-func (m *MockMachineSeriesDetector) Detect(project, zone string) ([]string, error) {
+func (m *MockMachineSeriesDetector) Detect(project, zone string, requested []string) ([]string, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Detect", project, zone)
+	ret := m.ctrl.Call(m, "Detect", project, zone, requested)
 	ret0, _ := ret[0].([]string)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // Detect indicates an expected call of Detect.
-func (mr *MockMachineSeriesDetectorMockRecorder) Detect(project, zone interface{}) *gomock.Call {
+func (mr *MockMachineSeriesDetectorMockRecorder) Detect(project, zone, requested interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Detect", reflect.TypeOf((*MockMachineSeriesDetector)(nil).Detect), project, zone)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Detect", reflect.TypeOf((*MockMachineSeriesDetector)(nil).Detect), project, zone, requested)
 }
@@ -75,3 +75,18 @@ func (mr *MockResourceLocationRetrieverInterfaceMockRecorder) GetZone(arg0, arg1
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetZone", reflect.TypeOf((*MockResourceLocationRetrieverInterface)(nil).GetZone), arg0, arg1)
 }
+
+// GetZonesInRegion mocks base method.
+func (m *MockResourceLocationRetrieverInterface) GetZonesInRegion(arg0, arg1 string) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetZonesInRegion", arg0, arg1)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetZonesInRegion indicates an expected call of GetZonesInRegion.
+func (mr *MockResourceLocationRetrieverInterfaceMockRecorder) GetZonesInRegion(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetZonesInRegion", reflect.TypeOf((*MockResourceLocationRetrieverInterface)(nil).GetZonesInRegion), arg0, arg1)
+}
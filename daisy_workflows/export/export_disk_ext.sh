@@ -96,16 +96,40 @@ SOURCE_DEVICE=$(readlink -f /dev/disk/by-id/google-disk-image-export-ext)
 SIZE_BYTES=$(lsblk "${SOURCE_DEVICE}" --output=size -b | sed -n 2p)
 # 2. Round up to the next GB.
 SIZE_OUTPUT_GB=$(awk "BEGIN {print int(((${SIZE_BYTES}-1)/${BYTES_1GB}) + 1)}")
-# 3. Add 5GB of additional space to max size to prevent the corner case that output
-# file is slightly larger than source disk.
-MAX_BUFFER_DISK_SIZE_GB=$(awk "BEGIN {print int(${SIZE_OUTPUT_GB} + 5)}")
+# 3. tar.gz and raw are streamed to GCS, and only buffer a few parts of the
+# output at a time. Other formats are written to the buffer disk by qemu-img.
+IFS=',' read -r -a FORMATS <<< "${FORMAT}"
+STREAMED_FORMATS=()
+BUFFERED_FORMATS=()
+for format in "${FORMATS[@]}"; do
+  if [[ "${format}" == "tar.gz" || "${format}" == "raw" ]]; then
+    STREAMED_FORMATS+=("${format}")
+  else
+    BUFFERED_FORMATS+=("${format}")
+  fi
+done
+# 4. Add 5GB of additional space to max size to prevent the corner case that output
+# file is slightly larger than source disk. With multiple buffered formats, the
+# buffer holds a raw copy of the disk in addition to the output.
+if [[ ${#BUFFERED_FORMATS[@]} -gt 1 ]]; then
+  MAX_BUFFER_DISK_SIZE_GB=$(awk "BEGIN {print int(${SIZE_OUTPUT_GB} * 2 + 5)}")
+elif [[ ${#BUFFERED_FORMATS[@]} -eq 1 ]]; then
+  MAX_BUFFER_DISK_SIZE_GB=$(awk "BEGIN {print int(${SIZE_OUTPUT_GB} + 5)}")
+else
+  MAX_BUFFER_DISK_SIZE_GB=0
+fi
+# 5. Streamed outputs are uploaded in at most 10000 parts, which is the limit of
+# a GCS multipart upload. Parts are at least 64MB, and may be slightly larger
+# than the source disk when it's compressed.
+STREAM_PART_SIZE_MB=$(awk "BEGIN {s=int((${SIZE_BYTES}+5*${BYTES_1GB})/9000/1048576)+1; print (s>64?s:64)}")
+STREAM_PARTS_IN_FLIGHT=8
 
 set +x
 serialOutputPrefixedKeyValue "GCEExport" "source-size-gb" "${SIZE_OUTPUT_GB}"
 set -x
 
 # Prepare buffer disk.
-echo "GCEExport: Initializing buffer disk for qemu-img output..."
+echo "GCEExport: Initializing buffer disk..."
 BUFFER_DEVICE=$(readlink -f /dev/disk/by-id/google-disk-export-disk-buffer*)
 mkfs.ext4 "${BUFFER_DEVICE}"
 mount "${BUFFER_DEVICE}" "/var/gs/${OUTS_PATH}"
@@ -135,53 +159,252 @@ if ! out=$(docker pull "${GCLOUD_CLI_IMAGE}" 2>&1); then
 fi
 echo "${out}"
 
-echo "GCEExport: Launching disk size monitor in background..."
-disk_resizing_monitor "${MAX_BUFFER_DISK_SIZE_GB}" &
+if [[ ${MAX_BUFFER_DISK_SIZE_GB} -gt 0 ]]; then
+  echo "GCEExport: Launching disk size monitor in background..."
+  disk_resizing_monitor "${MAX_BUFFER_DISK_SIZE_GB}" &
+fi
 
-echo "GCEExport: Exporting disk of size ${SIZE_OUTPUT_GB}GB and format ${FORMAT}."
-# If format is tar.gz, dd the source device to a raw disk file and then tar it.
-# Otherwise, use docker to run qemu-img convert.
-if [[ "${FORMAT}" == "tar.gz" ]]; then
-  RAW_DISK_PATH="/var/gs/${OUTS_PATH}/disk.raw"
-  if ! out=$(dd if="${SOURCE_DEVICE}" of="${RAW_DISK_PATH}" bs=4M); then
-    echo "ExportFailed: Failed to export disk source to GCS [Privacy-> ${GS_PATH} <-Privacy] due to dd error: [Privacy-> ${out} <-Privacy]"
-    exit
+# Writes an authorization header for GCS requests to $1. The token is never
+# assigned to a variable, so it doesn't show up in the trace.
+function write_auth_header() {
+  curl -sSf -H Metadata-Flavor:Google "${METADATA_URL}/service-accounts/default/token" \
+    | sed -n 's/.*"access_token": *"\([^"]*\)".*/Authorization: Bearer \1/p' > "$1"
+}
+
+# Run by split for each part of a streamed output, which is read from stdin and
+# written to ${FILE}. Waits until fewer than STREAM_PARTS_IN_FLIGHT parts are
+# buffered, then uploads the part in the background. The part's ETag is
+# written to etag-<part>, or etag-<part>.failed is created on error. A part
+# that can't be buffered is removed, since stream_disk waits for all parts.
+function upload_part() {
+  local part=${FILE##*-}
+  while [[ $(find "${STREAM_PARTS_DIR}" -name 'part-?????' | wc -l) -ge ${STREAM_PARTS_IN_FLIGHT} ]]; do
+    sleep 1
+  done
+  if ! cat > "${FILE}"; then
+    rm -f "${FILE}"
+    return 1
   fi
-  if ! out=$(tar --create --format=gnu --owner=0 --group=0 --mode=0600 --gzip --file "/var/gs/${IMAGE_OUTPUT_PATH}" -C "/var/gs/${OUTS_PATH}" "disk.raw" 2>&1); then
-    echo "ExportFailed: Failed to export disk source to GCS [Privacy-> ${GS_PATH} <-Privacy] due to tar error: [Privacy-> ${out} <-Privacy]"
-    exit
+  (
+    local etag=""
+    if write_auth_header "${FILE}.auth"; then
+      etag=$(curl -sSf --retry 5 -X PUT -T "${FILE}" -H @"${FILE}.auth" -D - -o /dev/null \
+        "${STREAM_URL}?partNumber=$((10#${part}))&uploadId=${STREAM_UPLOAD_ID}" \
+        | tr -d '\r' | sed -n 's/^[Ee][Tt][Aa][Gg]: *//p')
+    fi
+    if [[ -n "${etag}" ]]; then
+      echo "${etag}" > "${STREAM_PARTS_DIR}/etag-${part}"
+    else
+      touch "${STREAM_PARTS_DIR}/etag-${part}.failed"
+    fi
+    rm -f "${FILE}" "${FILE}.auth"
+  ) < /dev/null 2>> "${STREAM_PARTS_DIR}/errors.txt" &
+}
+
+# Streams source device $1 in format $2 (tar.gz or raw) to GCS path $3, and
+# emits the size and SHA256 of the output using the serial output keys $4 and
+# $5. The output is uploaded with a parallel multipart upload, so it's never
+# written to the buffer disk as a whole. Returns non-zero after printing
+# ExportFailed on error.
+function stream_disk() {
+  local source=$1
+  local format=$2
+  local gs_path=$3
+  local size_key=$4
+  local sha256_key=$5
+  local bucket=${gs_path#gs://}
+  bucket=${bucket%%/*}
+  local object=${gs_path#gs://*/}
+  export METADATA_URL STREAM_PARTS_IN_FLIGHT
+  export STREAM_URL="https://storage.googleapis.com/${bucket}/${object}"
+  export STREAM_PARTS_DIR="/var/gs/${OUTS_PATH}/parts"
+  export -f write_auth_header upload_part
+  rm -rf "${STREAM_PARTS_DIR}"
+  mkdir -p "${STREAM_PARTS_DIR}"
+  local auth_header="${STREAM_PARTS_DIR}/auth"
+  local sha256_file="${STREAM_PARTS_DIR}/sha256"
+  # sha256sum's exit status is written here once the digest is done.
+  local sha256_status="${STREAM_PARTS_DIR}/sha256.status"
+
+  echo "GCEExport: Streaming disk as ${format} to GCS..."
+  write_auth_header "${auth_header}"
+  STREAM_UPLOAD_ID=$(curl -sSf --retry 5 -X POST -H @"${auth_header}" -H "Content-Length: 0" "${STREAM_URL}?uploads" \
+    | sed -n 's:.*<UploadId>\(.*\)</UploadId>.*:\1:p')
+  export STREAM_UPLOAD_ID
+  if [[ -z "${STREAM_UPLOAD_ID}" ]]; then
+    echo "ExportFailed: Failed to start upload to GCS [Privacy-> ${gs_path} <-Privacy]"
+    return 1
   fi
-  echo "${out}"
-else
-  QEMU_IMG_DOCKER_IMAGE=$(curl -f -H Metadata-Flavor:Google ${ATTRIBUTES_URL}/qemu-img-docker-image)
-  echo "GCEExport: Pulling docker image ${QEMU_IMG_DOCKER_IMAGE}..."
-  if ! out=$(docker pull "${QEMU_IMG_DOCKER_IMAGE}" 2>&1); then
-    echo "ExportFailed: Failed to pull docker image [Privacy-> ${QEMU_IMG_DOCKER_IMAGE} <-Privacy]. Error: [Privacy-> ${out} <-Privacy]"
-    exit
+
+  # tar.gz has the same layout as `tar --format=gnu --owner=0 --group=0
+  # --mode=0600`, which needs a file rather than a device, so the tar stream is
+  # written by python in the gcloud image.
+  local tar_py='
+import sys, tarfile, time
+with open(sys.argv[1], "rb") as src, tarfile.open(fileobj=sys.stdout.buffer, mode="w|", format=tarfile.GNU_FORMAT) as tar:
+  info = tarfile.TarInfo("disk.raw")
+  info.size = int(sys.argv[2])
+  info.mode = 0o600
+  info.mtime = int(time.time())
+  tar.addfile(info, src)
+'
+  if [[ "${format}" == "tar.gz" ]]; then
+    docker run --rm --device="${source}":"${source}" "${GCLOUD_CLI_IMAGE}" python3 -c "${tar_py}" "${source}" "${SIZE_BYTES}" \
+      2> >(tee /var/gs/stream_err.txt >&2) | gzip \
+      | tee >(sha256sum > "${sha256_file}"; echo $? > "${sha256_status}") \
+      | SHELL=/bin/bash split --bytes="${STREAM_PART_SIZE_MB}M" --numeric-suffixes=1 --suffix-length=5 --filter=upload_part - "${STREAM_PARTS_DIR}/part-"
+  else
+    dd if="${source}" bs=4M status=none 2> >(tee /var/gs/stream_err.txt >&2) \
+      | tee >(sha256sum > "${sha256_file}"; echo $? > "${sha256_status}") \
+      | SHELL=/bin/bash split --bytes="${STREAM_PART_SIZE_MB}M" --numeric-suffixes=1 --suffix-length=5 --filter=upload_part - "${STREAM_PARTS_DIR}/part-"
+  fi
+  local statuses=("${PIPESTATUS[@]}")
+
+  # Wait for the parts that are still uploading, and for the digest.
+  while compgen -G "${STREAM_PARTS_DIR}/part-?????" > /dev/null || [[ ! -s "${sha256_status}" ]]; do
+    sleep 1
+  done
+  write_auth_header "${auth_header}"
+  if [[ "${statuses[*]}" =~ [1-9] ]] || [[ "$(<"${sha256_status}")" != 0 ]] \
+    || compgen -G "${STREAM_PARTS_DIR}/*.failed" > /dev/null; then
+    curl -sS -X DELETE -H @"${auth_header}" "${STREAM_URL}?uploadId=${STREAM_UPLOAD_ID}"
+    echo "ExportFailed: Failed to stream disk source to GCS [Privacy-> ${gs_path}, error: $(cat /var/gs/stream_err.txt "${STREAM_PARTS_DIR}/errors.txt" 2>/dev/null) <-Privacy]"
+    return 1
+  fi
+
+  local body="${STREAM_PARTS_DIR}/complete.xml"
+  {
+    echo "<CompleteMultipartUpload>"
+    for etag_file in "${STREAM_PARTS_DIR}"/etag-*; do
+      echo "<Part><PartNumber>$((10#${etag_file##*-}))</PartNumber><ETag>$(<"${etag_file}")</ETag></Part>"
+    done
+    echo "</CompleteMultipartUpload>"
+  } > "${body}"
+  if ! out=$(curl -sSf --retry 5 -X POST -H @"${auth_header}" -H "Content-Type: application/xml" --data-binary @"${body}" \
+    "${STREAM_URL}?uploadId=${STREAM_UPLOAD_ID}" 2>&1); then
+    echo "ExportFailed: Failed to complete upload to GCS [Privacy-> ${gs_path}, error: ${out} <-Privacy]"
+    return 1
+  fi
+
+  # Exported image size info.
+  local target_size_bytes
+  target_size_bytes=$(curl -sSf -I -H @"${auth_header}" "${STREAM_URL}" \
+    | tr -d '\r' | sed -n 's/^[Xx]-[Gg]oog-[Ss]tored-[Cc]ontent-[Ll]ength: *//p')
+  local sha256
+  sha256=$(cut -d' ' -f1 "${sha256_file}")
+  rm -rf "${STREAM_PARTS_DIR}"
+  local target_size_gb
+  target_size_gb=$(awk "BEGIN {print int(((${target_size_bytes:-1}-1)/${BYTES_1GB}) + 1)}")
+  set +x
+  serialOutputPrefixedKeyValue "GCEExport" "${size_key}" "${target_size_gb}"
+  serialOutputPrefixedKeyValue "GCEExport" "${sha256_key}" "${sha256}"
+  set -x
+}
+
+# Converts $1 (a device or raw disk file) to format $2 with qemu-img, writing
+# it to $3. Returns non-zero after printing ExportFailed on error.
+function convert_disk() {
+  local source=$1
+  local format=$2
+  local output=$3
+  if [[ -z "${QEMU_IMG_DOCKER_IMAGE}" ]]; then
+    QEMU_IMG_DOCKER_IMAGE=$(curl -f -H Metadata-Flavor:Google ${ATTRIBUTES_URL}/qemu-img-docker-image)
+    echo "GCEExport: Pulling docker image ${QEMU_IMG_DOCKER_IMAGE}..."
+    if ! out=$(docker pull "${QEMU_IMG_DOCKER_IMAGE}" 2>&1); then
+      echo "ExportFailed: Failed to pull docker image [Privacy-> ${QEMU_IMG_DOCKER_IMAGE} <-Privacy]. Error: [Privacy-> ${out} <-Privacy]"
+      return 1
+    fi
+    echo "${out}"
   fi
-  echo "${out}"
 
-  echo "GCEExport: Running qemu-img convert..."
-  docker run --rm -v /tmp:/t -e HOME=/root -v /var/gs:/var/gs --device="${SOURCE_DEVICE}":"${SOURCE_DEVICE}" --privileged "${QEMU_IMG_DOCKER_IMAGE}" /qemu-img convert "${SOURCE_DEVICE}" "/var/gs/${IMAGE_OUTPUT_PATH}" -p -O "${FORMAT}" 2> >(tee /var/gs/qemu_err.txt >&2)
+  echo "GCEExport: Running qemu-img convert to ${format}..."
+  docker run --rm -v /tmp:/t -e HOME=/root -v /var/gs:/var/gs --device="${SOURCE_DEVICE}":"${SOURCE_DEVICE}" --privileged "${QEMU_IMG_DOCKER_IMAGE}" /qemu-img convert "${source}" "${output}" -p -O "${format}" 2> >(tee /var/gs/qemu_err.txt >&2)
   if [[ $? -ne 0 ]]; then
     echo "ExportFailed: Failed to export disk source to GCS [Privacy-> ${GS_PATH} <-Privacy] due to qemu-img error: [Privacy-> $(</var/gs/qemu_err.txt) <-Privacy]"
-    exit
+    return 1
   fi
-fi
+}
 
-# Exported image size info.
-TARGET_SIZE_BYTES=$(du -b "/var/gs/${IMAGE_OUTPUT_PATH}" | awk '{print $1}')
-TARGET_SIZE_GB=$(awk "BEGIN {print int(((${TARGET_SIZE_BYTES}-1)/${BYTES_1GB}) + 1)}")
-set +x
-serialOutputPrefixedKeyValue "GCEExport" "target-size-gb" "${TARGET_SIZE_GB}"
-set -x
+# Emits the size and SHA256 of $1 using the serial output keys $2 and $3, and
+# copies it to $4. Returns non-zero after printing ExportFailed on error.
+function upload_output() {
+  local output=$1
+  local size_key=$2
+  local sha256_key=$3
+  local gs_path=$4
+  # Exported image size info.
+  local target_size_bytes
+  target_size_bytes=$(du -b "${output}" | awk '{print $1}')
+  local target_size_gb
+  target_size_gb=$(awk "BEGIN {print int(((${target_size_bytes}-1)/${BYTES_1GB}) + 1)}")
+  local sha256
+  sha256=$(sha256sum "${output}" | cut -d' ' -f1)
+  set +x
+  serialOutputPrefixedKeyValue "GCEExport" "${size_key}" "${target_size_gb}"
+  serialOutputPrefixedKeyValue "GCEExport" "${sha256_key}" "${sha256}"
+  set -x
 
-echo "GCEExport: Copying output image to target GCS path..."
-docker run --rm -v /var/gs:/var/gs "${GCLOUD_CLI_IMAGE}" gcloud storage cp "/var/gs/${IMAGE_OUTPUT_PATH}" "${GS_PATH}" 2> >(tee /var/gs/gcloud_err.txt >&2)
-if [[ $? -ne 0 ]] ; then
-  echo "ExportFailed: Failed to copy output image to GCS [Privacy-> ${GS_PATH}, error: $(</var/gs/gcloud_err.txt) <-Privacy]"
-  exit
+  echo "GCEExport: Copying output image to target GCS path..."
+  docker run --rm -v /var/gs:/var/gs "${GCLOUD_CLI_IMAGE}" gcloud storage cp "${output}" "${gs_path}" 2> >(tee /var/gs/gcloud_err.txt >&2)
+  if [[ $? -ne 0 ]] ; then
+    echo "ExportFailed: Failed to copy output image to GCS [Privacy-> ${gs_path}, error: $(</var/gs/gcloud_err.txt) <-Privacy]"
+    return 1
+  fi
+}
+
+# With a single format, the output is written to gcs-path. With multiple
+# formats, gcs-path is a prefix, and each format is written to
+# <gcs-path>.<format>.
+function output_gs_path() {
+  if [[ ${#FORMATS[@]} -eq 1 ]]; then
+    echo "${GS_PATH}"
+  else
+    echo "${GS_PATH}.$1"
+  fi
+}
+
+# With a single format, the serial output key is $1. With multiple formats,
+# it's $1-<format>.
+function output_key() {
+  if [[ ${#FORMATS[@]} -eq 1 ]]; then
+    echo "$1"
+  else
+    echo "$1-$2"
+  fi
+}
+
+echo "GCEExport: Exporting disk of size ${SIZE_OUTPUT_GB}GB to formats ${FORMAT}."
+for format in "${STREAMED_FORMATS[@]}"; do
+  stream_disk "${SOURCE_DEVICE}" "${format}" "$(output_gs_path "${format}")" \
+    "$(output_key target-size-gb "${format}")" "$(output_key sha256 "${format}")" || exit
+done
+
+if [[ ${#BUFFERED_FORMATS[@]} -eq 1 ]]; then
+  format=${BUFFERED_FORMATS[0]}
+  gs_path=$(output_gs_path "${format}")
+  output="/var/gs/${gs_path##*//}"
+  convert_disk "${SOURCE_DEVICE}" "${format}" "${output}" || exit
+  upload_output "${output}" "$(output_key target-size-gb "${format}")" "$(output_key sha256 "${format}")" "${gs_path}" || exit
+  rm -f "${output}"
+elif [[ ${#BUFFERED_FORMATS[@]} -gt 1 ]]; then
+  # The disk is copied once to a raw file, which is converted to each format in
+  # turn. Each output is deleted after it's uploaded, so the buffer disk holds
+  # the raw file and one output at a time.
+  RAW_DISK_PATH="/var/gs/${OUTS_PATH}/disk.raw"
+  if ! out=$(dd if="${SOURCE_DEVICE}" of="${RAW_DISK_PATH}" bs=4M); then
+    echo "ExportFailed: Failed to copy disk source due to dd error: [Privacy-> ${out} <-Privacy]"
+    exit
+  fi
+  for format in "${BUFFERED_FORMATS[@]}"; do
+    gs_path=$(output_gs_path "${format}")
+    output="/var/gs/${gs_path##*//}"
+    convert_disk "${RAW_DISK_PATH}" "${format}" "${output}" || exit
+    upload_output "${output}" "$(output_key target-size-gb "${format}")" "$(output_key sha256 "${format}")" "${gs_path}" || exit
+    rm -f "${output}"
+  done
+  rm -f "${RAW_DISK_PATH}"
 fi
+
 # TODO(b/460360483): Change the success/failure signal as sometimes the last
 # lines are not printed. Use another signal - https://github.com/GoogleCloudPlatform/compute-daisy/blob/master/step_wait_for_instances_signal.go#L81.
 echo "export success"
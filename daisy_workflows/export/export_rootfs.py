@@ -0,0 +1,143 @@
+#!/usr/bin/env python3
+# Copyright 2026 Google Inc. All Rights Reserved.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+# http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+"""Archives the root filesystem of the attached disk to a tarball in GCS.
+
+The filesystems of the disk's operating system are mounted read-only, using
+the same logic as boot inspection, and archived with their extended
+attributes, POSIX ACLs and SELinux labels. The tarball is streamed to the
+`destination` metadata attribute, so it's never written to the worker's disk.
+
+Reports on the serial console:
+  source-size-gb: The size of the disk.
+  target-size-gb: The size of the tarball.
+  sha256: The SHA256 digest of the tarball.
+  rootfs-mounts: The base64-encoded JSON of the mount points that were
+    archived, mapped to their devices.
+"""
+
+import base64
+import hashlib
+import json
+import math
+import os
+import subprocess
+import sys
+import tempfile
+import threading
+
+from boot_inspect import inspection
+from boot_inspect.cli import wait_for_device
+import guestfs
+import utils
+
+_device = '/dev/sdb'
+_chunk_size = 4 * 1024 * 1024
+_bytes_1gb = 1024 * 1024 * 1024
+
+
+def _daisy_kv(key: str, value: str):
+  print("Status: <serial-output key:'{key}' value:'{value}'>".format(
+      key=key, value=value), flush=True)
+
+
+def _size_gb(size_bytes: int) -> int:
+  return max(1, math.ceil(size_bytes / _bytes_1gb))
+
+
+def mount_rootfs(g) -> dict:
+  """Mounts the filesystems of the disk's operating system, read-only.
+
+  Returns:
+    The mount points, mapped to their devices.
+  """
+  roots = g.inspect_os()
+  if len(roots) != 1:
+    raise RuntimeError(
+        'Expected one operating system on the disk, found %d.' % len(roots))
+  return inspection.mount_root(g, roots[0])
+
+
+def stream_rootfs(g, destination):
+  """Streams a tarball of the mounted filesystems to destination.
+
+  Returns:
+    The size and SHA256 digest of the tarball.
+  """
+  fifo = os.path.join(tempfile.mkdtemp(), 'rootfs.tar')
+  os.mkfifo(fifo)
+  upload = subprocess.Popen(['gsutil', '-q', 'cp', '-', destination],
+                            stdin=subprocess.PIPE)
+  digest = hashlib.sha256()
+  size = 0
+
+  def copy():
+    nonlocal size
+    with open(fifo, 'rb') as tar:
+      chunk = tar.read(_chunk_size)
+      while chunk:
+        digest.update(chunk)
+        size += len(chunk)
+        upload.stdin.write(chunk)
+        chunk = tar.read(_chunk_size)
+    upload.stdin.close()
+
+  copier = threading.Thread(target=copy)
+  copier.start()
+  try:
+    g.tar_out('/', fifo, numericowner=True, xattrs=True, selinux=True,
+              acls=True)
+  except RuntimeError:
+    # Unblock the copier when tar_out failed before opening the fifo.
+    try:
+      os.close(os.open(fifo, os.O_WRONLY | os.O_NONBLOCK))
+    except OSError:
+      pass
+    raise
+  finally:
+    copier.join()
+  if upload.wait() != 0:
+    raise RuntimeError('Failed to upload the tarball to %s.' % destination)
+  return size, digest.hexdigest()
+
+
+def main():
+  destination = utils.GetMetadataAttribute('destination',
+                                           raise_on_not_found=True)
+  wait_for_device(_device)
+
+  g = guestfs.GuestFS(python_return_dict=True)
+  g.add_drive_opts(_device, format='raw', readonly=1)
+  g.launch()
+  _daisy_kv('source-size-gb',
+            str(_size_gb(g.blockdev_getsize64('/dev/sda'))))
+
+  mount_points = mount_rootfs(g)
+  print('Status: Archiving %s.' % ', '.join(sorted(mount_points)), flush=True)
+  size, sha256 = stream_rootfs(g, destination)
+  g.umount_all()
+
+  _daisy_kv('target-size-gb', str(_size_gb(size)))
+  _daisy_kv('sha256', sha256)
+  _daisy_kv('rootfs-mounts', base64.standard_b64encode(
+      json.dumps(mount_points).encode()).decode())
+  print('Success: Exported the root filesystem.', flush=True)
+
+
+if __name__ == '__main__':
+  try:
+    main()
+  except Exception as e:
+    print('Failed: %s' % e, flush=True)
+    sys.exit(1)
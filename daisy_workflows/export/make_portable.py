@@ -0,0 +1,328 @@
+#!/usr/bin/env python3
+# Copyright 2026 Google Inc. All Rights Reserved.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+# http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+"""Prepare a Linux disk from GCE to boot on another hypervisor.
+
+The GCE guest environment is removed, GCE-specific cloud-init, network,
+and time configuration is replaced with generic configuration, and the
+guest tools and drivers of the target hypervisor are installed.
+
+The changes are reported to Daisy with the serial output key
+portable-changes, as base64-encoded JSON.
+
+Parameters (retrieved from instance metadata):
+
+portable_for: The hypervisor to prepare the disk for: vmware, kvm,
+              or hyperv.
+"""
+
+import base64
+import json
+import logging
+import re
+import time
+
+import utils
+import utils.diskutils as diskutils
+from utils.guestfsprocess import run
+
+# Packages of the GCE guest environment.
+_gce_packages = [
+    'google-guest-agent',
+    'google-osconfig-agent',
+    'google-compute-engine',
+    'google-compute-engine-oslogin',
+    'python-google-compute-engine',
+    'python3-google-compute-engine',
+    'gce-disk-expand',
+    'google-cloud-packages-archive-keyring',
+]
+
+# Package repositories added by the guest environment and by image import.
+_gce_repos = [
+    '/etc/apt/sources.list.d/google-cloud.list',
+    '/etc/yum.repos.d/google-cloud.repo',
+    '/etc/zypp/repos.d/google-compute-engine.repo',
+    '/etc/zypp/repos.d/google-cloud-sdk.repo',
+]
+
+# cloud-init configuration that only works on GCE. See the translate
+# scripts in daisy_workflows/image_import.
+_gce_cloud_init_configs = [
+    '/etc/cloud/cloud.cfg.d/91-gce.cfg',
+    '/etc/cloud/cloud.cfg.d/91-gce-system.cfg',
+    '/etc/cloud/cloud.cfg.d/91-google-cloud-sdk.cfg',
+    '/etc/cloud/cloud.cfg.d/99-disable-network-activation.cfg',
+]
+
+_cloud_init_config = '''
+# Written when the image was exported from Google Compute Engine.
+datasource_list: [ %s ]
+'''
+
+_netplan_config = '''
+# Written when the image was exported from Google Compute Engine.
+network:
+  version: 2
+  ethernets:
+    primary:
+      match:
+        name: "e*"
+      dhcp4: true
+'''
+
+_ntp_pool = 'pool pool.ntp.org iburst'
+
+# The guest tools, initramfs drivers, and cloud-init datasources
+# for each hypervisor, keyed by distro family.
+_targets = {
+    'vmware': {
+        'packages': {
+            'debian': ['open-vm-tools'],
+            'el': ['open-vm-tools'],
+            'suse': ['open-vm-tools'],
+        },
+        'drivers': ['vmw_pvscsi', 'vmxnet3'],
+        'datasources': ['VMware', 'OVF', 'NoCloud', 'None'],
+    },
+    'kvm': {
+        'packages': {
+            'debian': ['qemu-guest-agent'],
+            'el': ['qemu-guest-agent'],
+            'suse': ['qemu-guest-agent'],
+        },
+        'drivers': ['virtio_pci', 'virtio_blk', 'virtio_scsi', 'virtio_net'],
+        'datasources': ['NoCloud', 'ConfigDrive', 'OpenStack', 'None'],
+    },
+    'hyperv': {
+        'packages': {
+            'debian': ['hyperv-daemons'],
+            'el': ['hyperv-daemons'],
+            'suse': ['hyper-v'],
+        },
+        'drivers': ['hv_vmbus', 'hv_storvsc', 'hv_netvsc'],
+        'datasources': ['NoCloud', 'ConfigDrive', 'None'],
+    },
+}
+
+
+class Report:
+  """The changes made to the disk, and the steps left to the user."""
+
+  def __init__(self, target):
+    self.target = target
+    self.distro = ''
+    self.changes = []
+    self.notes = []
+
+  def change(self, message):
+    logging.info(message)
+    self.changes.append(message)
+
+  def note(self, message):
+    logging.info(message)
+    self.notes.append(message)
+
+  def send(self):
+    content = json.dumps({
+        'target': self.target,
+        'distro': self.distro,
+        'changes': self.changes,
+        'notes': self.notes,
+    })
+    logging.info("<serial-output key:'portable-changes' value:'%s'>",
+                 base64.b64encode(content.encode()).decode())
+
+
+def distro_family(distro):
+  if distro in ('debian', 'ubuntu'):
+    return 'debian'
+  if distro in ('rhel', 'centos', 'rocky', 'almalinux', 'oraclelinux',
+                'amazonlinux'):
+    return 'el'
+  if distro in ('sles', 'opensuse', 'suse'):
+    return 'suse'
+  raise RuntimeError('Preparing %s for another hypervisor is not '
+                     'supported.' % distro)
+
+
+def is_installed(g, family, package):
+  if family == 'debian':
+    p = run(g, ['dpkg-query', '--show', '--showformat=${Status}', package],
+            raiseOnError=False)
+    return p.code == 0 and 'install ok installed' in p.stdout
+  return run(g, ['rpm', '--query', package], raiseOnError=False).code == 0
+
+
+def remove_packages(g, family, packages):
+  if family == 'debian':
+    run(g, 'DEBIAN_FRONTEND=noninteractive apt-get purge -y '
+        + ' '.join(packages))
+  elif family == 'el':
+    run(g, ['yum', 'remove', '-y'] + packages)
+  else:
+    run(g, ['zypper', '--non-interactive', 'remove'] + packages)
+
+
+def install_packages(g, family, packages):
+  if family == 'debian':
+    utils.update_apt(g)
+    utils.install_apt_packages(g, *packages)
+  elif family == 'el':
+    run(g, ['yum', 'install', '-y'] + packages)
+  else:
+    run(g, ['zypper', '--non-interactive', 'install'] + packages)
+
+
+def remove_guest_environment(g, family, report):
+  installed = [p for p in _gce_packages if is_installed(g, family, p)]
+  if installed:
+    remove_packages(g, family, installed)
+    report.change('Removed the GCE guest environment packages: %s.'
+                  % ', '.join(installed))
+  # The repositories aren't owned by the packages, so they're left behind.
+  for repo in _gce_repos:
+    if g.exists(repo):
+      g.rm(repo)
+      report.change('Removed the GCE package repository %s.' % repo)
+
+
+def restore_cloud_init(g, target, report):
+  if not g.exists('/etc/cloud/cloud.cfg.d'):
+    return
+  for config in _gce_cloud_init_configs:
+    if g.exists(config):
+      g.rm(config)
+      report.change('Removed the GCE cloud-init configuration %s.' % config)
+  datasources = ', '.join(_targets[target]['datasources'])
+  g.write('/etc/cloud/cloud.cfg.d/90-portable.cfg',
+          _cloud_init_config % datasources)
+  report.change('Configured cloud-init to use the datasources %s.'
+                % datasources)
+  # Let cloud-init detect the new platform on first boot.
+  run(g, ['cloud-init', 'clean'], raiseOnError=False)
+
+
+def restore_network(g, report):
+  if g.exists('/etc/hosts'):
+    hosts = g.cat('/etc/hosts')
+    kept = [ln for ln in hosts.splitlines(True)
+            if 'metadata.google.internal' not in ln]
+    if len(kept) != len(hosts.splitlines(True)):
+      g.write('/etc/hosts', ''.join(kept))
+      report.change('Removed metadata.google.internal from /etc/hosts.')
+
+  # The interface is ens4 on GCE, and has a different name elsewhere.
+  netplan_configs = g.glob_expand('/etc/netplan/*.yaml')
+  for config in netplan_configs:
+    if re.search(r'\bens4\b|macaddress', g.cat(config)):
+      g.rm(config)
+      report.change('Removed the GCE network configuration %s.' % config)
+  if netplan_configs:
+    g.write('/etc/netplan/90-portable.yaml', _netplan_config)
+    g.chmod(0o600, '/etc/netplan/90-portable.yaml')
+    report.change('Configured netplan to use DHCP on the first Ethernet '
+                  'interface.')
+  if (g.exists('/etc/network/interfaces')
+      and re.search(r'\bens4\b', g.cat('/etc/network/interfaces'))):
+    report.note('/etc/network/interfaces configures ens4, the interface name '
+                'on GCE. Update it to the interface name on the new '
+                'hypervisor.')
+
+  for config in g.glob_expand('/etc/sysconfig/network-scripts/ifcfg-*'):
+    content = g.cat(config)
+    patched = re.sub(r'(?m)^(HWADDR|MTU)=.*\n', '', content)
+    if patched != content:
+      g.write(config, patched)
+      report.change('Removed the GCE MAC address and MTU from %s.' % config)
+
+  for config in ('/etc/chrony.conf', '/etc/chrony/chrony.conf',
+                 '/etc/ntp.conf'):
+    if not g.exists(config):
+      continue
+    content = g.cat(config)
+    patched = re.sub(r'(?m)^server\s+metadata\.google\.internal.*$',
+                     _ntp_pool, content, count=1)
+    patched = re.sub(r'(?m)^server\s+metadata\.google\.internal.*\n', '',
+                     patched)
+    if patched != content:
+      g.write(config, patched)
+      report.change('Replaced the GCE time server with %s in %s.'
+                    % (_ntp_pool, config))
+
+
+def add_drivers(g, family, target, report):
+  drivers = _targets[target]['drivers']
+  if family == 'debian':
+    modules = g.cat('/etc/initramfs-tools/modules')
+    missing = [d for d in drivers if not re.search(r'(?m)^%s\b' % d, modules)]
+    if missing:
+      if not modules.endswith('\n'):
+        modules += '\n'
+      g.write('/etc/initramfs-tools/modules',
+              modules + ''.join(d + '\n' for d in missing))
+    run(g, ['update-initramfs', '-u', '-k', 'all'])
+  else:
+    g.write('/etc/dracut.conf.d/90-portable.conf',
+            'add_drivers+=" %s "\n' % ' '.join(drivers))
+    utils.RebuildInitramfs(g)
+  report.change('Added the %s drivers to the initramfs: %s.'
+                % (target, ', '.join(drivers)))
+
+
+def install_guest_tools(g, family, target, report):
+  packages = _targets[target]['packages'][family]
+  install_packages(g, family, packages)
+  report.change('Installed %s.' % ', '.join(packages))
+
+
+def get_input_disks():
+  """Waits for the disk to prepare to be attached, and returns its path in a list."""
+  attached_disks = []
+  for i in range(4):
+    time.sleep(i * 10)
+    attached_disks = diskutils.get_physical_drives()
+    # attached_disks include the worker disk
+    if len(attached_disks) == 2:
+      break
+  else:
+    raise RuntimeError('Expected one disk to prepare, found: %s' %
+                       ', '.join(attached_disks[1:]))
+
+  # remove the boot disk of the worker instance
+  attached_disks.remove('/dev/sda')
+  return attached_disks
+
+
+def main():
+  target = utils.GetMetadataAttribute('portable_for', raise_on_not_found=True)
+  if target not in _targets:
+    raise RuntimeError('Unsupported hypervisor %s.' % target)
+  report = Report(target)
+
+  g = diskutils.MountDisks(get_input_disks())
+  report.distro = g.gcp_image_distro
+  family = distro_family(g.gcp_image_distro)
+  remove_guest_environment(g, family, report)
+  restore_cloud_init(g, target, report)
+  restore_network(g, report)
+  install_guest_tools(g, family, target, report)
+  add_drivers(g, family, target, report)
+  diskutils.UnmountDisk(g)
+  report.send()
+
+
+if __name__ == '__main__':
+  utils.RunTranslate(main)
@@ -17,11 +17,12 @@
 
 Parameters (retrieved from instance metadata):
 
-debian_release: The version of the distro (stretch)
+debian_release: The codename of the distro (eg: bookworm)
 install_gce_packages: True if GCE agent and SDK should be installed
 """
 
 import logging
+import os
 
 import utils
 import utils.diskutils as diskutils
@@ -33,6 +34,19 @@ deb http://packages.cloud.google.com/apt google-compute-engine-{deb_release}-sta
 deb http://packages.cloud.google.com/apt google-cloud-packages-archive-keyring-{deb_release} main
 '''  # noqa: E501
 
+# Starting at Debian 12, apt-key is deprecated, so the Google Cloud key is
+# added to a keyring that's referenced by the repos. The Cloud SDK repo isn't
+# published for each release.
+google_cloud_keyring = '/etc/apt/keyrings/google-cloud.gpg'
+google_cloud_signed = '''
+deb [signed-by={keyring}] http://packages.cloud.google.com/apt cloud-sdk main
+deb [signed-by={keyring}] http://packages.cloud.google.com/apt google-compute-engine-{deb_release}-stable main
+deb [signed-by={keyring}] http://packages.cloud.google.com/apt google-cloud-packages-archive-keyring-{deb_release} main
+'''  # noqa: E501
+
+# Releases that add the Google Cloud key using apt-key.
+apt_key_releases = {'jessie', 'stretch', 'buster', 'bullseye'}
+
 interfaces = '''
 source-directory /etc/network/interfaces.d
 auto lo
@@ -70,11 +84,17 @@ def DistroSpecific(g):
         logging.debug('Installing wget')
         run(g, ['apt-get', 'install', '-y', 'wget'])
         run(g, cmd)
-    run(g, ['apt-key', 'add', '/tmp/gce_key'])
+    if deb_release in apt_key_releases:
+      run(g, ['apt-key', 'add', '/tmp/gce_key'])
+      sources = google_cloud.format(deb_release=deb_release)
+    else:
+      g.mkdir_p(os.path.dirname(google_cloud_keyring))
+      run(g, ['gpg', '--batch', '--yes', '--dearmor',
+              '-o', google_cloud_keyring, '/tmp/gce_key'])
+      sources = google_cloud_signed.format(deb_release=deb_release,
+                                           keyring=google_cloud_keyring)
     g.rm('/tmp/gce_key')
-    g.write(
-        '/etc/apt/sources.list.d/google-cloud.list',
-        google_cloud.format(deb_release=deb_release))
+    g.write('/etc/apt/sources.list.d/google-cloud.list', sources)
     # Remove Azure agent.
     try:
       run(g, ['apt-get', 'remove', '-y', '-f', 'waagent', 'walinuxagent'])
@@ -95,8 +115,11 @@ def DistroSpecific(g):
                'python3-google-compute-engine']
       logging.info('Skipping installation of OS Config agent. '
                    'Requires Debian 9 or newer.')
-    else:
+    elif deb_release in apt_key_releases:
       pkgs += ['google-cloud-sdk', 'google-osconfig-agent']
+    else:
+      # The Cloud SDK is packaged as google-cloud-cli in the cloud-sdk repo.
+      pkgs += ['google-cloud-cli', 'google-osconfig-agent']
     utils.install_apt_packages(g, *pkgs)
 
   # Update grub config to log to console.
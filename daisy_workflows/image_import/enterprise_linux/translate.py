@@ -17,7 +17,8 @@
 
 Parameters (retrieved from instance metadata):
 
-el_release: The version of the distro (6 or 7)
+el_release: The major version of the distro (6 to 10), or 2023 for
+  Amazon Linux 2023
 install_gce_packages: True if GCE agent and SDK should be installed
 use_rhel_gce_license: True if GCE RHUI package should be installed
 """
@@ -80,6 +81,45 @@ GRUB_CMDLINE_LINUX="crashkernel=auto console=ttyS0,38400n8 elevator=noop"
 GRUB_DISABLE_RECOVERY="true"
 '''
 
+# EL10 kernels don't support `crashkernel=auto` or `elevator`, and the kernel
+# command line is stored in the boot loader entries.
+grub2_cfg_el10 = '''
+GRUB_TIMEOUT=0
+GRUB_DISTRIBUTOR="$(sed 's, release .*$,,g' /etc/system-release)"
+GRUB_DEFAULT=saved
+GRUB_DISABLE_SUBMENU=true
+GRUB_TERMINAL="serial console"
+GRUB_SERIAL_COMMAND="serial --speed=38400"
+GRUB_CMDLINE_LINUX="console=ttyS0,38400n8"
+GRUB_DISABLE_RECOVERY="true"
+GRUB_ENABLE_BLSCFG=true
+'''
+
+# EL10's NetworkManager doesn't read ifcfg files.
+nmconnection_eth0 = '''
+[connection]
+id=System eth0
+type=ethernet
+interface-name=eth0
+autoconnect=true
+
+[ethernet]
+mtu=1460
+
+[ipv4]
+method=auto
+dhcp-hostname=localhost
+may-fail=true
+
+[ipv6]
+method=ignore
+'''
+
+# The Cloud SDK's EL9 repo is used for releases that don't have their own.
+sdk_repo_releases = {
+    '10': '9',
+}
+
 grub_cfg = '''
 default=0
 timeout=0
@@ -88,9 +128,40 @@ terminal --timeout=0 serial console
 '''
 
 
+# Amazon Linux configures cloud-init to use the EC2 datasource.
+cloud_init_gce = '''
+datasource_list: [ GCE ]
+'''
+
+# Amazon Linux 2023 uses systemd-networkd. Its EC2 network utilities, which
+# read the EC2 metadata API, are replaced with DHCP on the primary interface.
+networkd_amazon_2023 = '''
+[Match]
+Name=eth0 en*
+
+[Link]
+MTUBytes=1460
+
+[Network]
+DHCP=ipv4
+'''
+
+
 class Distro(Enum):
   CENTOS = 1
   RHEL = 2
+  ALMALINUX = 3
+  ORACLE = 4
+  AMAZON = 5
+
+
+# Maps the `ID` field of /etc/os-release to a Distro. Distros that aren't
+# listed are translated as CentOS, unless they're RHEL.
+os_release_ids = {
+    'almalinux': Distro.ALMALINUX,
+    'ol': Distro.ORACLE,
+    'amzn': Distro.AMAZON,
+}
 
 
 class TranslateSpec:
@@ -191,7 +262,15 @@ def check_rhel_license(spec: TranslateSpec) -> str:
 
 def reset_network_for_dhcp(spec: TranslateSpec):
   logging.info('Resetting network to DHCP for eth0.')
-  spec.g.write('/etc/sysconfig/network-scripts/ifcfg-eth0', ifcfg_eth0)
+  if int(spec.el_release) >= 10:
+    connection = '/etc/NetworkManager/system-connections/eth0.nmconnection'
+    spec.g.mkdir_p('/etc/NetworkManager/system-connections')
+    spec.g.write(connection, nmconnection_eth0)
+    # NetworkManager ignores keyfiles that other users can read.
+    spec.g.chmod(0o600, connection)
+    spec.g.rm_f('/etc/sysconfig/network-scripts/ifcfg-eth0')
+  else:
+    spec.g.write('/etc/sysconfig/network-scripts/ifcfg-eth0', ifcfg_eth0)
   # Remove NetworkManager-config-server if it's present. The package configures
   # NetworkManager to *not* use DHCP.
   #  https://access.redhat.com/solutions/894763
@@ -211,10 +290,48 @@ checks = [
 ]
 
 
+def translate_amazon_2023(spec: TranslateSpec):
+  """Translates Amazon Linux 2023, which is based on Fedora rather than EL.
+
+  Google doesn't publish guest environment packages for Amazon Linux 2023,
+  so cloud-init from the distro's repos is configured to use the GCE
+  datasource, which provides SSH keys and the hostname.
+  """
+  g = spec.g
+  utils.common.ClearEtcResolv(g)
+
+  if spec.install_gce:
+    logging.info('Installing cloud-init from the Amazon Linux repos.')
+    yum_install(spec, 'cloud-init')
+  if g.exists('/etc/cloud/cloud.cfg.d'):
+    logging.info('Configuring cloud-init to use the GCE datasource.')
+    run(g, 'rm -f /etc/cloud/cloud.cfg.d/*amazon* '
+           '/etc/cloud/cloud.cfg.d/*ec2*')
+    g.write('/etc/cloud/cloud.cfg.d/91-gce.cfg', cloud_init_gce)
+  logging.warning('Google guest environment packages are not available for '
+                  'Amazon Linux 2023, and were not installed.')
+
+  utils.RebuildInitramfs(g)
+
+  logging.info('Update grub configuration')
+  run(g, ['grubby', '--update-kernel=ALL',
+          '--remove-args=console', '--args=console=ttyS0,38400n8'])
+
+  logging.info('Resetting network to DHCP.')
+  if package_is_installed(spec, 'amazon-ec2-net-utils'):
+    run(g, ['yum', 'remove', '-y', 'amazon-ec2-net-utils'])
+  g.mkdir_p('/etc/systemd/network')
+  g.write('/etc/systemd/network/80-gce.network', networkd_amazon_2023)
+
+
 def DistroSpecific(spec: TranslateSpec):
   g = spec.g
   el_release = spec.el_release
 
+  if spec.distro == Distro.AMAZON and el_release == '2023':
+    translate_amazon_2023(spec)
+    return
+
   utils.common.ClearEtcResolv(g)
 
   # Some imported images haven't contained `/etc/yum.repos.d`.
@@ -228,6 +345,11 @@ def DistroSpecific(spec: TranslateSpec):
       g.write('/etc/yum.repos.d/google-cloud.repo', repo_compute % el_release)
       yum_install(spec, 'google-rhui-client-rhel' + el_release)
 
+  if spec.distro == Distro.AMAZON and g.exists('/etc/cloud/cloud.cfg.d'):
+    logging.info('Configuring cloud-init to use the GCE datasource.')
+    run(g, 'rm -f /etc/cloud/cloud.cfg.d/*amazon* /etc/cloud/cloud.cfg.d/*ec2*')
+    g.write('/etc/cloud/cloud.cfg.d/91-gce.cfg', cloud_init_gce)
+
   if spec.install_gce:
     logging.info('Installing GCE packages.')
 
@@ -288,7 +410,8 @@ def DistroSpecific(spec: TranslateSpec):
         run(g, 'chkconfig rsyslog on')
     else:
       g.write_append(
-          '/etc/yum.repos.d/google-cloud.repo', repo_sdk % el_release)
+          '/etc/yum.repos.d/google-cloud.repo',
+          repo_sdk % sdk_repo_releases.get(el_release, el_release))
       yum_install(spec, 'google-cloud-sdk')
     yum_install(spec, 'google-compute-engine', 'google-osconfig-agent')
 
@@ -305,6 +428,10 @@ def DistroSpecific(spec: TranslateSpec):
         r'sed -i "/^[\t ]*kernel/s/$/ console=ttyS0,38400n8/" '
         r'/tmp/grub_gce_generated;'
         r'mv /tmp/grub_gce_generated /boot/grub/grub.conf')
+  elif int(el_release) >= 10:
+    g.write('/etc/default/grub', grub2_cfg_el10)
+    run(g, ['grub2-mkconfig', '--update-bls-cmdline',
+            '-o', '/boot/grub2/grub.cfg'])
   else:
     g.write('/etc/default/grub', grub2_cfg)
     run(g, ['grub2-mkconfig', '-o', '/boot/grub2/grub.cfg'])
@@ -354,12 +481,21 @@ def yum_install(spec, *packages):
       ', '.join(packages), p))
 
 
-def run_translate(g: guestfs.GuestFS):
+def detect_distro(g: guestfs.GuestFS) -> Distro:
+  if g.exists('/etc/os-release'):
+    for line in g.cat('/etc/os-release').splitlines():
+      if line.startswith('ID='):
+        os_release_id = line[len('ID='):].strip('"\'')
+        if os_release_id in os_release_ids:
+          return os_release_ids[os_release_id]
   if (g.exists('/etc/redhat-release')
       and 'Red Hat' in g.cat('/etc/redhat-release')):
-    distro = Distro.RHEL
-  else:
-    distro = Distro.CENTOS
+    return Distro.RHEL
+  return Distro.CENTOS
+
+
+def run_translate(g: guestfs.GuestFS):
+  distro = detect_distro(g)
 
   use_rhel_gce_license = utils.GetMetadataAttribute('use_rhel_gce_license')
   el_release = utils.GetMetadataAttribute('el_release')
@@ -18,11 +18,13 @@
 Parameters (retrieved from instance metadata):
 
 install_gce_packages: True if GCE agent and SDK should be installed
+freebsd_release: The major version of FreeBSD, such as 14. Optional.
 """
 
 import glob
 import logging
 import os
+import re
 import subprocess
 
 import utils
@@ -56,8 +58,20 @@ google_network_setup_enable=YES
 '''
 
 
+def GCEPackage(freebsd_release):
+  """Returns the package containing the GCE guest environment.
+
+  The py27 flavor was removed from ports, so newer releases install
+  the port's default flavor by referencing its origin.
+  """
+  if freebsd_release and int(freebsd_release) >= 13:
+    return 'sysutils/py-google-compute-engine'
+  return 'py27-google-compute-engine'
+
+
 def DistroSpecific(c):
   install_gce = utils.GetMetadataAttribute('install_gce_packages')
+  freebsd_release = utils.GetMetadataAttribute('freebsd_release')
 
   def UpdateConfigs(config, filename):
     """
@@ -87,7 +101,7 @@ def DistroSpecific(c):
     c.sh('ASSUME_ALWAYS_YES=yes pkg update')
 
     logging.info('Installing GCE packages.')
-    c.sh('pkg install --yes py27-google-compute-engine google-cloud-sdk')
+    c.sh('pkg install --yes %s google-cloud-sdk' % GCEPackage(freebsd_release))
 
     # Activate google services
     UpdateConfigs(google_services, '/etc/rc.conf')
@@ -110,6 +124,7 @@ class Chroot(object):
   """
   def __init__(self, device):
     self.mount_point = '/translate'
+    self.zfs_pool = 'translate_root'
     os.mkdir(self.mount_point)
 
     def FindAndMountRootPartition():
@@ -136,7 +151,32 @@ class Chroot(object):
       # Too bad. Didn't find one
       return False
 
-    if not FindAndMountRootPartition():
+    def ImportZFSRoot():
+      """
+      Import the ZFS pool on @device, using a temporary name to avoid
+      conflicts with the translator's pool, and mount its boot environment
+      onto @self.mount_point. Return false if a pool isn't found.
+      """
+      for part in glob.glob(device + 'p*'):
+        label = self.output('zdb -l %s' % part, check=False)
+        match = re.search(r'pool_guid: (\d+)', label)
+        if not match:
+          continue
+        self.sh('zpool import -f -N -R %s -t %s %s' % (
+            self.mount_point, match.group(1), self.zfs_pool))
+        bootfs = self.output(
+            'zpool get -H -o value bootfs %s' % self.zfs_pool).strip()
+        if '/' in bootfs:
+          # Use the temporary pool name.
+          bootfs = self.zfs_pool + bootfs[bootfs.index('/'):]
+        else:
+          bootfs = '%s/ROOT/default' % self.zfs_pool
+        self.sh('zfs mount %s' % bootfs)
+        self.sh('zfs mount -a')
+        return True
+      return False
+
+    if not FindAndMountRootPartition() and not ImportZFSRoot():
       raise Exception("No root partition found on disk %s" % device)
 
     # copy resolv.conf for using internet connection before chroot
@@ -152,6 +192,13 @@ class Chroot(object):
     if returncode != 0:
       raise subprocess.CalledProcessError(returncode, cmd)
 
+  def output(self, cmd, check=True):
+    p = subprocess.run(cmd, shell=True, stdout=subprocess.PIPE,
+                       universal_newlines=True)
+    if check and p.returncode != 0:
+      raise subprocess.CalledProcessError(p.returncode, cmd)
+    return p.stdout
+
   def write_append(self, content, filename):
     with open(filename, 'a') as dst:
       dst.write(content)
@@ -142,6 +142,12 @@ def main():
     results.bios_bootable = boot_results.bios_bootable
     results.uefi_bootable = boot_results.uefi_bootable
     results.root_fs = boot_results.root_fs
+    # Windows installations don't expose their boot mode through the
+    # filesystem, so fall back to what the partition table reports.
+    for installation in results.os_installations:
+      if not (installation.bios_bootable or installation.uefi_bootable):
+        installation.bios_bootable = boot_results.bios_bootable
+        installation.uefi_bootable = boot_results.uefi_bootable
   except BaseException as e:
     print('Failed to inspect boot loader: ', e)
     results.ErrorWhen = \
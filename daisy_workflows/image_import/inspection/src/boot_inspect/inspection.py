@@ -33,11 +33,12 @@ import os
 import re
 import sys
 
-from boot_inspect.inspectors.os import architecture, linux, windows
+from boot_inspect.inspectors.os import architecture, freebsd, linux, windows
 import boot_inspect.system.filesystems
 from compute_image_tools_proto import inspect_pb2
 
 _LINUX = [
+    linux.Fingerprint(inspect_pb2.Distro.ALMALINUX),
     linux.Fingerprint(inspect_pb2.Distro.AMAZON,
                       aliases=['amzn', 'amazonlinux']),
     linux.Fingerprint(
@@ -46,6 +47,7 @@ _LINUX = [
             require={'/etc/centos-release', '/etc/os-release'},
             disallow={'/etc/fedora-release',
                       '/etc/rocky-release',
+                      '/etc/almalinux-release',
                       '/etc/oracle-release'}),
         version_reader=linux.VersionReader(
             metadata_file='/etc/centos-release',
@@ -57,6 +59,7 @@ _LINUX = [
             require={'/etc/centos-release'},
             disallow={'/etc/fedora-release',
                       '/etc/rocky-release',
+                      '/etc/almalinux-release',
                       '/etc/oracle-release'}),
         version_reader=linux.VersionReader(
             metadata_file='/etc/centos-release',
@@ -77,6 +80,7 @@ _LINUX = [
             require={'/etc/redhat-release'},
             disallow={'/etc/fedora-release',
                       '/etc/rocky-release',
+                      '/etc/almalinux-release',
                       '/etc/oracle-release',
                       '/etc/centos-release'}),
         version_reader=linux.VersionReader(
@@ -108,6 +112,15 @@ _LINUX = [
 ]
 
 
+# Directories holding GRUB's BIOS modules. When present, the
+# installation's bootloader was installed for BIOS.
+_bios_grub_directories = ['/boot/grub/i386-pc', '/boot/grub2/i386-pc']
+
+# Mount points of the EFI system partition. When the installation mounts
+# one, its bootloader was installed for UEFI.
+_efi_mount_points = ['/boot/efi', '/efi']
+
+
 def inspect_device(g) -> inspect_pb2.InspectionResults:
   """Finds boot-related properties for a device using offline inspection.
 
@@ -124,30 +137,87 @@ def inspect_device(g) -> inspect_pb2.InspectionResults:
 
   roots = g.inspect_os()
   if len(roots) == 0:
+    # FreeBSD systems with a ZFS root aren't found by guestfs.
+    operating_system = freebsd.inspect_zfs_root(g, '/dev/sda')
     return inspect_pb2.InspectionResults(
-        os_count=len(roots)
-
+        os_release=operating_system,
+        os_count=1 if operating_system else 0,
     )
-  root = roots[0]
+  installations = []
+  for root in sorted(roots):
+    installation = _inspect_root(g, root)
+    if installation:
+      installations.append(installation)
+
+  results = inspect_pb2.InspectionResults(
+      os_count=len(installations),
+      os_installations=installations,
+  )
+  # os_release is only populated when the choice of OS is unambiguous.
+  if len(installations) == 1:
+    results.os_release.CopyFrom(installations[0].os_release)
+  return results
+
+
+def mount_root(g, root) -> dict:
+  """Mounts the filesystems of the operating system on `root`, read-only.
+
+  Filesystems are mounted in order of their mount point's length, so that
+  /boot is mounted after /. Filesystems that can't be mounted are skipped.
+
+  Args:
+    g (guestfs.GuestFS): A launched GuestFS instance with nothing mounted.
+    root: The device holding the root filesystem, as returned by
+      `g.inspect_os()`.
+
+  Returns:
+    The installation's mount points, as returned by
+    `g.inspect_get_mountpoints(root)`.
+  """
   mount_points = g.inspect_get_mountpoints(root)
   for dev, mp in sorted(mount_points.items(), key=lambda k: len(k[0])):
     try:
       g.mount_ro(mp, dev)
     except RuntimeError as msg:
-      print('%s (ignored)' % msg, file=sys.stderr)
-  fs = boot_inspect.system.filesystems.GuestFSFilesystem(g)
-  operating_system = linux.Inspector(fs, _LINUX).inspect()
-  if not operating_system:
-    operating_system = windows.Inspector(g, root).inspect()
-  if operating_system:
-    operating_system.architecture = architecture.Inspector(g, root).inspect()
+      try:
+        # FreeBSD's UFS requires the ufstype option.
+        g.mount_vfs('ro,ufstype=ufs2', 'ufs', dev, mp)
+      except RuntimeError:
+        print('%s (ignored)' % msg, file=sys.stderr)
+  return mount_points
 
-  g.umount_all()
 
-  return inspect_pb2.InspectionResults(
-      os_release=operating_system,
-      os_count=1 if operating_system else 0,
-  )
+def _inspect_root(g, root) -> inspect_pb2.OsInstallation:
+  """Inspects the operating system whose root filesystem is on `root`.
+
+  Args:
+    g (guestfs.GuestFS): A launched GuestFS instance with nothing mounted.
+    root: The device holding the root filesystem, as returned by
+      `g.inspect_os()`.
+
+  Returns:
+    The installation, or None if no operating system was recognized.
+  """
+  mount_points = mount_root(g, root)
+  try:
+    fs = boot_inspect.system.filesystems.GuestFSFilesystem(g)
+    operating_system = linux.Inspector(fs, _LINUX).inspect()
+    if not operating_system:
+      operating_system = freebsd.Inspector(fs).inspect()
+    if not operating_system:
+      operating_system = windows.Inspector(g, root).inspect()
+    if not operating_system:
+      return None
+    operating_system.architecture = architecture.Inspector(g, root).inspect()
+    return inspect_pb2.OsInstallation(
+        root_partition=root,
+        os_release=operating_system,
+        bios_bootable=any(
+            fs.is_directory(d) for d in _bios_grub_directories),
+        uefi_bootable=any(mp in mount_points for mp in _efi_mount_points),
+    )
+  finally:
+    g.umount_all()
 
 
 def inspect_boot_loader(g, device) -> inspect_pb2.InspectionResults:
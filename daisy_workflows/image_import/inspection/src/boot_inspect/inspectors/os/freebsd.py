@@ -0,0 +1,73 @@
+#!/usr/bin/env python3
+# Copyright 2026 Google Inc. All Rights Reserved.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+# http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+import re
+
+import boot_inspect.system.filesystems
+from compute_image_tools_proto import inspect_pb2
+
+# freebsd-version(1) is a shell script that embeds the userland version.
+# Example: USERLAND_VERSION="14.1-RELEASE-p5"
+_version_file = '/bin/freebsd-version'
+_version_pattern = re.compile(r'USERLAND_VERSION="(\d+)\.(\d+)')
+
+# GPT partition type of a FreeBSD ZFS pool member.
+_zfs_partition_type = '516E7CBA-6ECF-11D6-8FF8-00022D09712B'
+
+
+class Inspector:
+
+  def __init__(self, fs: boot_inspect.system.filesystems.Filesystem):
+    """Supports inspecting offline FreeBSD systems with a mounted UFS root.
+
+    Args:
+      fs: Filesystem of the mounted root.
+    """
+    self._fs = fs
+
+  def inspect(self) -> inspect_pb2.OsRelease:
+    if not self._fs.is_file(_version_file):
+      return None
+    match = _version_pattern.search(self._fs.read_utf8(_version_file))
+    if not match:
+      return inspect_pb2.OsRelease(distro_id=inspect_pb2.Distro.FREEBSD)
+    return inspect_pb2.OsRelease(
+        major_version=match.group(1),
+        minor_version=match.group(2),
+        distro_id=inspect_pb2.Distro.FREEBSD,
+    )
+
+
+def inspect_zfs_root(g, device: str) -> inspect_pb2.OsRelease:
+  """Detects FreeBSD on a disk whose root is a ZFS pool.
+
+  guestfs can't mount ZFS, so the version isn't available; users
+  are required to specify it using the `-os` flag.
+
+  Args:
+    g (guestfs.GuestFS): A launched, but unmounted, GuestFS instance.
+    device: The block device to inspect, such as /dev/sda.
+  """
+  try:
+    parts = g.part_list(device)
+  except RuntimeError:
+    return None
+  for part in parts:
+    try:
+      guid = g.part_get_gpt_type(device, part['part_num'])
+    except RuntimeError:
+      continue
+    if guid.upper() == _zfs_partition_type:
+      return inspect_pb2.OsRelease(distro_id=inspect_pb2.Distro.FREEBSD)
+  return None
@@ -30,8 +30,9 @@ _server_versions = {
     (6, 2): ('2012', ''),
     (6, 3): ('2012', 'r2'),
     # (10,0) is resolved in code since, since it's used for
-    # Windows 2016, Windows 2019 and Windows 2022.
+    # Windows 2016, Windows 2019, Windows 2022 and Windows 2025.
 }
+_nt10_server_versions = ['2016', '2019', '2022', '2025']
 _client_versions = {
     (6, 0): ('Vista', ''),
     (6, 1): ('7', ''),
@@ -60,15 +61,34 @@ class Inspector:
           major_nt=self._g.inspect_get_major_version(self._root),
           minor_nt=self._g.inspect_get_minor_version(self._root),
           variant=self._g.inspect_get_product_variant(self._root),
-          product_name=self._g.inspect_get_product_name(self._root)
+          product_name=self._g.inspect_get_product_name(self._root),
+          build_number=self._get_build_number(),
       )
 
+  def _get_build_number(self) -> int:
+    """Returns the Windows build number, or zero if it's not available.
+
+    inspect_get_build_id was added in libguestfs 1.49.
+    """
+    try:
+      return int(self._g.inspect_get_build_id(self._root))
+    except (AttributeError, RuntimeError, ValueError):
+      return 0
+
 
 def _from_nt_version(
     variant: str,
     major_nt: int,
     minor_nt: int,
-    product_name: str) -> inspect_pb2.OsRelease:
+    product_name: str,
+    build_number: int = 0) -> inspect_pb2.OsRelease:
+  """Maps an NT version to a marketing version.
+
+  Releases that share NT 10.0 are told apart using the product name. The
+  build number is returned to the caller, which uses it to distinguish
+  Windows 11 from Windows 10, and as a fallback for servers whose
+  product name doesn't include a version.
+  """
   major, minor = None, None
   nt_version = major_nt, minor_nt
   if _client_pattern.search(variant):
@@ -77,16 +97,16 @@ def _from_nt_version(
     if nt_version in _server_versions:
       major, minor = _server_versions.get(nt_version, (None, None))
     elif nt_version == (10, 0):
-      if '2016' in product_name:
-        major, minor = '2016', ''
-      elif '2019' in product_name:
-        major, minor = '2019', ''
-      elif '2022' in product_name:
-        major, minor = '2022', ''
+      for version in _nt10_server_versions:
+        if version in product_name:
+          major, minor = version, ''
+      if major is None and build_number > 0:
+        major, minor = _nt10_server_versions[0], ''
 
   if major is not None and minor is not None:
     return inspect_pb2.OsRelease(
         major_version=major,
         minor_version=minor,
         distro_id=inspect_pb2.Distro.WINDOWS,
+        build_number=build_number,
     )
@@ -0,0 +1,56 @@
+import unittest
+
+from boot_inspect.inspectors.os import freebsd
+from boot_inspect.system import filesystems
+from compute_image_tools_proto import inspect_pb2
+
+_freebsd_version = '''#!/bin/sh
+USERLAND_VERSION="14.1-RELEASE-p5"
+'''
+
+
+class TestInspector(unittest.TestCase):
+
+  def test_reads_userland_version(self):
+    fs = filesystems.DictBackedFilesystem(
+        {'/bin/freebsd-version': _freebsd_version})
+    assert freebsd.Inspector(fs).inspect() == inspect_pb2.OsRelease(
+        major_version='14',
+        minor_version='1',
+        distro_id=inspect_pb2.Distro.FREEBSD,
+    )
+
+  def test_omits_version_when_not_found(self):
+    fs = filesystems.DictBackedFilesystem({'/bin/freebsd-version': '#!/bin/sh'})
+    assert freebsd.Inspector(fs).inspect() == inspect_pb2.OsRelease(
+        distro_id=inspect_pb2.Distro.FREEBSD)
+
+  def test_returns_none_when_not_freebsd(self):
+    fs = filesystems.DictBackedFilesystem({'/etc/os-release': 'ID=debian'})
+    assert freebsd.Inspector(fs).inspect() is None
+
+
+class FakeGuestFS:
+
+  def __init__(self, gpt_types):
+    self._gpt_types = gpt_types
+
+  def part_list(self, device):
+    return [{'part_num': i + 1} for i in range(len(self._gpt_types))]
+
+  def part_get_gpt_type(self, device, part_num):
+    return self._gpt_types[part_num - 1]
+
+
+class TestInspectZFSRoot(unittest.TestCase):
+
+  def test_detects_zfs_partition(self):
+    g = FakeGuestFS(['83BD6B9D-7F41-11DC-BE0B-001560B84F0F',
+                     '516E7CB5-6ECF-11D6-8FF8-00022D09712B',
+                     '516e7cba-6ecf-11d6-8ff8-00022d09712b'])
+    assert freebsd.inspect_zfs_root(g, '/dev/sda') == inspect_pb2.OsRelease(
+        distro_id=inspect_pb2.Distro.FREEBSD)
+
+  def test_returns_none_without_zfs_partition(self):
+    g = FakeGuestFS(['0FC63DAF-8483-4772-8E79-3D69D8477DE4'])
+    assert freebsd.inspect_zfs_root(g, '/dev/sda') is None
@@ -31,6 +31,14 @@ class TestNTMapping_Desktop(unittest.TestCase):
         variant='Client', major_nt=10, minor_nt=0,
         product_name='Windows 10 Enterprise') == windows('10')
 
+  def test_11_reports_build(self):
+    # Windows 11 reports NT 10.0, and its product name is often
+    # 'Windows 10'. The caller disambiguates using the build number.
+    assert _from_nt_version(
+        variant='Client', major_nt=10, minor_nt=0,
+        product_name='Windows 10 Pro',
+        build_number=22631) == windows('10', build_number=22631)
+
 
 class TestNTMapping_Server(unittest.TestCase):
 
@@ -64,6 +72,23 @@ class TestNTMapping_Server(unittest.TestCase):
         product_name='Windows Server 2019 Datacenter', variant='Server',
         major_nt=10, minor_nt=0) == windows('2019')
 
+  def test_2022(self):
+    assert _from_nt_version(
+        product_name='Windows Server 2022 Datacenter', variant='Server',
+        major_nt=10, minor_nt=0) == windows('2022')
+
+  def test_2025(self):
+    assert _from_nt_version(
+        product_name='Windows Server 2025 Datacenter', variant='Server',
+        major_nt=10, minor_nt=0,
+        build_number=26100) == windows('2025', build_number=26100)
+
+  def test_nt10_without_version_in_product_name_uses_build(self):
+    assert _from_nt_version(
+        product_name='Windows Server', variant='Server',
+        major_nt=10, minor_nt=0,
+        build_number=26100) == windows('2016', build_number=26100)
+
 
 class TestNTMapping_Unmatched(unittest.TestCase):
 
@@ -83,9 +108,10 @@ class TestNTMapping_Unmatched(unittest.TestCase):
               product_name='Windows ' + variant) is None
 
 
-def windows(major, minor='') -> inspect_pb2.OsRelease:
+def windows(major, minor='', build_number=0) -> inspect_pb2.OsRelease:
   return inspect_pb2.OsRelease(
       major_version=major,
       minor_version=minor,
       distro_id=inspect_pb2.Distro.WINDOWS,
+      build_number=build_number,
   )
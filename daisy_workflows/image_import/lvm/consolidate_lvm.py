@@ -0,0 +1,128 @@
+#!/usr/bin/env python3
+# Copyright 2026 Google Inc. All Rights Reserved.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+# http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+"""Move an LVM volume group that spans multiple disks onto the boot disk.
+
+The boot disk is attached with the device name `boot-disk`, and the other
+disks of the volume group with `additional-disk-N`. The boot disk must
+have been grown to fit the volume group, and its physical volume must be
+on the last partition (or the whole disk).
+
+Parameters (retrieved from instance metadata):
+
+input_disks_count: The number of disks to wait for, including the boot disk.
+"""
+
+import glob
+import logging
+import os
+import re
+import time
+
+import utils
+
+_by_id = '/dev/disk/by-id/google-'
+
+
+def get_input_disks():
+  """Waits for the input disks to be attached.
+
+  Returns:
+    The boot disk, and a list of the additional disks.
+  """
+  input_disks_count = int(utils.GetMetadataAttribute('input_disks_count', 1))
+  logging.debug('Waiting for %d input disk(s) to be attached.',
+                input_disks_count)
+  additional_disks = []
+  for i in range(4):
+    time.sleep(i * 10)
+    # Skip the links to partitions, such as additional-disk-2-part1.
+    additional_disks = [d for d in glob.glob(_by_id + 'additional-disk-*')
+                        if re.search(r'-disk-\d+$', d)]
+    if (os.path.exists(_by_id + 'boot-disk') and
+        len(additional_disks) + 1 == input_disks_count):
+      break
+  else:
+    raise RuntimeError(
+        'Input disk(s) were not attached within the expected timeout; '
+        'expected {}, but attached {}.'.format(input_disks_count,
+                                               len(additional_disks) + 1))
+  return (os.path.realpath(_by_id + 'boot-disk'),
+          [os.path.realpath(d) for d in sorted(additional_disks)])
+
+
+def physical_volumes():
+  """Returns a list of (pv_name, vg_name) tuples."""
+  utils.Execute(['pvscan', '--cache'], raise_errors=False)
+  _, output = utils.Execute(
+      ['pvs', '--noheadings', '--separator', ',', '-o', 'pv_name,vg_name'],
+      capture_output=True)
+  pvs = []
+  for line in output.splitlines():
+    if line.strip():
+      pv, vg = line.strip().split(',')
+      pvs.append((pv, vg))
+  return pvs
+
+
+def on_disk(pv, disk):
+  return pv == disk or re.match(re.escape(disk) + r'\d+$', pv)
+
+
+def grow_physical_volume(pv, disk):
+  """Grows `pv` to fill the free space at the end of `disk`."""
+  if pv != disk:
+    partition = pv[len(disk):]
+    code, _ = utils.Execute(['growpart', disk, partition],
+                            capture_output=True, raise_errors=False)
+    # growpart returns 1 when the partition already fills the disk.
+    if code not in (0, 1):
+      raise RuntimeError(
+          'Failed to grow {}. To consolidate the volume group, its physical '
+          'volume must be the last partition on the boot disk.'.format(pv))
+    utils.Execute(['partprobe', disk])
+  utils.Execute(['pvresize', pv])
+
+
+def main():
+  utils.AptGetInstall(['lvm2', 'cloud-guest-utils', 'parted'])
+  boot_disk, additional_disks = get_input_disks()
+  pvs = physical_volumes()
+
+  boot_pvs = [(pv, vg) for pv, vg in pvs if on_disk(pv, boot_disk)]
+  groups = {vg for _, vg in boot_pvs if vg}
+  moves = [(pv, vg) for pv, vg in pvs if vg in groups and
+           any(on_disk(pv, d) for d in additional_disks)]
+  if not moves:
+    raise RuntimeError('No volume group spans the boot disk and the '
+                       'additional disks.')
+
+  for pv, vg in boot_pvs:
+    if vg in groups:
+      logging.info('Growing %s.', pv)
+      grow_physical_volume(pv, boot_disk)
+
+  for pv, vg in moves:
+    logging.info('Moving %s off of %s.', vg, pv)
+    utils.Execute(['pvmove', pv])
+    utils.Execute(['vgreduce', vg, pv])
+    utils.Execute(['pvremove', pv])
+
+  for vg in groups:
+    utils.Execute(['vgchange', '--activate', 'n', vg])
+
+
+if __name__ == '__main__':
+  utils.RunTranslate(main)
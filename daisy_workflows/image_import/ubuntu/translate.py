@@ -231,6 +231,21 @@ def install_osconfig_agent(g: guestfs.GuestFS):
       'https://cloud.google.com/compute/docs/manage-os#agent-install .')
 
 
+def install_guest_environment(g: guestfs.GuestFS):
+  """ Installs the guest environment packages of the release.
+
+  Args:
+    g: A mounted GuestFS instance.
+  """
+  # Starting at 24.04, the guest agent and OS Login are packaged separately,
+  # rather than by gce-compute-image-packages.
+  if g.gcp_image_major >= '24':
+    utils.install_apt_packages(g, 'google-guest-agent',
+                               'google-compute-engine-oslogin')
+  else:
+    utils.install_apt_packages(g, 'gce-compute-image-packages')
+
+
 def setup_cloud_init(g: guestfs.GuestFS):
   """ Install cloud-init if not present, and configure to the cloud provider.
 
@@ -292,6 +307,8 @@ def DistroSpecific(g):
   elif g.is_dir('/etc/netplan'):
     run(g, 'rm -f /etc/netplan/*.yaml')
     g.write('/etc/netplan/config.yaml', network_netplan)
+    # Starting at 24.04, netplan warns when its configs are readable by others.
+    g.chmod(0o600, '/etc/netplan/config.yaml')
     run(g, 'netplan apply')
 
   if install_gce == 'true':
@@ -301,7 +318,7 @@ def DistroSpecific(g):
 
     if g.gcp_image_major > '14':
       install_osconfig_agent(g)
-    utils.install_apt_packages(g, 'gce-compute-image-packages')
+    install_guest_environment(g)
     install_cloud_sdk(g, ubuntu_release)
 
   # Update grub config to log to console.
@@ -0,0 +1,89 @@
+#  Copyright 2026 Google Inc. All Rights Reserved.
+#
+#  Licensed under the Apache License, Version 2.0 (the "License");
+#  you may not use this file except in compliance with the License.
+#  You may obtain a copy of the License at
+#
+#      http://www.apache.org/licenses/LICENSE-2.0
+#
+#  Unless required by applicable law or agreed to in writing, software
+#  distributed under the License is distributed on an "AS IS" BASIS,
+#  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+#  See the License for the specific language governing permissions and
+#  limitations under the License.
+
+# Converts the Windows installation on disk 1 from MBR/BIOS to GPT/UEFI.
+
+$ErrorActionPreference = 'Stop'
+
+$script:esp_guid = '{c12a7328-f81f-11d2-ba4b-00a0c93ec93b}'
+
+function Run-Command {
+ [CmdletBinding(SupportsShouldProcess=$true)]
+  param (
+    [Parameter(Mandatory=$true, ValueFromPipelineByPropertyName=$true)]
+      [string]$Executable,
+    [Parameter(ValueFromRemainingArguments=$true,
+               ValueFromPipelineByPropertyName=$true)]
+      $Arguments = $null
+  )
+  Write-Output "Running $Executable with arguments $Arguments."
+  $out = &$executable $arguments 2>&1 | Out-String
+  $out.Trim()
+  if ($LASTEXITCODE -ne 0) {
+    throw "$Executable exited with code ${LASTEXITCODE}: $out"
+  }
+}
+
+try {
+  Write-Output 'ConvertStatus: Beginning UEFI conversion.'
+  Get-Disk | Where-Object -Property OperationalStatus -EQ 'Offline' | Set-Disk -IsOffline $false
+  Set-Disk -Number 1 -IsReadOnly $false
+
+  $partition_style = Get-Disk 1 | Select-Object -Expand PartitionStyle
+  if ($partition_style -eq 'MBR') {
+    Write-Output 'ConvertStatus: Validating disk for MBR to GPT conversion.'
+    Run-Command mbr2gpt.exe /validate /disk:1 /allowFullOS
+    Write-Output 'ConvertStatus: Converting partition table from MBR to GPT.'
+    Run-Command mbr2gpt.exe /convert /disk:1 /allowFullOS
+  }
+  else {
+    Write-Output 'GPT partition detected.'
+  }
+
+  $esp = Get-Disk 1 | Get-Partition | Where-Object -Property GptType -EQ $script:esp_guid
+  if (!$esp) {
+    throw 'No EFI system partition found after conversion.'
+  }
+  Add-PartitionAccessPath -DiskNumber 1 -PartitionNumber $esp.PartitionNumber -AccessPath 'S:\'
+
+  $os_drive = ''
+  Get-Disk 1 | Get-Partition | ForEach-Object {
+    if (-not $_.DriveLetter -and $_.GptType -ne $script:esp_guid) {
+      Add-PartitionAccessPath -DiskNumber 1 -PartitionNumber $_.PartitionNumber -AssignDriveLetter -ErrorAction SilentlyContinue
+    }
+  }
+  Get-Disk 1 | Get-Partition | ForEach-Object {
+    if ($_.DriveLetter -and (Test-Path "$($_.DriveLetter):\Windows")) {
+      $os_drive = "$($_.DriveLetter):"
+    }
+  }
+  if (!$os_drive) {
+    $partitions = Get-Disk 1 | Get-Partition
+    throw "No Windows folder found on any partition: $partitions"
+  }
+  Write-Output "Detected Windows folder drive letter: ${os_drive}"
+
+  Write-Output 'ConvertStatus: Writing UEFI boot files.'
+  Run-Command bcdboot "${os_drive}\Windows" /s S: /f UEFI
+
+  Remove-PartitionAccessPath -DiskNumber 1 -PartitionNumber $esp.PartitionNumber -AccessPath 'S:\'
+  Set-Disk -Number 1 -IsOffline $true
+  Write-Output 'ConvertSuccess: Finished UEFI conversion.'
+}
+catch {
+  Write-Output 'Exception caught in script:'
+  Write-Output $_.InvocationInfo.PositionMessage
+  Write-Output "ConvertFailed: $($_.Exception.Message)"
+  exit 1
+}
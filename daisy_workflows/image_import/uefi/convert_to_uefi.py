@@ -0,0 +1,176 @@
+#!/usr/bin/env python3
+# Copyright 2026 Google Inc. All Rights Reserved.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+# http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+"""Convert a BIOS-booted Linux disk to boot with UEFI.
+
+The partition table is converted to GPT, an EFI system partition is
+created at the end of the disk, and a UEFI bootloader is installed
+into the guest.
+
+Parameters (retrieved from instance metadata):
+
+root_device: Optional root filesystem of the OS to convert, for disks
+             with multiple operating systems.
+"""
+
+import logging
+import os
+import time
+
+import utils
+import utils.diskutils as diskutils
+from utils.guestfsprocess import run
+
+_esp_size = '-260M'
+_esp_mount_point = '/boot/efi'
+
+
+def partition_numbers(device):
+  """Returns the partition numbers on `device`."""
+  _, output = utils.Execute(
+      ['partx', '--show', '--noheadings', '--output', 'NR', device],
+      capture_output=True)
+  return {int(n) for n in output.split()}
+
+
+def convert_partition_table(device) -> int:
+  """Converts `device` to GPT and creates an EFI system partition.
+
+  Returns:
+    The partition number of the EFI system partition.
+  """
+  utils.AptGetInstall(['gdisk'])
+  _, pttype = utils.Execute(
+      ['blkid', '--probe', '--match-tag', 'PTTYPE', '--output', 'value',
+       device], capture_output=True, raise_errors=False)
+  if (pttype or '').strip() == 'gpt':
+    logging.info('Partition table is already GPT.')
+  else:
+    logging.info('Converting partition table from MBR to GPT.')
+    utils.Execute(['sgdisk', '--mbrtogpt', device])
+
+  # The disk was grown prior to conversion; move the backup
+  # GPT header to the new end of the disk.
+  utils.Execute(['sgdisk', '--move-second-header', device])
+
+  before = partition_numbers(device)
+  logging.info('Creating EFI system partition.')
+  utils.Execute(['sgdisk', '--new', '0:%s:0' % _esp_size,
+                 '--typecode', '0:EF00', '--change-name', '0:EFI', device])
+  utils.Execute(['partprobe', device])
+  created = partition_numbers(device) - before
+  if len(created) != 1:
+    raise RuntimeError('Failed to create EFI system partition.')
+  return created.pop()
+
+
+def mount_esp(g, esp):
+  """Formats `esp`, mounts it, and adds it to /etc/fstab."""
+  g.mkfs('vfat', esp)
+  g.mkdir_p(_esp_mount_point)
+  g.mount(esp, _esp_mount_point)
+  fstab = g.cat('/etc/fstab')
+  if not fstab.endswith('\n'):
+    fstab += '\n'
+  fstab += 'UUID=%s %s vfat umask=0077 0 1\n' % (g.vfs_uuid(esp),
+                                                 _esp_mount_point)
+  g.write('/etc/fstab', fstab)
+
+
+def install_debian_bootloader(g):
+  utils.update_apt(g)
+  utils.install_apt_packages(g, 'grub-efi-amd64', 'grub-efi-amd64-signed',
+                             'shim-signed')
+  grub_install = ['grub-install', '--target=x86_64-efi',
+                  '--efi-directory=' + _esp_mount_point,
+                  '--uefi-secure-boot', '--no-nvram']
+  run(g, grub_install)
+  # GCE doesn't keep boot entries in NVRAM, so install to the
+  # fallback path too.
+  run(g, grub_install + ['--removable'])
+  run(g, ['update-grub'])
+
+
+def install_el_bootloader(g):
+  packages = ['grub2-efi-x64', 'shim-x64']
+  run(g, ['yum', 'install', '-y'] + packages)
+  # Reinstalling writes the bootloader files to the new ESP when
+  # the packages were already installed.
+  run(g, ['yum', 'reinstall', '-y'] + packages)
+  vendors = [d for d in g.ls('/boot/efi/EFI') if d.upper() != 'BOOT']
+  if not vendors:
+    raise RuntimeError('No UEFI bootloader was installed in /boot/efi/EFI.')
+  efi_grub_cfg = os.path.join('/boot/efi/EFI', vendors[0], 'grub.cfg')
+  # EL 9 and later ship a grub.cfg on the ESP that loads /boot/grub2/grub.cfg.
+  if not g.exists(efi_grub_cfg):
+    run(g, ['grub2-mkconfig', '-o', efi_grub_cfg])
+  run(g, ['grub2-mkconfig', '-o', '/boot/grub2/grub.cfg'])
+
+
+def install_suse_bootloader(g):
+  run(g, ['zypper', '--non-interactive', 'install', 'grub2-x86_64-efi',
+          'shim'])
+  run(g, ['sed', '-i', 's/^LOADER_TYPE=.*/LOADER_TYPE="grub2-efi"/',
+          '/etc/sysconfig/bootloader'])
+  run(g, ['shim-install', '--config-file=/boot/grub2/grub.cfg',
+          '--removable'])
+  run(g, ['grub2-mkconfig', '-o', '/boot/grub2/grub.cfg'])
+
+
+def install_bootloader(g):
+  distro = g.gcp_image_distro
+  logging.info('Installing UEFI bootloader for %s.', distro)
+  if distro in ('debian', 'ubuntu'):
+    install_debian_bootloader(g)
+  elif distro in ('rhel', 'centos', 'rocky', 'almalinux', 'oraclelinux'):
+    install_el_bootloader(g)
+  elif distro in ('sles', 'opensuse', 'suse'):
+    install_suse_bootloader(g)
+  else:
+    raise RuntimeError('UEFI conversion is not supported for %s.' % distro)
+
+
+def get_input_disks():
+  """Waits for the disk to convert to be attached, and returns its path in a list."""
+  attached_disks = []
+  for i in range(4):
+    time.sleep(i * 10)
+    attached_disks = diskutils.get_physical_drives()
+    # attached_disks include the worker disk
+    if len(attached_disks) == 2:
+      break
+  else:
+    raise RuntimeError('Expected one disk to convert, found: %s' %
+                       ', '.join(attached_disks[1:]))
+
+  # remove the boot disk of the worker instance
+  attached_disks.remove('/dev/sda')
+  return attached_disks
+
+
+def main():
+  attached_disks = get_input_disks()
+
+  esp_number = convert_partition_table(attached_disks[0])
+
+  # Within guestfs, the disk being converted is /dev/sda.
+  g = diskutils.MountDisks(attached_disks)
+  mount_esp(g, '/dev/sda%d' % esp_number)
+  install_bootloader(g)
+  diskutils.UnmountDisk(g)
+
+
+if __name__ == '__main__':
+  utils.RunTranslate(main)
@@ -81,13 +81,23 @@ def MountDisks(disks) -> guestfs.GuestFS:
   if len(roots) == 0:
     raise Exception('inspect_vm: no operating systems found')
 
+  # When a disk has multiple operating systems, the importer
+  # passes the root partition of the one to translate.
+  root = roots[0]
+  root_device = utils.GetMetadataAttribute('root_device')
+  if root_device:
+    if root_device not in roots:
+      raise Exception('inspect_vm: root device %s not found. Found: %s' %
+                      (root_device, ', '.join(roots)))
+    root = root_device
+
   # Sort keys by length, shortest first, so that we end up
   # mounting the filesystems in the correct order.
-  mps = g.inspect_get_mountpoints(roots[0])
+  mps = g.inspect_get_mountpoints(root)
 
-  g.gcp_image_distro = g.inspect_get_distro(roots[0])
-  g.gcp_image_major = str(g.inspect_get_major_version(roots[0]))
-  g.gcp_image_minor = str(g.inspect_get_minor_version(roots[0]))
+  g.gcp_image_distro = g.inspect_get_distro(root)
+  g.gcp_image_major = str(g.inspect_get_major_version(root))
+  g.gcp_image_minor = str(g.inspect_get_minor_version(root))
 
   for device in sorted(list(mps.keys()), key=len):
     try:
@@ -66,8 +66,10 @@ const (
 	Distro_ORACLE         Distro = 4004
 	Distro_ROCKY          Distro = 4005
 	Distro_CENTOS_STREAM  Distro = 4006
+	Distro_ALMALINUX      Distro = 4007
 	Distro_ARCH           Distro = 5000
 	Distro_CLEAR          Distro = 6000
+	Distro_FREEBSD        Distro = 7000
 )
 
 // Enum value maps for Distro.
@@ -88,8 +90,10 @@ var (
 		4004: "ORACLE",
 		4005: "ROCKY",
 		4006: "CENTOS_STREAM",
+		4007: "ALMALINUX",
 		5000: "ARCH",
 		6000: "CLEAR",
+		7000: "FREEBSD",
 	}
 	Distro_value = map[string]int32{
 		"DISTRO_UNKNOWN": 0,
@@ -107,8 +111,10 @@ var (
 		"ORACLE":         4004,
 		"ROCKY":          4005,
 		"CENTOS_STREAM":  4006,
+		"ALMALINUX":      4007,
 		"ARCH":           5000,
 		"CLEAR":          6000,
+		"FREEBSD":        7000,
 	}
 )
 
@@ -249,7 +255,7 @@ func (x InspectionResults_ErrorWhen) Number() protoreflect.EnumNumber {
 
 // Deprecated: Use InspectionResults_ErrorWhen.Descriptor instead.
 func (InspectionResults_ErrorWhen) EnumDescriptor() ([]byte, []int) {
-	return file_inspect_proto_rawDescGZIP(), []int{1, 0}
+	return file_inspect_proto_rawDescGZIP(), []int{2, 0}
 }
 
 // OsRelease records the name and version of an operating system.
@@ -283,6 +289,12 @@ type OsRelease struct {
 	// Enumerated representation of the distro. Prefer this for
 	// programmatic usage.
 	DistroId Distro `protobuf:"varint,6,opt,name=distro_id,json=distroId,proto3,enum=Distro" json:"distro_id,omitempty"`
+	// build_number of the OS, for vendors that distinguish releases
+	// sharing a version by their build. Zero when unknown.
+	// Examples:
+	//   - Windows 11 23H2: 22631
+	//   - Windows Server 2025: 26100
+	BuildNumber int32 `protobuf:"varint,7,opt,name=build_number,json=buildNumber,proto3" json:"build_number,omitempty"`
 }
 
 func (x *OsRelease) Reset() {
@@ -359,6 +371,90 @@ func (x *OsRelease) GetDistroId() Distro {
 	return Distro_DISTRO_UNKNOWN
 }
 
+func (x *OsRelease) GetBuildNumber() int32 {
+	if x != nil {
+		return x.BuildNumber
+	}
+	return 0
+}
+
+// OsInstallation describes one operating system found on the disk.
+type OsInstallation struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// root_partition is the block device that holds the root directory
+	// ("/") of this installation, such as /dev/sda2.
+	RootPartition string `protobuf:"bytes,1,opt,name=root_partition,json=rootPartition,proto3" json:"root_partition,omitempty"`
+	// The OS and version of this installation.
+	OsRelease *OsRelease `protobuf:"bytes,2,opt,name=os_release,json=osRelease,proto3" json:"os_release,omitempty"`
+	// bios_bootable indicates whether this installation is bootable using bios.
+	BiosBootable bool `protobuf:"varint,3,opt,name=bios_bootable,json=biosBootable,proto3" json:"bios_bootable,omitempty"`
+	// uefi_bootable indicates whether this installation is bootable with UEFI.
+	UefiBootable bool `protobuf:"varint,4,opt,name=uefi_bootable,json=uefiBootable,proto3" json:"uefi_bootable,omitempty"`
+}
+
+func (x *OsInstallation) Reset() {
+	*x = OsInstallation{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_inspect_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *OsInstallation) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*OsInstallation) ProtoMessage() {}
+
+func (x *OsInstallation) ProtoReflect() protoreflect.Message {
+	mi := &file_inspect_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use OsInstallation.ProtoReflect.Descriptor instead.
+func (*OsInstallation) Descriptor() ([]byte, []int) {
+	return file_inspect_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *OsInstallation) GetRootPartition() string {
+	if x != nil {
+		return x.RootPartition
+	}
+	return ""
+}
+
+func (x *OsInstallation) GetOsRelease() *OsRelease {
+	if x != nil {
+		return x.OsRelease
+	}
+	return nil
+}
+
+func (x *OsInstallation) GetBiosBootable() bool {
+	if x != nil {
+		return x.BiosBootable
+	}
+	return false
+}
+
+func (x *OsInstallation) GetUefiBootable() bool {
+	if x != nil {
+		return x.UefiBootable
+	}
+	return false
+}
+
 // InspectionResults contains metadata determined using automated inspection
 // of the guest image.
 type InspectionResults struct {
@@ -387,12 +483,15 @@ type InspectionResults struct {
 	ElapsedTimeMs int64 `protobuf:"varint,6,opt,name=elapsed_time_ms,json=elapsedTimeMs,proto3" json:"elapsed_time_ms,omitempty"`
 	// Number of operating systems detected on the disk.
 	OsCount int32 `protobuf:"varint,7,opt,name=os_count,json=osCount,proto3" json:"os_count,omitempty"`
+	// Every operating system detected on the disk. Populated even when
+	// a single OS is detected.
+	OsInstallations []*OsInstallation `protobuf:"bytes,8,rep,name=os_installations,json=osInstallations,proto3" json:"os_installations,omitempty"`
 }
 
 func (x *InspectionResults) Reset() {
 	*x = InspectionResults{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_inspect_proto_msgTypes[1]
+		mi := &file_inspect_proto_msgTypes[2]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -405,7 +504,7 @@ func (x *InspectionResults) String() string {
 func (*InspectionResults) ProtoMessage() {}
 
 func (x *InspectionResults) ProtoReflect() protoreflect.Message {
-	mi := &file_inspect_proto_msgTypes[1]
+	mi := &file_inspect_proto_msgTypes[2]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -418,7 +517,7 @@ func (x *InspectionResults) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use InspectionResults.ProtoReflect.Descriptor instead.
 func (*InspectionResults) Descriptor() ([]byte, []int) {
-	return file_inspect_proto_rawDescGZIP(), []int{1}
+	return file_inspect_proto_rawDescGZIP(), []int{2}
 }
 
 func (x *InspectionResults) GetOsRelease() *OsRelease {
@@ -470,76 +569,87 @@ func (x *InspectionResults) GetOsCount() int32 {
 	return 0
 }
 
+func (x *InspectionResults) GetOsInstallations() []*OsInstallation {
+	if x != nil {
+		return x.OsInstallations
+	}
+	return nil
+}
+
 var File_inspect_proto protoreflect.FileDescriptor
 
 var file_inspect_proto_rawDesc = []byte{
 	0x0a, 0x0d, 0x69, 0x6e, 0x73, 0x70, 0x65, 0x63, 0x74, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x22,
-	0xeb, 0x01, 0x0a, 0x09, 0x4f, 0x73, 0x52, 0x65, 0x6c, 0x65, 0x61, 0x73, 0x65, 0x12, 0x23, 0x0a,
+	0xb7, 0x01, 0x0a, 0x09, 0x4f, 0x73, 0x52, 0x65, 0x6c, 0x65, 0x61, 0x73, 0x65, 0x12, 0x15, 0x0a,
 	0x0d, 0x63, 0x6c, 0x69, 0x5f, 0x66, 0x6f, 0x72, 0x6d, 0x61, 0x74, 0x74, 0x65, 0x64, 0x18, 0x01,
-	0x20, 0x01, 0x28, 0x09, 0x52, 0x0c, 0x63, 0x6c, 0x69, 0x46, 0x6f, 0x72, 0x6d, 0x61, 0x74, 0x74,
-	0x65, 0x64, 0x12, 0x16, 0x0a, 0x06, 0x64, 0x69, 0x73, 0x74, 0x72, 0x6f, 0x18, 0x02, 0x20, 0x01,
-	0x28, 0x09, 0x52, 0x06, 0x64, 0x69, 0x73, 0x74, 0x72, 0x6f, 0x12, 0x23, 0x0a, 0x0d, 0x6d, 0x61,
-	0x6a, 0x6f, 0x72, 0x5f, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x18, 0x03, 0x20, 0x01, 0x28,
-	0x09, 0x52, 0x0c, 0x6d, 0x61, 0x6a, 0x6f, 0x72, 0x56, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x12,
-	0x23, 0x0a, 0x0d, 0x6d, 0x69, 0x6e, 0x6f, 0x72, 0x5f, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e,
-	0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0c, 0x6d, 0x69, 0x6e, 0x6f, 0x72, 0x56, 0x65, 0x72,
-	0x73, 0x69, 0x6f, 0x6e, 0x12, 0x31, 0x0a, 0x0c, 0x61, 0x72, 0x63, 0x68, 0x69, 0x74, 0x65, 0x63,
-	0x74, 0x75, 0x72, 0x65, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x0d, 0x2e, 0x41, 0x72, 0x63,
-	0x68, 0x69, 0x74, 0x65, 0x63, 0x74, 0x75, 0x72, 0x65, 0x52, 0x0c, 0x61, 0x72, 0x63, 0x68, 0x69,
-	0x74, 0x65, 0x63, 0x74, 0x75, 0x72, 0x65, 0x12, 0x24, 0x0a, 0x09, 0x64, 0x69, 0x73, 0x74, 0x72,
+	0x20, 0x01, 0x28, 0x09, 0x12, 0x0e, 0x0a, 0x06, 0x64, 0x69, 0x73, 0x74, 0x72, 0x6f, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x09, 0x12, 0x15, 0x0a, 0x0d, 0x6d, 0x61, 0x6a, 0x6f, 0x72, 0x5f, 0x76, 0x65,
+	0x72, 0x73, 0x69, 0x6f, 0x6e, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x12, 0x15, 0x0a, 0x0d, 0x6d,
+	0x69, 0x6e, 0x6f, 0x72, 0x5f, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x18, 0x04, 0x20, 0x01,
+	0x28, 0x09, 0x12, 0x23, 0x0a, 0x0c, 0x61, 0x72, 0x63, 0x68, 0x69, 0x74, 0x65, 0x63, 0x74, 0x75,
+	0x72, 0x65, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x0d, 0x2e, 0x41, 0x72, 0x63, 0x68, 0x69,
+	0x74, 0x65, 0x63, 0x74, 0x75, 0x72, 0x65, 0x12, 0x1a, 0x0a, 0x09, 0x64, 0x69, 0x73, 0x74, 0x72,
 	0x6f, 0x5f, 0x69, 0x64, 0x18, 0x06, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x07, 0x2e, 0x44, 0x69, 0x73,
-	0x74, 0x72, 0x6f, 0x52, 0x08, 0x64, 0x69, 0x73, 0x74, 0x72, 0x6f, 0x49, 0x64, 0x22, 0xf0, 0x03,
-	0x0a, 0x11, 0x49, 0x6e, 0x73, 0x70, 0x65, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x65, 0x73, 0x75,
-	0x6c, 0x74, 0x73, 0x12, 0x29, 0x0a, 0x0a, 0x6f, 0x73, 0x5f, 0x72, 0x65, 0x6c, 0x65, 0x61, 0x73,
-	0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0a, 0x2e, 0x4f, 0x73, 0x52, 0x65, 0x6c, 0x65,
-	0x61, 0x73, 0x65, 0x52, 0x09, 0x6f, 0x73, 0x52, 0x65, 0x6c, 0x65, 0x61, 0x73, 0x65, 0x12, 0x23,
-	0x0a, 0x0d, 0x62, 0x69, 0x6f, 0x73, 0x5f, 0x62, 0x6f, 0x6f, 0x74, 0x61, 0x62, 0x6c, 0x65, 0x18,
-	0x02, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0c, 0x62, 0x69, 0x6f, 0x73, 0x42, 0x6f, 0x6f, 0x74, 0x61,
-	0x62, 0x6c, 0x65, 0x12, 0x23, 0x0a, 0x0d, 0x75, 0x65, 0x66, 0x69, 0x5f, 0x62, 0x6f, 0x6f, 0x74,
-	0x61, 0x62, 0x6c, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0c, 0x75, 0x65, 0x66, 0x69,
-	0x42, 0x6f, 0x6f, 0x74, 0x61, 0x62, 0x6c, 0x65, 0x12, 0x17, 0x0a, 0x07, 0x72, 0x6f, 0x6f, 0x74,
-	0x5f, 0x66, 0x73, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x72, 0x6f, 0x6f, 0x74, 0x46,
-	0x73, 0x12, 0x3b, 0x0a, 0x0a, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x5f, 0x77, 0x68, 0x65, 0x6e, 0x18,
-	0x05, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x1c, 0x2e, 0x49, 0x6e, 0x73, 0x70, 0x65, 0x63, 0x74, 0x69,
-	0x6f, 0x6e, 0x52, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x73, 0x2e, 0x45, 0x72, 0x72, 0x6f, 0x72, 0x57,
-	0x68, 0x65, 0x6e, 0x52, 0x09, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x57, 0x68, 0x65, 0x6e, 0x12, 0x26,
-	0x0a, 0x0f, 0x65, 0x6c, 0x61, 0x70, 0x73, 0x65, 0x64, 0x5f, 0x74, 0x69, 0x6d, 0x65, 0x5f, 0x6d,
-	0x73, 0x18, 0x06, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0d, 0x65, 0x6c, 0x61, 0x70, 0x73, 0x65, 0x64,
-	0x54, 0x69, 0x6d, 0x65, 0x4d, 0x73, 0x12, 0x19, 0x0a, 0x08, 0x6f, 0x73, 0x5f, 0x63, 0x6f, 0x75,
-	0x6e, 0x74, 0x18, 0x07, 0x20, 0x01, 0x28, 0x05, 0x52, 0x07, 0x6f, 0x73, 0x43, 0x6f, 0x75, 0x6e,
-	0x74, 0x22, 0xcc, 0x01, 0x0a, 0x09, 0x45, 0x72, 0x72, 0x6f, 0x72, 0x57, 0x68, 0x65, 0x6e, 0x12,
-	0x0c, 0x0a, 0x08, 0x4e, 0x4f, 0x5f, 0x45, 0x52, 0x52, 0x4f, 0x52, 0x10, 0x00, 0x12, 0x13, 0x0a,
-	0x0f, 0x53, 0x54, 0x41, 0x52, 0x54, 0x49, 0x4e, 0x47, 0x5f, 0x57, 0x4f, 0x52, 0x4b, 0x45, 0x52,
-	0x10, 0x64, 0x12, 0x12, 0x0a, 0x0e, 0x52, 0x55, 0x4e, 0x4e, 0x49, 0x4e, 0x47, 0x5f, 0x57, 0x4f,
-	0x52, 0x4b, 0x45, 0x52, 0x10, 0x65, 0x12, 0x13, 0x0a, 0x0e, 0x4d, 0x4f, 0x55, 0x4e, 0x54, 0x49,
-	0x4e, 0x47, 0x5f, 0x47, 0x55, 0x45, 0x53, 0x54, 0x10, 0xc8, 0x01, 0x12, 0x12, 0x0a, 0x0d, 0x49,
-	0x4e, 0x53, 0x50, 0x45, 0x43, 0x54, 0x49, 0x4e, 0x47, 0x5f, 0x4f, 0x53, 0x10, 0xc9, 0x01, 0x12,
-	0x1a, 0x0a, 0x15, 0x49, 0x4e, 0x53, 0x50, 0x45, 0x43, 0x54, 0x49, 0x4e, 0x47, 0x5f, 0x42, 0x4f,
-	0x4f, 0x54, 0x4c, 0x4f, 0x41, 0x44, 0x45, 0x52, 0x10, 0xca, 0x01, 0x12, 0x1d, 0x0a, 0x18, 0x44,
-	0x45, 0x43, 0x4f, 0x44, 0x49, 0x4e, 0x47, 0x5f, 0x57, 0x4f, 0x52, 0x4b, 0x45, 0x52, 0x5f, 0x52,
-	0x45, 0x53, 0x50, 0x4f, 0x4e, 0x53, 0x45, 0x10, 0xac, 0x02, 0x12, 0x24, 0x0a, 0x1f, 0x49, 0x4e,
-	0x54, 0x45, 0x52, 0x50, 0x52, 0x45, 0x54, 0x49, 0x4e, 0x47, 0x5f, 0x49, 0x4e, 0x53, 0x50, 0x45,
-	0x43, 0x54, 0x49, 0x4f, 0x4e, 0x5f, 0x52, 0x45, 0x53, 0x55, 0x4c, 0x54, 0x53, 0x10, 0xad, 0x02,
-	0x2a, 0xee, 0x01, 0x0a, 0x06, 0x44, 0x69, 0x73, 0x74, 0x72, 0x6f, 0x12, 0x12, 0x0a, 0x0e, 0x44,
-	0x49, 0x53, 0x54, 0x52, 0x4f, 0x5f, 0x55, 0x4e, 0x4b, 0x4e, 0x4f, 0x57, 0x4e, 0x10, 0x00, 0x12,
-	0x0c, 0x0a, 0x07, 0x57, 0x49, 0x4e, 0x44, 0x4f, 0x57, 0x53, 0x10, 0xe8, 0x07, 0x12, 0x0b, 0x0a,
-	0x06, 0x44, 0x45, 0x42, 0x49, 0x41, 0x4e, 0x10, 0xd0, 0x0f, 0x12, 0x0b, 0x0a, 0x06, 0x55, 0x42,
-	0x55, 0x4e, 0x54, 0x55, 0x10, 0xd1, 0x0f, 0x12, 0x09, 0x0a, 0x04, 0x4b, 0x41, 0x4c, 0x49, 0x10,
-	0xd2, 0x0f, 0x12, 0x0d, 0x0a, 0x08, 0x4f, 0x50, 0x45, 0x4e, 0x53, 0x55, 0x53, 0x45, 0x10, 0xb8,
-	0x17, 0x12, 0x09, 0x0a, 0x04, 0x53, 0x4c, 0x45, 0x53, 0x10, 0xb9, 0x17, 0x12, 0x0d, 0x0a, 0x08,
-	0x53, 0x4c, 0x45, 0x53, 0x5f, 0x53, 0x41, 0x50, 0x10, 0xba, 0x17, 0x12, 0x0b, 0x0a, 0x06, 0x46,
-	0x45, 0x44, 0x4f, 0x52, 0x41, 0x10, 0xa0, 0x1f, 0x12, 0x09, 0x0a, 0x04, 0x52, 0x48, 0x45, 0x4c,
-	0x10, 0xa1, 0x1f, 0x12, 0x0b, 0x0a, 0x06, 0x43, 0x45, 0x4e, 0x54, 0x4f, 0x53, 0x10, 0xa2, 0x1f,
-	0x12, 0x0b, 0x0a, 0x06, 0x41, 0x4d, 0x41, 0x5a, 0x4f, 0x4e, 0x10, 0xa3, 0x1f, 0x12, 0x0b, 0x0a,
-	0x06, 0x4f, 0x52, 0x41, 0x43, 0x4c, 0x45, 0x10, 0xa4, 0x1f, 0x12, 0x0a, 0x0a, 0x05, 0x52, 0x4f,
-	0x43, 0x4b, 0x59, 0x10, 0xa5, 0x1f, 0x12, 0x12, 0x0a, 0x0d, 0x43, 0x45, 0x4e, 0x54, 0x4f, 0x53,
-	0x5f, 0x53, 0x54, 0x52, 0x45, 0x41, 0x4d, 0x10, 0xa6, 0x1f, 0x12, 0x09, 0x0a, 0x04, 0x41, 0x52,
-	0x43, 0x48, 0x10, 0x88, 0x27, 0x12, 0x0a, 0x0a, 0x05, 0x43, 0x4c, 0x45, 0x41, 0x52, 0x10, 0xf0,
-	0x2e, 0x2a, 0x3a, 0x0a, 0x0c, 0x41, 0x72, 0x63, 0x68, 0x69, 0x74, 0x65, 0x63, 0x74, 0x75, 0x72,
-	0x65, 0x12, 0x18, 0x0a, 0x14, 0x41, 0x52, 0x43, 0x48, 0x49, 0x54, 0x45, 0x43, 0x54, 0x55, 0x52,
-	0x45, 0x5f, 0x55, 0x4e, 0x4b, 0x4e, 0x4f, 0x57, 0x4e, 0x10, 0x00, 0x12, 0x07, 0x0a, 0x03, 0x58,
-	0x38, 0x36, 0x10, 0x01, 0x12, 0x07, 0x0a, 0x03, 0x58, 0x36, 0x34, 0x10, 0x02, 0x42, 0x06, 0x5a,
-	0x04, 0x2e, 0x3b, 0x70, 0x62, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+	0x74, 0x72, 0x6f, 0x12, 0x14, 0x0a, 0x0c, 0x62, 0x75, 0x69, 0x6c, 0x64, 0x5f, 0x6e, 0x75, 0x6d,
+	0x62, 0x65, 0x72, 0x18, 0x07, 0x20, 0x01, 0x28, 0x05, 0x22, 0x76, 0x0a, 0x0e, 0x4f, 0x73, 0x49,
+	0x6e, 0x73, 0x74, 0x61, 0x6c, 0x6c, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x16, 0x0a, 0x0e, 0x72,
+	0x6f, 0x6f, 0x74, 0x5f, 0x70, 0x61, 0x72, 0x74, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x09, 0x12, 0x1e, 0x0a, 0x0a, 0x6f, 0x73, 0x5f, 0x72, 0x65, 0x6c, 0x65, 0x61, 0x73,
+	0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0a, 0x2e, 0x4f, 0x73, 0x52, 0x65, 0x6c, 0x65,
+	0x61, 0x73, 0x65, 0x12, 0x15, 0x0a, 0x0d, 0x62, 0x69, 0x6f, 0x73, 0x5f, 0x62, 0x6f, 0x6f, 0x74,
+	0x61, 0x62, 0x6c, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x08, 0x12, 0x15, 0x0a, 0x0d, 0x75, 0x65,
+	0x66, 0x69, 0x5f, 0x62, 0x6f, 0x6f, 0x74, 0x61, 0x62, 0x6c, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28,
+	0x08, 0x22, 0xc9, 0x03, 0x0a, 0x11, 0x49, 0x6e, 0x73, 0x70, 0x65, 0x63, 0x74, 0x69, 0x6f, 0x6e,
+	0x52, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x73, 0x12, 0x1e, 0x0a, 0x0a, 0x6f, 0x73, 0x5f, 0x72, 0x65,
+	0x6c, 0x65, 0x61, 0x73, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0a, 0x2e, 0x4f, 0x73,
+	0x52, 0x65, 0x6c, 0x65, 0x61, 0x73, 0x65, 0x12, 0x15, 0x0a, 0x0d, 0x62, 0x69, 0x6f, 0x73, 0x5f,
+	0x62, 0x6f, 0x6f, 0x74, 0x61, 0x62, 0x6c, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x08, 0x12, 0x15,
+	0x0a, 0x0d, 0x75, 0x65, 0x66, 0x69, 0x5f, 0x62, 0x6f, 0x6f, 0x74, 0x61, 0x62, 0x6c, 0x65, 0x18,
+	0x03, 0x20, 0x01, 0x28, 0x08, 0x12, 0x0f, 0x0a, 0x07, 0x72, 0x6f, 0x6f, 0x74, 0x5f, 0x66, 0x73,
+	0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x12, 0x30, 0x0a, 0x0a, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x5f,
+	0x77, 0x68, 0x65, 0x6e, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x1c, 0x2e, 0x49, 0x6e, 0x73,
+	0x70, 0x65, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x73, 0x2e, 0x45,
+	0x72, 0x72, 0x6f, 0x72, 0x57, 0x68, 0x65, 0x6e, 0x12, 0x17, 0x0a, 0x0f, 0x65, 0x6c, 0x61, 0x70,
+	0x73, 0x65, 0x64, 0x5f, 0x74, 0x69, 0x6d, 0x65, 0x5f, 0x6d, 0x73, 0x18, 0x06, 0x20, 0x01, 0x28,
+	0x03, 0x12, 0x10, 0x0a, 0x08, 0x6f, 0x73, 0x5f, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x18, 0x07, 0x20,
+	0x01, 0x28, 0x05, 0x12, 0x29, 0x0a, 0x10, 0x6f, 0x73, 0x5f, 0x69, 0x6e, 0x73, 0x74, 0x61, 0x6c,
+	0x6c, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x18, 0x08, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x0f, 0x2e,
+	0x4f, 0x73, 0x49, 0x6e, 0x73, 0x74, 0x61, 0x6c, 0x6c, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x22, 0xcc,
+	0x01, 0x0a, 0x09, 0x45, 0x72, 0x72, 0x6f, 0x72, 0x57, 0x68, 0x65, 0x6e, 0x12, 0x0c, 0x0a, 0x08,
+	0x4e, 0x4f, 0x5f, 0x45, 0x52, 0x52, 0x4f, 0x52, 0x10, 0x00, 0x12, 0x13, 0x0a, 0x0f, 0x53, 0x54,
+	0x41, 0x52, 0x54, 0x49, 0x4e, 0x47, 0x5f, 0x57, 0x4f, 0x52, 0x4b, 0x45, 0x52, 0x10, 0x64, 0x12,
+	0x12, 0x0a, 0x0e, 0x52, 0x55, 0x4e, 0x4e, 0x49, 0x4e, 0x47, 0x5f, 0x57, 0x4f, 0x52, 0x4b, 0x45,
+	0x52, 0x10, 0x65, 0x12, 0x13, 0x0a, 0x0e, 0x4d, 0x4f, 0x55, 0x4e, 0x54, 0x49, 0x4e, 0x47, 0x5f,
+	0x47, 0x55, 0x45, 0x53, 0x54, 0x10, 0xc8, 0x01, 0x12, 0x12, 0x0a, 0x0d, 0x49, 0x4e, 0x53, 0x50,
+	0x45, 0x43, 0x54, 0x49, 0x4e, 0x47, 0x5f, 0x4f, 0x53, 0x10, 0xc9, 0x01, 0x12, 0x1a, 0x0a, 0x15,
+	0x49, 0x4e, 0x53, 0x50, 0x45, 0x43, 0x54, 0x49, 0x4e, 0x47, 0x5f, 0x42, 0x4f, 0x4f, 0x54, 0x4c,
+	0x4f, 0x41, 0x44, 0x45, 0x52, 0x10, 0xca, 0x01, 0x12, 0x1d, 0x0a, 0x18, 0x44, 0x45, 0x43, 0x4f,
+	0x44, 0x49, 0x4e, 0x47, 0x5f, 0x57, 0x4f, 0x52, 0x4b, 0x45, 0x52, 0x5f, 0x52, 0x45, 0x53, 0x50,
+	0x4f, 0x4e, 0x53, 0x45, 0x10, 0xac, 0x02, 0x12, 0x24, 0x0a, 0x1f, 0x49, 0x4e, 0x54, 0x45, 0x52,
+	0x50, 0x52, 0x45, 0x54, 0x49, 0x4e, 0x47, 0x5f, 0x49, 0x4e, 0x53, 0x50, 0x45, 0x43, 0x54, 0x49,
+	0x4f, 0x4e, 0x5f, 0x52, 0x45, 0x53, 0x55, 0x4c, 0x54, 0x53, 0x10, 0xad, 0x02, 0x2a, 0x8c, 0x02,
+	0x0a, 0x06, 0x44, 0x69, 0x73, 0x74, 0x72, 0x6f, 0x12, 0x12, 0x0a, 0x0e, 0x44, 0x49, 0x53, 0x54,
+	0x52, 0x4f, 0x5f, 0x55, 0x4e, 0x4b, 0x4e, 0x4f, 0x57, 0x4e, 0x10, 0x00, 0x12, 0x0c, 0x0a, 0x07,
+	0x57, 0x49, 0x4e, 0x44, 0x4f, 0x57, 0x53, 0x10, 0xe8, 0x07, 0x12, 0x0b, 0x0a, 0x06, 0x44, 0x45,
+	0x42, 0x49, 0x41, 0x4e, 0x10, 0xd0, 0x0f, 0x12, 0x0b, 0x0a, 0x06, 0x55, 0x42, 0x55, 0x4e, 0x54,
+	0x55, 0x10, 0xd1, 0x0f, 0x12, 0x09, 0x0a, 0x04, 0x4b, 0x41, 0x4c, 0x49, 0x10, 0xd2, 0x0f, 0x12,
+	0x0d, 0x0a, 0x08, 0x4f, 0x50, 0x45, 0x4e, 0x53, 0x55, 0x53, 0x45, 0x10, 0xb8, 0x17, 0x12, 0x09,
+	0x0a, 0x04, 0x53, 0x4c, 0x45, 0x53, 0x10, 0xb9, 0x17, 0x12, 0x0d, 0x0a, 0x08, 0x53, 0x4c, 0x45,
+	0x53, 0x5f, 0x53, 0x41, 0x50, 0x10, 0xba, 0x17, 0x12, 0x0b, 0x0a, 0x06, 0x46, 0x45, 0x44, 0x4f,
+	0x52, 0x41, 0x10, 0xa0, 0x1f, 0x12, 0x09, 0x0a, 0x04, 0x52, 0x48, 0x45, 0x4c, 0x10, 0xa1, 0x1f,
+	0x12, 0x0b, 0x0a, 0x06, 0x43, 0x45, 0x4e, 0x54, 0x4f, 0x53, 0x10, 0xa2, 0x1f, 0x12, 0x0b, 0x0a,
+	0x06, 0x41, 0x4d, 0x41, 0x5a, 0x4f, 0x4e, 0x10, 0xa3, 0x1f, 0x12, 0x0b, 0x0a, 0x06, 0x4f, 0x52,
+	0x41, 0x43, 0x4c, 0x45, 0x10, 0xa4, 0x1f, 0x12, 0x0a, 0x0a, 0x05, 0x52, 0x4f, 0x43, 0x4b, 0x59,
+	0x10, 0xa5, 0x1f, 0x12, 0x12, 0x0a, 0x0d, 0x43, 0x45, 0x4e, 0x54, 0x4f, 0x53, 0x5f, 0x53, 0x54,
+	0x52, 0x45, 0x41, 0x4d, 0x10, 0xa6, 0x1f, 0x12, 0x0e, 0x0a, 0x09, 0x41, 0x4c, 0x4d, 0x41, 0x4c,
+	0x49, 0x4e, 0x55, 0x58, 0x10, 0xa7, 0x1f, 0x12, 0x09, 0x0a, 0x04, 0x41, 0x52, 0x43, 0x48, 0x10,
+	0x88, 0x27, 0x12, 0x0a, 0x0a, 0x05, 0x43, 0x4c, 0x45, 0x41, 0x52, 0x10, 0xf0, 0x2e, 0x12, 0x0c,
+	0x0a, 0x07, 0x46, 0x52, 0x45, 0x45, 0x42, 0x53, 0x44, 0x10, 0xd8, 0x36, 0x2a, 0x3a, 0x0a, 0x0c,
+	0x41, 0x72, 0x63, 0x68, 0x69, 0x74, 0x65, 0x63, 0x74, 0x75, 0x72, 0x65, 0x12, 0x18, 0x0a, 0x14,
+	0x41, 0x52, 0x43, 0x48, 0x49, 0x54, 0x45, 0x43, 0x54, 0x55, 0x52, 0x45, 0x5f, 0x55, 0x4e, 0x4b,
+	0x4e, 0x4f, 0x57, 0x4e, 0x10, 0x00, 0x12, 0x07, 0x0a, 0x03, 0x58, 0x38, 0x36, 0x10, 0x01, 0x12,
+	0x07, 0x0a, 0x03, 0x58, 0x36, 0x34, 0x10, 0x02, 0x42, 0x06, 0x5a, 0x04, 0x2e, 0x3b, 0x70, 0x62,
+	0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
 }
 
 var (
@@ -555,24 +665,27 @@ func file_inspect_proto_rawDescGZIP() []byte {
 }
 
 var file_inspect_proto_enumTypes = make([]protoimpl.EnumInfo, 3)
-var file_inspect_proto_msgTypes = make([]protoimpl.MessageInfo, 2)
+var file_inspect_proto_msgTypes = make([]protoimpl.MessageInfo, 3)
 var file_inspect_proto_goTypes = []interface{}{
 	(Distro)(0),                      // 0: Distro
 	(Architecture)(0),                // 1: Architecture
 	(InspectionResults_ErrorWhen)(0), // 2: InspectionResults.ErrorWhen
 	(*OsRelease)(nil),                // 3: OsRelease
-	(*InspectionResults)(nil),        // 4: InspectionResults
+	(*OsInstallation)(nil),           // 4: OsInstallation
+	(*InspectionResults)(nil),        // 5: InspectionResults
 }
 var file_inspect_proto_depIdxs = []int32{
 	1, // 0: OsRelease.architecture:type_name -> Architecture
 	0, // 1: OsRelease.distro_id:type_name -> Distro
-	3, // 2: InspectionResults.os_release:type_name -> OsRelease
-	2, // 3: InspectionResults.error_when:type_name -> InspectionResults.ErrorWhen
-	4, // [4:4] is the sub-list for method output_type
-	4, // [4:4] is the sub-list for method input_type
-	4, // [4:4] is the sub-list for extension type_name
-	4, // [4:4] is the sub-list for extension extendee
-	0, // [0:4] is the sub-list for field type_name
+	3, // 2: OsInstallation.os_release:type_name -> OsRelease
+	3, // 3: InspectionResults.os_release:type_name -> OsRelease
+	2, // 4: InspectionResults.error_when:type_name -> InspectionResults.ErrorWhen
+	4, // 5: InspectionResults.os_installations:type_name -> OsInstallation
+	6, // [6:6] is the sub-list for method output_type
+	6, // [6:6] is the sub-list for method input_type
+	6, // [6:6] is the sub-list for extension type_name
+	6, // [6:6] is the sub-list for extension extendee
+	0, // [0:6] is the sub-list for field type_name
 }
 
 func init() { file_inspect_proto_init() }
@@ -594,6 +707,18 @@ func file_inspect_proto_init() {
 			}
 		}
 		file_inspect_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*OsInstallation); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_inspect_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*InspectionResults); i {
 			case 0:
 				return &v.state
@@ -612,7 +737,7 @@ func file_inspect_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: file_inspect_proto_rawDesc,
 			NumEnums:      3,
-			NumMessages:   2,
+			NumMessages:   3,
 			NumExtensions: 0,
 			NumServices:   0,
 		},
@@ -16,7 +16,9 @@ isort:skip_file
  limitations under the License.
 """
 import builtins
+import collections.abc
 import google.protobuf.descriptor
+import google.protobuf.internal.containers
 import google.protobuf.internal.enum_type_wrapper
 import google.protobuf.message
 import sys
@@ -50,8 +52,10 @@ class _DistroEnumTypeWrapper(google.protobuf.internal.enum_type_wrapper._EnumTyp
     ORACLE: _Distro.ValueType  # 4004
     ROCKY: _Distro.ValueType  # 4005
     CENTOS_STREAM: _Distro.ValueType  # 4006
+    ALMALINUX: _Distro.ValueType  # 4007
     ARCH: _Distro.ValueType  # 5000
     CLEAR: _Distro.ValueType  # 6000
+    FREEBSD: _Distro.ValueType  # 7000
 
 class Distro(_Distro, metaclass=_DistroEnumTypeWrapper):
     """Distro denotes a product line of operating systems, using the following
@@ -84,8 +88,10 @@ AMAZON: Distro.ValueType  # 4003
 ORACLE: Distro.ValueType  # 4004
 ROCKY: Distro.ValueType  # 4005
 CENTOS_STREAM: Distro.ValueType  # 4006
+ALMALINUX: Distro.ValueType  # 4007
 ARCH: Distro.ValueType  # 5000
 CLEAR: Distro.ValueType  # 6000
+FREEBSD: Distro.ValueType  # 7000
 global___Distro = Distro
 
 class _Architecture:
@@ -117,6 +123,7 @@ class OsRelease(google.protobuf.message.Message):
     MINOR_VERSION_FIELD_NUMBER: builtins.int
     ARCHITECTURE_FIELD_NUMBER: builtins.int
     DISTRO_ID_FIELD_NUMBER: builtins.int
+    BUILD_NUMBER_FIELD_NUMBER: builtins.int
     cli_formatted: builtins.str
     """cli_formatted is a concatenation of distro, major_version, and
     minor_version using the format expected by the `--os` flag.
@@ -146,6 +153,13 @@ class OsRelease(google.protobuf.message.Message):
     """Enumerated representation of the distro. Prefer this for
     programmatic usage.
     """
+    build_number: builtins.int
+    """build_number of the OS, for vendors that distinguish releases
+    sharing a version by their build. Zero when unknown.
+    Examples:
+      - Windows 11 23H2: 22631
+      - Windows Server 2025: 26100
+    """
     def __init__(
         self,
         *,
@@ -155,11 +169,46 @@ class OsRelease(google.protobuf.message.Message):
         minor_version: builtins.str = ...,
         architecture: global___Architecture.ValueType = ...,
         distro_id: global___Distro.ValueType = ...,
+        build_number: builtins.int = ...,
     ) -> None: ...
-    def ClearField(self, field_name: typing_extensions.Literal["architecture", b"architecture", "cli_formatted", b"cli_formatted", "distro", b"distro", "distro_id", b"distro_id", "major_version", b"major_version", "minor_version", b"minor_version"]) -> None: ...
+    def ClearField(self, field_name: typing_extensions.Literal["architecture", b"architecture", "build_number", b"build_number", "cli_formatted", b"cli_formatted", "distro", b"distro", "distro_id", b"distro_id", "major_version", b"major_version", "minor_version", b"minor_version"]) -> None: ...
 
 global___OsRelease = OsRelease
 
+@typing_extensions.final
+class OsInstallation(google.protobuf.message.Message):
+    """OsInstallation describes one operating system found on the disk."""
+
+    DESCRIPTOR: google.protobuf.descriptor.Descriptor
+
+    ROOT_PARTITION_FIELD_NUMBER: builtins.int
+    OS_RELEASE_FIELD_NUMBER: builtins.int
+    BIOS_BOOTABLE_FIELD_NUMBER: builtins.int
+    UEFI_BOOTABLE_FIELD_NUMBER: builtins.int
+    root_partition: builtins.str
+    """root_partition is the block device that holds the root directory
+    ("/") of this installation, such as /dev/sda2.
+    """
+    @property
+    def os_release(self) -> global___OsRelease:
+        """The OS and version of this installation."""
+    bios_bootable: builtins.bool
+    """bios_bootable indicates whether this installation is bootable using bios."""
+    uefi_bootable: builtins.bool
+    """uefi_bootable indicates whether this installation is bootable with UEFI."""
+    def __init__(
+        self,
+        *,
+        root_partition: builtins.str = ...,
+        os_release: global___OsRelease | None = ...,
+        bios_bootable: builtins.bool = ...,
+        uefi_bootable: builtins.bool = ...,
+    ) -> None: ...
+    def HasField(self, field_name: typing_extensions.Literal["os_release", b"os_release"]) -> builtins.bool: ...
+    def ClearField(self, field_name: typing_extensions.Literal["bios_bootable", b"bios_bootable", "os_release", b"os_release", "root_partition", b"root_partition", "uefi_bootable", b"uefi_bootable"]) -> None: ...
+
+global___OsInstallation = OsInstallation
+
 @typing_extensions.final
 class InspectionResults(google.protobuf.message.Message):
     """InspectionResults contains metadata determined using automated inspection
@@ -200,6 +249,7 @@ class InspectionResults(google.protobuf.message.Message):
     ERROR_WHEN_FIELD_NUMBER: builtins.int
     ELAPSED_TIME_MS_FIELD_NUMBER: builtins.int
     OS_COUNT_FIELD_NUMBER: builtins.int
+    OS_INSTALLATIONS_FIELD_NUMBER: builtins.int
     @property
     def os_release(self) -> global___OsRelease:
         """The OS and version detected. Populated when a single OS is
@@ -226,6 +276,11 @@ class InspectionResults(google.protobuf.message.Message):
     """
     os_count: builtins.int
     """Number of operating systems detected on the disk."""
+    @property
+    def os_installations(self) -> google.protobuf.internal.containers.RepeatedCompositeFieldContainer[global___OsInstallation]:
+        """Every operating system detected on the disk. Populated even when
+        a single OS is detected.
+        """
     def __init__(
         self,
         *,
@@ -236,8 +291,9 @@ class InspectionResults(google.protobuf.message.Message):
         error_when: global___InspectionResults.ErrorWhen.ValueType = ...,
         elapsed_time_ms: builtins.int = ...,
         os_count: builtins.int = ...,
+        os_installations: collections.abc.Iterable[global___OsInstallation] | None = ...,
     ) -> None: ...
     def HasField(self, field_name: typing_extensions.Literal["os_release", b"os_release"]) -> builtins.bool: ...
-    def ClearField(self, field_name: typing_extensions.Literal["bios_bootable", b"bios_bootable", "elapsed_time_ms", b"elapsed_time_ms", "error_when", b"error_when", "os_count", b"os_count", "os_release", b"os_release", "root_fs", b"root_fs", "uefi_bootable", b"uefi_bootable"]) -> None: ...
+    def ClearField(self, field_name: typing_extensions.Literal["bios_bootable", b"bios_bootable", "elapsed_time_ms", b"elapsed_time_ms", "error_when", b"error_when", "os_count", b"os_count", "os_installations", b"os_installations", "os_release", b"os_release", "root_fs", b"root_fs", "uefi_bootable", b"uefi_bootable"]) -> None: ...
 
 global___InspectionResults = InspectionResults